@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"protoc-gen-go-dep/dep"
+)
+
+// audit holds the fields of a resource marked with the audit field option,
+// nil where it has none.
+type audit struct {
+	createdAt, updatedAt, createdBy, updatedBy *protogen.Field
+}
+
+// auditFields returns the audit fields of message, checkAudit reports the
+// ones that cannot hold what they record.
+func auditFields(message *protogen.Message) audit {
+	var a audit
+	for _, field := range message.Fields {
+		switch fieldOptions(field).Audit {
+		case dep.Audit_AUDIT_CREATED_AT:
+			a.createdAt = field
+		case dep.Audit_AUDIT_UPDATED_AT:
+			a.updatedAt = field
+		case dep.Audit_AUDIT_CREATED_BY:
+			a.createdBy = field
+		case dep.Audit_AUDIT_UPDATED_BY:
+			a.updatedBy = field
+		}
+	}
+	return a
+}
+
+// checkAudit fails on audit fields of the wrong type, and on a message
+// marking several fields for the same thing.
+func checkAudit(message *protogen.Message) error {
+	seen := make(map[dep.Audit]bool)
+	for _, field := range message.Fields {
+		kind := fieldOptions(field).Audit
+		if kind == dep.Audit_AUDIT_UNSPECIFIED {
+			continue
+		}
+		if seen[kind] {
+			return fmt.Errorf("%s: %s is marked on more than one field", message.Desc.FullName(), kind)
+		}
+		seen[kind] = true
+
+		singular := !field.Desc.IsList() && !field.Desc.IsMap() && field.Oneof == nil
+		switch kind {
+		case dep.Audit_AUDIT_CREATED_AT, dep.Audit_AUDIT_UPDATED_AT:
+			if !singular || field.Message == nil || field.Message.Desc.FullName() != timestampName {
+				return fmt.Errorf("%s: %s fields have to be a google.protobuf.Timestamp", field.Desc.FullName(), kind)
+			}
+		default:
+			if !singular || field.Desc.Kind() != protoreflect.StringKind || field.Desc.HasPresence() {
+				return fmt.Errorf("%s: %s fields have to be a plain string", field.Desc.FullName(), kind)
+			}
+		}
+	}
+	return nil
+}
+
+// created returns the fields recording the creation, which only Create
+// writes.
+func (a audit) created() []*protogen.Field {
+	return nonNil(a.createdAt, a.createdBy)
+}
+
+// updated returns the fields every write fills in.
+func (a audit) updated() []*protogen.Field {
+	return nonNil(a.updatedAt, a.updatedBy)
+}
+
+// any reports whether the resource has audit fields at all.
+func (a audit) any() bool {
+	return len(a.created())+len(a.updated()) > 0
+}
+
+func nonNil(fields ...*protogen.Field) []*protogen.Field {
+	var ret []*protogen.Field
+	for _, field := range fields {
+		if field != nil {
+			ret = append(ret, field)
+		}
+	}
+	return ret
+}
+
+// jsonNames are the keys of fields in the stored documents.
+func jsonNames(fields []*protogen.Field) []string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Desc.JSONName()
+	}
+	return names
+}
+
+// protoNames are the names of fields as field mask paths.
+func protoNames(fields []*protogen.Field) []string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = string(field.Desc.Name())
+	}
+	return names
+}
+
+// keepCreated is the SQLite expression storing the document in the given
+// parameter with the fields recording the creation taken from the stored
+// one, a missing member removes the key.
+func keepCreated(param string, a audit) string {
+	var members []string
+	for _, name := range jsonNames(a.created()) {
+		members = append(members, "'"+name+"', json_extract(data, '$."+name+"')")
+	}
+	return "json_patch(" + param + ", json_object(" + strings.Join(members, ", ") + "))"
+}
+
+// generateStampFunction emits stamp, filling in the audit fields of a
+// record before it is written.
+func (p *Generator) generateStampFunction(g *protogen.GeneratedFile, message *protogen.Message, a audit) {
+	g.P("// stamp fills in the audit fields of x from the time and the actor of ctx. The ones")
+	g.P("// recording the creation are cleared unless created is set, the writes after")
+	g.P("// Create keep them as stored.")
+	g.P("func (x *", message.GoIdent, ") stamp(ctx ", contextPackage.Ident("Context"), ", created bool) {")
+	if a.createdAt != nil || a.updatedAt != nil {
+		g.P("   now := ", timePackage.Ident("Now"), "()")
+	}
+	if a.createdBy != nil || a.updatedBy != nil {
+		g.P("   actor := ", depPackage.Ident("Actor"), "(ctx)")
+	}
+	if a.updatedAt != nil {
+		g.P("   x.", a.updatedAt.GoName, " = ", timestamppbPackage.Ident("New"), "(now)")
+	}
+	if a.updatedBy != nil {
+		g.P("   x.", a.updatedBy.GoName, " = actor")
+	}
+	if len(a.created()) > 0 {
+		g.P("   if created {")
+		if a.createdAt != nil {
+			g.P("       x.", a.createdAt.GoName, " = ", timestamppbPackage.Ident("New"), "(now)")
+		}
+		if a.createdBy != nil {
+			g.P("       x.", a.createdBy.GoName, " = actor")
+		}
+		g.P("   } else {")
+		if a.createdAt != nil {
+			g.P("       x.", a.createdAt.GoName, " = nil")
+		}
+		if a.createdBy != nil {
+			g.P(`       x.`, a.createdBy.GoName, ` = ""`)
+		}
+		g.P("   }")
+	}
+	g.P("}")
+	g.P("")
+}
+
+// readsBack reports whether the Update of a resource reads the object it
+// stored back, for its revision or for the audit fields it kept.
+func readsBack(message *protogen.Message, opts *dep.DepMessageOptions) bool {
+	return opts.History || hasOperation(opts, dep.Operation_OPERATION_UPDATE) && len(auditFields(message).created()) > 0
+}
+
+// generateKeptFields emits copying the audit fields recording the creation
+// from current, the object an update read back, into data.
+func generateKeptFields(g *protogen.GeneratedFile, a audit) {
+	for _, field := range a.created() {
+		g.P("   data.", field.GoName, " = current.", field.GoName)
+	}
+}
+
+// generateStampPaths emits the statement restricting the paths of a patch
+// to the ones clients may write, plus the updated audit fields.
+func generateStampPaths(g *protogen.GeneratedFile, a audit) {
+	g.P("   paths = ", depPackage.Ident("StampPaths"), "(paths, ", stringSlice(protoNames(a.created())), ", ", stringSlice(protoNames(a.updated())), ")")
+}
+
+// stringSlice is the Go literal of names, nil when there are none.
+func stringSlice(names []string) string {
+	if len(names) == 0 {
+		return "nil"
+	}
+	return "[]string{" + quoteAll(names) + "}"
+}
+
+// keepData is the call of the keep_data routine storing the document in the
+// given parameter with the fields recording the creation taken from the
+// stored one, the tenant, table and id are the first three parameters.
+func keepData(param string, a audit) string {
+	return "keep_data($1, $2, $3, " + param + ", '{" + strings.Join(jsonNames(a.created()), ",") + "}')"
+}
+
+// generateKeepRoutine emits keep_data, which the routines updates of audited
+// documents pass their document through.
+func generateKeepRoutine(s *protogen.GeneratedFile) {
+	s.P("")
+	s.P("-- keep_data returns p_data with the keys in p_keep as the stored row has them,")
+	s.P("-- so an update cannot change them. Keys the row lacks are removed.")
+	s.P("CREATE OR REPLACE FUNCTION keep_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_data JSONB, p_keep TEXT[])")
+	s.P("RETURNS JSONB")
+	s.P("LANGUAGE plpgsql STABLE AS $$")
+	s.P("DECLARE")
+	s.P("    v_kept JSONB;")
+	s.P("BEGIN")
+	s.P("    EXECUTE format('SELECT jsonb_object_agg(k, data -> k) FROM %I, unnest($3) AS k WHERE tenant = $1 AND id = $2', p_table)")
+	s.P("        INTO v_kept")
+	s.P("        USING p_tenant, p_id, p_keep;")
+	s.P("    RETURN jsonb_merge_patch(p_data, COALESCE(v_kept, '{}'));")
+	s.P("END")
+	s.P("$$;")
+}
@@ -0,0 +1,567 @@
+package main
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// column is a field of a resource stored with STORAGE_COLUMNS.
+type column struct {
+	field *protogen.Field
+	// name is the column name, quoted where needed.
+	name    string
+	sqlType string
+}
+
+// storageColumns returns the columns backing message in dialect, one per
+// field in field order.
+func storageColumns(message *protogen.Message, dialect string) []column {
+	columns := make([]column, 0, len(message.Fields))
+	for _, field := range message.Fields {
+		columns = append(columns, column{
+			field:   field,
+			name:    sqlIdent(fieldOptions(field).Column),
+			sqlType: columnType(field, dialect),
+		})
+	}
+	return columns
+}
+
+// columnType maps field to the type of its column. Nested messages and
+// maps are kept as JSON, repeated scalars as Postgres arrays. SQLite has
+// neither, it keeps both as text.
+func columnType(field *protogen.Field, dialect string) string {
+	json, array := "JSONB", "[]"
+	if dialect == dialectSQLite {
+		json, array = "TEXT", ""
+	}
+
+	if field.Desc.IsMap() {
+		return json + " NOT NULL"
+	}
+	if field.Desc.IsList() {
+		if field.Message != nil {
+			return json + " NOT NULL"
+		}
+		if dialect == dialectSQLite {
+			return "TEXT NOT NULL"
+		}
+		return scalarColumnType(field.Desc.Kind(), dialect) + array + " NOT NULL"
+	}
+	if field.Message != nil {
+		if field.Message.Desc.FullName() == timestampName {
+			if dialect == dialectSQLite {
+				return "DATETIME"
+			}
+			return "TIMESTAMPTZ"
+		}
+		return json
+	}
+	// Empty bytes are stored as NULL.
+	if field.Desc.HasPresence() || field.Desc.Kind() == protoreflect.BytesKind {
+		return scalarColumnType(field.Desc.Kind(), dialect)
+	}
+	return scalarColumnType(field.Desc.Kind(), dialect) + " NOT NULL"
+}
+
+func scalarColumnType(kind protoreflect.Kind, dialect string) string {
+	if dialect == dialectSQLite {
+		switch kind {
+		case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+			// Past the int64 range of INTEGER, kept as decimal text.
+			return "TEXT"
+		case protoreflect.BoolKind:
+			return "BOOLEAN"
+		case protoreflect.FloatKind, protoreflect.DoubleKind:
+			return "REAL"
+		case protoreflect.BytesKind:
+			return "BLOB"
+		case protoreflect.StringKind, protoreflect.EnumKind:
+			return "TEXT"
+		}
+		return "INTEGER"
+	}
+
+	switch kind {
+	case protoreflect.BoolKind:
+		return "BOOLEAN"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "INTEGER"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "BIGINT"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "NUMERIC(20,0)"
+	case protoreflect.FloatKind:
+		return "REAL"
+	case protoreflect.DoubleKind:
+		return "DOUBLE PRECISION"
+	case protoreflect.BytesKind:
+		return "BYTEA"
+	}
+	// Strings, and enums by value name.
+	return "TEXT"
+}
+
+// scalarGoType is the Go type protoc-gen-go uses for a singular field.
+func scalarGoType(g *protogen.GeneratedFile, field *protogen.Field) string {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return "bool"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "int32"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "int64"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "uint32"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "uint64"
+	case protoreflect.FloatKind:
+		return "float32"
+	case protoreflect.DoubleKind:
+		return "float64"
+	case protoreflect.BytesKind:
+		return "[]byte"
+	case protoreflect.EnumKind:
+		return g.QualifiedGoIdent(field.Enum.GoIdent)
+	}
+	return "string"
+}
+
+// columnList joins the names of columns for a SELECT or INSERT.
+func columnList(columns []column) string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// generateColumnValues emits columnValues, the arguments that store x in
+// the columns, and scanColumns that reads them back.
+func (p *Generator) generateColumnValues(g *protogen.GeneratedFile, message *protogen.Message) {
+	columns := storageColumns(message, p.dialect)
+
+	g.P("// columnValues returns the values of the columns backing x in field order")
+	g.P("func (x *", message.GoIdent, ") columnValues() ([]any, error) {")
+	g.P("   values := make([]any, 0, ", len(columns), ")")
+	for _, c := range columns {
+		p.generateColumnValue(g, c.field)
+	}
+	g.P("")
+	g.P("   return values, nil")
+	g.P("}")
+	g.P("")
+
+	g.P("// scanColumns scans a row holding dest followed by the columns backing x,")
+	g.P("// replacing what x held")
+	g.P("func (x *", message.GoIdent, ") scanColumns(row interface{ Scan(...any) error }, dest ...any) error {")
+	g.P("   ", protoPackage.Ident("Reset"), "(x)")
+	var temps []string
+	for _, c := range columns {
+		if t := columnTemp(g, c.field); t != "" {
+			temps = append(temps, columnTempName(c.field)+" "+t)
+		}
+	}
+	if len(temps) > 0 {
+		g.P("   var (")
+		for _, t := range temps {
+			g.P("       ", t)
+		}
+		g.P("   )")
+	}
+	g.P("   dest = append(dest,")
+	for _, c := range columns {
+		g.P("       ", p.columnScanTarget(g, c.field), ",")
+	}
+	g.P("   )")
+	g.P("   if err := row.Scan(dest...); err != nil {")
+	g.P("       return err")
+	g.P("   }")
+	for _, c := range columns {
+		generateColumnAssign(g, c.field)
+	}
+	g.P("")
+	g.P("   return nil")
+	g.P("}")
+	g.P("")
+}
+
+// isUint64 reports whether field holds 64 bit unsigned integers, which
+// database/sql refuses past the int64 range. Their columns are written and
+// scanned as decimal text.
+func isUint64(field *protogen.Field) bool {
+	switch field.Desc.Kind() {
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return true
+	}
+	return false
+}
+
+// isOneofMember reports whether field belongs to a oneof written in the
+// proto, as opposed to the synthetic oneof of a proto3 optional field.
+func isOneofMember(field *protogen.Field) bool {
+	return field.Oneof != nil && !field.Oneof.Desc.IsSynthetic()
+}
+
+// columnTempName is the variable scanColumns reads a column into before
+// it is converted to the field.
+func columnTempName(field *protogen.Field) string {
+	return lowerFirst(field.GoName) + "Column"
+}
+
+// columnTemp is the type of the variable a column is scanned into, empty
+// when it is scanned straight into the field.
+func columnTemp(g *protogen.GeneratedFile, field *protogen.Field) string {
+	switch {
+	case field.Desc.IsMap(), field.Desc.IsList() && field.Message != nil:
+		return "[]byte"
+	case field.Desc.IsList() && field.Enum != nil:
+		return "[]string"
+	case field.Desc.IsList():
+		return ""
+	case field.Message != nil && field.Message.Desc.FullName() == timestampName:
+		return "*" + g.QualifiedGoIdent(timePackage.Ident("Time"))
+	case field.Message != nil:
+		return "[]byte"
+	case field.Enum != nil && field.Desc.HasPresence():
+		return "*string"
+	case field.Enum != nil:
+		return "string"
+	case isUint64(field) && field.Desc.HasPresence():
+		return "*string"
+	case isUint64(field):
+		return "string"
+	case isOneofMember(field) && field.Desc.Kind() == protoreflect.BytesKind:
+		return "[]byte"
+	case isOneofMember(field):
+		return "*" + scalarGoType(g, field)
+	}
+	return ""
+}
+
+// columnScanTarget is what scanColumns passes to Scan for field. pgx
+// handles arrays itself, database/sql needs dep.Array.
+func (p *Generator) columnScanTarget(g *protogen.GeneratedFile, field *protogen.Field) string {
+	switch {
+	case p.driver == driverPgx && field.Desc.IsList() && field.Enum != nil:
+		return "&" + columnTempName(field)
+	case p.driver == driverPgx && field.Desc.IsList() && field.Message == nil && !field.Desc.IsMap():
+		return "&x." + field.GoName
+	case field.Desc.IsList() && field.Enum != nil:
+		return g.QualifiedGoIdent(depPackage.Ident("Array")) + "(&" + columnTempName(field) + ")"
+	case columnTemp(g, field) != "":
+		return "&" + columnTempName(field)
+	case field.Desc.IsList():
+		return g.QualifiedGoIdent(depPackage.Ident("Array")) + "(&x." + field.GoName + ")"
+	}
+	return "&x." + field.GoName
+}
+
+// columnIsJSON reports whether field is kept in a JSONB column.
+func columnIsJSON(field *protogen.Field) bool {
+	return field.Desc.IsMap() || (field.Message != nil && field.Message.Desc.FullName() != timestampName)
+}
+
+// generateColumnAssign emits the conversion from the temp of field, if it
+// has one, to the field itself.
+func generateColumnAssign(g *protogen.GeneratedFile, field *protogen.Field) {
+	temp := columnTempName(field)
+	set := func(value string) string {
+		if isOneofMember(field) {
+			return "x." + field.Oneof.GoName + " = &" + g.QualifiedGoIdent(field.GoIdent) + "{" + field.GoName + ": " + value + "}"
+		}
+		return "x." + field.GoName + " = " + value
+	}
+	if columnTemp(g, field) == "" {
+		return
+	}
+
+	switch {
+	case field.Desc.IsMap() && (mapValue(field).Message != nil || mapValue(field).Enum != nil):
+		item := g.QualifiedGoIdent(jsonPackage.Ident("RawMessage"))
+		if mapValue(field).Enum != nil {
+			item = "string"
+		}
+		g.P("   var ", temp, "Items map[", mapKeyType(g, field), "]", item)
+		g.P("   if err := ", jsonPackage.Ident("Unmarshal"), "(", temp, ", &", temp, "Items); err != nil {")
+		g.P("       return err")
+		g.P("   }")
+		g.P("   x.", field.GoName, " = make(map[", mapKeyType(g, field), "]", mapValueType(g, field), ", len(", temp, "Items))")
+		g.P("   for k, item := range ", temp, "Items {")
+		if value := mapValue(field); value.Enum != nil {
+			g.P("       n, err := ", depPackage.Ident("EnumNumber"), "(", enumValues(value), ", item)")
+			g.P("       if err != nil {")
+			g.P("           return err")
+			g.P("       }")
+			g.P("       x.", field.GoName, "[k] = ", value.Enum.GoIdent, "(n)")
+		} else {
+			g.P("       m := new(", value.Message.GoIdent, ")")
+			g.P("       if err := ", protojsonPackage.Ident("Unmarshal"), "(item, m); err != nil {")
+			g.P("           return err")
+			g.P("       }")
+			g.P("       x.", field.GoName, "[k] = m")
+		}
+		g.P("   }")
+
+	case field.Desc.IsMap():
+		g.P("   if err := ", jsonPackage.Ident("Unmarshal"), "(", temp, ", &x.", field.GoName, "); err != nil {")
+		g.P("       return err")
+		g.P("   }")
+
+	case field.Desc.IsList() && field.Message != nil:
+		g.P("   var ", temp, "Items []", jsonPackage.Ident("RawMessage"))
+		g.P("   if err := ", jsonPackage.Ident("Unmarshal"), "(", temp, ", &", temp, "Items); err != nil {")
+		g.P("       return err")
+		g.P("   }")
+		g.P("   x.", field.GoName, " = nil")
+		g.P("   for _, item := range ", temp, "Items {")
+		g.P("       m := new(", field.Message.GoIdent, ")")
+		g.P("       if err := ", protojsonPackage.Ident("Unmarshal"), "(item, m); err != nil {")
+		g.P("           return err")
+		g.P("       }")
+		g.P("       x.", field.GoName, " = append(x.", field.GoName, ", m)")
+		g.P("   }")
+
+	case field.Desc.IsList():
+		g.P("   x.", field.GoName, " = nil")
+		g.P("   for _, name := range ", temp, " {")
+		g.P("       n, err := ", depPackage.Ident("EnumNumber"), "(", enumValues(field), ", name)")
+		g.P("       if err != nil {")
+		g.P("           return err")
+		g.P("       }")
+		g.P("       x.", field.GoName, " = append(x.", field.GoName, ", ", field.Enum.GoIdent, "(n))")
+		g.P("   }")
+
+	case field.Message != nil && field.Message.Desc.FullName() == timestampName:
+		g.P("   if ", temp, " != nil {")
+		g.P("       ", set(g.QualifiedGoIdent(timestamppbPackage.Ident("New"))+"(*"+temp+")"))
+		g.P("   }")
+
+	case columnIsJSON(field):
+		g.P("   if ", temp, " != nil {")
+		g.P("       m := new(", field.Message.GoIdent, ")")
+		g.P("       if err := ", protojsonPackage.Ident("Unmarshal"), "(", temp, ", m); err != nil {")
+		g.P("           return err")
+		g.P("       }")
+		g.P("       ", set("m"))
+		g.P("   }")
+
+	case field.Enum != nil && field.Desc.HasPresence():
+		g.P("   if ", temp, " != nil {")
+		g.P("       n, err := ", depPackage.Ident("EnumNumber"), "(", enumValues(field), ", *", temp, ")")
+		g.P("       if err != nil {")
+		g.P("           return err")
+		g.P("       }")
+		g.P("       v := ", field.Enum.GoIdent, "(n)")
+		if isOneofMember(field) {
+			g.P("       ", set("v"))
+		} else {
+			g.P("       ", set("&v"))
+		}
+		g.P("   }")
+
+	case field.Enum != nil:
+		g.P("   if n, err := ", depPackage.Ident("EnumNumber"), "(", enumValues(field), ", ", temp, "); err != nil {")
+		g.P("       return err")
+		g.P("   } else {")
+		g.P("       ", set(g.QualifiedGoIdent(field.Enum.GoIdent)+"(n)"))
+		g.P("   }")
+
+	case isUint64(field) && field.Desc.HasPresence():
+		g.P("   if ", temp, " != nil {")
+		g.P("       v, err := ", strconvPackage.Ident("ParseUint"), "(*", temp, ", 10, 64)")
+		g.P("       if err != nil {")
+		g.P("           return err")
+		g.P("       }")
+		if isOneofMember(field) {
+			g.P("       ", set("v"))
+		} else {
+			g.P("       ", set("&v"))
+		}
+		g.P("   }")
+
+	case isUint64(field):
+		g.P("   if v, err := ", strconvPackage.Ident("ParseUint"), "(", temp, ", 10, 64); err != nil {")
+		g.P("       return err")
+		g.P("   } else {")
+		g.P("       ", set("v"))
+		g.P("   }")
+
+	case field.Desc.Kind() == protoreflect.BytesKind:
+		g.P("   if ", temp, " != nil {")
+		g.P("       ", set(temp))
+		g.P("   }")
+
+	default:
+		g.P("   if ", temp, " != nil {")
+		g.P("       ", set("*"+temp))
+		g.P("   }")
+	}
+}
+
+// mapValue is the value field of the entries of a map field.
+func mapValue(field *protogen.Field) *protogen.Field {
+	return field.Message.Fields[1]
+}
+
+// mapKeyType is the Go type of the keys of a map field.
+func mapKeyType(g *protogen.GeneratedFile, field *protogen.Field) string {
+	return scalarGoType(g, field.Message.Fields[0])
+}
+
+// mapValueType is the Go type of the values of a map field.
+func mapValueType(g *protogen.GeneratedFile, field *protogen.Field) string {
+	if value := mapValue(field); value.Message != nil {
+		return "*" + g.QualifiedGoIdent(value.Message.GoIdent)
+	}
+	return scalarGoType(g, mapValue(field))
+}
+
+// enumValues is the name to number map protoc-gen-go generates for the enum
+// of field.
+func enumValues(field *protogen.Field) protogen.GoIdent {
+	return protogen.GoIdent{
+		GoName:       field.Enum.GoIdent.GoName + "_value",
+		GoImportPath: field.Enum.GoIdent.GoImportPath,
+	}
+}
+
+// generateColumnValue emits appending the column value of field to values.
+func (p *Generator) generateColumnValue(g *protogen.GeneratedFile, field *protogen.Field) {
+	array := func(slice string) string {
+		if p.driver == driverPgx {
+			return slice
+		}
+		return g.QualifiedGoIdent(depPackage.Ident("Array")) + "(&" + slice + ")"
+	}
+	switch {
+	case field.Desc.IsMap() && mapValue(field).Message != nil:
+		g.P("   {")
+		g.P("       items := make(map[", mapKeyType(g, field), "]", jsonPackage.Ident("RawMessage"), ", len(x.", field.GoName, "))")
+		g.P("       for k, m := range x.", field.GoName, " {")
+		g.P("           b, err := ", protojsonPackage.Ident("Marshal"), "(m)")
+		g.P("           if err != nil {")
+		g.P("               return nil, err")
+		g.P("           }")
+		g.P("           items[k] = b")
+		g.P("       }")
+		g.P("       b, err := ", jsonPackage.Ident("Marshal"), "(items)")
+		g.P("       if err != nil {")
+		g.P("           return nil, err")
+		g.P("       }")
+		g.P("       values = append(values, string(b))")
+		g.P("   }")
+
+	case field.Desc.IsMap() && mapValue(field).Enum != nil:
+		g.P("   {")
+		g.P("       names := make(map[", mapKeyType(g, field), "]string, len(x.", field.GoName, "))")
+		g.P("       for k, v := range x.", field.GoName, " {")
+		g.P("           names[k] = v.String()")
+		g.P("       }")
+		g.P("       b, err := ", jsonPackage.Ident("Marshal"), "(names)")
+		g.P("       if err != nil {")
+		g.P("           return nil, err")
+		g.P("       }")
+		g.P("       values = append(values, string(b))")
+		g.P("   }")
+
+	case field.Desc.IsMap():
+		g.P("   if b, err := ", jsonPackage.Ident("Marshal"), "(x.", field.GoName, "); err != nil {")
+		g.P("       return nil, err")
+		g.P("   } else {")
+		g.P("       values = append(values, string(b))")
+		g.P("   }")
+
+	case field.Desc.IsList() && field.Message != nil:
+		g.P("   {")
+		g.P("       items := make([]", jsonPackage.Ident("RawMessage"), ", 0, len(x.", field.GoName, "))")
+		g.P("       for _, m := range x.", field.GoName, " {")
+		g.P("           b, err := ", protojsonPackage.Ident("Marshal"), "(m)")
+		g.P("           if err != nil {")
+		g.P("               return nil, err")
+		g.P("           }")
+		g.P("           items = append(items, b)")
+		g.P("       }")
+		g.P("       b, err := ", jsonPackage.Ident("Marshal"), "(items)")
+		g.P("       if err != nil {")
+		g.P("           return nil, err")
+		g.P("       }")
+		g.P("       values = append(values, string(b))")
+		g.P("   }")
+
+	case field.Desc.IsList() && field.Enum != nil:
+		g.P("   {")
+		g.P("       names := make([]string, len(x.", field.GoName, "))")
+		g.P("       for i, v := range x.", field.GoName, " {")
+		g.P("           names[i] = v.String()")
+		g.P("       }")
+		g.P("       values = append(values, ", array("names"), ")")
+		g.P("   }")
+
+	case field.Desc.IsList():
+		g.P("   values = append(values, ", array("x."+field.GoName), ")")
+
+	case field.Message != nil && field.Message.Desc.FullName() == timestampName:
+		g.P("   if t := x.Get", field.GoName, "(); t != nil {")
+		if p.dialect == dialectSQLite {
+			g.P("       values = append(values, t.AsTime().Format(", depPackage.Ident("SQLiteTime"), "))")
+		} else {
+			g.P("       values = append(values, t.AsTime())")
+		}
+		g.P("   } else {")
+		g.P("       values = append(values, nil)")
+		g.P("   }")
+
+	case field.Message != nil:
+		g.P("   if m := x.Get", field.GoName, "(); m != nil {")
+		g.P("       b, err := ", protojsonPackage.Ident("Marshal"), "(m)")
+		g.P("       if err != nil {")
+		g.P("           return nil, err")
+		g.P("       }")
+		g.P("       values = append(values, string(b))")
+		g.P("   } else {")
+		g.P("       values = append(values, nil)")
+		g.P("   }")
+
+	case isOneofMember(field):
+		value := "v." + field.GoName
+		switch {
+		case field.Enum != nil:
+			value += ".String()"
+		case isUint64(field):
+			value = g.QualifiedGoIdent(strconvPackage.Ident("FormatUint")) + "(" + value + ", 10)"
+		}
+		g.P("   if v, ok := x.", field.Oneof.GoName, ".(*", field.GoIdent, "); ok {")
+		g.P("       values = append(values, ", value, ")")
+		g.P("   } else {")
+		g.P("       values = append(values, nil)")
+		g.P("   }")
+
+	case field.Enum != nil && field.Desc.HasPresence():
+		g.P("   if x.", field.GoName, " != nil {")
+		g.P("       values = append(values, x.", field.GoName, ".String())")
+		g.P("   } else {")
+		g.P("       values = append(values, nil)")
+		g.P("   }")
+
+	case field.Enum != nil:
+		g.P("   values = append(values, x.", field.GoName, ".String())")
+
+	case isUint64(field) && field.Desc.HasPresence():
+		g.P("   if x.", field.GoName, " != nil {")
+		g.P("       values = append(values, ", strconvPackage.Ident("FormatUint"), "(*x.", field.GoName, ", 10))")
+		g.P("   } else {")
+		g.P("       values = append(values, nil)")
+		g.P("   }")
+
+	case isUint64(field):
+		g.P("   values = append(values, ", strconvPackage.Ident("FormatUint"), "(x.", field.GoName, ", 10))")
+
+	default:
+		// Optional fields are pointers, database/sql stores nil as NULL.
+		g.P("   values = append(values, x.", field.GoName, ")")
+	}
+}
@@ -0,0 +1,481 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"protoc-gen-go-dep/dep"
+)
+
+// timestampName is the well known type forms read from date inputs.
+const timestampName = protoreflect.FullName("google.protobuf.Timestamp")
+
+// timestampLayouts are tried in order when parsing a Timestamp form value,
+// covering datetime-local inputs with and without seconds and date inputs.
+var timestampLayouts = []string{"2006-01-02T15:04:05Z07:00", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"}
+
+func (p *Generator) generateFormHandler(g *protogen.GeneratedFile, message *protogen.Message) {
+	g.P("// A simple function to handle a htmx form and populate the struct, returning")
+	g.P("// the mask of the fields the form submitted. Values that fail to parse are")
+	g.P("// collected per field before the submitted fields are validated.")
+	g.P("func (x *", message.GoIdent, ") HandleForm(req *", httpPackage.Ident("Request"), ") (*", fieldmaskpbPackage.Ident("FieldMask"), ", error) {")
+	if hasBytesField(message, make(map[protoreflect.FullName]bool)) {
+		g.P("   if err := req.ParseMultipartForm(32 << 20); err != nil && !", errorsPackage.Ident("Is"), "(err, ", httpPackage.Ident("ErrNotMultipart"), ") {")
+	} else {
+		g.P("   if err := req.ParseForm(); err != nil {")
+	}
+	g.P("       return nil, err")
+	g.P("   }")
+	g.P("")
+	g.P("   errs := make(", depPackage.Ident("ValidationErrors"), ")")
+	generateFormFields(g, message, "x", string(message.Desc.Name())+"__", "", map[protoreflect.FullName]bool{message.Desc.FullName(): true})
+	g.P("   if err := errs.Err(); err != nil {")
+	g.P("       return nil, err")
+	g.P("   }")
+	g.P("")
+	generateFormMask(g, message, string(message.Desc.Name())+"__")
+	g.P("   return mask, ", depPackage.Ident("MaskedErrors"), "(x.Validate(), mask.Paths)")
+	g.P("}")
+	g.P("")
+}
+
+// generateFormMask emits the mask of the top level fields the form has inputs
+// for, an input left empty clears its field. File inputs only count with an
+// upload and password inputs when filled in, so a stored file or password is
+// kept when none is given.
+func generateFormMask(g *protogen.GeneratedFile, message *protogen.Message, prefix string) {
+	g.P("   mask := new(", fieldmaskpbPackage.Ident("FieldMask"), ")")
+	for _, field := range message.Fields {
+		fieldOpts := fieldOptions(field)
+		if fieldOpts.ReadOnly || fieldOpts.Hidden || field.Desc.IsMap() {
+			continue
+		}
+		oneof := field.Oneof != nil && !field.Oneof.Desc.IsSynthetic()
+		if field.Message != nil && field.Message.Desc.FullName() != timestampName &&
+			(field.Desc.IsList() || oneof || field.Message.Desc.FullName() == message.Desc.FullName()) {
+			continue
+		}
+
+		name := strconv.Quote(prefix + field.GoName)
+		switch {
+		case field.Desc.Kind() == protoreflect.BytesKind && !field.Desc.IsList():
+			g.P("   if req.FormValue(", name, `) != "" || req.MultipartForm != nil && len(req.MultipartForm.File[`, name, "]) > 0 {")
+		case fieldOpts.Widget == dep.Widget_WIDGET_PASSWORD:
+			// Password inputs are rendered empty, leaving one empty keeps
+			// the stored password.
+			g.P("   if req.FormValue(", name, `) != "" {`)
+		default:
+			g.P("   if ", depPackage.Ident("FormHas"), "(req.Form, ", name, ") {")
+		}
+		g.P(`       mask.Paths = append(mask.Paths, "`, field.Desc.Name(), `")`)
+		g.P("   }")
+	}
+}
+
+// generateFormFields emits the parsing of every field of message into recv.
+// Nested messages are read from dotted input names, seen stops recursive
+// message types from nesting forever.
+func generateFormFields(g *protogen.GeneratedFile, message *protogen.Message, recv string, prefix string, keyPrefix string, seen map[protoreflect.FullName]bool) {
+	for _, field := range message.Fields {
+		fieldOpts := fieldOptions(field)
+		if fieldOpts.ReadOnly || fieldOpts.Hidden || field.Desc.IsMap() {
+			continue
+		}
+
+		name := strconv.Quote(prefix + field.GoName)
+		key := keyPrefix + string(field.Desc.Name())
+		oneof := field.Oneof != nil && !field.Oneof.Desc.IsSynthetic()
+
+		// assign stores the parsed value, which the parsers leave in value.
+		var assign string
+		switch {
+		case field.Desc.IsList():
+			assign = recv + "." + field.GoName + " = append(" + recv + "." + field.GoName + ", value)"
+		case oneof:
+			assign = recv + "." + field.Oneof.GoName + " = &" + g.QualifiedGoIdent(field.GoIdent) + "{" + field.GoName + ": value}"
+		case field.Desc.HasPresence() && field.Desc.Kind() != protoreflect.MessageKind:
+			assign = recv + "." + field.GoName + " = &value"
+		default:
+			assign = recv + "." + field.GoName + " = value"
+		}
+
+		switch {
+		case field.Desc.IsList():
+			if field.Desc.Kind() == protoreflect.MessageKind || field.Desc.Kind() == protoreflect.GroupKind {
+				continue
+			}
+			g.P("   if _, ok := req.Form[", name, "]; ok {")
+			g.P("       ", recv, ".", field.GoName, " = nil")
+			if fieldOpts.Widget == dep.Widget_WIDGET_TEXTAREA {
+				g.P("       // One item per line")
+				g.P("       for _, v := range ", stringsPackage.Ident("Split"), "(req.FormValue(", name, `), "\n") {`)
+				g.P("           v = ", stringsPackage.Ident("TrimSpace"), "(v)")
+				g.P(`           if v == "" {`)
+				g.P("               continue")
+				g.P("           }")
+			} else {
+				g.P("       for _, v := range req.Form[", name, "] {")
+			}
+			generateFormValue(g, field, key, assign)
+			g.P("       }")
+			g.P("   }")
+
+		case field.Desc.Kind() == protoreflect.MessageKind && field.Message.Desc.FullName() == timestampName:
+			g.P("   if v := req.FormValue(", name, `); v != "" {`)
+			g.P("       var parsed bool")
+			g.P("       for _, layout := range []string{", quoteAll(timestampLayouts), "} {")
+			g.P("           if t, err := ", timePackage.Ident("Parse"), "(layout, v); err == nil {")
+			g.P("               value := ", timestamppbPackage.Ident("New"), "(t)")
+			g.P("               ", assign)
+			g.P("               parsed = true")
+			g.P("               break")
+			g.P("           }")
+			g.P("       }")
+			g.P("       if !parsed {")
+			g.P(`           errs.Add("`, key, `", "must be a date")`)
+			g.P("       }")
+			g.P("   }")
+
+		case field.Desc.Kind() == protoreflect.MessageKind || field.Desc.Kind() == protoreflect.GroupKind:
+			if oneof || seen[field.Message.Desc.FullName()] {
+				continue
+			}
+			target := recv + "." + field.GoName
+			g.P("   for key := range req.Form {")
+			g.P("       if ", stringsPackage.Ident("HasPrefix"), "(key, ", strconv.Quote(prefix+field.GoName+"."), ") {")
+			g.P("           if ", target, " == nil {")
+			g.P("               ", target, " = new(", field.Message.GoIdent, ")")
+			g.P("           }")
+			g.P("           break")
+			g.P("       }")
+			g.P("   }")
+			g.P("   if ", target, " != nil {")
+			nested := make(map[protoreflect.FullName]bool, len(seen)+1)
+			for k := range seen {
+				nested[k] = true
+			}
+			nested[field.Message.Desc.FullName()] = true
+			generateFormFields(g, field.Message, target, prefix+field.GoName+".", key+".", nested)
+			g.P("   }")
+
+		case field.Desc.Kind() == protoreflect.BytesKind:
+			g.P("   if file, _, err := req.FormFile(", name, "); err == nil {")
+			g.P("       value, err := ", ioPackage.Ident("ReadAll"), "(file)")
+			g.P("       file.Close()")
+			g.P("       if err != nil {")
+			g.P(`           errs.Add("`, key, `", "could not be read")`)
+			g.P("       } else {")
+			g.P("           ", assign)
+			g.P("       }")
+			g.P("   } else if v := req.FormValue(", name, `); v != "" {`)
+			generateFormValue(g, field, key, assign)
+			g.P("   }")
+
+		case field.Desc.Kind() == protoreflect.BoolKind && !oneof && !field.Desc.HasPresence():
+			// Unchecked checkboxes are not submitted at all.
+			g.P("   {")
+			g.P("       v := req.FormValue(", name, ")")
+			generateFormValue(g, field, key, assign)
+			g.P("   }")
+
+		case field.Desc.Kind() == protoreflect.StringKind && !oneof && !field.Desc.HasPresence():
+			g.P("   ", recv, ".", field.GoName, " = req.FormValue(", name, ")")
+
+		default:
+			g.P("   if v := req.FormValue(", name, `); v != "" {`)
+			generateFormValue(g, field, key, assign)
+			g.P("   }")
+		}
+	}
+}
+
+// generateFormValue emits the parsing of the form value in v according to
+// the kind of field, running assign with the result in value.
+func generateFormValue(g *protogen.GeneratedFile, field *protogen.Field, key string, assign string) {
+	fail := func(msg string) {
+		g.P(`       errs.Add("`, key, `", "`, msg, `")`)
+	}
+	parseNumber := func(parse string, goType string, msg string) {
+		g.P("   if n, err := ", strconvPackage.Ident(parse), "; err != nil {")
+		fail(msg)
+		g.P("   } else {")
+		g.P("       value := ", goType, "(n)")
+		g.P("       ", assign)
+		g.P("   }")
+	}
+
+	switch field.Desc.Kind() {
+	case protoreflect.StringKind:
+		g.P("   value := v")
+		g.P("   ", assign)
+	case protoreflect.BoolKind:
+		g.P(`   value := v != "" && v != "false" && v != "off" && v != "0"`)
+		g.P("   ", assign)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		parseNumber("ParseInt(v, 10, 32)", "int32", "must be a whole number")
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		parseNumber("ParseInt(v, 10, 64)", "int64", "must be a whole number")
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		parseNumber("ParseUint(v, 10, 32)", "uint32", "must be a positive whole number")
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		parseNumber("ParseUint(v, 10, 64)", "uint64", "must be a positive whole number")
+	case protoreflect.FloatKind:
+		parseNumber("ParseFloat(v, 32)", "float32", "must be a number")
+	case protoreflect.DoubleKind:
+		parseNumber("ParseFloat(v, 64)", "float64", "must be a number")
+	case protoreflect.EnumKind:
+		values := protogen.GoIdent{
+			GoName:       field.Enum.GoIdent.GoName + "_value",
+			GoImportPath: field.Enum.GoIdent.GoImportPath,
+		}
+		names := protogen.GoIdent{
+			GoName:       field.Enum.GoIdent.GoName + "_name",
+			GoImportPath: field.Enum.GoIdent.GoImportPath,
+		}
+		g.P("   // Either the name or the number of a defined enum value")
+		g.P("   if n, ok := ", values, "[v]; ok {")
+		g.P("       value := ", field.Enum.GoIdent, "(n)")
+		g.P("       ", assign)
+		g.P("   } else if n, err := ", strconvPackage.Ident("ParseInt"), "(v, 10, 32); err == nil && ", names, `[int32(n)] != "" {`)
+		g.P("       value := ", field.Enum.GoIdent, "(n)")
+		g.P("       ", assign)
+		g.P("   } else {")
+		fail("must be one of the defined values")
+		g.P("   }")
+	case protoreflect.BytesKind:
+		g.P("   if value, err := ", base64Package.Ident("StdEncoding"), ".DecodeString(v); err != nil {")
+		fail("must be base64 encoded")
+		g.P("   } else {")
+		g.P("       ", assign)
+		g.P("   }")
+	}
+}
+
+// hasBytesField reports whether a form for message may carry file uploads.
+func hasBytesField(message *protogen.Message, seen map[protoreflect.FullName]bool) bool {
+	seen[message.Desc.FullName()] = true
+	for _, field := range message.Fields {
+		switch {
+		case field.Desc.IsMap():
+		case field.Desc.Kind() == protoreflect.BytesKind && !field.Desc.IsList():
+			return true
+		case field.Message != nil && !field.Desc.IsList() && !seen[field.Message.Desc.FullName()]:
+			if hasBytesField(field.Message, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Generator) generateFormTemplate(g *protogen.GeneratedFile, message *protogen.Message) {
+	templateName := lowerFirst(message.GoIdent.GoName) + "FormTemplate"
+
+	// The template is executed with a dep.FormData, the inputs show the
+	// errors keyed by the proto names of their fields.
+	g.P("var ", templateName, " = ", templatePackage.Ident("Must"), "(", templatePackage.Ident("New"), "(\"form\").Parse(`{{ with .Value }}")
+	generateFormInputs(g, message, string(message.Desc.Name())+"__", "", nil, map[protoreflect.FullName]bool{message.Desc.FullName(): true})
+	g.P("{{ end }}`))")
+	g.P("")
+	g.P("// RenderForm will take in a http writer and render a htmx form for the object")
+	g.P("func (x *", message.GoIdent, ") RenderForm(w ", httpPackage.Ident("ResponseWriter"), ") error {")
+	g.P("   return x.RenderFormErrors(w, nil)")
+	g.P("}")
+	g.P("")
+	g.P("// RenderFormErrors renders the htmx form for the object with errs shown next to")
+	g.P("// the inputs of their fields")
+	g.P("func (x *", message.GoIdent, ") RenderFormErrors(w ", httpPackage.Ident("ResponseWriter"), ", errs ", depPackage.Ident("ValidationErrors"), ") error {")
+	g.P("   return ", templateName, ".Execute(w, ", depPackage.Ident("FormData"), "{Value: x, Errors: errs})")
+	g.P("}")
+	g.P("")
+}
+
+// generateFormInputs emits the inputs for the fields of message, path holds
+// the Go field names leading to message from the rendered object and
+// keyPrefix the proto names its errors are keyed by.
+func generateFormInputs(g *protogen.GeneratedFile, message *protogen.Message, prefix string, keyPrefix string, path []string, seen map[protoreflect.FullName]bool) {
+	for _, field := range message.Fields {
+		fieldOpts := fieldOptions(field)
+		if fieldOpts.Hidden || field.Desc.IsMap() {
+			continue
+		}
+
+		name := prefix + field.GoName
+		fieldPath := append(append([]string(nil), path...), templateFieldName(field))
+		value := templateField(fieldPath, func(f string) string { return "{{ " + f + " }}" })
+		isTimestamp := field.Message != nil && field.Message.Desc.FullName() == timestampName
+
+		if field.Message != nil && !isTimestamp {
+			oneof := field.Oneof != nil && !field.Oneof.Desc.IsSynthetic()
+			if field.Desc.IsList() || oneof || seen[field.Message.Desc.FullName()] {
+				continue
+			}
+			nested := make(map[protoreflect.FullName]bool, len(seen)+1)
+			for k := range seen {
+				nested[k] = true
+			}
+			nested[field.Message.Desc.FullName()] = true
+
+			g.P("<fieldset>")
+			g.P("  <legend>", templateText(fieldOpts.Label), "</legend>")
+			generateFormInputs(g, field.Message, name+".", keyPrefix+string(field.Desc.Name())+".", fieldPath, nested)
+			g.P("</fieldset>")
+			continue
+		}
+
+		attrs := ""
+		if fieldOpts.Required {
+			attrs += " required"
+		}
+		if fieldOpts.ReadOnly {
+			attrs += " disabled"
+		}
+		if fieldOpts.Placeholder != "" {
+			attrs += ` placeholder="` + templateText(fieldOpts.Placeholder) + `"`
+		}
+		if field.Desc.Kind() == protoreflect.StringKind && !field.Desc.IsList() {
+			if fieldOpts.MinLen > 0 {
+				attrs += fmt.Sprintf(` minlength="%d"`, fieldOpts.MinLen)
+			}
+			if fieldOpts.MaxLen > 0 {
+				attrs += fmt.Sprintf(` maxlength="%d"`, fieldOpts.MaxLen)
+			}
+		}
+		if fieldOpts.Widget == dep.Widget_WIDGET_NUMBER {
+			if fieldOpts.Min != nil {
+				attrs += ` min="` + strconv.FormatFloat(fieldOpts.GetMin(), 'g', -1, 64) + `"`
+			}
+			if fieldOpts.Max != nil {
+				attrs += ` max="` + strconv.FormatFloat(fieldOpts.GetMax(), 'g', -1, 64) + `"`
+			}
+		}
+
+		switch {
+		case isTimestamp:
+			layout := timestampLayouts[1]
+			if fieldOpts.Widget == dep.Widget_WIDGET_DATE {
+				layout = timestampLayouts[3]
+			} else {
+				// Without a step the inputs drop the seconds of the value.
+				attrs += ` step="1"`
+			}
+			value = templateField(fieldPath, func(f string) string {
+				return "{{ with " + f + " }}{{ .AsTime.Format " + strconv.Quote(layout) + " }}{{ end }}"
+			})
+		case field.Desc.IsList():
+			value = templateField(fieldPath, func(f string) string {
+				return "{{ range $i, $v := " + f + " }}{{ if $i }}&#10;{{ end }}{{ $v }}{{ end }}"
+			})
+		case field.Desc.Kind() == protoreflect.BytesKind:
+			// Uploads are never rendered back into the form.
+			value = ""
+		}
+
+		if fieldOpts.Widget == dep.Widget_WIDGET_HIDDEN {
+			g.P(`<input type="hidden" name="`, name, `" value="`, value, `">`)
+			continue
+		}
+
+		g.P(`<label class="w-16">`)
+		g.P("  <span>", templateText(fieldOpts.Label), "</span>")
+		switch {
+		case fieldOpts.Widget == dep.Widget_WIDGET_TEXTAREA:
+			g.P(`  <textarea name="`, name, `"`, attrs, `>`, value, `</textarea>`)
+		case fieldOpts.Widget == dep.Widget_WIDGET_CHECKBOX:
+			checked := templateField(fieldPath, func(f string) string { return "{{ if " + f + " }} checked{{ end }}" })
+			g.P(`  <input type="checkbox" name="`, name, `" value="on"`, checked, attrs, `>`)
+			// Unchecked boxes are not submitted, the hidden input that follows
+			// is, so the field is part of the form either way.
+			g.P(`  <input type="hidden" name="`, name, `" value="off">`)
+		case fieldOpts.Widget == dep.Widget_WIDGET_SELECT && field.Enum != nil:
+			if field.Desc.IsList() {
+				attrs += " multiple"
+			}
+			g.P(`  <select name="`, name, `"`, attrs, `>`)
+			for _, enumValue := range field.Enum.Values {
+				valueName := string(enumValue.Desc.Name())
+				selected := templateField(fieldPath, func(f string) string {
+					if field.Desc.IsList() {
+						return `{{ range ` + f + ` }}{{ if eq (print .) "` + valueName + `" }} selected{{ end }}{{ end }}`
+					}
+					return `{{ if eq (print ` + f + `) "` + valueName + `" }} selected{{ end }}`
+				})
+				g.P(`    <option value="`, valueName, `"`, selected, `>`, valueName, `</option>`)
+			}
+			g.P(`  </select>`)
+		case field.Desc.Kind() == protoreflect.BytesKind:
+			g.P(`  <input type="file" name="`, name, `"`, attrs, `>`)
+		case fieldOpts.Widget == dep.Widget_WIDGET_PASSWORD:
+			// Stored passwords are never sent back to the client.
+			g.P(`  <input type="password" name="`, name, `"`, attrs, `>`)
+		default:
+			g.P(`  <input type="`, inputType(fieldOpts.Widget), `" name="`, name, `" value="`, value, `"`, attrs, `>`)
+		}
+		g.P(`  {{ with index $.Errors "`, keyPrefix, field.Desc.Name(), `" }}<small class="error">{{ . }}</small>{{ end }}`)
+		g.P("</label>")
+	}
+}
+
+// templateFieldName is how templates refer to field, oneof members and optional
+// fields are read through their getters so unset values render empty.
+func templateFieldName(field *protogen.Field) string {
+	if field.Oneof != nil {
+		return "Get" + field.GoName
+	}
+	return field.GoName
+}
+
+// templateField renders action for the last field of path, wrapped in the
+// with blocks that skip it when a message on the way there is nil.
+func templateField(path []string, action func(field string) string) string {
+	var b strings.Builder
+	for _, name := range path[:len(path)-1] {
+		b.WriteString("{{ with ." + name + " }}")
+	}
+	b.WriteString(action("." + path[len(path)-1]))
+	for range path[:len(path)-1] {
+		b.WriteString("{{ end }}")
+	}
+	return b.String()
+}
+
+// inputType maps a widget to the type attribute of its html input.
+func inputType(widget dep.Widget) string {
+	switch widget {
+	case dep.Widget_WIDGET_EMAIL:
+		return "email"
+	case dep.Widget_WIDGET_PASSWORD:
+		return "password"
+	case dep.Widget_WIDGET_NUMBER:
+		return "number"
+	case dep.Widget_WIDGET_DATE:
+		return "date"
+	case dep.Widget_WIDGET_DATETIME:
+		return "datetime-local"
+	case dep.Widget_WIDGET_URL:
+		return "url"
+	}
+	return "text"
+}
+
+// templateText escapes user supplied text for the raw string literal
+// holding a generated html template.
+func templateText(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, "`", "&#96;")
+	return strings.ReplaceAll(s, "{{", "&#123;&#123;")
+}
+
+// quoteAll renders strs as the elements of a Go string slice literal.
+func quoteAll(strs []string) string {
+	quoted := make([]string, len(strs))
+	for i, s := range strs {
+		quoted[i] = strconv.Quote(s)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"protoc-gen-go-dep/dep"
+)
+
+// historyTable is the name of the table holding the revisions of a
+// resource.
+func historyTable(opts *dep.DepMessageOptions) string {
+	return opts.Table + "_history"
+}
+
+// generateHistoryTable emits the table holding the revisions of a resource,
+// one row per write numbered from 1 for every record. The values are
+// protojson documents whatever the storage of the resource.
+func (p *Generator) generateHistoryTable(s *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	table := sqlIdent(historyTable(opts))
+
+	s.P("")
+	s.P("-- Revisions of ", sqlIdent(opts.Table), ", the values before and after every write.")
+	s.P("CREATE TABLE IF NOT EXISTS ", table, " (")
+	s.P("    tenant TEXT NOT NULL,")
+	s.P("    id ", p.idSQLType(message, opts), " NOT NULL,")
+	if p.dialect == dialectSQLite {
+		s.P("    revision INTEGER NOT NULL,")
+	} else {
+		s.P("    revision BIGINT NOT NULL,")
+	}
+	s.P("    operation TEXT NOT NULL,")
+	s.P("    actor TEXT NOT NULL,")
+	if p.dialect == dialectSQLite {
+		s.P("    changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,")
+		s.P("    old_data TEXT,")
+		s.P("    new_data TEXT,")
+	} else {
+		s.P("    changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,")
+		s.P("    old_data JSONB,")
+		s.P("    new_data JSONB,")
+	}
+	s.P("    PRIMARY KEY (tenant, id, revision)")
+	s.P(");")
+}
+
+// generateHistoryQueries emits the statements of the history of a resource
+// that does not go through the shared routines, into the const block of
+// generateQueries.
+func (p *Generator) generateHistoryQueries(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	table := sqlIdent(historyTable(opts))
+	prefix := lowerFirst(message.GoIdent.GoName)
+	tenantID := "tenant = " + p.placeholder(1) + " AND id = " + p.placeholder(2)
+
+	// The insert numbers the revision after the last one, whose value is the
+	// one before the write. It names the tenant and the id several times, SQLite
+	// takes numbered parameters for that.
+	insert := "INSERT INTO " + table + " (tenant, id, revision, operation, actor, old_data, new_data)" +
+		" SELECT $1, $2, COALESCE(MAX(revision), 0) + 1, $3, $4," +
+		" (SELECT new_data FROM " + table + " WHERE tenant = $1 AND id = $2 ORDER BY revision DESC LIMIT 1), $5::jsonb" +
+		" FROM " + table + " WHERE tenant = $1 AND id = $2"
+	if p.dialect == dialectSQLite {
+		insert = strings.ReplaceAll(strings.ReplaceAll(insert, "$", "?"), "::jsonb", "")
+	}
+	g.P("   ", prefix, "RevisionInsertQuery = ", strconv.Quote(insert))
+	g.P("   ", prefix, "HistoryQuery = ", strconv.Quote("SELECT revision, operation, actor, changed_at, old_data, new_data FROM "+table+" WHERE "+tenantID+" ORDER BY revision"))
+	g.P("   ", prefix, "RevisionQuery = ", strconv.Quote("SELECT new_data FROM "+table+" WHERE "+tenantID+" AND revision = "+p.placeholder(3)+" AND new_data IS NOT NULL"))
+}
+
+// generateHistoryRoutines emits the routines of resources keeping a history,
+// they are passed the table of the records and find the history next to it.
+func generateHistoryRoutines(s *protogen.GeneratedFile) {
+	s.P("")
+	s.P("-- Routines of resources keeping a history, <table>_history holds a revision")
+	s.P("-- for every write to a row of <table>.")
+	s.P("")
+	s.P("-- record_revision appends the revision of a write leaving the row at p_data,")
+	s.P("-- NULL when it is gone. The insert numbers it after the last revision, whose")
+	s.P("-- value is the one before. It is called in the transaction of the write, the")
+	s.P("-- lock the write holds on the row keeps others from taking the same number.")
+	s.P("CREATE OR REPLACE PROCEDURE record_revision(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_operation TEXT, p_actor TEXT, p_data JSONB)")
+	s.P("LANGUAGE plpgsql AS $$")
+	s.P("BEGIN")
+	s.P("    EXECUTE format('INSERT INTO %1$I (tenant, id, revision, operation, actor, old_data, new_data) SELECT $1, $2, COALESCE(MAX(revision), 0) + 1, $3, $4, (SELECT new_data FROM %1$I WHERE tenant = $1 AND id = $2 ORDER BY revision DESC LIMIT 1), $5 FROM %1$I WHERE tenant = $1 AND id = $2', p_table || '_history')")
+	s.P("        USING p_tenant, p_id, p_operation, p_actor, p_data;")
+	s.P("END")
+	s.P("$$;")
+	s.P("")
+	s.P("CREATE OR REPLACE FUNCTION list_revisions(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT)")
+	s.P("RETURNS TABLE (revision BIGINT, operation TEXT, actor TEXT, changed_at TIMESTAMPTZ, old_data JSONB, new_data JSONB)")
+	s.P("LANGUAGE plpgsql STABLE AS $$")
+	s.P("BEGIN")
+	s.P("    RETURN QUERY EXECUTE format('SELECT revision, operation, actor, changed_at, old_data, new_data FROM %I WHERE tenant = $1 AND id = $2 ORDER BY revision', p_table || '_history')")
+	s.P("        USING p_tenant, p_id;")
+	s.P("END")
+	s.P("$$;")
+}
+
+// generateRecordFunction emits record, which the writes of a resource keeping
+// a history call once they found the record, in the transaction they began.
+func (p *Generator) generateRecordFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	prefix := lowerFirst(message.GoIdent.GoName)
+
+	g.P("// record appends the revision of a write to the history of the object at the")
+	g.P("// given ID, value is the object as the write left it, nil when it is gone. It")
+	g.P("// has to run in the transaction of the write, whose lock on the row keeps")
+	g.P("// concurrent writes from taking the same revision")
+	g.P("func (x *", message.GoIdent, ") record(ctx ", contextPackage.Ident("Context"), ", db DBTX", tenantParam(opts), ", id ", idType(g, message, opts), ", operation string, value *", message.GoIdent, ") error {")
+	g.P("   data, err := ", depPackage.Ident("MarshalDocument"), "(value)")
+	g.P("   if err != nil {")
+	g.P("       return err")
+	g.P("   }")
+	g.P("")
+	if p.usesRoutines(opts) {
+		g.P(`   _, err = `, p.dbCall("Exec"), `"CALL record_revision($1, $2, $3`, p.idCast(message, opts), `, $4, $5, $6)",`)
+		g.P("       ", tenantArg(opts), ", x.TableName(), id, operation, ", depPackage.Ident("Actor"), "(ctx), data)")
+		g.P("   return err")
+		g.P("}")
+		g.P("")
+		return
+	}
+	g.P("   _, err = ", p.dbCall("Exec"), prefix, "RevisionInsertQuery, ", tenantArg(opts), ", id, operation, ", depPackage.Ident("Actor"), "(ctx), data)")
+	g.P("   return err")
+	g.P("}")
+	g.P("")
+}
+
+// generateHistoryFunctions emits History and GetAtRevision, reading back the
+// revisions record wrote.
+func (p *Generator) generateHistoryFunctions(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	prefix := lowerFirst(message.GoIdent.GoName)
+	revision := g.QualifiedGoIdent(depPackage.Ident("Revision")) + "[*" + g.QualifiedGoIdent(message.GoIdent) + "]"
+
+	g.P("// History function returns the revisions of the object at the given ID, oldest first")
+	g.P("func (x *", message.GoIdent, ") History(ctx ", contextPackage.Ident("Context"), ", db DBTX", tenantParam(opts), ", id ", idType(g, message, opts), ") ([]", revision, ", error) {")
+	if p.usesRoutines(opts) {
+		g.P(`   rows, err := `, p.dbCall("Query"), `"SELECT revision, operation, actor, changed_at, old_data, new_data FROM list_revisions($1, $2, $3`, p.idCast(message, opts), `)",`)
+		g.P("       ", tenantArg(opts), ", x.TableName(), id)")
+	} else {
+		g.P("   rows, err := ", p.dbCall("Query"), prefix, "HistoryQuery, ", tenantArg(opts), ", id)")
+	}
+	g.P("   if err != nil {")
+	g.P("       return nil, err")
+	g.P("   }")
+	g.P("")
+	g.P("   defer rows.Close()")
+	g.P("")
+	g.P("   var ret []", revision)
+	g.P("   for rows.Next() {")
+	g.P("       r, err := ", depPackage.Ident("ScanRevision"), "[*", message.GoIdent, "](rows)")
+	g.P("       if err != nil {")
+	g.P("           return nil, err")
+	g.P("       }")
+	g.P("       ret = append(ret, r)")
+	g.P("   }")
+	g.P("   return ret, rows.Err()")
+	g.P("}")
+	g.P("")
+	g.P("// GetAtRevision function reads the object at the given ID as the given revision")
+	g.P("// left it into x, there is no row for revisions that removed it")
+	g.P("func (x *", message.GoIdent, ") GetAtRevision(ctx ", contextPackage.Ident("Context"), ", db DBTX", tenantParam(opts), ", id ", idType(g, message, opts), ", revision int64) error {")
+	g.P("   var data []byte")
+	if p.usesRoutines(opts) {
+		g.P(`   err := `, p.dbCall("QueryRow"), `"SELECT new_data FROM list_revisions($1, $2, $3`, p.idCast(message, opts), `) WHERE revision = $4 AND new_data IS NOT NULL",`)
+		g.P("       ", tenantArg(opts), ", x.TableName(), id, revision).Scan(&data)")
+	} else {
+		g.P("   err := ", p.dbCall("QueryRow"), prefix, "RevisionQuery, ", tenantArg(opts), ", id, revision).Scan(&data)")
+	}
+	g.P("   if err != nil {")
+	g.P("       return err")
+	g.P("   }")
+	g.P("   return ", depPackage.Ident("UnmarshalDocument"), "(data, x)")
+	g.P("}")
+	g.P("")
+}
+
+// generateReadBack emits the statements of a write reading the object at the
+// given ID as it is now stored into current, the value its revision records
+// and the one an update takes the fields recording the creation from. They
+// return zero and the error on failure.
+func generateReadBack(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions, zero string) {
+	got := "err"
+	if opts.Versioned {
+		got = "_, err"
+	}
+	g.P("   current := new(", message.GoIdent, ")")
+	g.P("   if ", got, " := current.Get(ctx, db", tenantForward(opts), ", id); err != nil {")
+	g.P("       return ", zero, "err")
+	g.P("   }")
+}
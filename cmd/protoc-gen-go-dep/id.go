@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"protoc-gen-go-dep/dep"
+)
+
+// checkID fails on resources whose id strategy lacks the field it needs, or
+// whose natural key cannot be one.
+func checkID(message *protogen.Message, opts *dep.DepMessageOptions) error {
+	if opts.IdStrategy != dep.IdStrategy_ID_STRATEGY_NATURAL_KEY {
+		if opts.IdField != "" {
+			return fmt.Errorf("%s: id_field is only used by ID_STRATEGY_NATURAL_KEY", message.Desc.FullName())
+		}
+		return nil
+	}
+
+	field := keyField(message, opts)
+	if field == nil {
+		return fmt.Errorf("%s: ID_STRATEGY_NATURAL_KEY needs id_field to name a field, got %q", message.Desc.FullName(), opts.IdField)
+	}
+	fieldOpts := fieldOptions(field)
+	switch {
+	case field.Desc.IsList() || field.Desc.IsMap() || field.Oneof != nil || field.Desc.HasPresence():
+		return fmt.Errorf("%s: the natural key has to be a plain field", field.Desc.FullName())
+	case keyKind(field) == "":
+		return fmt.Errorf("%s: the natural key has to be a string or a signed integer", field.Desc.FullName())
+	case !fieldOpts.Required:
+		return fmt.Errorf("%s: the natural key has to be required", field.Desc.FullName())
+	case fieldOpts.ReadOnly || fieldOpts.Hidden:
+		return fmt.Errorf("%s: the natural key is set by clients, it cannot be read only or hidden", field.Desc.FullName())
+	}
+	return nil
+}
+
+// keyField returns the field holding the id of a resource with a natural
+// key, nil for the other strategies.
+func keyField(message *protogen.Message, opts *dep.DepMessageOptions) *protogen.Field {
+	if opts.IdStrategy != dep.IdStrategy_ID_STRATEGY_NATURAL_KEY {
+		return nil
+	}
+	for _, field := range message.Fields {
+		if string(field.Desc.Name()) == opts.IdField {
+			return field
+		}
+	}
+	return nil
+}
+
+// keyKind is the Go type of a natural key field, empty for kinds that
+// cannot be one.
+func keyKind(field *protogen.Field) string {
+	switch field.Desc.Kind() {
+	case protoreflect.StringKind:
+		return "string"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "int32"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "int64"
+	}
+	return ""
+}
+
+// reservedKeys are the string natural keys the routes of the handlers match
+// before /{id}, records stored under them could not be reached.
+func reservedKeys(message *protogen.Message, opts *dep.DepMessageOptions) []string {
+	if field := keyField(message, opts); field == nil || keyKind(field) != "string" {
+		return nil
+	}
+	var keys []string
+	if hasOperation(opts, dep.Operation_OPERATION_CREATE) && opts.UiMode == dep.UiMode_UI_MODE_HTMX {
+		keys = append(keys, "new")
+	}
+	if opts.SoftDelete && hasOperation(opts, dep.Operation_OPERATION_DELETE) {
+		keys = append(keys, "deleted")
+	}
+	return keys
+}
+
+// mintsID reports whether the ids of a resource are known before its insert,
+// which is passed them, rather than handed out by the database.
+func mintsID(opts *dep.DepMessageOptions) bool {
+	return opts.IdStrategy != dep.IdStrategy_ID_STRATEGY_SERIAL
+}
+
+// idType is the Go type of the ids of a resource.
+func idType(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) string {
+	switch opts.IdStrategy {
+	case dep.IdStrategy_ID_STRATEGY_UUID_V4, dep.IdStrategy_ID_STRATEGY_UUID_V7:
+		return g.QualifiedGoIdent(uuidPackage.Ident("UUID"))
+	case dep.IdStrategy_ID_STRATEGY_ULID:
+		return g.QualifiedGoIdent(ulidPackage.Ident("ULID"))
+	case dep.IdStrategy_ID_STRATEGY_NATURAL_KEY:
+		return keyKind(keyField(message, opts))
+	}
+	return "int64"
+}
+
+// idZero is the zero id returned alongside errors.
+func idZero(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) string {
+	switch idType(g, message, opts) {
+	case "string":
+		return `""`
+	case "int32", "int64":
+		return "0"
+	}
+	if opts.IdStrategy == dep.IdStrategy_ID_STRATEGY_ULID {
+		return g.QualifiedGoIdent(ulidPackage.Ident("ULID")) + "{}"
+	}
+	return g.QualifiedGoIdent(uuidPackage.Ident("Nil"))
+}
+
+// idValue is a value of the id type for the ID of a dep.Schema, untyped
+// constants would be taken for an int or a string.
+func idValue(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) string {
+	switch k := idType(g, message, opts); k {
+	case "int32", "int64":
+		return k + "(0)"
+	}
+	return idZero(g, message, opts)
+}
+
+// idSQLType is the column type of the ids of a resource in the dialect.
+func (p *Generator) idSQLType(message *protogen.Message, opts *dep.DepMessageOptions) string {
+	sqlite := p.dialect == dialectSQLite
+	switch opts.IdStrategy {
+	case dep.IdStrategy_ID_STRATEGY_UUID_V4, dep.IdStrategy_ID_STRATEGY_UUID_V7:
+		if sqlite {
+			return "TEXT"
+		}
+		return "UUID"
+	case dep.IdStrategy_ID_STRATEGY_ULID:
+		// ulid.ULID is stored as its 16 bytes.
+		if sqlite {
+			return "BLOB"
+		}
+		return "BYTEA"
+	case dep.IdStrategy_ID_STRATEGY_NATURAL_KEY:
+		switch keyKind(keyField(message, opts)) {
+		case "string":
+			return "TEXT"
+		case "int32":
+			return "INTEGER"
+		}
+	}
+	if sqlite {
+		return "INTEGER"
+	}
+	return "BIGINT"
+}
+
+// idCast is the cast to the id type of a resource, which tells the
+// polymorphic routines what they are passed.
+func (p *Generator) idCast(message *protogen.Message, opts *dep.DepMessageOptions) string {
+	return "::" + strings.ToLower(p.idSQLType(message, opts))
+}
+
+// idColumn is the definition of the id column of a resource. Natural keys
+// are only unique within the tenant, the table has a primary key on both.
+func (p *Generator) idColumn(message *protogen.Message, opts *dep.DepMessageOptions) string {
+	switch {
+	case opts.IdStrategy == dep.IdStrategy_ID_STRATEGY_NATURAL_KEY:
+		return "id " + p.idSQLType(message, opts) + " NOT NULL"
+	case opts.IdStrategy != dep.IdStrategy_ID_STRATEGY_SERIAL:
+		return "id " + p.idSQLType(message, opts) + " PRIMARY KEY"
+	case p.dialect == dialectSQLite:
+		return "id INTEGER PRIMARY KEY AUTOINCREMENT"
+	}
+	return "id BIGSERIAL PRIMARY KEY"
+}
+
+// generateNewID emits the statements of Create minting the id of data, when
+// the resource mints them, returning zero and the error on failure. It
+// reports whether they declared err.
+func generateNewID(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions, zero string) bool {
+	switch opts.IdStrategy {
+	case dep.IdStrategy_ID_STRATEGY_UUID_V4:
+		g.P("   id := ", uuidPackage.Ident("New"), "()")
+	case dep.IdStrategy_ID_STRATEGY_UUID_V7:
+		g.P("   id, err := ", uuidPackage.Ident("NewV7"), "()")
+		g.P("   if err != nil {")
+		g.P("       return ", zero, ", err")
+		g.P("   }")
+		return true
+	case dep.IdStrategy_ID_STRATEGY_ULID:
+		g.P("   id := ", ulidPackage.Ident("Make"), "()")
+	case dep.IdStrategy_ID_STRATEGY_NATURAL_KEY:
+		g.P("   id := data.", keyField(message, opts).GoName)
+	}
+	return false
+}
+
+// generateKeepKey emits the statement of a write keeping the natural key of
+// data at the id it is stored under.
+func generateKeepKey(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	if field := keyField(message, opts); field != nil {
+		g.P("   data.", field.GoName, " = id")
+	}
+}
+
+// generateIDFunction emits id, reading the {id} url parameter of the
+// handlers as the id type of the resource.
+func generateIDFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	param := g.QualifiedGoIdent(chiPackage.Ident("URLParam")) + `(req, "id")`
+
+	g.P("// id reads the {id} url parameter, false when it holds no valid id")
+	g.P("func (h *", message.GoIdent.GoName, "Handler) id(req *", httpPackage.Ident("Request"), ") (", idType(g, message, opts), ", bool) {")
+	switch idType(g, message, opts) {
+	case "string":
+		g.P("   id := ", param)
+		g.P(`   return id, id != ""`)
+	case "int32":
+		g.P("   id, err := ", strconvPackage.Ident("ParseInt"), "(", param, ", 10, 32)")
+		g.P("   return int32(id), err == nil")
+	case "int64":
+		g.P("   id, err := ", strconvPackage.Ident("ParseInt"), "(", param, ", 10, 64)")
+		g.P("   return id, err == nil")
+	default:
+		parse := uuidPackage.Ident("Parse")
+		if opts.IdStrategy == dep.IdStrategy_ID_STRATEGY_ULID {
+			parse = ulidPackage.Ident("ParseStrict")
+		}
+		g.P("   id, err := ", parse, "(", param, ")")
+		g.P("   return id, err == nil")
+	}
+	g.P("}")
+	g.P("")
+}
+
+// generateReadID emits the statements of a handler reading the {id} url
+// parameter into id, answering a 404 when it is no valid id.
+func generateReadID(g *protogen.GeneratedFile, indent string) {
+	g.P(indent, "id, ok := h.id(req)")
+	g.P(indent, "if !ok {")
+	g.P(indent, "    ", httpPackage.Ident("NotFound"), "(w, req)")
+	g.P(indent, "    return")
+	g.P(indent, "}")
+}
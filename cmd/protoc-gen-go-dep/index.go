@@ -0,0 +1,490 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"protoc-gen-go-dep/dep"
+)
+
+// index is an index of a resource over one or more of its fields, within
+// the tenant.
+type index struct {
+	fields []*protogen.Field
+	unique bool
+}
+
+// resourceIndexes returns the indexes of a resource, the ones of its unique
+// and indexed fields in field order followed by those of the indexes
+// option. Fields the option names that do not exist are left out,
+// checkIndexes reports them.
+func resourceIndexes(message *protogen.Message, opts *dep.DepMessageOptions) []index {
+	var ret []index
+	for _, field := range message.Fields {
+		fieldOpts := fieldOptions(field)
+		if fieldOpts.Unique || fieldOpts.Indexed {
+			ret = append(ret, index{fields: []*protogen.Field{field}, unique: fieldOpts.Unique})
+		}
+	}
+	for _, idx := range opts.Indexes {
+		var fields []*protogen.Field
+		for _, name := range idx.Fields {
+			if field := fieldByName(message, name); field != nil {
+				fields = append(fields, field)
+			}
+		}
+		ret = append(ret, index{fields: fields, unique: idx.Unique})
+	}
+	return ret
+}
+
+// uniqueIndexes returns the unique indexes of a resource, the natural key
+// aside.
+func uniqueIndexes(message *protogen.Message, opts *dep.DepMessageOptions) []index {
+	var ret []index
+	for _, idx := range resourceIndexes(message, opts) {
+		if idx.unique {
+			ret = append(ret, idx)
+		}
+	}
+	return ret
+}
+
+// hasUniqueKeys reports whether writes of a resource can fail with
+// dep.ErrAlreadyExists, natural keys being unique too.
+func hasUniqueKeys(message *protogen.Message, opts *dep.DepMessageOptions) bool {
+	return keyField(message, opts) != nil || len(uniqueIndexes(message, opts)) > 0
+}
+
+func fieldByName(message *protogen.Message, name string) *protogen.Field {
+	for _, field := range message.Fields {
+		if string(field.Desc.Name()) == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// checkIndexes fails on indexes over fields that cannot be indexed, and on
+// two indexes over the same fields.
+func checkIndexes(message *protogen.Message, opts *dep.DepMessageOptions) error {
+	for _, field := range message.Fields {
+		fieldOpts := fieldOptions(field)
+		if fieldOpts.Unique && fieldOpts.Indexed {
+			return fmt.Errorf("%s: unique fields are indexed already", field.Desc.FullName())
+		}
+	}
+	for _, idx := range opts.Indexes {
+		if len(idx.Fields) == 0 {
+			return fmt.Errorf("%s: indexes need fields", message.Desc.FullName())
+		}
+		seen := make(map[string]bool)
+		for _, name := range idx.Fields {
+			if fieldByName(message, name) == nil {
+				return fmt.Errorf("%s: index names unknown field %q", message.Desc.FullName(), name)
+			}
+			if seen[name] {
+				return fmt.Errorf("%s: index names field %q twice", message.Desc.FullName(), name)
+			}
+			seen[name] = true
+		}
+	}
+
+	key := keyField(message, opts)
+	names := make(map[string]bool)
+	for _, idx := range resourceIndexes(message, opts) {
+		for _, field := range idx.fields {
+			if !indexable(field) {
+				return fmt.Errorf("%s: only plain scalar and enum fields can be unique or indexed", field.Desc.FullName())
+			}
+		}
+		if idx.unique && len(idx.fields) == 1 && idx.fields[0] == key {
+			return fmt.Errorf("%s: the natural key is unique already", key.Desc.FullName())
+		}
+		name := strings.Join(protoNames(idx.fields), ", ")
+		if names[name] {
+			return fmt.Errorf("%s: two indexes over %s", message.Desc.FullName(), name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// indexable reports whether field can be part of an index. Timestamps are
+// left out as Postgres reads them from documents with a cast that cannot be
+// indexed.
+func indexable(field *protogen.Field) bool {
+	switch {
+	case field.Desc.IsList(), field.Desc.IsMap(), field.Oneof != nil, field.Desc.HasPresence():
+		return false
+	case field.Message != nil:
+		return false
+	}
+	return field.Desc.Kind() != protoreflect.BytesKind
+}
+
+// name is the name of the index on the table of the resource. Unique ones
+// end in _key like the unique constraints Postgres names, the others in
+// _idx.
+func (i index) name(opts *dep.DepMessageOptions) string {
+	suffix := "_idx"
+	if i.unique {
+		suffix = "_key"
+	}
+	return opts.Table + "_" + strings.Join(protoNames(i.fields), "_") + suffix
+}
+
+// finder is the name of the method reading the record holding the given
+// values of a unique index.
+func (i index) finder() string {
+	names := make([]string, len(i.fields))
+	for j, field := range i.fields {
+		names[j] = field.GoName
+	}
+	return "GetBy" + strings.Join(names, "And")
+}
+
+// reservedParams are the names the finders use for their other parameters
+// and variables, or for packages their bodies refer to.
+var reservedParams = map[string]bool{
+	"ctx": true, "db": true, "tenant": true, "x": true, "r": true, "id": true,
+	"version": true, "err": true, "errors": true, "sql": true, "pgx": true,
+	"dep": true, "proto": true, "uuid": true, "v2": true,
+}
+
+// param is the name of the finder parameter holding the value of field.
+func param(field *protogen.Field) string {
+	name := lowerFirst(field.GoName)
+	if token.IsKeyword(name) || reservedParams[name] {
+		name += "Value"
+	}
+	return name
+}
+
+// finderParams renders the parameters of a finder for its values.
+func finderParams(g *protogen.GeneratedFile, i index) string {
+	var b strings.Builder
+	for _, field := range i.fields {
+		b.WriteString(", " + param(field) + " " + scalarGoType(g, field))
+	}
+	return b.String()
+}
+
+// goZero is the Go zero value of an indexable field.
+func goZero(field *protogen.Field) string {
+	switch field.Desc.Kind() {
+	case protoreflect.StringKind:
+		return `""`
+	case protoreflect.BoolKind:
+		return "false"
+	}
+	return "0"
+}
+
+// finderArgs renders the values of a finder as query arguments, enums are
+// stored by value name and 64 bit unsigned integers as decimal text.
+func finderArgs(g *protogen.GeneratedFile, i index) string {
+	var b strings.Builder
+	for _, field := range i.fields {
+		switch {
+		case field.Enum != nil:
+			b.WriteString(", " + param(field) + ".String()")
+		case isUint64(field):
+			b.WriteString(", " + g.QualifiedGoIdent(strconvPackage.Ident("FormatUint")) + "(" + param(field) + ", 10)")
+		default:
+			b.WriteString(", " + param(field))
+		}
+	}
+	return b.String()
+}
+
+// indexExpr is the SQL indexing field, the one the finders compare so they
+// use the index. Expressions other than columns are parenthesized for
+// CREATE INDEX. Unique indexes read zero values as NULL, which never
+// collides, so any number of records can leave a unique field unset.
+func (p *Generator) indexExpr(field *protogen.Field, opts *dep.DepMessageOptions, unique bool) (expr string, column bool) {
+	expr = p.listExpr(field, opts)
+	if unique {
+		return "NULLIF(" + expr + ", " + p.zeroExpr(field) + ")", false
+	}
+	return expr, expr == sqlIdent(fieldOptions(field).Column) && opts.Storage == dep.Storage_STORAGE_COLUMNS
+}
+
+// generateIndexes emits the indexes of a resource after its table. Unique
+// ones only cover the records outside of the trash, so deleted records do
+// not hold on to their values.
+func (p *Generator) generateIndexes(s *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	for _, idx := range resourceIndexes(message, opts) {
+		exprs := []string{"tenant"}
+		for _, field := range idx.fields {
+			expr, column := p.indexExpr(field, opts, idx.unique)
+			if !column {
+				expr = "(" + expr + ")"
+			}
+			exprs = append(exprs, expr)
+		}
+		create, where := "CREATE INDEX", ""
+		if idx.unique {
+			create = "CREATE UNIQUE INDEX"
+			if opts.SoftDelete {
+				where = " WHERE deleted_at IS NULL"
+			}
+		}
+		s.P(create, " IF NOT EXISTS ", sqlIdent(idx.name(opts)), " ON ", sqlIdent(opts.Table), " (", strings.Join(exprs, ", "), ")", where, ";")
+	}
+}
+
+// generateUniqueKeys emits <message>UniqueKeys, the unique indexes of a
+// resource the SQL repository maps the violations of to the fields they
+// keep unique. SQLite names the columns of the natural key rather than its
+// index.
+func (p *Generator) generateUniqueKeys(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	if !hasUniqueKeys(message, opts) {
+		return
+	}
+	prefix := lowerFirst(message.GoIdent.GoName)
+	key := func(name, columns string, fields []*protogen.Field) {
+		g.P("   {")
+		g.P("       Index:   ", strconv.Quote(name), ",")
+		if columns != "" {
+			g.P("       Columns: ", strconv.Quote(columns), ",")
+		}
+		g.P("       Fields:  []string{", quoteAll(protoNames(fields)), "},")
+		g.P("   },")
+	}
+
+	g.P("// ", prefix, "UniqueKeys are the unique indexes of ", message.GoIdent.GoName, ", for dep.AlreadyExists")
+	g.P("var ", prefix, "UniqueKeys = []", depPackage.Ident("UniqueKey"), "{")
+	if field := keyField(message, opts); field != nil {
+		key(opts.Table+"_pkey", opts.Table+".tenant, "+opts.Table+".id", []*protogen.Field{field})
+	}
+	for _, idx := range uniqueIndexes(message, opts) {
+		key(idx.name(opts), "", idx.fields)
+	}
+	g.P("}")
+	g.P("")
+}
+
+// generateFinderQueries emits the statements of the finders of a resource
+// into the const block of generateQueries. They compare the expressions of
+// the unique index, so the lookup uses it.
+func (p *Generator) generateFinderQueries(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions, selected, live string) {
+	prefix := lowerFirst(message.GoIdent.GoName)
+	for _, idx := range uniqueIndexes(message, opts) {
+		conds := []string{"tenant = " + p.placeholder(1)}
+		for j, field := range idx.fields {
+			expr, _ := p.indexExpr(field, opts, true)
+			conds = append(conds, expr+" = "+p.placeholder(j+2))
+		}
+		g.P("   ", prefix, idx.finder(), "Query = ", strconv.Quote("SELECT id, "+selected+" FROM "+sqlIdent(opts.Table)+" WHERE "+strings.Join(conds, " AND ")+live))
+	}
+}
+
+// generateFinderFunctions emits GetBy<Field> for every unique index of a
+// resource, reading the record holding the given values.
+func (p *Generator) generateFinderFunctions(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	prefix := lowerFirst(message.GoIdent.GoName)
+	id := idType(g, message, opts)
+
+	for _, idx := range uniqueIndexes(message, opts) {
+		finder := idx.finder()
+		values := strings.Join(protoNames(idx.fields), " and ")
+		g.P("// ", finder, " function acquires the record holding the given ", values)
+		if opts.Versioned {
+			g.P("// into x and returns its ID and version")
+			g.P("func (x *", message.GoIdent, ") ", finder, "(ctx ", contextPackage.Ident("Context"), ", db DBTX", tenantParam(opts), finderParams(g, idx), ") (", id, ", int64, error) {")
+		} else {
+			g.P("// into x and returns its ID")
+			g.P("func (x *", message.GoIdent, ") ", finder, "(ctx ", contextPackage.Ident("Context"), ", db DBTX", tenantParam(opts), finderParams(g, idx), ") (", id, ", error) {")
+		}
+		g.P("   var id ", id)
+
+		query := p.dbCall("QueryRow") + prefix + finder + "Query, " + tenantArg(opts) + finderArgs(g, idx) + ")"
+		switch {
+		case opts.Storage == dep.Storage_STORAGE_COLUMNS && opts.Versioned:
+			g.P("   var version int64")
+			g.P("   err := x.scanColumns(", query, ", &id, &version)")
+		case opts.Storage == dep.Storage_STORAGE_COLUMNS:
+			g.P("   err := x.scanColumns(", query, ", &id)")
+		case opts.Versioned:
+			g.P("   var version int64")
+			g.P("   err := ", query, ".Scan(&id, &version, x)")
+		default:
+			g.P("   err := ", query, ".Scan(&id, x)")
+		}
+		if opts.Versioned {
+			g.P("   return id, version, err")
+		} else {
+			g.P("   return id, err")
+		}
+		g.P("}")
+		g.P("")
+	}
+}
+
+// generateRepositoryFinders emits the finders of the SQL repository of a
+// resource, returning dep.ErrNotFound when no record holds the values.
+func (p *Generator) generateRepositoryFinders(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions, sqlName, tenantParam, forward string) {
+	sig := repositorySignatures(g, message, opts)
+	zero := idZero(g, message, opts)
+	version, versionVar := "", ""
+	if opts.Versioned {
+		version, versionVar = "0, ", "version, "
+	}
+
+	for _, idx := range uniqueIndexes(message, opts) {
+		finder := idx.finder()
+		g.P("func (r *", sqlName, ") ", finder, "(", strings.TrimSuffix(tenantParam, ", "), finderParams(g, idx), ") ", sig.find, " {")
+		g.P("   x := new(", message.GoIdent, ")")
+		g.P("   id, ", versionVar, "err := x.", finder, "(ctx, r.DB", forward, finderArgNames(idx), ")")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", p.errNoRows(), ") {")
+		g.P("       return nil, ", zero, ", ", version, depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   if err != nil {")
+		g.P("       return nil, ", zero, ", ", version, "err")
+		g.P("   }")
+		g.P("")
+		g.P("   return x, id, ", versionVar, "nil")
+		g.P("}")
+		g.P("")
+	}
+}
+
+// finderArgNames passes the parameters of a finder on to another one.
+func finderArgNames(i index) string {
+	var b strings.Builder
+	for _, field := range i.fields {
+		b.WriteString(", " + param(field))
+	}
+	return b.String()
+}
+
+// generateMemoryTaken emits taken, which the writes of a memory repository
+// call before storing a record so no two hold the values of a unique index.
+func generateMemoryTaken(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions, memName string) {
+	indexes := uniqueIndexes(message, opts)
+	if len(indexes) == 0 {
+		return
+	}
+	sig := repositorySignatures(g, message, opts)
+
+	g.P("// taken fails with dep.ErrAlreadyExists when a record other than the one at id")
+	g.P("// holds the unique fields of x, the lock has to be held. Zero values are not")
+	g.P("// taken, like the NULLs of the SQL indexes.")
+	g.P("func (r *", memName, ") taken(tenant string, id ", sig.id, ", x *", message.GoIdent, ") error {")
+	g.P("   for other, y := range r.tenants[tenant] {")
+	g.P("       if other == id {")
+	g.P("           continue")
+	g.P("       }")
+	for _, idx := range indexes {
+		var conds []string
+		for _, field := range idx.fields {
+			conds = append(conds, "x.Get"+field.GoName+"() != "+goZero(field))
+		}
+		for _, field := range idx.fields {
+			conds = append(conds, "x.Get"+field.GoName+"() == y.Get"+field.GoName+"()")
+		}
+		g.P("       if ", strings.Join(conds, " && "), " {")
+		g.P("           return &", depPackage.Ident("AlreadyExistsError"), "{Fields: []string{", quoteAll(protoNames(idx.fields)), "}}")
+		g.P("       }")
+	}
+	g.P("   }")
+	g.P("   return nil")
+	g.P("}")
+	g.P("")
+}
+
+// generateMemoryTakenCheck emits the call of taken a memory write makes
+// before storing value at id, failing with results followed by the error.
+func generateMemoryTakenCheck(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions, tenant, id, value, results string) {
+	if len(uniqueIndexes(message, opts)) == 0 {
+		return
+	}
+	g.P("   if err := r.taken(", tenant, ", ", id, ", ", value, "); err != nil {")
+	g.P("       return ", results, "err")
+	g.P("   }")
+}
+
+// generateMemoryFinders emits the finders of the memory repository of a
+// resource. Zero values find nothing, the SQL indexes hold them as NULL.
+func generateMemoryFinders(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions, memName, tenantParam, tenant string, clone func(string) string) {
+	sig := repositorySignatures(g, message, opts)
+	zero := idZero(g, message, opts)
+	version, stored := "", ""
+	if opts.Versioned {
+		version, stored = "0, ", "r.versions["+tenant+"][id], "
+	}
+
+	for _, idx := range uniqueIndexes(message, opts) {
+		var conds []string
+		for _, field := range idx.fields {
+			conds = append(conds, param(field)+" != "+goZero(field))
+		}
+		for _, field := range idx.fields {
+			conds = append(conds, "x.Get"+field.GoName+"() == "+param(field))
+		}
+		g.P("func (r *", memName, ") ", idx.finder(), "(", strings.TrimSuffix(tenantParam, ", "), finderParams(g, idx), ") ", sig.find, " {")
+		g.P("   r.mu.RLock()")
+		g.P("   defer r.mu.RUnlock()")
+		g.P("")
+		g.P("   for id, x := range r.tenants[", tenant, "] {")
+		g.P("       if ", strings.Join(conds, " && "), " {")
+		g.P("           return ", clone("x"), ", id, ", stored, "nil")
+		g.P("       }")
+		g.P("   }")
+		g.P("   return nil, ", zero, ", ", version, depPackage.Ident("ErrNotFound"))
+		g.P("}")
+		g.P("")
+	}
+}
+
+// generateAlreadyExists emits answering a write that failed with
+// dep.ErrAlreadyExists with a 409, htmx requests get the form back with the
+// taken fields marked.
+func generateAlreadyExists(g *protogen.GeneratedFile, htmx bool, value string) {
+	g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrAlreadyExists"), ") {")
+	if htmx {
+		g.P("       h.alreadyExists(w, req, ", value, ", err)")
+	} else {
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusConflict"), ")")
+	}
+	g.P("       return")
+	g.P("   }")
+}
+
+// generateAlreadyExistsHandler emits alreadyExists, answering htmx requests
+// whose write found unique fields taken with the submitted form marking
+// them. htmx has to be told to swap 409 responses in.
+func generateAlreadyExistsHandler(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	handlerName := message.GoIdent.GoName + "Handler"
+
+	g.P("// alreadyExists answers a write that found unique fields of x taken with a 409,")
+	g.P("// htmx requests get the form back with the fields marked")
+	g.P("func (h *", handlerName, ") alreadyExists(w ", httpPackage.Ident("ResponseWriter"), ", req *", httpPackage.Ident("Request"), ", x *", message.GoIdent, ", err error) {")
+	g.P(`   if req.Header.Get("HX-Request") != "true" {`)
+	g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusConflict"), ")")
+	g.P("       return")
+	g.P("   }")
+	g.P("")
+	g.P("   var errs ", depPackage.Ident("ValidationErrors"))
+	g.P("   var exists *", depPackage.Ident("AlreadyExistsError"))
+	g.P("   if ", errorsPackage.Ident("As"), "(err, &exists) {")
+	g.P("       errs = exists.ValidationErrors()")
+	g.P("   }")
+	g.P(`   w.Header().Set("Content-Type", "text/html; charset=utf-8")`)
+	g.P("   w.WriteHeader(", httpPackage.Ident("StatusConflict"), ")")
+	if opts.Versioned {
+		g.P("   if version, err := ", depPackage.Ident("IfMatch"), "(req); err == nil && version > 0 {")
+		g.P("       // The form keeps the version it was submitted with")
+		g.P("       ", fmtPackage.Ident("Fprintf"), `(w, "<input type=\"hidden\" name=\"%s\" value=\"%d\">\n", `, depPackage.Ident("VersionFormValue"), ", version)")
+		g.P("   }")
+	}
+	g.P("   x.RenderFormErrors(w, errs)")
+	g.P("}")
+	g.P("")
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"protoc-gen-go-dep/dep"
+)
+
+// generateListSchema emits the dep.Schema List checks filter and order_by
+// against, holding the searchable and sortable fields.
+func (p *Generator) generateListSchema(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) error {
+	dialect := depPackage.Ident("Postgres")
+	if p.dialect == dialectSQLite {
+		dialect = depPackage.Ident("SQLite")
+	}
+
+	g.P("// ", lowerFirst(message.GoIdent.GoName), "ListSchema holds the fields List can filter and order by")
+	g.P("var ", lowerFirst(message.GoIdent.GoName), "ListSchema = &", depPackage.Ident("Schema"), "{")
+	g.P("   Message: new(", message.GoIdent, "),")
+	g.P("   Dialect: ", dialect, ",")
+	var fields []string
+	for _, field := range message.Fields {
+		fieldOpts := fieldOptions(field)
+		if !fieldOpts.Searchable && !fieldOpts.Sortable {
+			continue
+		}
+		if !listable(field) {
+			return fmt.Errorf("%s: only scalar, enum and Timestamp fields can be searchable or sortable", field.Desc.FullName())
+		}
+		fields = append(fields, fmt.Sprintf("{Name: %q, Expr: %s, Filter: %t, Sort: %t},",
+			field.Desc.Name(), strconv.Quote(p.listExpr(field, opts)), fieldOpts.Searchable, fieldOpts.Sortable))
+	}
+	if len(fields) > 0 {
+		g.P("   Fields: []", depPackage.Ident("ListField"), "{")
+		for _, f := range fields {
+			g.P("       ", f)
+		}
+		g.P("   },")
+	}
+	if mintsID(opts) {
+		g.P("   ID: ", idValue(g, message, opts), ",")
+	}
+	g.P("}")
+	g.P("")
+	return nil
+}
+
+// listable reports whether List can compare field.
+func listable(field *protogen.Field) bool {
+	switch {
+	case field.Desc.IsList(), field.Desc.IsMap():
+		return false
+	case field.Message != nil:
+		return field.Message.Desc.FullName() == timestampName
+	}
+	return field.Desc.Kind() != protoreflect.BytesKind
+}
+
+// zeroExpr is the SQL zero value of field, the one Go reads unset fields as.
+func (p *Generator) zeroExpr(field *protogen.Field) string {
+	switch {
+	case field.Message != nil && p.dialect == dialectSQLite:
+		return "'1970-01-01T00:00:00Z'"
+	case field.Message != nil:
+		return "to_timestamp(0)"
+	case field.Enum != nil:
+		return "'" + string(field.Enum.Values[0].Desc.Name()) + "'"
+	case field.Desc.Kind() == protoreflect.StringKind:
+		return "''"
+	case field.Desc.Kind() == protoreflect.BoolKind:
+		return "FALSE"
+	case isUint64(field) && p.dialect == dialectSQLite:
+		return "'0'"
+	}
+	return "0"
+}
+
+// listExpr is the SQL reading field for filters and ordering. Unset fields
+// read as their zero value, the way Go sees them, protojson leaves those out
+// of documents and optional columns hold NULL.
+func (p *Generator) listExpr(field *protogen.Field, opts *dep.DepMessageOptions) string {
+	sqlite := p.dialect == dialectSQLite
+	zero := p.zeroExpr(field)
+
+	if opts.Storage == dep.Storage_STORAGE_COLUMNS {
+		column := sqlIdent(fieldOptions(field).Column)
+		if field.Desc.HasPresence() {
+			return "COALESCE(" + column + ", " + zero + ")"
+		}
+		return column
+	}
+
+	name := field.Desc.JSONName()
+	if sqlite {
+		value := "COALESCE(json_extract(data, '$." + name + "'), " + zero + ")"
+		switch field.Desc.Kind() {
+		case protoreflect.FloatKind, protoreflect.DoubleKind:
+			return "CAST(" + value + " AS REAL)"
+		case protoreflect.StringKind, protoreflect.BoolKind, protoreflect.EnumKind, protoreflect.MessageKind,
+			protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+			// dep.Schema compares 64 bit unsigned integers as padded text.
+			return value
+		}
+		// protojson writes 64 bit integers as strings
+		return "CAST(" + value + " AS INTEGER)"
+	}
+
+	value := "data->>'" + name + "'"
+	switch field.Desc.Kind() {
+	case protoreflect.StringKind, protoreflect.EnumKind:
+	case protoreflect.BoolKind:
+		value = "(" + value + ")::boolean"
+	case protoreflect.MessageKind:
+		value = "(" + value + ")::timestamptz"
+	default:
+		value = "(" + value + ")::numeric"
+	}
+	return "COALESCE(" + value + ", " + zero + ")"
+}
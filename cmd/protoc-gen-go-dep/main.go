@@ -1,311 +1,1785 @@
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
 	"google.golang.org/protobuf/compiler/protogen"
-    "google.golang.org/protobuf/proto"
-    "google.golang.org/protobuf/types/pluginpb"
-    "google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"protoc-gen-go-dep/dep"
+)
 
-    "fmt"
-    "os"
-    "io/ioutil"
-    "strings"
+// Packages referenced by the generated code, protogen adds the imports for
+// whatever ends up being used.
+const (
+	sqlPackage      = protogen.GoImportPath("database/sql")
+	errorsPackage   = protogen.GoImportPath("errors")
+	jsonPackage     = protogen.GoImportPath("encoding/json")
+	mimePackage     = protogen.GoImportPath("mime")
+	httpPackage     = protogen.GoImportPath("net/http")
+	templatePackage = protogen.GoImportPath("html/template")
+	regexpPackage   = protogen.GoImportPath("regexp")
+	utf8Package     = protogen.GoImportPath("unicode/utf8")
+	mailPackage     = protogen.GoImportPath("net/mail")
+	urlPackage      = protogen.GoImportPath("net/url")
+	chiPackage      = protogen.GoImportPath("github.com/go-chi/chi")
+	pgxPackage      = protogen.GoImportPath("github.com/jackc/pgx/v5")
+	pgconnPackage   = protogen.GoImportPath("github.com/jackc/pgx/v5/pgconn")
+	contextPackage  = protogen.GoImportPath("context")
+	depPackage      = protogen.GoImportPath("protoc-gen-go-dep/dep")
 
+	ioPackage          = protogen.GoImportPath("io")
+	pathPackage        = protogen.GoImportPath("path")
+	timePackage        = protogen.GoImportPath("time")
+	base64Package      = protogen.GoImportPath("encoding/base64")
+	strconvPackage     = protogen.GoImportPath("strconv")
+	stringsPackage     = protogen.GoImportPath("strings")
+	timestamppbPackage = protogen.GoImportPath("google.golang.org/protobuf/types/known/timestamppb")
+	protojsonPackage   = protogen.GoImportPath("google.golang.org/protobuf/encoding/protojson")
+	protoPackage       = protogen.GoImportPath("google.golang.org/protobuf/proto")
+	driverPackage      = protogen.GoImportPath("database/sql/driver")
+	fmtPackage         = protogen.GoImportPath("fmt")
+	syncPackage        = protogen.GoImportPath("sync")
+	fieldmaskpbPackage = protogen.GoImportPath("google.golang.org/protobuf/types/known/fieldmaskpb")
+	uuidPackage        = protogen.GoImportPath("github.com/google/uuid")
+	ulidPackage        = protogen.GoImportPath("github.com/oklog/ulid/v2")
 )
 
-type Generator struct {
-    plugin *protogen.Plugin
-    write bool
-    messages map[string]struct{}
-    suppressWarn bool
+// versionedImports maps import paths used above to the module version they
+// stand for. protogen names a package after the last element of its path,
+// so chi is referred to without its /v5 and imported as chi rather than v5.
+var versionedImports = map[protogen.GoImportPath]protogen.GoImportPath{
+	chiPackage: "github.com/go-chi/chi/v5",
 }
 
+// Databases the generated code can target with the db parameter.
+const (
+	dialectPostgres = "postgres"
+	dialectSQLite   = "sqlite"
+)
 
+// Client libraries the generated code can use with the driver parameter.
+const (
+	driverSQL = "sql"
+	driverPgx = "pgx"
+)
+
+type Generator struct {
+	plugin       *protogen.Plugin
+	write        bool
+	messages     map[string]struct{}
+	suppressWarn bool
+	dialect      string
+	driver       string
+}
 
 func NewGenerator(opts protogen.Options, request *pluginpb.CodeGeneratorRequest) (*Generator, error) {
-    plugin, err := opts.New(request)
-    if err != nil {
-        return nil, err
-    }
-
-    generator := &Generator{
-        plugin: plugin,
-        messages: make(map[string]struct{}),
-        suppressWarn: false,
-    }
-    
-    params := parseParameter(request.GetParameter())
-
-    if _, ok := params["quiet"]; ok {
+	rewrite := opts.ImportRewriteFunc
+	opts.ImportRewriteFunc = func(importPath protogen.GoImportPath) protogen.GoImportPath {
+		if versioned, ok := versionedImports[importPath]; ok {
+			importPath = versioned
+		}
+		if rewrite != nil {
+			importPath = rewrite(importPath)
+		}
+		return importPath
+	}
+
+	plugin, err := opts.New(request)
+	if err != nil {
+		return nil, err
+	}
+
+	generator := &Generator{
+		plugin:       plugin,
+		messages:     make(map[string]struct{}),
+		suppressWarn: false,
+		dialect:      dialectPostgres,
+		driver:       driverSQL,
+	}
+
+	params := parseParameter(request.GetParameter())
+
+	if _, ok := params["quiet"]; ok {
 		generator.suppressWarn = true
 	}
 
-    return generator, nil
+	if db, ok := params["db"]; ok {
+		switch db {
+		case dialectPostgres, dialectSQLite:
+			generator.dialect = db
+		default:
+			return nil, fmt.Errorf("unknown db %q, expected %s or %s", db, dialectPostgres, dialectSQLite)
+		}
+	}
+
+	if driver, ok := params["driver"]; ok {
+		switch driver {
+		case driverSQL, driverPgx:
+			generator.driver = driver
+		default:
+			return nil, fmt.Errorf("unknown driver %q, expected %s or %s", driver, driverSQL, driverPgx)
+		}
+	}
+	if generator.driver == driverPgx && generator.dialect != dialectPostgres {
+		return nil, fmt.Errorf("driver=%s only works with db=%s", driverPgx, dialectPostgres)
+	}
+
+	return generator, nil
 }
 
 func (p *Generator) Name() string {
-    return "dep"
+	return "dep"
 }
 
 func (p *Generator) Generate() (*pluginpb.CodeGeneratorResponse, error) {
 	genFileMap := make(map[string]*protogen.GeneratedFile)
-    
-    for _, protoFile := range p.plugin.Files {
-        if fileHasOurOptions(protoFile) != true {
-            continue
-        }
-
-        fileName := protoFile.GeneratedFilenamePrefix + ".pb.dep.go"
-		g := p.plugin.NewGeneratedFile(fileName, ".")
+
+	for _, protoFile := range p.plugin.Files {
+		if !protoFile.Generate || fileHasOurOptions(protoFile) != true {
+			continue
+		}
+
+		fileName := protoFile.GeneratedFilenamePrefix + ".pb.dep.go"
+		g := p.plugin.NewGeneratedFile(fileName, protoFile.GoImportPath)
 		genFileMap[fileName] = g
 
+		g.P("// Code generated by protoc-gen-go-dep. DO NOT EDIT.")
+		g.P("// source: ", protoFile.Desc.Path())
+		g.P("")
+		g.P("package ", protoFile.GoPackageName)
+		g.P("")
+		p.generateDBTX(g)
 
-        g.P("package ", protoFile.GoPackageName)
-        g.P("import (")
-        g.P(`   "database/sql"`)
-        g.P(`   _ "github.com/lib/pq"`)
-        g.P(`   "net/http"`)
-        g.P(`   "github.com/go-chi/chi/v5"`)
-        g.P(")")
-        g.P("")
+		var resources []*protogen.Message
+		for _, message := range protoFile.Messages {
+			opts := resourceOptions(message)
+			if opts == nil {
+				continue
+			}
+			resources = append(resources, message)
 
-        for _, message := range protoFile.Messages {
-            if messageHasOurOptions(message) == false {
-                continue
-            }
-            p.generateListFunction(g, message)
-            p.generateGetFunction(g, message)
-            p.generateCreateFunction(g, message)
-            p.generateUpdateFunction(g, message)
-            p.generateDeleteFunction(g, message)
-            p.generateFormHandler(g, message)
-            p.generateTableFunction(g, message)
-        }
+			if err := checkAudit(message); err != nil {
+				p.plugin.Error(err)
+				return p.plugin.Response(), nil
+			}
+			if err := checkID(message, opts); err != nil {
+				p.plugin.Error(err)
+				return p.plugin.Response(), nil
+			}
+			if err := checkIndexes(message, opts); err != nil {
+				p.plugin.Error(err)
+				return p.plugin.Response(), nil
+			}
+			p.generateModel(g, message, opts)
+			p.generateQueries(g, message, opts)
+			p.generateUniqueKeys(g, message, opts)
+			if opts.Storage == dep.Storage_STORAGE_COLUMNS {
+				p.generateColumnValues(g, message)
+			}
+			if a := auditFields(message); a.any() {
+				p.generateStampFunction(g, message, a)
+			}
+			if hasOperation(opts, dep.Operation_OPERATION_LIST) {
+				if err := p.generateListSchema(g, message, opts); err != nil {
+					p.plugin.Error(err)
+					return p.plugin.Response(), nil
+				}
+				p.generateListFunction(g, message, opts, false)
+				if opts.SoftDelete && hasOperation(opts, dep.Operation_OPERATION_DELETE) {
+					p.generateListFunction(g, message, opts, true)
+				}
+			}
+			// The writes of a resource keeping a history read back what they
+			// stored, so do updates keeping audit fields.
+			if hasOperation(opts, dep.Operation_OPERATION_GET) || readsBack(message, opts) {
+				p.generateGetFunction(g, message, opts)
+			}
+			if hasOperation(opts, dep.Operation_OPERATION_GET) {
+				p.generateFinderFunctions(g, message, opts)
+			}
+			if hasOperation(opts, dep.Operation_OPERATION_CREATE) {
+				p.generateCreateFunction(g, message, opts)
+			}
+			if hasOperation(opts, dep.Operation_OPERATION_UPDATE) {
+				p.generateUpdateFunction(g, message, opts)
+				p.generatePatchFunction(g, message, opts)
+			}
+			if hasOperation(opts, dep.Operation_OPERATION_DELETE) {
+				p.generateDeleteFunction(g, message, opts)
+			}
+			if opts.SoftDelete && hasOperation(opts, dep.Operation_OPERATION_DELETE) {
+				p.generateTrashFunctions(g, message, opts)
+			}
+			if opts.Versioned && (hasOperation(opts, dep.Operation_OPERATION_UPDATE) || hasOperation(opts, dep.Operation_OPERATION_DELETE)) {
+				p.generateConflictFunction(g, message, opts)
+			}
+			if opts.History {
+				p.generateRecordFunction(g, message, opts)
+				p.generateHistoryFunctions(g, message, opts)
+			}
+			p.generateRepository(g, message, opts)
+			p.generateHandlers(g, message, opts)
+			p.generateRouteFunction(g, message, opts)
+			if opts.UiMode == dep.UiMode_UI_MODE_HTMX {
+				p.generateFormHandler(g, message)
+				p.generateViewTemplate(g, message)
+				p.generateFormTemplate(g, message)
+			}
+			if err := p.generateValidateFunction(g, message, opts); err != nil {
+				p.plugin.Error(err)
+				return p.plugin.Response(), nil
+			}
+			p.generateTableFunction(g, message, opts)
+			if opts.Storage == dep.Storage_STORAGE_DOCUMENT {
+				if err := p.generateDocumentMethods(g, message); err != nil {
+					p.plugin.Error(err)
+					return p.plugin.Response(), nil
+				}
+			}
+		}
 
-    }
+		p.generateRegisterFunction(g, protoFile, resources)
+		p.generateSchemaFile(protoFile, resources)
+	}
 
-    return p.plugin.Response(), nil
+	return p.plugin.Response(), nil
 }
 
 func fileHasOurOptions(file *protogen.File) bool {
-    for _, message := range file.Messages {
-        if messageHasOurOptions(message) == true {
-            return true
-        }
-    }
-    return false
+	for _, message := range file.Messages {
+		if messageHasOurOptions(message) == true {
+			return true
+		}
+	}
+	return false
 }
 
 func messageHasOurOptions(message *protogen.Message) bool {
-    opts := message.Desc.Options().(*descriptorpb.MessageOptions)
-    if proto.HasExtension(opts, dep.E_Opts) {
-        val := proto.GetExtension(opts, dep.E_Opts).(string)
-		if val == "htmx" {
-			return true
+	return resourceOptions(message) != nil
+}
+
+func (p *Generator) generateModel(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	handlerName := message.GoIdent.GoName + "Handler"
+
+	g.P("// ", handlerName, " serves the http routes of ", message.GoIdent.GoName)
+	g.P("type ", handlerName, " struct {")
+	g.P("   Repo ", message.GoIdent.GoName, "Repository")
+	if !opts.Global {
+		g.P("   // Tenant resolves the tenant of a request, by default the {tenant} url parameter")
+		g.P("   Tenant func(*", httpPackage.Ident("Request"), ") string")
+	}
+	g.P("}")
+	g.P("")
+	g.P("// New", handlerName, " returns a ", handlerName, " backed by repo")
+	g.P("func New", handlerName, "(repo ", message.GoIdent.GoName, "Repository) *", handlerName, " {")
+	g.P("   return &", handlerName, "{Repo: repo}")
+	g.P("}")
+	g.P("")
+	if !opts.Global {
+		g.P("func (h *", handlerName, ") tenant(req *", httpPackage.Ident("Request"), ") string {")
+		g.P("   if h.Tenant != nil {")
+		g.P("       return h.Tenant(req)")
+		g.P("   }")
+		g.P(`   return `, chiPackage.Ident("URLParam"), `(req, "tenant")`)
+		g.P("}")
+		g.P("")
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_GET) || hasOperation(opts, dep.Operation_OPERATION_UPDATE) || hasOperation(opts, dep.Operation_OPERATION_DELETE) || opts.History {
+		generateIDFunction(g, message, opts)
+	}
+}
+
+// errNoRows is the error the driver returns when a row is missing.
+func (p *Generator) errNoRows() protogen.GoIdent {
+	if p.driver == driverPgx {
+		return pgxPackage.Ident("ErrNoRows")
+	}
+	return sqlPackage.Ident("ErrNoRows")
+}
+
+// dbCall starts a call of the db method named like the pgx one, with ctx
+// as its first argument.
+func (p *Generator) dbCall(method string) string {
+	if p.driver == driverPgx {
+		return "db." + method + "(ctx, "
+	}
+	return "db." + method + "Context(ctx, "
+}
+
+// generateDBTX emits the interface the persistence methods take, so they
+// run the same on a connection pool and in a transaction, and WithTx to
+// start one.
+func (p *Generator) generateDBTX(g *protogen.GeneratedFile) {
+	if p.driver == driverPgx {
+		g.P("// DBTX is what the persistence methods need from pgx, it is satisfied by")
+		g.P("// *pgxpool.Pool, *pgx.Conn and pgx.Tx alike")
+		g.P("type DBTX interface {")
+		g.P("   Exec(ctx ", contextPackage.Ident("Context"), ", sql string, args ...any) (", pgconnPackage.Ident("CommandTag"), ", error)")
+		g.P("   Query(ctx ", contextPackage.Ident("Context"), ", sql string, args ...any) (", pgxPackage.Ident("Rows"), ", error)")
+		g.P("   QueryRow(ctx ", contextPackage.Ident("Context"), ", sql string, args ...any) ", pgxPackage.Ident("Row"))
+		g.P("}")
+		g.P("")
+		g.P("// WithTx runs fn in a transaction begun on db, it is committed when fn")
+		g.P("// returns nil and rolled back otherwise")
+		g.P("func WithTx(ctx ", contextPackage.Ident("Context"), ", db interface {")
+		g.P("   Begin(", contextPackage.Ident("Context"), ") (", pgxPackage.Ident("Tx"), ", error)")
+		g.P("}, fn func(tx ", pgxPackage.Ident("Tx"), ") error) error {")
+		g.P("   return ", pgxPackage.Ident("BeginFunc"), "(ctx, db, fn)")
+		g.P("}")
+		g.P("")
+		g.P("// begin starts a transaction on db for a write made of several statements, a")
+		g.P("// savepoint when db is a transaction already. end commits it when err is nil")
+		g.P("// and rolls it back otherwise, returning err")
+		g.P("func begin(ctx ", contextPackage.Ident("Context"), ", db DBTX) (DBTX, func(err error) error, error) {")
+		g.P("   b, ok := db.(interface {")
+		g.P("       Begin(", contextPackage.Ident("Context"), ") (", pgxPackage.Ident("Tx"), ", error)")
+		g.P("   })")
+		g.P("   if !ok {")
+		g.P("       return db, func(err error) error { return err }, nil")
+		g.P("   }")
+		g.P("   tx, err := b.Begin(ctx)")
+		g.P("   if err != nil {")
+		g.P("       return nil, nil, err")
+		g.P("   }")
+		g.P("   return tx, func(err error) error {")
+		g.P("       if err != nil {")
+		g.P("           tx.Rollback(ctx)")
+		g.P("           return err")
+		g.P("       }")
+		g.P("       return tx.Commit(ctx)")
+		g.P("   }, nil")
+		g.P("}")
+		g.P("")
+		return
+	}
+
+	g.P("// DBTX is what the persistence methods need from database/sql, it is")
+	g.P("// satisfied by both *sql.DB and *sql.Tx")
+	g.P("type DBTX interface {")
+	g.P("   ExecContext(ctx ", contextPackage.Ident("Context"), ", query string, args ...any) (", sqlPackage.Ident("Result"), ", error)")
+	g.P("   QueryContext(ctx ", contextPackage.Ident("Context"), ", query string, args ...any) (*", sqlPackage.Ident("Rows"), ", error)")
+	g.P("   QueryRowContext(ctx ", contextPackage.Ident("Context"), ", query string, args ...any) *", sqlPackage.Ident("Row"))
+	g.P("}")
+	g.P("")
+	g.P("// WithTx runs fn in a transaction begun on db, it is committed when fn")
+	g.P("// returns nil and rolled back otherwise")
+	g.P("func WithTx(ctx ", contextPackage.Ident("Context"), ", db *", sqlPackage.Ident("DB"), ", fn func(tx *", sqlPackage.Ident("Tx"), ") error) error {")
+	g.P("   tx, err := db.BeginTx(ctx, nil)")
+	g.P("   if err != nil {")
+	g.P("       return err")
+	g.P("   }")
+	g.P("   // Rolling back a committed transaction does nothing, this covers")
+	g.P("   // both an error and a panic in fn")
+	g.P("   defer tx.Rollback()")
+	g.P("")
+	g.P("   if err := fn(tx); err != nil {")
+	g.P("       return err")
+	g.P("   }")
+	g.P("")
+	g.P("   return tx.Commit()")
+	g.P("}")
+	g.P("")
+	g.P("// begin starts a transaction on db for a write made of several statements, or")
+	g.P("// returns db when it is a transaction already. end commits the transaction it")
+	g.P("// began when err is nil and rolls it back otherwise, returning err. Within a")
+	g.P("// transaction of the caller rolling back is left to them")
+	g.P("func begin(ctx ", contextPackage.Ident("Context"), ", db DBTX) (DBTX, func(err error) error, error) {")
+	g.P("   b, ok := db.(interface {")
+	g.P("       BeginTx(", contextPackage.Ident("Context"), ", *", sqlPackage.Ident("TxOptions"), ") (*", sqlPackage.Ident("Tx"), ", error)")
+	g.P("   })")
+	g.P("   if !ok {")
+	g.P("       return db, func(err error) error { return err }, nil")
+	g.P("   }")
+	g.P("   tx, err := b.BeginTx(ctx, nil)")
+	g.P("   if err != nil {")
+	g.P("       return nil, nil, err")
+	g.P("   }")
+	g.P("   return tx, func(err error) error {")
+	g.P("       if err != nil {")
+	g.P("           tx.Rollback()")
+	g.P("           return err")
+	g.P("       }")
+	g.P("       return tx.Commit()")
+	g.P("   }, nil")
+	g.P("}")
+	g.P("")
+}
+
+// write describes the signature of a persistence method writing records.
+type write struct {
+	name string
+	// params and args follow db in the signature and in calls.
+	params, args string
+	results      string
+	// result names the value returned before the error, if any, zero leads
+	// the error returns.
+	result, zero string
+}
+
+// generateWrite emits the signature of w. With inTx the method runs an
+// unexported counterpart named like it in lower case in a transaction begun by
+// begin, and the signature of that one follows, the caller emits its body.
+// With validate the object read into x has to be valid for the transaction to
+// commit.
+func generateWrite(g *protogen.GeneratedFile, message *protogen.Message, w write, inTx, validate bool) {
+	signature := func(name string) {
+		g.P("func (x *", message.GoIdent, ") ", name, "(ctx ", contextPackage.Ident("Context"), ", db DBTX", w.params, ") ", w.results, " {")
+	}
+	signature(w.name)
+	if !inTx {
+		return
+	}
+
+	g.P("   tx, end, err := begin(ctx, db)")
+	g.P("   if err != nil {")
+	g.P("       return ", w.zero, "err")
+	g.P("   }")
+	result := ""
+	if w.result == "" {
+		g.P("   err = x.", lowerFirst(w.name), "(ctx, tx", w.args, ")")
+	} else {
+		g.P("   ", w.result, ", err := x.", lowerFirst(w.name), "(ctx, tx", w.args, ")")
+		result = w.result + ", "
+	}
+	if validate {
+		g.P("   if err == nil {")
+		g.P("       err = x.Validate()")
+		g.P("   }")
+	}
+	g.P("   return ", result, "end(err)")
+	g.P("}")
+	g.P("")
+	g.P("// ", lowerFirst(w.name), " makes the writes of ", w.name, " in the transaction it began")
+	signature(lowerFirst(w.name))
+}
+
+// generateListFunction emits List, or ListDeleted listing the trash of a soft
+// deleted resource when deleted is set.
+func (p *Generator) generateListFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions, deleted bool) {
+	key := idType(g, message, opts)
+	page := g.QualifiedGoIdent(depPackage.Ident("Page")) + "[*" + g.QualifiedGoIdent(message.GoIdent) + ", " + key + "]"
+	record := g.QualifiedGoIdent(depPackage.Ident("Record")) + "[*" + g.QualifiedGoIdent(message.GoIdent) + ", " + key + "]"
+	prefix := lowerFirst(message.GoIdent.GoName)
+
+	// The statements select the records of the tenant, the query adds the
+	// filter, order and page to them.
+	name, countQuery, listQuery, args := "List", prefix+"CountQuery", prefix+"ListQuery", tenantArg(opts)
+	if deleted {
+		name, countQuery, listQuery = "ListDeleted", prefix+"DeletedCountQuery", prefix+"DeletedListQuery"
+	}
+
+	if deleted {
+		g.P("// ListDeleted function returns the page of these objects in the trash opts selects")
+	} else {
+		g.P("// List function returns the page of these objects opts selects")
+	}
+	g.P("func (x *", message.GoIdent, ") ", name, "(ctx ", contextPackage.Ident("Context"), ", db DBTX", tenantParam(opts), ", opts ", depPackage.Ident("ListOptions"), ") (*", page, ", error) {")
+	g.P("   q, err := ", prefix, "ListSchema.Query(opts)")
+	g.P("   if err != nil { return nil, err }")
+	g.P("")
+	g.P("   ret := new(", page, ")")
+	g.P("   query, args := q.Count(", countQuery, ", ", args, ")")
+	g.P("   if err := ", p.dbCall("QueryRow"), "query, args...).Scan(&ret.TotalSize); err != nil { return nil, err }")
+	g.P("")
+	g.P("   query, args = q.Select(", listQuery, ", ", args, ")")
+	g.P("   rows, err := ", p.dbCall("Query"), "query, args...)")
+	g.P("   if err != nil { return nil, err }")
+	g.P("")
+
+	scan := "rows.Scan(&id, row)"
+	if opts.Storage == dep.Storage_STORAGE_COLUMNS {
+		scan = "row.scanColumns(rows, &id)"
+	}
+
+	if p.driver == driverPgx {
+		g.P("   ret.Items, err = ", pgxPackage.Ident("CollectRows"), "(rows, func(rows ", pgxPackage.Ident("CollectableRow"), ") (", record, ", error) {")
+		g.P("       row := new(", message.GoIdent, ")")
+		g.P("       var id ", key)
+		g.P("       err := ", scan)
+		g.P("       return ", record, "{ID: id, Value: row}, err")
+		g.P("   })")
+		g.P("   if err != nil { return nil, err }")
+	} else {
+		g.P("   defer rows.Close()")
+		g.P("")
+		g.P("   for rows.Next() {")
+		g.P("       row := new(", message.GoIdent, ")")
+		g.P("       var id ", key)
+		g.P("")
+		g.P("       err := ", scan)
+		g.P("       if err != nil { return nil, err }")
+		g.P("")
+		g.P("       ret.Items = append(ret.Items, ", record, "{ID: id, Value: row})")
+		g.P("   }")
+		g.P("   if err := rows.Err(); err != nil { return nil, err }")
+	}
+	g.P("")
+	g.P("   ", depPackage.Ident("Paginate"), "(q, ret)")
+	g.P("   return ret, nil")
+	g.P("}")
+	g.P("")
+}
+
+func (p *Generator) generateGetFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	if opts.Versioned {
+		p.generateVersionedGetFunction(g, message, opts)
+		return
+	}
+
+	g.P("// Get function acquires a single record based on ID in database")
+	g.P("func (x *", message.GoIdent, ") Get(ctx ", contextPackage.Ident("Context"), ", db DBTX", tenantParam(opts), ", id ", idType(g, message, opts), ") error {")
+	switch {
+	case opts.Storage == dep.Storage_STORAGE_COLUMNS:
+		g.P("   return x.scanColumns(", p.dbCall("QueryRow"), lowerFirst(message.GoIdent.GoName), "GetQuery, ", tenantArg(opts), ", id))")
+	default:
+		g.P("   return ", p.dbCall("QueryRow"), lowerFirst(message.GoIdent.GoName), "GetQuery, ", tenantArg(opts), ", id).Scan(x)")
+	}
+	g.P("}")
+	g.P("")
+}
+
+func (p *Generator) generateVersionedGetFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	g.P("// Get function acquires a single record based on ID in database and returns its version")
+	g.P("func (x *", message.GoIdent, ") Get(ctx ", contextPackage.Ident("Context"), ", db DBTX", tenantParam(opts), ", id ", idType(g, message, opts), ") (int64, error) {")
+	g.P("   var version int64")
+	switch {
+	case opts.Storage == dep.Storage_STORAGE_COLUMNS:
+		g.P("   err := x.scanColumns(", p.dbCall("QueryRow"), lowerFirst(message.GoIdent.GoName), "GetQuery, ", tenantArg(opts), ", id), &version)")
+	default:
+		g.P("   err := ", p.dbCall("QueryRow"), lowerFirst(message.GoIdent.GoName), "GetQuery, ", tenantArg(opts), ", id).Scan(&version, x)")
+	}
+	g.P("   return version, err")
+	g.P("}")
+	g.P("")
+}
+
+func (p *Generator) generateCreateFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	audited := auditFields(message).any()
+	key, zero := idType(g, message, opts), idZero(g, message, opts)
+
+	if audited {
+		g.P("// Create function will create a new object of this type and return its ID,")
+		g.P("// filling in the audit fields of data")
+	} else {
+		g.P("// Create function will create a new object of this type and return its ID")
+	}
+	if opts.History {
+		g.P("// The object and its first revision are stored in one transaction.")
+	}
+	generateWrite(g, message, write{
+		name:    "Create",
+		params:  tenantParam(opts) + ", data *" + g.QualifiedGoIdent(message.GoIdent),
+		args:    tenantForward(opts) + ", data",
+		results: "(" + key + ", error)",
+		result:  "id",
+		zero:    zero + ", ",
+	}, opts.History, false)
+	if audited {
+		g.P("   data.stamp(ctx, true)")
+	}
+	g.P("   if err := data.Validate(); err != nil {")
+	g.P("       return ", zero, ", err")
+	g.P("   }")
+	g.P("")
+	// Minted ids are passed to the insert, serial ones are returned by it.
+	define := ":="
+	if generateNewID(g, message, opts, zero) {
+		define = "="
+	}
+	switch {
+	case p.usesRoutines(opts) && mintsID(opts):
+		g.P(`   _, err `, define, ` `, p.dbCall("Exec"), `"SELECT create_data($1, $2, $3`, p.idCast(message, opts), `, $4)", `, tenantArg(opts), `, x.TableName(), id, data)`)
+	case p.usesRoutines(opts):
+		g.P("   var id ", key)
+		g.P(`   err := `, p.dbCall("QueryRow"), `"SELECT create_data($1, $2, NULL`, p.idCast(message, opts), `, $3)", `, tenantArg(opts), `, x.TableName(), data).Scan(&id)`)
+	case opts.Storage == dep.Storage_STORAGE_COLUMNS:
+		g.P("   values, err := data.columnValues()")
+		g.P("   if err != nil {")
+		g.P("       return ", zero, ", err")
+		g.P("   }")
+		g.P("")
+		define = "="
+		fallthrough
+	default:
+		args := tenantArg(opts) + ", data"
+		if opts.Storage == dep.Storage_STORAGE_COLUMNS {
+			args = "append([]any{" + tenantArg(opts) + "}, values...)"
+		}
+		if mintsID(opts) {
+			if opts.Storage == dep.Storage_STORAGE_COLUMNS {
+				args = "append(" + args + ", id)..."
+			} else {
+				args += ", id"
+			}
+			g.P("   _, err ", define, " ", p.dbCall("Exec"), lowerFirst(message.GoIdent.GoName), "InsertQuery, ", args, ")")
+		} else {
+			if opts.Storage == dep.Storage_STORAGE_COLUMNS {
+				args += "..."
+			}
+			g.P("   var id ", key)
+			g.P("   err ", define, " ", p.dbCall("QueryRow"), lowerFirst(message.GoIdent.GoName), "InsertQuery, ", args, ").Scan(&id)")
 		}
 	}
-	return false
+	g.P("   if err != nil {")
+	g.P("       return ", zero, ", err")
+	g.P("   }")
+	if opts.History {
+		g.P("   if err := x.record(ctx, db", tenantForward(opts), ", id, ", depPackage.Ident("RevisionCreate"), ", data); err != nil {")
+		g.P("       return ", zero, ", err")
+		g.P("   }")
+	}
+	g.P("   return id, nil")
+	g.P("}")
+	g.P("")
+}
+
+func (p *Generator) generateUpdateFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	if opts.Versioned {
+		p.generateVersionedUpdateFunction(g, message, opts)
+		return
+	}
+
+	a := auditFields(message)
+
+	g.P("// Update function will replace the object stored at the given ID")
+	if a.any() {
+		g.P("// but for the fields recording its creation, filling in the audit fields of data")
+	}
+	if len(a.created()) > 0 {
+		g.P("// The fields recording the creation are read back into data.")
+	}
+	if opts.History {
+		g.P("// The object and its revision are stored in one transaction.")
+	}
+	generateWrite(g, message, write{
+		name:    "Update",
+		params:  tenantParam(opts) + ", id " + idType(g, message, opts) + ", data *" + g.QualifiedGoIdent(message.GoIdent),
+		args:    tenantForward(opts) + ", id, data",
+		results: "error",
+	}, readsBack(message, opts), false)
+	generateKeepKey(g, message, opts)
+	if a.any() {
+		g.P("   data.stamp(ctx, false)")
+	}
+	g.P("   if err := data.Validate(); err != nil {")
+	g.P("       return err")
+	g.P("   }")
+	g.P("")
+	// The id is read back so a missing record fails the scan with no rows.
+	// With a history the error is checked before the revision is recorded,
+	// rather than returned.
+	ret, assign := "return ", "return "
+	if readsBack(message, opts) {
+		ret, assign = "err := ", "err = "
+	}
+	switch {
+	case p.usesRoutines(opts):
+		value := "$4"
+		if len(a.created()) > 0 {
+			value = keepData("$4", a)
+		}
+		g.P("   var found ", idType(g, message, opts))
+		g.P(`   `, ret, p.dbCall("QueryRow"), `"SELECT id FROM update_data($1, $2, $3`, p.idCast(message, opts), `, `, value, `) AS id WHERE id IS NOT NULL",`)
+		g.P("       ", tenantArg(opts), ", ", routineTable(opts), ", id, data).Scan(&found)")
+	case opts.Storage == dep.Storage_STORAGE_COLUMNS:
+		g.P("   values, err := data.columnValues()")
+		g.P("   if err != nil {")
+		g.P("       return err")
+		g.P("   }")
+		g.P("")
+		g.P("   var found ", idType(g, message, opts))
+		if p.dialect == dialectSQLite {
+			g.P("   ", assign, p.dbCall("QueryRow"), lowerFirst(message.GoIdent.GoName), "UpdateQuery, append(values, ", tenantArg(opts), ", id)...).Scan(&found)")
+		} else {
+			g.P("   ", assign, p.dbCall("QueryRow"), lowerFirst(message.GoIdent.GoName), "UpdateQuery, append([]any{", tenantArg(opts), ", id}, values...)...).Scan(&found)")
+		}
+	case p.dialect == dialectSQLite:
+		g.P("   var found ", idType(g, message, opts))
+		g.P("   ", ret, p.dbCall("QueryRow"), lowerFirst(message.GoIdent.GoName), "UpdateQuery, data, ", tenantArg(opts), ", id).Scan(&found)")
+	default:
+		g.P("   var found ", idType(g, message, opts))
+		g.P("   ", ret, p.dbCall("QueryRow"), lowerFirst(message.GoIdent.GoName), "UpdateQuery, ", tenantArg(opts), ", id, data).Scan(&found)")
+	}
+	if readsBack(message, opts) {
+		g.P("   if err != nil {")
+		g.P("       return err")
+		g.P("   }")
+		generateReadBack(g, message, opts, "")
+		generateKeptFields(g, a)
+	}
+	if opts.History {
+		g.P("   return x.record(ctx, db", tenantForward(opts), ", id, ", depPackage.Ident("RevisionUpdate"), ", current)")
+	} else if readsBack(message, opts) {
+		g.P("   return nil")
+	}
+	g.P("}")
+	g.P("")
+}
+
+func (p *Generator) generateVersionedUpdateFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	prefix := lowerFirst(message.GoIdent.GoName)
+
+	a := auditFields(message)
+
+	g.P("// Update function will replace the object stored at the given ID while it is at")
+	g.P("// version, any version when it is 0, and returns the version it stored")
+	if len(a.created()) > 0 {
+		g.P("// The fields recording its creation are kept and read back into data, the other")
+		g.P("// audit fields of data filled in.")
+	} else if a.any() {
+		g.P("// The fields recording its creation are kept, the audit fields of data filled in.")
+	}
+	if opts.History {
+		g.P("// The object and its revision are stored in one transaction.")
+	}
+	generateWrite(g, message, write{
+		name:    "Update",
+		params:  tenantParam(opts) + ", id " + idType(g, message, opts) + ", version int64, data *" + g.QualifiedGoIdent(message.GoIdent),
+		args:    tenantForward(opts) + ", id, version, data",
+		results: "(int64, error)",
+		result:  "stored",
+		zero:    "0, ",
+	}, readsBack(message, opts), false)
+	generateKeepKey(g, message, opts)
+	if a.any() {
+		g.P("   data.stamp(ctx, false)")
+	}
+	g.P("   if err := data.Validate(); err != nil {")
+	g.P("       return 0, err")
+	g.P("   }")
+	g.P("")
+	var row string
+	switch {
+	case p.usesRoutines(opts):
+		data := "$5"
+		if len(a.created()) > 0 {
+			data = keepData("$5", a)
+		}
+		g.P("   var stored int64")
+		g.P(`   err := `, p.dbCall("QueryRow"), `"SELECT version FROM update_versioned_data($1, $2, $3`, p.idCast(message, opts), `, $4, `, data, `) AS version WHERE version IS NOT NULL",`)
+		g.P("       ", tenantArg(opts), ", ", routineTable(opts), ", id, version, data).Scan(&stored)")
+	case opts.Storage == dep.Storage_STORAGE_COLUMNS:
+		g.P("   values, err := data.columnValues()")
+		g.P("   if err != nil {")
+		g.P("       return 0, err")
+		g.P("   }")
+		g.P("")
+		g.P("   var stored int64")
+		if p.dialect == dialectSQLite {
+			row = "append(values, " + tenantArg(opts) + ", id, version)..."
+		} else {
+			row = "append(append([]any{" + tenantArg(opts) + ", id}, values...), version)..."
+		}
+		g.P("   err = ", p.dbCall("QueryRow"), prefix, "UpdateQuery, ", row, ").Scan(&stored)")
+	default:
+		g.P("   var stored int64")
+		g.P("   err := ", p.dbCall("QueryRow"), prefix, "UpdateQuery, data, ", tenantArg(opts), ", id, version).Scan(&stored)")
+	}
+	g.P("   if err != nil {")
+	g.P("       return 0, x.conflict(ctx, db", tenantForward(opts), ", id, version, err)")
+	g.P("   }")
+	if readsBack(message, opts) {
+		generateReadBack(g, message, opts, "0, ")
+		generateKeptFields(g, a)
+	}
+	if opts.History {
+		g.P("   if err := x.record(ctx, db", tenantForward(opts), ", id, ", depPackage.Ident("RevisionUpdate"), ", current); err != nil {")
+		g.P("       return 0, err")
+		g.P("   }")
+	}
+	g.P("   return stored, nil")
+	g.P("}")
+	g.P("")
+}
+
+func (p *Generator) generatePatchFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	prefix := lowerFirst(message.GoIdent.GoName)
+
+	// Versioned resources take the version expected and return the one
+	// stored, zero leads their error returns.
+	version, results, zero := "", "error", ""
+	if opts.Versioned {
+		version, results, zero = ", version int64", "(int64, error)", "0, "
+	}
+
+	g.P("// Patch function stores the fields of data named by mask in the object at the")
+	if opts.Versioned {
+		g.P("// given ID while it is at version, any version when it is 0, leaving the others")
+		g.P("// as they are, reads the result into x and returns the version it stored")
+	} else {
+		g.P("// given ID, leaving the others as they are, and reads the result into x")
+	}
+	g.P("// The result is validated as a whole, the patch is rolled back when it is not valid.")
+	if opts.History {
+		g.P("// The write and its revision are made in one transaction.")
+	}
+	w := write{
+		name:    "Patch",
+		params:  tenantParam(opts) + ", id " + idType(g, message, opts) + version + ", data *" + g.QualifiedGoIdent(message.GoIdent) + ", mask *" + g.QualifiedGoIdent(fieldmaskpbPackage.Ident("FieldMask")),
+		args:    tenantForward(opts) + ", id" + strings.TrimSuffix(version, " int64") + ", data, mask",
+		results: results,
+		zero:    zero,
+	}
+	if opts.Versioned {
+		w.result = "stored"
+	}
+	generateWrite(g, message, w, true, true)
+	g.P("   paths, err := ", depPackage.Ident("FieldMaskPaths"), "(data, mask)")
+	g.P("   if err != nil {")
+	g.P("       return ", zero, "err")
+	g.P("   }")
+	generateKeepKey(g, message, opts)
+	if a := auditFields(message); a.any() {
+		g.P("   data.stamp(ctx, false)")
+		generateStampPaths(g, a)
+	}
+	g.P("   if err := ", depPackage.Ident("MaskedErrors"), "(data.Validate(), paths); err != nil {")
+	g.P("       return ", zero, "err")
+	g.P("   }")
+	g.P("")
+
+	// scan is the tail of the statement reading the result into x. Without a
+	// version or a history to handle the statement is returned right away.
+	var scan string
+	ret := "return "
+	if opts.History {
+		ret = "err = "
+	}
+	if opts.Storage == dep.Storage_STORAGE_COLUMNS {
+		dialect, first := depPackage.Ident("Postgres"), 3
+		if p.dialect == dialectSQLite {
+			dialect, first = depPackage.Ident("SQLite"), 1
+		} else if opts.Versioned {
+			first = 4
+		}
+		g.P("   values, err := data.columnValues()")
+		g.P("   if err != nil {")
+		g.P("       return ", zero, "err")
+		g.P("   }")
+		g.P("   set, values, err := ", depPackage.Ident("PatchColumns"), "(", dialect, ", ", first, ", data, paths, ", prefix, "Columns, values)")
+		g.P("   if err != nil {")
+		g.P("       return ", zero, "err")
+		g.P("   }")
+		g.P("")
+		g.P("   query := ", fmtPackage.Ident("Sprintf"), "(", prefix, "PatchQuery, set)")
+		var args string
+		switch {
+		case p.dialect == dialectSQLite && opts.Versioned:
+			args = "append(values, " + tenantArg(opts) + ", id, version)..."
+		case p.dialect == dialectSQLite:
+			args = "append(values, " + tenantArg(opts) + ", id)..."
+		case opts.Versioned:
+			args = "append([]any{" + tenantArg(opts) + ", id, version}, values...)..."
+		default:
+			args = "append([]any{" + tenantArg(opts) + ", id}, values...)..."
+		}
+		if !opts.Versioned {
+			g.P("   ", ret, "x.scanColumns(", p.dbCall("QueryRow"), "query, ", args, "))")
+		} else {
+			scan = "x.scanColumns(" + p.dbCall("QueryRow") + "query, " + args + "), &stored)"
+		}
+	} else {
+		g.P("   // The first patch removes the masked fields, the second stores the ones")
+		g.P("   // data has, so messages, lists and maps are replaced rather than merged.")
+		g.P("   remove, store, err := ", depPackage.Ident("MergePatches"), "(data, paths)")
+		g.P("   if err != nil {")
+		g.P("       return ", zero, "err")
+		g.P("   }")
+		g.P("")
+		switch {
+		case p.usesRoutines(opts) && opts.Versioned:
+			g.P("   var stored int64")
+			g.P(`   err = `, p.dbCall("QueryRow"), `"SELECT version, data FROM patch_versioned_data($1, $2, $3`, p.idCast(message, opts), `, $4, $5, $6) WHERE version IS NOT NULL",`)
+			g.P("       ", tenantArg(opts), ", ", routineTable(opts), ", id, version, remove, store).Scan(&stored, x)")
+		case p.usesRoutines(opts):
+			g.P(`   `, ret, p.dbCall("QueryRow"), `"SELECT data FROM patch_data($1, $2, $3`, p.idCast(message, opts), `, $4, $5) AS data WHERE data IS NOT NULL",`)
+			g.P("       ", tenantArg(opts), ", ", routineTable(opts), ", id, remove, store).Scan(x)")
+		case opts.Versioned:
+			scan = p.dbCall("QueryRow") + prefix + "PatchQuery, remove, store, " + tenantArg(opts) + ", id, version).Scan(&stored, x)"
+		default:
+			g.P("   ", ret, p.dbCall("QueryRow"), prefix, "PatchQuery, remove, store, ", tenantArg(opts), ", id).Scan(x)")
+		}
+	}
+	if !opts.Versioned {
+		if opts.History {
+			g.P("   if err != nil {")
+			g.P("       return err")
+			g.P("   }")
+			g.P("   return x.record(ctx, db", tenantForward(opts), ", id, ", depPackage.Ident("RevisionPatch"), ", x)")
+		}
+		g.P("}")
+		g.P("")
+		return
+	}
+
+	if scan != "" {
+		g.P("   var stored int64")
+		g.P("   err = ", scan)
+	}
+	g.P("   if err != nil {")
+	g.P("       return 0, x.conflict(ctx, db", tenantForward(opts), ", id, version, err)")
+	g.P("   }")
+	if opts.History {
+		g.P("   if err := x.record(ctx, db", tenantForward(opts), ", id, ", depPackage.Ident("RevisionPatch"), ", x); err != nil {")
+		g.P("       return 0, err")
+		g.P("   }")
+	}
+	g.P("   return stored, nil")
+	g.P("}")
+	g.P("")
+}
+
+func (p *Generator) generateDeleteFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	prefix := lowerFirst(message.GoIdent.GoName)
+
+	if opts.Versioned {
+		if opts.SoftDelete {
+			g.P("// Delete function will move the object at given ID to the trash while it is at")
+			g.P("// version, any version when it is 0")
+		} else {
+			g.P("// Delete function will delete the object at given ID while it is at version, any")
+			g.P("// version when it is 0")
+		}
+		if opts.History {
+			g.P("// The write and its revision are made in one transaction.")
+		}
+		generateWrite(g, message, write{
+			name:    "Delete",
+			params:  tenantParam(opts) + ", id " + idType(g, message, opts) + ", version int64",
+			args:    tenantForward(opts) + ", id, version",
+			results: "error",
+		}, opts.History, false)
+		g.P("   var stored int64")
+		switch {
+		case p.usesRoutines(opts) && opts.SoftDelete:
+			g.P(`   err := `, p.dbCall("QueryRow"), `"SELECT version FROM trash_versioned_data($1, $2, $3`, p.idCast(message, opts), `, $4) AS version WHERE version IS NOT NULL",`)
+			g.P("       ", tenantArg(opts), ", x.TableName(), id, version).Scan(&stored)")
+		case p.usesRoutines(opts):
+			g.P(`   err := `, p.dbCall("QueryRow"), `"SELECT version FROM delete_versioned_data($1, $2, $3`, p.idCast(message, opts), `, $4) AS version WHERE version IS NOT NULL",`)
+			g.P("       ", tenantArg(opts), ", x.TableName(), id, version).Scan(&stored)")
+		default:
+			g.P("   err := ", p.dbCall("QueryRow"), prefix, "DeleteQuery, ", tenantArg(opts), ", id, version).Scan(&stored)")
+		}
+		if opts.History {
+			g.P("   if err != nil {")
+			g.P("       return x.conflict(ctx, db", tenantForward(opts), ", id, version, err)")
+			g.P("   }")
+			g.P("   return x.record(ctx, db", tenantForward(opts), ", id, ", depPackage.Ident("RevisionDelete"), ", nil)")
+		} else {
+			g.P("   return x.conflict(ctx, db", tenantForward(opts), ", id, version, err)")
+		}
+		g.P("}")
+		g.P("")
+		return
+	}
+
+	if opts.SoftDelete {
+		g.P("// Delete function will move the object at given ID to the trash")
+	} else {
+		g.P("// Delete function will... well delete the object at given ID")
+	}
+	if opts.History {
+		g.P("// The write and its revision are made in one transaction.")
+	}
+	generateWrite(g, message, write{
+		name:    "Delete",
+		params:  tenantParam(opts) + ", id " + idType(g, message, opts),
+		args:    tenantForward(opts) + ", id",
+		results: "error",
+	}, opts.History, false)
+	// With a history the statement is checked before the revision is
+	// recorded, rather than returned.
+	ret := "return "
+	if opts.History {
+		ret = "err := "
+	}
+	g.P("   var found ", idType(g, message, opts))
+	switch {
+	case p.usesRoutines(opts) && opts.SoftDelete:
+		g.P(`   `, ret, p.dbCall("QueryRow"), `"SELECT id FROM trash_data($1, $2, $3`, p.idCast(message, opts), `) AS id WHERE id IS NOT NULL",`)
+		g.P("       ", tenantArg(opts), ", x.TableName(), id).Scan(&found)")
+	case p.usesRoutines(opts):
+		g.P(`   `, ret, p.dbCall("QueryRow"), `"SELECT id FROM delete_data_by_id($1, $2, $3`, p.idCast(message, opts), `) AS id WHERE id IS NOT NULL",`)
+		g.P("       ", tenantArg(opts), ", x.TableName(), id).Scan(&found)")
+	default:
+		g.P("   ", ret, p.dbCall("QueryRow"), prefix, "DeleteQuery, ", tenantArg(opts), ", id).Scan(&found)")
+	}
+	if opts.History {
+		g.P("   if err != nil {")
+		g.P("       return err")
+		g.P("   }")
+		g.P("   return x.record(ctx, db", tenantForward(opts), ", id, ", depPackage.Ident("RevisionDelete"), ", nil)")
+	}
+	g.P("}")
+	g.P("")
+}
+
+// generateTrashFunctions emits Restore and Purge, which only find records
+// in the trash of a soft deleted resource.
+func (p *Generator) generateTrashFunctions(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	prefix := lowerFirst(message.GoIdent.GoName)
+
+	for _, op := range []struct{ name, doc, routine, revision string }{
+		{"Restore", "// Restore function brings the object at the given ID back from the trash", "restore_data", "RevisionRestore"},
+		{"Purge", "// Purge function deletes the object at the given ID from the trash for good", "purge_data", "RevisionPurge"},
+	} {
+		// With a history the statement is checked before the revision is
+		// recorded, rather than returned.
+		ret := "return "
+		if opts.History {
+			ret = "err := "
+		}
+		g.P(op.doc)
+		if opts.History {
+			g.P("// The write and its revision are made in one transaction.")
+		}
+		generateWrite(g, message, write{
+			name:    op.name,
+			params:  tenantParam(opts) + ", id " + idType(g, message, opts),
+			args:    tenantForward(opts) + ", id",
+			results: "error",
+		}, opts.History, false)
+		g.P("   var found ", idType(g, message, opts))
+		if p.usesRoutines(opts) {
+			g.P(`   `, ret, p.dbCall("QueryRow"), `"SELECT id FROM `, op.routine, `($1, $2, $3`, p.idCast(message, opts), `) AS id WHERE id IS NOT NULL",`)
+			g.P("       ", tenantArg(opts), ", x.TableName(), id).Scan(&found)")
+		} else {
+			g.P("   ", ret, p.dbCall("QueryRow"), prefix, op.name, "Query, ", tenantArg(opts), ", id).Scan(&found)")
+		}
+		switch {
+		case opts.History && op.name == "Restore":
+			g.P("   if err != nil {")
+			g.P("       return err")
+			g.P("   }")
+			generateReadBack(g, message, opts, "")
+			g.P("   return x.record(ctx, db", tenantForward(opts), ", id, ", depPackage.Ident(op.revision), ", current)")
+		case opts.History:
+			g.P("   if err != nil {")
+			g.P("       return err")
+			g.P("   }")
+			g.P("   return x.record(ctx, db", tenantForward(opts), ", id, ", depPackage.Ident(op.revision), ", nil)")
+		}
+		g.P("}")
+		g.P("")
+	}
+}
+
+// generateConflictFunction emits conflict, which the writes of a versioned
+// resource call when they found no row to tell why.
+func (p *Generator) generateConflictFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	g.P("// conflict tells a record at another version from a missing one after a write")
+	g.P("// checking version found no row, returning dep.ErrConflict for the former")
+	g.P("func (x *", message.GoIdent, ") conflict(ctx ", contextPackage.Ident("Context"), ", db DBTX", tenantParam(opts), ", id ", idType(g, message, opts), ", version int64, err error) error {")
+	g.P("   if version == 0 || !", errorsPackage.Ident("Is"), "(err, ", p.errNoRows(), ") {")
+	g.P("       return err")
+	g.P("   }")
+	g.P("")
+	g.P("   var n int")
+	g.P("   if err := ", p.dbCall("QueryRow"), lowerFirst(message.GoIdent.GoName), "ExistsQuery, ", tenantArg(opts), ", id).Scan(&n); err != nil {")
+	g.P("       return err")
+	g.P("   }")
+	g.P("   if n == 0 {")
+	g.P("       return err")
+	g.P("   }")
+	g.P("   return ", depPackage.Ident("ErrConflict"))
+	g.P("}")
+	g.P("")
+}
+
+func (p *Generator) generateTableFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	g.P("// TableName returns the name of the table backing ", message.GoIdent.GoName)
+	g.P("func (*", message.GoIdent, ") TableName() string {")
+	g.P(`   return "`, opts.Table, `"`)
+	g.P("}")
+	g.P("")
+}
+
+// generateDocumentMethods makes the message a sql.Scanner and driver.Valuer
+// so it round trips through the JSONB data column as protojson.
+func (p *Generator) generateDocumentMethods(g *protogen.GeneratedFile, message *protogen.Message) error {
+	for _, field := range message.Fields {
+		if field.GoName == "Scan" || field.GoName == "Value" {
+			return fmt.Errorf("%s: field %s clashes with the generated %s method, use STORAGE_COLUMNS",
+				message.Desc.FullName(), field.Desc.Name(), field.GoName)
+		}
+	}
+
+	g.P("// Scan implements sql.Scanner, reading x from the protojson document in a")
+	g.P("// JSONB column. Unknown fields are discarded so removed fields do not break reads.")
+	g.P("func (x *", message.GoIdent, ") Scan(src any) error {")
+	g.P("   var data []byte")
+	g.P("   switch src := src.(type) {")
+	g.P("   case nil:")
+	g.P("       ", protoPackage.Ident("Reset"), "(x)")
+	g.P("       return nil")
+	g.P("   case []byte:")
+	g.P("       data = src")
+	g.P("   case string:")
+	g.P("       data = []byte(src)")
+	g.P("   default:")
+	g.P(`       return `, fmtPackage.Ident("Errorf"), `("cannot scan %T into `, message.GoIdent.GoName, `", src)`)
+	g.P("   }")
+	g.P("")
+	g.P("   return ", protojsonPackage.Ident("UnmarshalOptions"), "{DiscardUnknown: true}.Unmarshal(data, x)")
+	g.P("}")
+	g.P("")
+	g.P("// Value implements driver.Valuer, storing x as a protojson document")
+	g.P("func (x *", message.GoIdent, ") Value() (", driverPackage.Ident("Value"), ", error) {")
+	g.P("   if x == nil {")
+	g.P("       return nil, nil")
+	g.P("   }")
+	g.P("")
+	g.P("   data, err := ", protojsonPackage.Ident("Marshal"), "(x)")
+	g.P("   if err != nil {")
+	g.P("       return nil, err")
+	g.P("   }")
+	g.P("")
+	g.P("   // Drivers send []byte as bytea, JSONB wants text.")
+	g.P("   return string(data), nil")
+	g.P("}")
+	g.P("")
+	return nil
+}
+
+func (p *Generator) generateHandlers(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	handlerName := message.GoIdent.GoName + "Handler"
+	htmx := opts.UiMode == dep.UiMode_UI_MODE_HTMX
+
+	// tenant is how the handlers pass the request context and the tenant on
+	// to the repository, it leads the arguments so the trailing comma is part
+	// of it.
+	tenant := "req.Context(), h.tenant(req), "
+	if opts.Global {
+		tenant = "req.Context(), "
+	}
+
+	trash := opts.SoftDelete && hasOperation(opts, dep.Operation_OPERATION_DELETE)
+	unique := hasUniqueKeys(message, opts)
+
+	var lists []string
+	if hasOperation(opts, dep.Operation_OPERATION_LIST) {
+		lists = append(lists, "List")
+		if trash {
+			lists = append(lists, "ListDeleted")
+		}
+	}
+	for _, list := range lists {
+		if list == "List" {
+			g.P("// ListHandler renders the page of objects selected by the page_size, page_token,")
+			g.P("// skip, filter and order_by query parameters")
+		} else {
+			g.P("// ListDeletedHandler renders the page of objects in the trash selected by the")
+			g.P("// page_size, page_token, skip, filter and order_by query parameters")
+		}
+		g.P("func (h *", handlerName, ") ", list, "Handler(w ", httpPackage.Ident("ResponseWriter"), ", req *", httpPackage.Ident("Request"), ") {")
+		g.P("   opts, err := ", depPackage.Ident("ParseListOptions"), "(req.URL.Query())")
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusBadRequest"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		g.P("   ret, err := h.Repo.", list, "(", tenant, "opts)")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrInvalidArgument"), ") {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusBadRequest"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		g.P("   // Records encode their values with protojson")
+		g.P("   jsonData, err := ", jsonPackage.Ident("Marshal"), "(ret)")
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		g.P(`   w.Header().Set("Content-Type", "application/json")`)
+		g.P("   w.Write(jsonData)")
+		g.P("}")
+		g.P("")
+	}
+
+	if hasOperation(opts, dep.Operation_OPERATION_GET) {
+		if opts.Versioned {
+			g.P("// GetHandler renders the object at the {id} url parameter with its version as the")
+			g.P("// ETag, or answers a 304 when If-None-Match names that version")
+		} else {
+			g.P("// GetHandler renders the object at the {id} url parameter")
+		}
+		g.P("func (h *", handlerName, ") GetHandler(w ", httpPackage.Ident("ResponseWriter"), ", req *", httpPackage.Ident("Request"), ") {")
+		generateReadID(g, "   ")
+		g.P("")
+		if opts.Versioned {
+			g.P("   x, version, err := h.Repo.Get(", tenant, "id)")
+		} else {
+			g.P("   x, err := h.Repo.Get(", tenant, "id)")
+		}
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrNotFound"), ") {")
+		g.P("       ", httpPackage.Ident("NotFound"), "(w, req)")
+		g.P("       return")
+		g.P("   }")
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		if opts.Versioned {
+			g.P(`   w.Header().Set("ETag", `, depPackage.Ident("ETag"), `(version))`)
+			g.P("   if ", depPackage.Ident("NoneMatch"), "(req, version) {")
+			g.P("       w.WriteHeader(", httpPackage.Ident("StatusNotModified"), ")")
+			g.P("       return")
+			g.P("   }")
+		}
+		g.P("   h.render(w, req, ", httpPackage.Ident("StatusOK"), ", x)")
+		g.P("}")
+		g.P("")
+	}
+
+	if hasOperation(opts, dep.Operation_OPERATION_CREATE) {
+		g.P("// CreateHandler stores the object sent in the request body, the Location header")
+		g.P("// of the response names its url")
+		g.P("func (h *", handlerName, ") CreateHandler(w ", httpPackage.Ident("ResponseWriter"), ", req *", httpPackage.Ident("Request"), ") {")
+		g.P("   x := new(", message.GoIdent, ")")
+		g.P("   if err := h.decode(req, x); err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusBadRequest"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		g.P("   id, err := h.Repo.Create(", tenant, "x)")
+		if unique {
+			generateAlreadyExists(g, htmx, "x")
+		}
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		g.P(`   w.Header().Set("Location", `, pathPackage.Ident("Join"), "(req.URL.Path, ", fmtPackage.Ident("Sprint"), "(id)))")
+		if opts.Versioned {
+			g.P("   // Records start at version 1")
+			g.P(`   w.Header().Set("ETag", `, depPackage.Ident("ETag"), `(1))`)
+		}
+		g.P("   h.render(w, req, ", httpPackage.Ident("StatusCreated"), ", x)")
+		g.P("}")
+		g.P("")
+	}
+
+	if hasOperation(opts, dep.Operation_OPERATION_UPDATE) {
+		g.P("// UpdateHandler replaces the object at the {id} url parameter with the request body")
+		if opts.Versioned {
+			g.P("// when it is at the version If-Match names")
+		}
+		g.P("func (h *", handlerName, ") UpdateHandler(w ", httpPackage.Ident("ResponseWriter"), ", req *", httpPackage.Ident("Request"), ") {")
+		generateReadID(g, "   ")
+		g.P("")
+		g.P("   x := new(", message.GoIdent, ")")
+		g.P("   if err := h.decode(req, x); err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusBadRequest"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		if opts.Versioned {
+			g.P("   version, err := ", depPackage.Ident("IfMatch"), "(req)")
+			g.P("   if err != nil {")
+			g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusBadRequest"), ")")
+			g.P("       return")
+			g.P("   }")
+			g.P("")
+		}
+		if opts.Versioned {
+			g.P("   version, err = h.Repo.Update(", tenant, "id, version, x)")
+		} else {
+			g.P("   err := h.Repo.Update(", tenant, "id, x)")
+		}
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrNotFound"), ") {")
+		g.P("       ", httpPackage.Ident("NotFound"), "(w, req)")
+		g.P("       return")
+		g.P("   }")
+		if opts.Versioned {
+			g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrConflict"), ") {")
+			g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusPreconditionFailed"), ")")
+			g.P("       return")
+			g.P("   }")
+		}
+		if unique {
+			generateAlreadyExists(g, htmx, "x")
+		}
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		if opts.Versioned {
+			g.P(`   w.Header().Set("ETag", `, depPackage.Ident("ETag"), `(version))`)
+		}
+		g.P("   h.render(w, req, ", httpPackage.Ident("StatusOK"), ", x)")
+		g.P("}")
+		g.P("")
+	}
+
+	if hasOperation(opts, dep.Operation_OPERATION_UPDATE) {
+		g.P("// PatchHandler stores the fields of the request body named by its mask in the object")
+		if opts.Versioned {
+			g.P("// at the {id} url parameter, when it is at the version If-Match names, and renders")
+			g.P("// the result")
+		} else {
+			g.P("// at the {id} url parameter and renders the result")
+		}
+		g.P("func (h *", handlerName, ") PatchHandler(w ", httpPackage.Ident("ResponseWriter"), ", req *", httpPackage.Ident("Request"), ") {")
+		generateReadID(g, "   ")
+		g.P("")
+		g.P("   x := new(", message.GoIdent, ")")
+		g.P("   mask, err := h.decodePatch(req, x)")
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusBadRequest"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		if opts.Versioned {
+			g.P("   version, err := ", depPackage.Ident("IfMatch"), "(req)")
+			g.P("   if err != nil {")
+			g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusBadRequest"), ")")
+			g.P("       return")
+			g.P("   }")
+			g.P("")
+		}
+		if opts.Versioned {
+			g.P("   ret, version, err := h.Repo.Patch(", tenant, "id, version, x, mask)")
+		} else {
+			g.P("   ret, err := h.Repo.Patch(", tenant, "id, x, mask)")
+		}
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrNotFound"), ") {")
+		g.P("       ", httpPackage.Ident("NotFound"), "(w, req)")
+		g.P("       return")
+		g.P("   }")
+		if opts.Versioned {
+			g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrConflict"), ") {")
+			g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusPreconditionFailed"), ")")
+			g.P("       return")
+			g.P("   }")
+		}
+		if unique {
+			generateAlreadyExists(g, htmx, "x")
+		}
+		g.P("   var invalid ", depPackage.Ident("ValidationErrors"))
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrInvalidArgument"), ") || ", errorsPackage.Ident("As"), "(err, &invalid) {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusBadRequest"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		if opts.Versioned {
+			g.P(`   w.Header().Set("ETag", `, depPackage.Ident("ETag"), `(version))`)
+		}
+		g.P("   h.render(w, req, ", httpPackage.Ident("StatusOK"), ", ret)")
+		g.P("}")
+		g.P("")
+	}
+
+	if hasOperation(opts, dep.Operation_OPERATION_DELETE) {
+		switch {
+		case trash && opts.Versioned:
+			g.P("// DeleteHandler moves the object at the {id} url parameter to the trash when it")
+			g.P("// is at the version If-Match names")
+		case trash:
+			g.P("// DeleteHandler moves the object at the {id} url parameter to the trash")
+		case opts.Versioned:
+			g.P("// DeleteHandler deletes the object at the {id} url parameter when it is at the")
+			g.P("// version If-Match names")
+		default:
+			g.P("// DeleteHandler deletes the object at the {id} url parameter")
+		}
+		g.P("func (h *", handlerName, ") DeleteHandler(w ", httpPackage.Ident("ResponseWriter"), ", req *", httpPackage.Ident("Request"), ") {")
+		generateReadID(g, "   ")
+		g.P("")
+		if opts.Versioned {
+			g.P("   version, err := ", depPackage.Ident("IfMatch"), "(req)")
+			g.P("   if err != nil {")
+			g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusBadRequest"), ")")
+			g.P("       return")
+			g.P("   }")
+			g.P("")
+			g.P("   err = h.Repo.Delete(", tenant, "id, version)")
+		} else {
+			g.P("   err := h.Repo.Delete(", tenant, "id)")
+		}
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrNotFound"), ") {")
+		g.P("       ", httpPackage.Ident("NotFound"), "(w, req)")
+		g.P("       return")
+		g.P("   }")
+		if opts.Versioned {
+			g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrConflict"), ") {")
+			g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusPreconditionFailed"), ")")
+			g.P("       return")
+			g.P("   }")
+		}
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		if htmx {
+			g.P("   // htmx only swaps the target on a 200")
+			g.P(`   if req.Header.Get("HX-Request") == "true" {`)
+			g.P("       w.WriteHeader(", httpPackage.Ident("StatusOK"), ")")
+			g.P("       return")
+			g.P("   }")
+		}
+		g.P("   w.WriteHeader(", httpPackage.Ident("StatusNoContent"), ")")
+		g.P("}")
+		g.P("")
+	}
+
+	if trash {
+		for _, method := range []struct{ name, doc string }{
+			{"Restore", "takes the object at the {id} url parameter back out of the trash"},
+			{"Purge", "deletes the object at the {id} url parameter from the trash for good"},
+		} {
+			g.P("// ", method.name, "Handler ", method.doc)
+			g.P("func (h *", handlerName, ") ", method.name, "Handler(w ", httpPackage.Ident("ResponseWriter"), ", req *", httpPackage.Ident("Request"), ") {")
+			generateReadID(g, "   ")
+			g.P("")
+			g.P("   err := h.Repo.", method.name, "(", tenant, "id)")
+			g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrNotFound"), ") {")
+			g.P("       ", httpPackage.Ident("NotFound"), "(w, req)")
+			g.P("       return")
+			g.P("   }")
+			if method.name == "Restore" && unique {
+				// There is no form to mark the fields in.
+				generateAlreadyExists(g, false, "")
+			}
+			g.P("   if err != nil {")
+			g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+			g.P("       return")
+			g.P("   }")
+			g.P("")
+			if htmx {
+				g.P("   // htmx only swaps the target on a 200")
+				g.P(`   if req.Header.Get("HX-Request") == "true" {`)
+				g.P("       w.WriteHeader(", httpPackage.Ident("StatusOK"), ")")
+				g.P("       return")
+				g.P("   }")
+			}
+			g.P("   w.WriteHeader(", httpPackage.Ident("StatusNoContent"), ")")
+			g.P("}")
+			g.P("")
+		}
+	}
+
+	if opts.History {
+		if htmx {
+			g.P("// HistoryHandler renders the revisions of the object at the {id} url parameter,")
+			g.P("// as a table of the fields each one changed to htmx requests")
+		} else {
+			g.P("// HistoryHandler renders the revisions of the object at the {id} url parameter")
+		}
+		g.P("func (h *", handlerName, ") HistoryHandler(w ", httpPackage.Ident("ResponseWriter"), ", req *", httpPackage.Ident("Request"), ") {")
+		generateReadID(g, "   ")
+		g.P("")
+		g.P("   revisions, err := h.Repo.History(", tenant, "id)")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrNotFound"), ") {")
+		g.P("       ", httpPackage.Ident("NotFound"), "(w, req)")
+		g.P("       return")
+		g.P("   }")
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		if htmx {
+			g.P(`   if req.Header.Get("HX-Request") == "true" {`)
+			g.P(`       w.Header().Set("Content-Type", "text/html; charset=utf-8")`)
+			g.P("       ", depPackage.Ident("RenderHistory"), "(w, revisions)")
+			g.P("       return")
+			g.P("   }")
+			g.P("")
+		}
+		g.P("   // Revisions encode their values with protojson")
+		g.P("   jsonData, err := ", jsonPackage.Ident("Marshal"), "(revisions)")
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		g.P(`   w.Header().Set("Content-Type", "application/json")`)
+		g.P("   w.Write(jsonData)")
+		g.P("}")
+		g.P("")
+		g.P("// RevisionHandler renders the object at the {id} url parameter as the revision in")
+		g.P("// the {revision} url parameter left it")
+		g.P("func (h *", handlerName, ") RevisionHandler(w ", httpPackage.Ident("ResponseWriter"), ", req *", httpPackage.Ident("Request"), ") {")
+		generateReadID(g, "   ")
+		g.P(`   revision, err := `, strconvPackage.Ident("ParseInt"), `(`, chiPackage.Ident("URLParam"), `(req, "revision"), 10, 64)`)
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("NotFound"), "(w, req)")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		g.P("   x, err := h.Repo.GetAtRevision(", tenant, "id, revision)")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrNotFound"), ") {")
+		g.P("       ", httpPackage.Ident("NotFound"), "(w, req)")
+		g.P("       return")
+		g.P("   }")
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		g.P("   h.render(w, req, ", httpPackage.Ident("StatusOK"), ", x)")
+		g.P("}")
+		g.P("")
+	}
+
+	if htmx && (hasOperation(opts, dep.Operation_OPERATION_CREATE) || hasOperation(opts, dep.Operation_OPERATION_UPDATE)) {
+		g.P("// FormHandler renders the form for a new object, or for editing the object at the {id} url parameter")
+		g.P("func (h *", handlerName, ") FormHandler(w ", httpPackage.Ident("ResponseWriter"), ", req *", httpPackage.Ident("Request"), ") {")
+		g.P("   x := new(", message.GoIdent, ")")
+		edit := hasOperation(opts, dep.Operation_OPERATION_GET) && hasOperation(opts, dep.Operation_OPERATION_UPDATE)
+		if edit && opts.Versioned {
+			g.P("   var version int64")
+		}
+		if edit {
+			g.P(`   if `, chiPackage.Ident("URLParam"), `(req, "id") != "" {`)
+			generateReadID(g, "       ")
+			if opts.Versioned {
+				g.P("       found, v, err := h.Repo.Get(", tenant, "id)")
+			} else {
+				g.P("       found, err := h.Repo.Get(", tenant, "id)")
+			}
+			g.P("       if ", errorsPackage.Ident("Is"), "(err, ", depPackage.Ident("ErrNotFound"), ") {")
+			g.P("           ", httpPackage.Ident("NotFound"), "(w, req)")
+			g.P("           return")
+			g.P("       }")
+			g.P("       if err != nil {")
+			g.P("           ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+			g.P("           return")
+			g.P("       }")
+			if opts.Versioned {
+				g.P("       x, version = found, v")
+			} else {
+				g.P("       x = found")
+			}
+			g.P("   }")
+			g.P("")
+		}
+		g.P(`   w.Header().Set("Content-Type", "text/html; charset=utf-8")`)
+		if edit && opts.Versioned {
+			g.P("   if version != 0 {")
+			g.P("       // htmx sends no If-Match, the form carries the version it shows instead")
+			g.P("       ", fmtPackage.Ident("Fprintf"), `(w, "<input type=\"hidden\" name=\"%s\" value=\"%d\">\n", `, depPackage.Ident("VersionFormValue"), ", version)")
+			g.P("   }")
+		}
+		g.P("   if err := x.RenderForm(w); err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+		g.P("   }")
+		g.P("}")
+		g.P("")
+	}
+
+	if htmx && unique && (hasOperation(opts, dep.Operation_OPERATION_CREATE) || hasOperation(opts, dep.Operation_OPERATION_UPDATE)) {
+		generateAlreadyExistsHandler(g, message, opts)
+	}
+
+	if hasOperation(opts, dep.Operation_OPERATION_CREATE) || hasOperation(opts, dep.Operation_OPERATION_UPDATE) {
+		g.P("// decode reads the object from a protojson body, skipping unknown fields, or from")
+		g.P("// a submitted form")
+		g.P("func (h *", handlerName, ") decode(req *", httpPackage.Ident("Request"), ", x *", message.GoIdent, ") error {")
+		if htmx {
+			g.P(`   if ct, _, _ := `, mimePackage.Ident("ParseMediaType"), `(req.Header.Get("Content-Type")); ct != "application/json" {`)
+			g.P("       if _, err := x.HandleForm(req); err != nil {")
+			g.P("           return err")
+			g.P("       }")
+			g.P("       // HandleForm only validates the fields the form submitted")
+			g.P("       return x.Validate()")
+			g.P("   }")
+			g.P("")
+		}
+		g.P("   body, err := ", ioPackage.Ident("ReadAll"), "(req.Body)")
+		g.P("   if err != nil {")
+		g.P("       return err")
+		g.P("   }")
+		g.P("   if err := (", protojsonPackage.Ident("UnmarshalOptions"), "{DiscardUnknown: true}).Unmarshal(body, x); err != nil {")
+		g.P("       return err")
+		g.P("   }")
+		g.P("")
+		g.P("   return x.Validate()")
+		g.P("}")
+		g.P("")
+	}
+
+	if hasOperation(opts, dep.Operation_OPERATION_UPDATE) {
+		g.P("// decodePatch reads the fields to patch from a submitted form, masking the ones it")
+		g.P("// holds, or from a protojson body masked by the update_mask query parameter or by")
+		g.P("// the fields present in the body, which may not name unknown fields")
+		g.P("func (h *", handlerName, ") decodePatch(req *", httpPackage.Ident("Request"), ", x *", message.GoIdent, ") (*", fieldmaskpbPackage.Ident("FieldMask"), ", error) {")
+		if htmx {
+			g.P(`   if ct, _, _ := `, mimePackage.Ident("ParseMediaType"), `(req.Header.Get("Content-Type")); ct != "application/json" {`)
+			g.P("       return x.HandleForm(req)")
+			g.P("   }")
+			g.P("")
+		}
+		g.P("   body, err := ", ioPackage.Ident("ReadAll"), "(req.Body)")
+		g.P("   if err != nil {")
+		g.P("       return nil, err")
+		g.P("   }")
+		g.P("   if err := ", protojsonPackage.Ident("Unmarshal"), "(body, x); err != nil {")
+		g.P("       return nil, err")
+		g.P("   }")
+		g.P("")
+		g.P("   return ", depPackage.Ident("ParseFieldMask"), "(req.URL.Query(), body)")
+		g.P("}")
+		g.P("")
+	}
+
+	if hasOperation(opts, dep.Operation_OPERATION_GET) || hasOperation(opts, dep.Operation_OPERATION_CREATE) || hasOperation(opts, dep.Operation_OPERATION_UPDATE) || opts.History {
+		g.P("// render writes the object as protojson, or as html to htmx requests")
+		g.P("func (h *", handlerName, ") render(w ", httpPackage.Ident("ResponseWriter"), ", req *", httpPackage.Ident("Request"), ", status int, x *", message.GoIdent, ") {")
+		if htmx {
+			g.P(`   if req.Header.Get("HX-Request") == "true" {`)
+			g.P(`       w.Header().Set("Content-Type", "text/html; charset=utf-8")`)
+			g.P("       w.WriteHeader(status)")
+			g.P("       x.RenderView(w)")
+			g.P("       return")
+			g.P("   }")
+			g.P("")
+		}
+		g.P("   jsonData, err := ", protojsonPackage.Ident("Marshal"), "(x)")
+		g.P("   if err != nil {")
+		g.P("       ", httpPackage.Ident("Error"), "(w, err.Error(), ", httpPackage.Ident("StatusInternalServerError"), ")")
+		g.P("       return")
+		g.P("   }")
+		g.P("")
+		g.P(`   w.Header().Set("Content-Type", "application/json")`)
+		g.P("   w.WriteHeader(status)")
+		g.P("   w.Write(jsonData)")
+		g.P("}")
+		g.P("")
+	}
 }
 
-func (p *Generator) generateModel(g *protogen.GeneratedFile, message *protogen.Message) {
-    typeName := string(message.Desc.Name())
-
-    g.P("// Lets start by creating a Model and Handler for our flow")
-    g.P("type Handler struct {")
-    g.P("   DB *sql.DB")
-    g.P("   Parent string")
-    g.P("   ID string")
-    g.P("}")
-    g.P("")
-    g.P("type model struct {")
-    g.P("   DB *sql.DB")
-    g.P("   Table string")
-    g.P("}")
-    g.P("")
-    g.P("func NewHandler(db *sql.DB) *Handler {")
-    g.P("   model := New(model)")
-    g.P("   model.DB = db")
-    g.P(`   model.Table = "`, strings.ToLower(typeName), `"`) 
-    g.P("}")
-    g.P("")
-}
-
-func (p *Generator) generateListFunction(g *protogen.GeneratedFile, message *protogen.Message) {
-    typeName := string(message.Desc.Name())
-
-    g.P("// ListHandler is our http handler that acquires and renders a list of objects")
-    g.P(`func (x *`, typeName, `) ListHandler(w http.ResponseWriter, req *http.Request) {`)
-    g.P(`   db, err := r.Context().Value("db").(*sql.DB)`)
-    g.P(`   if err != nil { return }`)
-    g.P("")
-    g.P(`   tenant := chi.URLParam(req, "id")`)
-    g.P(`   ret, err := x.List(db, tenant)`)
-    g.P(`   if err != nil { return }`)
-    g.P("")
-    g.P(`   jsonData, err := json.Marshal(data)`)
-    g.P(`   if err != nil { return }`)
-    g.P("")
-    g.P(`   w.Header().Set("Content-Type", "application/json")`)
-    g.P(`   w.Write(jsonData)`)
-    g.P("}")
-    g.P("")
-    g.P("")
-    g.P("// List function should return a list of these objects")
-    g.P(`func (x *`, typeName, `) List(db *sql.DB, tenant string) (map[int]`, typeName, `, error) {`)
-    g.P("   ret := make(map[int]", typeName, ")")
-    g.P("")
-    g.P(`   rows, err := db.Query("SELECT id, data FROM list_data($1, $2)", tenant, x.TableName())`)
-    g.P("   if err != nil { return ret, err }")
-    g.P("")
-    g.P("   defer rows.Close()")
-    g.P("")
-    g.P("   for rows.Next() {")
-    g.P("       var row ", typeName)
-    g.P("       var id int")
-    g.P("")
-    g.P("       err := rows.Scan(&id, &row)")
-    g.P("       if err != nil { return ret, err }")
-    g.P("")
-    g.P("       ret[id] = row")
-    g.P("   }")
-    g.P("")
-    g.P("   return ret, nil")
-    g.P("}")
-    g.P("")
-}
-
-func (p *Generator) generateGetFunction(g *protogen.GeneratedFile, message *protogen.Message) {
-    typeName := string(message.Desc.Name())
-
-    g.P("// Get function acquires a single record based on ID in database")
-    g.P(`func (x *`, typeName, `) Get(db *sql.DB, tenant string, id string) error {`)
-    g.P("")
-    g.P(`   return db.QueryRow("SELECT data FROM list_data($1, $2) WHERE id = $3",`)
-    g.P("       tenant, x.TableName(), id).Scan(x)")
-    g.P("")
-    g.P("}")
-    g.P("")
-}
-
-func (p *Generator) generateCreateFunction(g *protogen.GeneratedFile, message *protogen.Message) {
-    typeName := string(message.Desc.Name())
-    var firstField string
-    if len(message.Fields) > 0 {
-        firstField = message.Fields[0].GoName
-    } else {
-        return
-    }
-
-    checkField := strings.Join([]string{typeName, firstField}, ".")
-    
-    g.P("// Create function will create a new object of this type")
-    g.P(`func (x *`, typeName, `) Create(db *sql.DB, tenant string, data *`, typeName, `) error {`)
-    g.P("   if ", checkField, ` != "" {`)
-    g.P(`       _, err := db.Exec("CALL insert_data($1, $2, $3)", tenant, x.TableName(), contact)`)
-    g.P("")
-    g.P("       if err != nil { return err }")
-    g.P("   } else {")
-    g.P(`       return errors.New("`, firstField, ` Was not set")`)
-    g.P("   }")
-    g.P("")
-    g.P("   return nil")
-    g.P("}")
-    g.P("")
-}
-
-
-func (p *Generator) generateUpdateFunction(g *protogen.GeneratedFile, message *protogen.Message) {
-    typeName := string(message.Desc.Name())
-
-    g.P("// Update function will replace the object stored at the given ID")
-    g.P(`func (x *`, typeName, `) Update(db *sql.DB, tenant string, id string, data *`, typeName, `) error {`)
-    g.P(`   _, err := db.Exec("CALL update_data($1, $2, $3, $4)",`)
-    g.P("       tenant, x.TableName(), id, data)")
-    g.P("")
-    g.P("   return err")
-    g.P("}")
-    g.P("")
-}
-
-func (p *Generator) generateDeleteFunction(g *protogen.GeneratedFile, message *protogen.Message) {
-    typeName := string(message.Desc.Name())
-
-    g.P("// Delete function will... well delete the object at given ID")
-    g.P(`func (x *`, typeName, `) Delete(db *sql.DB, tenant string, id string) error {`)
-    g.P(`   _, err := db.Exec("CALL delete_data_by_id($1, $2, $3)",`)
-    g.P("       tenant, x.TableName(), id)")
-    g.P("")
-    g.P("   return err")
-    g.P("}")
-    g.P("")
-}
-
-func (p *Generator) generateFormHandler(g *protogen.GeneratedFile, message *protogen.Message) {
-    typeName := string(message.Desc.Name())
-
-    g.P("// A simple function to handle a htmx form and populate the struct")
-    g.P(`func (x *`, typeName, `) HandleForm(req *http.Request) error {`)
-    if len(message.Fields) > 0 {
-        for _, field := range message.Fields {
-            formField := strings.Join([]string{typeName, field.GoName}, "__")
-            g.P(`       x.`, field.GoName, ` = req.FormValue("`, formField, `")`)
-        }
-    }
-    g.P("   return x.Validate()")
-    g.P("}")
-}
-
-func (p *Generator) generateTableFunction(g *protogen.GeneratedFile, message *protogen.Message) {
-	typeName := string(message.Desc.Name())
-
-    g.P(`// Deps function returns a static string for the time being, needs dev`)
-	g.P(`func (*`, typeName, `) TableName() string {`)
-    g.P(`   return "`, strings.ToLower(typeName), `"`)
-	g.P(`}`)
-    g.P("")
-}
-
-func (p *Generator) generateRouteFunction(g *protogen.GeneratedFile, message *protogen.Message) {
-	typeName := string(message.Desc.Name())
-
-    g.P(`// Route function will return chi.Router that can be mounted to a parent router`)
-    g.P(`func (x *`, typeName, `) Routes() chi.Router {`)
-    g.P("   r := chi.NewRouter()")
-    g.P("")
-    g.P(`   r.Get("/", x.ListHandler)`)
-    g.P(`   r.Post("/", x.CreateHandler)`)
-    g.P(`   r.Route("/{contact}", func(r chi.Router) {`)
-    g.P(`       r.Get("/", x.GetHandler)`)
-    g.P(`       r.Put("/", x.UpdateHandler)`)
-    g.P(`       r.Delete("/", x.DeleteHandler)`)
-    g.P("   })")
-    g.P("")
-    g.P("   return r")
-    g.P("}")
+func (p *Generator) generateRouteFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	handlerName := message.GoIdent.GoName + "Handler"
+	htmx := opts.UiMode == dep.UiMode_UI_MODE_HTMX
+
+	g.P("// Routes returns a chi.Router with the ", message.GoIdent.GoName, " endpoints that can be mounted to a parent router")
+	g.P("func (h *", handlerName, ") Routes() ", chiPackage.Ident("Router"), " {")
+	g.P("   r := ", chiPackage.Ident("NewRouter"), "()")
+	g.P("")
+	if hasOperation(opts, dep.Operation_OPERATION_LIST) {
+		g.P(`   r.Get("/", h.ListHandler)`)
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_CREATE) {
+		g.P(`   r.Post("/", h.CreateHandler)`)
+		if htmx {
+			g.P(`   r.Get("/new", h.FormHandler)`)
+		}
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_GET) || hasOperation(opts, dep.Operation_OPERATION_UPDATE) || hasOperation(opts, dep.Operation_OPERATION_DELETE) || opts.History {
+		g.P(`   r.Route("/{id}", func(r `, chiPackage.Ident("Router"), `) {`)
+		if hasOperation(opts, dep.Operation_OPERATION_GET) {
+			g.P(`       r.Get("/", h.GetHandler)`)
+		}
+		if hasOperation(opts, dep.Operation_OPERATION_UPDATE) {
+			g.P(`       r.Put("/", h.UpdateHandler)`)
+			g.P(`       r.Patch("/", h.PatchHandler)`)
+			if htmx && hasOperation(opts, dep.Operation_OPERATION_GET) {
+				g.P(`       r.Get("/edit", h.FormHandler)`)
+			}
+		}
+		if hasOperation(opts, dep.Operation_OPERATION_DELETE) {
+			g.P(`       r.Delete("/", h.DeleteHandler)`)
+		}
+		if opts.History {
+			g.P(`       r.Get("/history", h.HistoryHandler)`)
+			g.P(`       r.Get("/history/{revision}", h.RevisionHandler)`)
+		}
+		g.P("   })")
+	}
+	if opts.SoftDelete && hasOperation(opts, dep.Operation_OPERATION_DELETE) {
+		if hasOperation(opts, dep.Operation_OPERATION_LIST) {
+			g.P(`   r.Get("/deleted", h.ListDeletedHandler)`)
+		}
+		g.P(`   r.Route("/deleted/{id}", func(r `, chiPackage.Ident("Router"), `) {`)
+		g.P(`       r.Post("/restore", h.RestoreHandler)`)
+		g.P(`       r.Delete("/", h.PurgeHandler)`)
+		g.P("   })")
+	}
+	g.P("")
+	g.P("   return r")
+	g.P("}")
+	g.P("")
+}
+
+func (p *Generator) generateRegisterFunction(g *protogen.GeneratedFile, file *protogen.File, resources []*protogen.Message) {
+	g.P("// Deps holds what the handlers of the resources in ", file.Desc.Path(), " need")
+	g.P("type Deps struct {")
+	g.P("   DB DBTX")
+	g.P("   // Tenant resolves the tenant of a request, by default the {tenant} url parameter")
+	g.P("   Tenant func(*", httpPackage.Ident("Request"), ") string")
+	g.P("}")
+	g.P("")
+	g.P("// RegisterAll mounts the routes of every resource in ", file.Desc.Path(), " on r")
+	g.P("func RegisterAll(r ", chiPackage.Ident("Router"), ", deps Deps) {")
+	for _, message := range resources {
+		opts := resourceOptions(message)
+		handlerName := message.GoIdent.GoName + "Handler"
+		if opts.Global {
+			g.P(`   r.Mount("`, opts.RoutePrefix, `", New`, handlerName, `(New`, message.GoIdent.GoName, `SQLRepository(deps.DB)).Routes())`)
+		} else {
+			g.P(`   r.Mount("`, opts.RoutePrefix, `", (&`, handlerName, `{Repo: New`, message.GoIdent.GoName, `SQLRepository(deps.DB), Tenant: deps.Tenant}).Routes())`)
+		}
+	}
+	g.P("}")
+	g.P("")
 }
 
 func (p *Generator) generateViewTemplate(g *protogen.GeneratedFile, message *protogen.Message) {
-	typeName := string(message.Desc.Name())
-
-    g.P(`// RenderView will take in a http writer and object to render the view`)
-    g.P(`func (x *`, typeName, `) RenderView(w http.ResponseWriter) {`)
-    g.P("   tmpl, err := template.New(\"view\").Parse(` ")
-    if len(message.Fields) > 0 {
-        for _, field := range message.Fields {
-         g.P(`<p class="w-16">`)
-         g.P("  <span>", field.GoName, "</span>")
-         g.P("  <span> {{ .", field.GoName, " }} </span>")
-         g.P("</p>")
-        }
-    }
-    g.P(    "`)")
-    g.P("")
-    g.P("   err := tmpl.Execute(w, x)")
-    g.P("")
+	templateName := lowerFirst(message.GoIdent.GoName) + "ViewTemplate"
+
+	g.P("var ", templateName, " = ", templatePackage.Ident("Must"), "(", templatePackage.Ident("New"), "(\"view\").Parse(`")
+	for _, field := range message.Fields {
+		fieldOpts := fieldOptions(field)
+		if fieldOpts.Hidden || fieldOpts.Widget == dep.Widget_WIDGET_PASSWORD {
+			continue
+		}
+		g.P(`<p class="w-16">`)
+		g.P("  <span>", templateText(fieldOpts.Label), "</span>")
+		if field.Message != nil && field.Message.Desc.FullName() == timestampName {
+			g.P("  <span> {{ with .", field.GoName, ` }}{{ .AsTime.Format "2006-01-02 15:04" }}{{ end }} </span>`)
+		} else {
+			g.P("  <span> {{ .", templateFieldName(field), " }} </span>")
+		}
+		g.P("</p>")
+	}
+	g.P("`))")
+	g.P("")
+	g.P("// RenderView will take in a http writer and object to render the view")
+	g.P("func (x *", message.GoIdent, ") RenderView(w ", httpPackage.Ident("ResponseWriter"), ") error {")
+	g.P("   return ", templateName, ".Execute(w, x)")
+	g.P("}")
+	g.P("")
+}
+
+// lowerFirst lower cases the first letter of a Go identifier.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
 }
 
 func main() {
-    input, err := ioutil.ReadAll(os.Stdin)
+	input, err := ioutil.ReadAll(os.Stdin)
 	if err != nil {
 		panic(err)
 	}
@@ -318,22 +1792,22 @@ func main() {
 
 	opts := protogen.Options{}
 
-    generator, err := NewGenerator(opts, &request)
-    if err != nil {
-        panic(err)
-    }
+	generator, err := NewGenerator(opts, &request)
+	if err != nil {
+		panic(err)
+	}
 
-    response, err := generator.Generate()
-    if err != nil {
-        panic(err)
-    }
+	response, err := generator.Generate()
+	if err != nil {
+		panic(err)
+	}
 
-    out, err := proto.Marshal(response)
-    if err != nil {
-        panic(err)
-    }
+	out, err := proto.Marshal(response)
+	if err != nil {
+		panic(err)
+	}
 
-    fmt.Fprint(os.Stdout, string(out))
+	fmt.Fprint(os.Stdout, string(out))
 }
 
 func parseParameter(param string) map[string]string {
@@ -351,5 +1825,3 @@ func parseParameter(param string) map[string]string {
 
 	return paramMap
 }
-
-
@@ -0,0 +1,504 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	// Output of driver=pgx is type checked against pgx, keep it in go.mod.
+	_ "github.com/jackc/pgx/v5"
+	// So are the ids of the uuid and ulid strategies.
+	_ "github.com/google/uuid"
+	_ "github.com/oklog/ulid/v2"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"protoc-gen-go-dep/dep"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// goldenCases are run against the plugin. Each proto in testdata has a
+// descriptor set next to it, compiled with `make testdata`, so protoc is not
+// needed to run the tests. Output is compared to testdata/golden/<name>.
+var goldenCases = []struct {
+	name  string
+	proto string
+	param string
+}{
+	{name: "hello", proto: "hello.proto", param: "paths=source_relative"},
+	{name: "options", proto: "options.proto", param: "paths=source_relative"},
+	{name: "constraints", proto: "constraints.proto", param: "paths=source_relative"},
+	{name: "plain", proto: "plain.proto", param: "paths=source_relative"},
+	{name: "columns", proto: "columns.proto", param: "paths=source_relative"},
+	{name: "sqlite", proto: "hello.proto", param: "paths=source_relative,db=sqlite"},
+	{name: "sqlite_columns", proto: "columns.proto", param: "paths=source_relative,db=sqlite"},
+	{name: "pgx", proto: "hello.proto", param: "paths=source_relative,driver=pgx"},
+	{name: "pgx_columns", proto: "columns.proto", param: "paths=source_relative,driver=pgx"},
+}
+
+// sourceImporter is shared by the cases so dependencies are only type
+// checked once.
+var sourceImporter = importer.ForCompiler(token.NewFileSet(), "source", nil)
+
+func TestGolden(t *testing.T) {
+	for _, tc := range goldenCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := loadRequest(t, tc.proto, tc.param)
+
+			generator, err := NewGenerator(protogen.Options{}, request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response, err := generator.Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.Error != nil {
+				t.Fatalf("plugin error: %s", response.GetError())
+			}
+
+			compareGolden(t, filepath.Join("testdata", "golden", tc.name), response.File)
+			typeCheck(t, request, response.File)
+		})
+	}
+}
+
+// errorCases edit the Hello resource of testdata/hello.proto into one the
+// plugin refuses, or pass it parameters it does not know.
+var errorCases = []struct {
+	name  string
+	param string
+	edit  func(hello *descriptorpb.DescriptorProto)
+	want  string
+}{
+	{
+		name: "audit marked twice",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editField(hello, "updated_at", func(opts *dep.DepFieldOptions) { opts.Audit = dep.Audit_AUDIT_CREATED_AT })
+		},
+		want: "hello.Hello: AUDIT_CREATED_AT is marked on more than one field",
+	},
+	{
+		name: "audit time not a timestamp",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editField(hello, "created_at", func(opts *dep.DepFieldOptions) { opts.Audit = dep.Audit_AUDIT_UNSPECIFIED })
+			editField(hello, "created_by", func(opts *dep.DepFieldOptions) { opts.Audit = dep.Audit_AUDIT_CREATED_AT })
+		},
+		want: "hello.Hello.created_by: AUDIT_CREATED_AT fields have to be a google.protobuf.Timestamp",
+	},
+	{
+		name: "audit actor not a string",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editField(hello, "created_by", func(opts *dep.DepFieldOptions) { opts.Audit = dep.Audit_AUDIT_UNSPECIFIED })
+			editField(hello, "created_at", func(opts *dep.DepFieldOptions) { opts.Audit = dep.Audit_AUDIT_CREATED_BY })
+		},
+		want: "hello.Hello.created_at: AUDIT_CREATED_BY fields have to be a plain string",
+	},
+	{
+		name: "id field without natural key",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editResource(hello, func(opts *dep.DepMessageOptions) { opts.IdField = "email" })
+		},
+		want: "hello.Hello: id_field is only used by ID_STRATEGY_NATURAL_KEY",
+	},
+	{
+		name: "natural key unknown",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editResource(hello, func(opts *dep.DepMessageOptions) {
+				opts.IdStrategy, opts.IdField = dep.IdStrategy_ID_STRATEGY_NATURAL_KEY, "nick"
+			})
+		},
+		want: `hello.Hello: ID_STRATEGY_NATURAL_KEY needs id_field to name a field, got "nick"`,
+	},
+	{
+		name: "natural key not plain",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editResource(hello, func(opts *dep.DepMessageOptions) {
+				opts.IdStrategy, opts.IdField = dep.IdStrategy_ID_STRATEGY_NATURAL_KEY, "created_at"
+			})
+		},
+		want: "hello.Hello.created_at: the natural key has to be a plain field",
+	},
+	{
+		name: "natural key not required",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editResource(hello, func(opts *dep.DepMessageOptions) {
+				opts.IdStrategy, opts.IdField = dep.IdStrategy_ID_STRATEGY_NATURAL_KEY, "name"
+			})
+		},
+		want: "hello.Hello.name: the natural key has to be required",
+	},
+	{
+		name: "natural key read only",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editResource(hello, func(opts *dep.DepMessageOptions) {
+				opts.IdStrategy, opts.IdField = dep.IdStrategy_ID_STRATEGY_NATURAL_KEY, "email"
+			})
+			editField(hello, "email", func(opts *dep.DepFieldOptions) { opts.ReadOnly = true })
+		},
+		want: "hello.Hello.email: the natural key is set by clients, it cannot be read only or hidden",
+	},
+	{
+		name: "natural key unique",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editResource(hello, func(opts *dep.DepMessageOptions) {
+				opts.IdStrategy, opts.IdField = dep.IdStrategy_ID_STRATEGY_NATURAL_KEY, "email"
+			})
+		},
+		want: "hello.Hello.email: the natural key is unique already",
+	},
+	{
+		name: "unique and indexed",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editField(hello, "email", func(opts *dep.DepFieldOptions) { opts.Indexed = true })
+		},
+		want: "hello.Hello.email: unique fields are indexed already",
+	},
+	{
+		name: "index without fields",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editResource(hello, func(opts *dep.DepMessageOptions) { opts.Indexes = []*dep.Index{{}} })
+		},
+		want: "hello.Hello: indexes need fields",
+	},
+	{
+		name: "index over unknown field",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editResource(hello, func(opts *dep.DepMessageOptions) {
+				opts.Indexes = []*dep.Index{{Fields: []string{"nick"}}}
+			})
+		},
+		want: `hello.Hello: index names unknown field "nick"`,
+	},
+	{
+		name: "index over field twice",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editResource(hello, func(opts *dep.DepMessageOptions) {
+				opts.Indexes = []*dep.Index{{Fields: []string{"name", "name"}}}
+			})
+		},
+		want: `hello.Hello: index names field "name" twice`,
+	},
+	{
+		name: "index over timestamp",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editResource(hello, func(opts *dep.DepMessageOptions) {
+				opts.Indexes = []*dep.Index{{Fields: []string{"created_at"}}}
+			})
+		},
+		want: "hello.Hello.created_at: only plain scalar and enum fields can be unique or indexed",
+	},
+	{
+		name: "two indexes over the same fields",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editResource(hello, func(opts *dep.DepMessageOptions) {
+				opts.Indexes = []*dep.Index{{Fields: []string{"name"}}, {Fields: []string{"name"}, Unique: true}}
+			})
+		},
+		want: "hello.Hello: two indexes over name",
+	},
+	{
+		name: "sortable list",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			fieldByProtoName(hello, "name").Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		},
+		want: "hello.Hello.name: only scalar, enum and Timestamp fields can be searchable or sortable",
+	},
+	{
+		name: "invalid pattern",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			editField(hello, "name", func(opts *dep.DepFieldOptions) { opts.Pattern = "(" })
+		},
+		want: "hello.Hello.name: invalid pattern: error parsing regexp: missing closing ): `(`",
+	},
+	{
+		name: "field clashing with Value",
+		edit: func(hello *descriptorpb.DescriptorProto) {
+			name := fieldByProtoName(hello, "name")
+			name.Name, name.JsonName = proto.String("value"), proto.String("value")
+		},
+		want: "hello.Hello: field value clashes with the generated Value method, use STORAGE_COLUMNS",
+	},
+	{
+		name:  "unknown db",
+		param: "db=mysql",
+		want:  `unknown db "mysql", expected postgres or sqlite`,
+	},
+	{
+		name:  "unknown driver",
+		param: "driver=lib/pq",
+		want:  `unknown driver "lib/pq", expected sql or pgx`,
+	},
+	{
+		name:  "pgx on sqlite",
+		param: "db=sqlite,driver=pgx",
+		want:  "driver=pgx only works with db=postgres",
+	},
+}
+
+func TestErrors(t *testing.T) {
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			param := "paths=source_relative"
+			if tc.param != "" {
+				param += "," + tc.param
+			}
+			request := loadRequest(t, "hello.proto", param)
+			if tc.edit != nil {
+				for _, file := range request.ProtoFile {
+					if file.GetName() == "hello.proto" {
+						tc.edit(file.MessageType[0])
+					}
+				}
+			}
+
+			var got string
+			generator, err := NewGenerator(protogen.Options{}, request)
+			if err != nil {
+				got = err.Error()
+			} else {
+				response, err := generator.Generate()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = response.GetError()
+			}
+			if got != tc.want {
+				t.Errorf("got error %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// editResource changes the dep.resource options of message.
+func editResource(message *descriptorpb.DescriptorProto, edit func(opts *dep.DepMessageOptions)) {
+	opts := proto.GetExtension(message.Options, dep.E_Resource).(*dep.DepMessageOptions)
+	edit(opts)
+	proto.SetExtension(message.Options, dep.E_Resource, opts)
+}
+
+// editField changes the dep.field options of the field of message called
+// name.
+func editField(message *descriptorpb.DescriptorProto, name string, edit func(opts *dep.DepFieldOptions)) {
+	field := fieldByProtoName(message, name)
+	if field.Options == nil {
+		field.Options = new(descriptorpb.FieldOptions)
+	}
+	opts := proto.GetExtension(field.Options, dep.E_Field).(*dep.DepFieldOptions)
+	if opts == nil {
+		opts = new(dep.DepFieldOptions)
+	}
+	edit(opts)
+	proto.SetExtension(field.Options, dep.E_Field, opts)
+}
+
+func fieldByProtoName(message *descriptorpb.DescriptorProto, name string) *descriptorpb.FieldDescriptorProto {
+	for _, field := range message.Field {
+		if field.GetName() == name {
+			return field
+		}
+	}
+	panic("no field " + name + " in " + message.GetName())
+}
+
+// loadRequest builds the request protoc would send for testdata/<file>.
+func loadRequest(t *testing.T, file string, param string) *pluginpb.CodeGeneratorRequest {
+	t.Helper()
+
+	setFile := strings.TrimSuffix(file, ".proto") + ".binpb"
+	raw, err := os.ReadFile(filepath.Join("testdata", setFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(raw, &set); err != nil {
+		t.Fatalf("%s: %v", setFile, err)
+	}
+
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{file},
+		Parameter:      proto.String(param),
+		ProtoFile:      set.File,
+	}
+}
+
+func compareGolden(t *testing.T, dir string, files []*pluginpb.CodeGeneratorResponse_File) {
+	t.Helper()
+
+	if *update {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range files {
+			path := filepath.Join(dir, file.GetName())
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(file.GetContent()), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+
+	want := make(map[string]bool)
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			want[filepath.ToSlash(rel)] = true
+		}
+		return nil
+	})
+
+	for _, file := range files {
+		if !want[file.GetName()] {
+			t.Errorf("%s: generated but has no golden file, run go test -update", file.GetName())
+			continue
+		}
+		delete(want, file.GetName())
+
+		golden, err := os.ReadFile(filepath.Join(dir, file.GetName()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line, ok := firstDifference(string(golden), file.GetContent()); !ok {
+			t.Errorf("%s: differs from golden file at line %d, run go test -update", file.GetName(), line)
+		}
+	}
+
+	missing := make([]string, 0, len(want))
+	for name := range want {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		t.Errorf("%s: golden file is no longer generated", name)
+	}
+}
+
+// firstDifference returns the first line where a and b differ.
+func firstDifference(a, b string) (int, bool) {
+	if a == b {
+		return 0, true
+	}
+	linesA, linesB := strings.Split(a, "\n"), strings.Split(b, "\n")
+	for i := range linesA {
+		if i >= len(linesB) || linesA[i] != linesB[i] {
+			return i + 1, false
+		}
+	}
+	return len(linesA) + 1, false
+}
+
+// typeCheck compiles the generated Go files together with the protoc-gen-go
+// output checked in next to the test protos, `make testdata` refreshes it.
+func typeCheck(t *testing.T, request *pluginpb.CodeGeneratorRequest, files []*pluginpb.CodeGeneratorResponse_File) {
+	t.Helper()
+
+	if len(files) == 0 {
+		return
+	}
+
+	// Pretend the files live in this directory so dependencies resolve
+	// through the module.
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Imported protos whose Go package is not a dependency of the module get
+	// type checked from their protoc-gen-go output.
+	plugin, err := protogen.Options{}.New(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imports := &protoImporter{
+		fset:  token.NewFileSet(),
+		files: make(map[string][]*ast.File),
+		pkgs:  make(map[string]*types.Package),
+	}
+
+	fset := imports.fset
+	var parsed []*ast.File
+	for _, file := range plugin.Files {
+		name := file.GeneratedFilenamePrefix + ".pb.go"
+		content, err := os.ReadFile(filepath.Join("testdata", name))
+		if os.IsNotExist(err) && !file.Generate {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), content, parser.AllErrors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if file.Generate {
+			parsed = append(parsed, f)
+			continue
+		}
+		path := string(file.GoImportPath)
+		imports.files[path] = append(imports.files[path], f)
+	}
+	for _, file := range files {
+		if !strings.HasSuffix(file.GetName(), ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, file.GetName()), file.GetContent(), parser.AllErrors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, f)
+	}
+
+	var errs bytes.Buffer
+	conf := types.Config{
+		Importer: imports,
+		Error: func(err error) {
+			errs.WriteString(err.Error() + "\n")
+		},
+	}
+	conf.Check(parsed[0].Name.Name, fset, parsed, nil)
+	if errs.Len() > 0 {
+		t.Errorf("generated code does not type check:\n%s", errs.String())
+	}
+}
+
+// protoImporter resolves imports through the module, falling back to the
+// checked in protoc-gen-go output for packages the module lacks.
+type protoImporter struct {
+	fset  *token.FileSet
+	files map[string][]*ast.File
+	pkgs  map[string]*types.Package
+}
+
+func (i *protoImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := i.pkgs[path]; ok {
+		return pkg, nil
+	}
+
+	pkg, err := sourceImporter.Import(path)
+	if err != nil && len(i.files[path]) > 0 {
+		conf := types.Config{Importer: i}
+		pkg, err = conf.Check(path, i.fset, i.files[path], nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	i.pkgs[path] = pkg
+	return pkg, nil
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"protoc-gen-go-dep/dep"
+)
+
+// allOperations is what a resource gets when its options list none.
+var allOperations = []dep.Operation{
+	dep.Operation_OPERATION_LIST,
+	dep.Operation_OPERATION_GET,
+	dep.Operation_OPERATION_CREATE,
+	dep.Operation_OPERATION_UPDATE,
+	dep.Operation_OPERATION_DELETE,
+}
+
+// resourceOptions returns the options of an annotated message with every
+// default filled in, or nil when the message should not be generated.
+// The legacy `option (dep.opts) = "htmx"` maps to the default options.
+func resourceOptions(message *protogen.Message) *dep.DepMessageOptions {
+	msgOpts, ok := message.Desc.Options().(*descriptorpb.MessageOptions)
+	if !ok {
+		return nil
+	}
+
+	var opts *dep.DepMessageOptions
+	switch {
+	case proto.HasExtension(msgOpts, dep.E_Resource):
+		ext := proto.GetExtension(msgOpts, dep.E_Resource).(*dep.DepMessageOptions)
+		opts = proto.Clone(ext).(*dep.DepMessageOptions)
+	case proto.HasExtension(msgOpts, dep.E_Opts):
+		if proto.GetExtension(msgOpts, dep.E_Opts).(string) != "htmx" {
+			return nil
+		}
+		opts = &dep.DepMessageOptions{}
+	default:
+		return nil
+	}
+
+	if opts.Table == "" {
+		opts.Table = strings.ToLower(string(message.Desc.Name()))
+	}
+	if opts.IdStrategy == dep.IdStrategy_ID_STRATEGY_UNSPECIFIED {
+		opts.IdStrategy = dep.IdStrategy_ID_STRATEGY_SERIAL
+	}
+	if len(opts.Operations) == 0 {
+		opts.Operations = allOperations
+	}
+	if opts.RoutePrefix == "" {
+		opts.RoutePrefix = "/" + opts.Table
+	}
+	if opts.UiMode == dep.UiMode_UI_MODE_UNSPECIFIED {
+		opts.UiMode = dep.UiMode_UI_MODE_HTMX
+	}
+	if opts.Storage == dep.Storage_STORAGE_UNSPECIFIED {
+		opts.Storage = dep.Storage_STORAGE_DOCUMENT
+	}
+
+	return opts
+}
+
+// hasOperation reports whether op should be generated for the resource.
+func hasOperation(opts *dep.DepMessageOptions, op dep.Operation) bool {
+	for _, o := range opts.Operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// tenantParam is the tenant parameter following db in generated method
+// signatures, empty for global resources.
+func tenantParam(opts *dep.DepMessageOptions) string {
+	if opts.Global {
+		return ""
+	}
+	return ", tenant string"
+}
+
+// tenantArg is the tenant passed to the stored procedures, global
+// resources are stored under the empty tenant.
+func tenantArg(opts *dep.DepMessageOptions) string {
+	if opts.Global {
+		return `""`
+	}
+	return "tenant"
+}
+
+// tenantForward passes the tenant parameter on to another generated
+// method, empty for global resources.
+func tenantForward(opts *dep.DepMessageOptions) string {
+	if opts.Global {
+		return ""
+	}
+	return ", tenant"
+}
+
+// fieldOptions returns the options of a field with every default filled in.
+// Fields without the (dep.field) option get the defaults as well.
+func fieldOptions(field *protogen.Field) *dep.DepFieldOptions {
+	opts := &dep.DepFieldOptions{}
+	if fieldOpts, ok := field.Desc.Options().(*descriptorpb.FieldOptions); ok &&
+		proto.HasExtension(fieldOpts, dep.E_Field) {
+		ext := proto.GetExtension(fieldOpts, dep.E_Field).(*dep.DepFieldOptions)
+		opts = proto.Clone(ext).(*dep.DepFieldOptions)
+	}
+
+	if opts.Label == "" {
+		opts.Label = field.GoName
+	}
+	if opts.Column == "" {
+		opts.Column = string(field.Desc.Name())
+	}
+	mergeBufValidate(opts, field)
+	if opts.Widget == dep.Widget_WIDGET_UNSPECIFIED {
+		opts.Widget = defaultWidget(field, opts)
+	}
+	// Audit fields are filled in by the writes.
+	if opts.Audit != dep.Audit_AUDIT_UNSPECIFIED {
+		opts.ReadOnly = true
+	}
+
+	return opts
+}
+
+// bufValidateField is the extension protovalidate declares field rules with.
+const bufValidateField = protoreflect.FullName("buf.validate.field")
+
+// numericRules are the buf.validate rule messages holding numeric bounds.
+var numericRules = []protoreflect.Name{
+	"float", "double", "int32", "int64", "uint32", "uint64",
+	"sint32", "sint64", "fixed32", "fixed64", "sfixed32", "sfixed64",
+}
+
+// mergeBufValidate fills the constraints of opts that are left unset from
+// buf.validate rules on field. protovalidate is not a dependency of the
+// plugin, the rules are read through the descriptors protoc sent along.
+func mergeBufValidate(opts *dep.DepFieldOptions, field *protogen.Field) {
+	rules := bufValidateRules(field)
+	if rules == nil {
+		return
+	}
+
+	if v, ok := ruleValue(rules, "required"); ok && v.Bool() {
+		opts.Required = true
+	}
+
+	for _, name := range []protoreflect.Name{"string", "bytes"} {
+		typed, ok := ruleValue(rules, name)
+		if !ok {
+			continue
+		}
+		if v, ok := ruleValue(typed.Message(), "min_len"); ok && opts.MinLen == 0 {
+			opts.MinLen = uint32(v.Uint())
+		}
+		if v, ok := ruleValue(typed.Message(), "max_len"); ok && opts.MaxLen == 0 {
+			opts.MaxLen = uint32(v.Uint())
+		}
+		if name != "string" {
+			continue
+		}
+		if v, ok := ruleValue(typed.Message(), "pattern"); ok && opts.Pattern == "" {
+			opts.Pattern = v.String()
+		}
+		if v, ok := ruleValue(typed.Message(), "email"); ok && v.Bool() {
+			opts.Email = true
+		}
+		if v, ok := ruleValue(typed.Message(), "uri"); ok && v.Bool() {
+			opts.Url = true
+		}
+	}
+
+	for _, name := range numericRules {
+		typed, ok := ruleValue(rules, name)
+		if !ok {
+			continue
+		}
+		// Exclusive bounds only carry over for integers, where they can be
+		// turned into inclusive ones.
+		integer := name != "float" && name != "double"
+		if v, ok := ruleValue(typed.Message(), "gte"); ok && opts.Min == nil {
+			opts.Min = proto.Float64(numberOf(v))
+		} else if v, ok := ruleValue(typed.Message(), "gt"); ok && opts.Min == nil && integer {
+			opts.Min = proto.Float64(numberOf(v) + 1)
+		}
+		if v, ok := ruleValue(typed.Message(), "lte"); ok && opts.Max == nil {
+			opts.Max = proto.Float64(numberOf(v))
+		} else if v, ok := ruleValue(typed.Message(), "lt"); ok && opts.Max == nil && integer {
+			opts.Max = proto.Float64(numberOf(v) - 1)
+		}
+	}
+
+	if typed, ok := ruleValue(rules, "enum"); ok {
+		if v, ok := ruleValue(typed.Message(), "defined_only"); ok && v.Bool() {
+			opts.DefinedOnly = true
+		}
+	}
+
+	for name, bounds := range map[protoreflect.Name][2]protoreflect.Name{
+		"repeated": {"min_items", "max_items"},
+		"map":      {"min_pairs", "max_pairs"},
+	} {
+		typed, ok := ruleValue(rules, name)
+		if !ok {
+			continue
+		}
+		if v, ok := ruleValue(typed.Message(), bounds[0]); ok && opts.MinItems == 0 {
+			opts.MinItems = uint32(v.Uint())
+		}
+		if v, ok := ruleValue(typed.Message(), bounds[1]); ok && opts.MaxItems == 0 {
+			opts.MaxItems = uint32(v.Uint())
+		}
+	}
+}
+
+// bufValidateRules returns the buf.validate.FieldConstraints set on field,
+// or nil when there are none.
+func bufValidateRules(field *protogen.Field) protoreflect.Message {
+	fieldOpts, ok := field.Desc.Options().(*descriptorpb.FieldOptions)
+	if !ok || len(fieldOpts.ProtoReflect().GetUnknown()) == 0 {
+		return nil
+	}
+
+	xd := findExtension(field.Desc.ParentFile(), bufValidateField, make(map[string]bool))
+	if xd == nil {
+		return nil
+	}
+	xt := dynamicpb.NewExtensionType(xd)
+	resolver := new(protoregistry.Types)
+	if err := resolver.RegisterExtension(xt); err != nil {
+		return nil
+	}
+
+	// The extension is still in the unknown fields, parse the options again
+	// now that it can be resolved.
+	raw, err := proto.Marshal(fieldOpts)
+	if err != nil {
+		return nil
+	}
+	parsed := new(descriptorpb.FieldOptions)
+	if err := (proto.UnmarshalOptions{Resolver: resolver}).Unmarshal(raw, parsed); err != nil {
+		return nil
+	}
+	if !parsed.ProtoReflect().Has(xt.TypeDescriptor()) {
+		return nil
+	}
+	return parsed.ProtoReflect().Get(xt.TypeDescriptor()).Message()
+}
+
+// findExtension looks up an extension in file and everything it imports.
+func findExtension(file protoreflect.FileDescriptor, name protoreflect.FullName, seen map[string]bool) protoreflect.ExtensionDescriptor {
+	if seen[file.Path()] {
+		return nil
+	}
+	seen[file.Path()] = true
+
+	if file.Package() == name.Parent() {
+		if xd := file.Extensions().ByName(name.Name()); xd != nil {
+			return xd
+		}
+	}
+	imports := file.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		if xd := findExtension(imports.Get(i).FileDescriptor, name, seen); xd != nil {
+			return xd
+		}
+	}
+	return nil
+}
+
+// ruleValue returns the named field of a rule message when it is set.
+func ruleValue(m protoreflect.Message, name protoreflect.Name) (protoreflect.Value, bool) {
+	fd := m.Descriptor().Fields().ByName(name)
+	if fd == nil || !m.Has(fd) {
+		return protoreflect.Value{}, false
+	}
+	return m.Get(fd), true
+}
+
+// numberOf converts a numeric rule value to a float64.
+func numberOf(v protoreflect.Value) float64 {
+	switch n := v.Interface().(type) {
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint32:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// defaultWidget picks the form input matching the kind and constraints of
+// field.
+func defaultWidget(field *protogen.Field, opts *dep.DepFieldOptions) dep.Widget {
+	if field.Desc.IsList() || field.Desc.IsMap() {
+		return dep.Widget_WIDGET_TEXTAREA
+	}
+	if opts.Email {
+		return dep.Widget_WIDGET_EMAIL
+	}
+	if opts.Url {
+		return dep.Widget_WIDGET_URL
+	}
+	if field.Message != nil && field.Message.Desc.FullName() == timestampName {
+		return dep.Widget_WIDGET_DATETIME
+	}
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return dep.Widget_WIDGET_CHECKBOX
+	case protoreflect.EnumKind:
+		return dep.Widget_WIDGET_SELECT
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind,
+		protoreflect.FloatKind, protoreflect.DoubleKind:
+		return dep.Widget_WIDGET_NUMBER
+	}
+	return dep.Widget_WIDGET_TEXT
+}
+
+// emptyCheck is a Go expression that is true when field of the value named
+// recv holds no data.
+func emptyCheck(recv string, field *protogen.Field) string {
+	access := recv + "." + field.GoName
+	if field.Oneof != nil && !field.Oneof.Desc.IsSynthetic() {
+		// Members of a real oneof live in a wrapper, go through the getter.
+		access = recv + ".Get" + field.GoName + "()"
+	}
+	switch {
+	case field.Desc.IsList() || field.Desc.IsMap():
+		return "len(" + access + ") == 0"
+	case field.Desc.Kind() == protoreflect.MessageKind || field.Desc.Kind() == protoreflect.GroupKind:
+		return access + " == nil"
+	case field.Desc.HasPresence() && (field.Oneof == nil || field.Oneof.Desc.IsSynthetic()):
+		// Optional scalars are generated as pointers.
+		return access + " == nil"
+	}
+	switch field.Desc.Kind() {
+	case protoreflect.StringKind:
+		return access + ` == ""`
+	case protoreflect.BytesKind:
+		return "len(" + access + ") == 0"
+	case protoreflect.BoolKind:
+		return "!" + access
+	}
+	return access + " == 0"
+}
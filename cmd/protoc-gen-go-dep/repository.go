@@ -0,0 +1,785 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"protoc-gen-go-dep/dep"
+)
+
+// generateRepository emits the <Message>Repository interface the handlers
+// depend on and <Message>SQLRepository, its implementation on top of the
+// generated persistence methods.
+func (p *Generator) generateRepository(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	name := message.GoIdent.GoName
+	repoName := name + "Repository"
+	sqlName := name + "SQLRepository"
+
+	// tenantParam leads the parameters of every method, forward passes the
+	// tenant on to the persistence methods after the db.
+	ctxParam := "ctx " + g.QualifiedGoIdent(contextPackage.Ident("Context")) + ", "
+	tenantParam, forward := ctxParam+"tenant string, ", ", tenant"
+	if opts.Global {
+		tenantParam, forward = ctxParam, ""
+	}
+	sig := repositorySignatures(g, message, opts)
+
+	// alreadyExists maps the unique violations of err to the fields of the
+	// index violated, it is err without unique keys.
+	unique := hasUniqueKeys(message, opts)
+	alreadyExists := "err"
+	if unique {
+		alreadyExists = g.QualifiedGoIdent(depPackage.Ident("AlreadyExists")) + "(err, " + lowerFirst(name) + "UniqueKeys...)"
+	}
+
+	g.P("// ", repoName, " stores ", name, " records. Get, Update, Patch and Delete return")
+	g.P("// dep.ErrNotFound for unknown ids, Patch returns the record as stored.")
+	if opts.Versioned {
+		g.P("//")
+		g.P("// Records are versioned, from 1 on every write. Get returns the version and the")
+		g.P("// writes the one stored, Update, Patch and Delete fail with dep.ErrConflict when")
+		g.P("// the record is not at the version they are given, 0 skips the check.")
+	}
+	trash := opts.SoftDelete && hasOperation(opts, dep.Operation_OPERATION_DELETE)
+	if trash {
+		g.P("//")
+		g.P("// Delete moves records to a trash the other methods do not see, ListDeleted lists")
+		g.P("// it. Restore and Purge return dep.ErrNotFound for ids not in the trash.")
+	}
+	if auditFields(message).any() {
+		g.P("//")
+		g.P("// The writes fill in the audit fields of data from the time and the actor of ctx")
+		g.P("// (see dep.WithActor), Update and Patch keep the ones recording the creation.")
+		if len(auditFields(message).created()) > 0 && hasOperation(opts, dep.Operation_OPERATION_UPDATE) {
+			g.P("// Update copies those into data, leaving it as stored.")
+		}
+	}
+	if hasUniqueKeys(message, opts) {
+		g.P("//")
+		g.P("// The writes fail with dep.ErrAlreadyExists, an *dep.AlreadyExistsError naming the")
+		g.P("// fields, when another record of the tenant holds the values of a unique index.")
+		if hasOperation(opts, dep.Operation_OPERATION_GET) && len(uniqueIndexes(message, opts)) > 0 {
+			g.P("// The GetBy methods return the record holding them, dep.ErrNotFound for none.")
+		}
+	}
+	if opts.History {
+		g.P("//")
+		g.P("// Every write adds a revision to the history of the record, History returns them")
+		g.P("// oldest first and GetAtRevision the record as one of them left it. Both return")
+		g.P("// dep.ErrNotFound for ids without a history and revisions that removed the record.")
+	}
+	g.P("type ", repoName, " interface {")
+	if hasOperation(opts, dep.Operation_OPERATION_LIST) {
+		g.P("   List(", tenantParam, "opts ", depPackage.Ident("ListOptions"), ") (*", depPackage.Ident("Page"), "[*", message.GoIdent, ", ", sig.id, "], error)")
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_GET) {
+		g.P("   Get(", tenantParam, "id ", sig.id, ") ", sig.get)
+		for _, idx := range uniqueIndexes(message, opts) {
+			g.P("   ", idx.finder(), "(", strings.TrimSuffix(tenantParam, ", "), finderParams(g, idx), ") ", sig.find)
+		}
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_CREATE) {
+		g.P("   Create(", tenantParam, "data *", message.GoIdent, ") (", sig.id, ", error)")
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_UPDATE) {
+		g.P("   Update(", tenantParam, "id ", sig.id, sig.version, ", data *", message.GoIdent, ") ", sig.update)
+		g.P("   Patch(", tenantParam, "id ", sig.id, sig.version, ", data *", message.GoIdent, ", mask *", fieldmaskpbPackage.Ident("FieldMask"), ") ", sig.patch)
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_DELETE) {
+		g.P("   Delete(", tenantParam, "id ", sig.id, sig.version, ") error")
+	}
+	if trash && hasOperation(opts, dep.Operation_OPERATION_LIST) {
+		g.P("   ListDeleted(", tenantParam, "opts ", depPackage.Ident("ListOptions"), ") (*", depPackage.Ident("Page"), "[*", message.GoIdent, ", ", sig.id, "], error)")
+	}
+	if trash {
+		g.P("   Restore(", tenantParam, "id ", sig.id, ") error")
+		g.P("   Purge(", tenantParam, "id ", sig.id, ") error")
+	}
+	if opts.History {
+		g.P("   History(", tenantParam, "id ", sig.id, ") ([]", sig.revision, ", error)")
+		g.P("   GetAtRevision(", tenantParam, "id ", sig.id, ", revision int64) (*", message.GoIdent, ", error)")
+	}
+	g.P("}")
+	g.P("")
+
+	g.P("// ", sqlName, " is the ", repoName, " backed by the ", name, " persistence methods")
+	g.P("type ", sqlName, " struct {")
+	g.P("   DB DBTX")
+	g.P("}")
+	g.P("")
+	g.P("// New", sqlName, " returns a ", sqlName, " using db")
+	g.P("func New", sqlName, "(db DBTX) *", sqlName, " {")
+	g.P("   return &", sqlName, "{DB: db}")
+	g.P("}")
+	g.P("")
+	g.P("var _ ", repoName, " = (*", sqlName, ")(nil)")
+	g.P("")
+
+	if hasOperation(opts, dep.Operation_OPERATION_LIST) {
+		g.P("func (r *", sqlName, ") List(", tenantParam, "opts ", depPackage.Ident("ListOptions"), ") (*", depPackage.Ident("Page"), "[*", message.GoIdent, ", ", sig.id, "], error) {")
+		g.P("   return new(", message.GoIdent, ").List(ctx, r.DB", forward, ", opts)")
+		g.P("}")
+		g.P("")
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_GET) && opts.Versioned {
+		g.P("func (r *", sqlName, ") Get(", tenantParam, "id ", sig.id, ") ", sig.get, " {")
+		g.P("   x := new(", message.GoIdent, ")")
+		g.P("   version, err := x.Get(ctx, r.DB", forward, ", id)")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", p.errNoRows(), ") {")
+		g.P("       return nil, 0, ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   if err != nil {")
+		g.P("       return nil, 0, err")
+		g.P("   }")
+		g.P("")
+		g.P("   return x, version, nil")
+		g.P("}")
+		g.P("")
+	} else if hasOperation(opts, dep.Operation_OPERATION_GET) {
+		g.P("func (r *", sqlName, ") Get(", tenantParam, "id ", sig.id, ") (*", message.GoIdent, ", error) {")
+		g.P("   x := new(", message.GoIdent, ")")
+		g.P("   err := x.Get(ctx, r.DB", forward, ", id)")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", p.errNoRows(), ") {")
+		g.P("       return nil, ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   if err != nil {")
+		g.P("       return nil, err")
+		g.P("   }")
+		g.P("")
+		g.P("   return x, nil")
+		g.P("}")
+		g.P("")
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_GET) {
+		p.generateRepositoryFinders(g, message, opts, sqlName, tenantParam, forward)
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_CREATE) && unique {
+		g.P("func (r *", sqlName, ") Create(", tenantParam, "data *", message.GoIdent, ") (", sig.id, ", error) {")
+		g.P("   id, err := data.Create(ctx, r.DB", forward, ", data)")
+		g.P("   return id, ", alreadyExists)
+		g.P("}")
+		g.P("")
+	} else if hasOperation(opts, dep.Operation_OPERATION_CREATE) {
+		g.P("func (r *", sqlName, ") Create(", tenantParam, "data *", message.GoIdent, ") (", sig.id, ", error) {")
+		g.P("   return data.Create(ctx, r.DB", forward, ", data)")
+		g.P("}")
+		g.P("")
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_UPDATE) && opts.Versioned {
+		g.P("func (r *", sqlName, ") Update(", tenantParam, "id ", sig.id, ", version int64, data *", message.GoIdent, ") (int64, error) {")
+		g.P("   stored, err := data.Update(ctx, r.DB", forward, ", id, version, data)")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", p.errNoRows(), ") {")
+		g.P("       return 0, ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		if unique {
+			g.P("   return stored, ", alreadyExists)
+		} else {
+			g.P("   return stored, err")
+		}
+		g.P("}")
+		g.P("")
+		g.P("func (r *", sqlName, ") Patch(", tenantParam, "id ", sig.id, ", version int64, data *", message.GoIdent, ", mask *", fieldmaskpbPackage.Ident("FieldMask"), ") ", sig.patch, " {")
+		g.P("   x := new(", message.GoIdent, ")")
+		g.P("   stored, err := x.Patch(ctx, r.DB", forward, ", id, version, data, mask)")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", p.errNoRows(), ") {")
+		g.P("       return nil, 0, ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   if err != nil {")
+		g.P("       return nil, 0, ", alreadyExists)
+		g.P("   }")
+		g.P("")
+		g.P("   return x, stored, nil")
+		g.P("}")
+		g.P("")
+	} else if hasOperation(opts, dep.Operation_OPERATION_UPDATE) {
+		g.P("func (r *", sqlName, ") Update(", tenantParam, "id ", sig.id, ", data *", message.GoIdent, ") error {")
+		g.P("   err := data.Update(ctx, r.DB", forward, ", id, data)")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", p.errNoRows(), ") {")
+		g.P("       return ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   return ", alreadyExists)
+		g.P("}")
+		g.P("")
+		g.P("func (r *", sqlName, ") Patch(", tenantParam, "id ", sig.id, ", data *", message.GoIdent, ", mask *", fieldmaskpbPackage.Ident("FieldMask"), ") (*", message.GoIdent, ", error) {")
+		g.P("   x := new(", message.GoIdent, ")")
+		g.P("   err := x.Patch(ctx, r.DB", forward, ", id, data, mask)")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", p.errNoRows(), ") {")
+		g.P("       return nil, ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   if err != nil {")
+		g.P("       return nil, ", alreadyExists)
+		g.P("   }")
+		g.P("")
+		g.P("   return x, nil")
+		g.P("}")
+		g.P("")
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_DELETE) && opts.Versioned {
+		g.P("func (r *", sqlName, ") Delete(", tenantParam, "id ", sig.id, ", version int64) error {")
+		g.P("   err := new(", message.GoIdent, ").Delete(ctx, r.DB", forward, ", id, version)")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", p.errNoRows(), ") {")
+		g.P("       return ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   return err")
+		g.P("}")
+		g.P("")
+	} else if hasOperation(opts, dep.Operation_OPERATION_DELETE) {
+		g.P("func (r *", sqlName, ") Delete(", tenantParam, "id ", sig.id, ") error {")
+		g.P("   err := new(", message.GoIdent, ").Delete(ctx, r.DB", forward, ", id)")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", p.errNoRows(), ") {")
+		g.P("       return ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   return err")
+		g.P("}")
+		g.P("")
+	}
+	if trash && hasOperation(opts, dep.Operation_OPERATION_LIST) {
+		g.P("func (r *", sqlName, ") ListDeleted(", tenantParam, "opts ", depPackage.Ident("ListOptions"), ") (*", depPackage.Ident("Page"), "[*", message.GoIdent, ", ", sig.id, "], error) {")
+		g.P("   return new(", message.GoIdent, ").ListDeleted(ctx, r.DB", forward, ", opts)")
+		g.P("}")
+		g.P("")
+	}
+	if trash {
+		for _, method := range []string{"Restore", "Purge"} {
+			g.P("func (r *", sqlName, ") ", method, "(", tenantParam, "id ", sig.id, ") error {")
+			g.P("   err := new(", message.GoIdent, ").", method, "(ctx, r.DB", forward, ", id)")
+			g.P("   if ", errorsPackage.Ident("Is"), "(err, ", p.errNoRows(), ") {")
+			g.P("       return ", depPackage.Ident("ErrNotFound"))
+			g.P("   }")
+			if method == "Restore" && unique {
+				g.P("   return ", alreadyExists)
+			} else {
+				g.P("   return err")
+			}
+			g.P("}")
+			g.P("")
+		}
+	}
+	if opts.History {
+		g.P("func (r *", sqlName, ") History(", tenantParam, "id ", sig.id, ") ([]", sig.revision, ", error) {")
+		g.P("   revisions, err := new(", message.GoIdent, ").History(ctx, r.DB", forward, ", id)")
+		g.P("   if err == nil && len(revisions) == 0 {")
+		g.P("       return nil, ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   return revisions, err")
+		g.P("}")
+		g.P("")
+		g.P("func (r *", sqlName, ") GetAtRevision(", tenantParam, "id ", sig.id, ", revision int64) (*", message.GoIdent, ", error) {")
+		g.P("   x := new(", message.GoIdent, ")")
+		g.P("   err := x.GetAtRevision(ctx, r.DB", forward, ", id, revision)")
+		g.P("   if ", errorsPackage.Ident("Is"), "(err, ", p.errNoRows(), ") {")
+		g.P("       return nil, ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   if err != nil {")
+		g.P("       return nil, err")
+		g.P("   }")
+		g.P("")
+		g.P("   return x, nil")
+		g.P("}")
+		g.P("")
+	}
+	p.generateMemoryRepository(g, message, opts)
+}
+
+// repoSignatures holds what sets the methods of a versioned repository
+// apart, the version parameter and the results.
+type repoSignatures struct {
+	// id is the type of the ids.
+	id                 string
+	version            string
+	get, update, patch string
+	// find is the results of the finders, which return the id as well.
+	find string
+	// revision is the type of the entries of the history.
+	revision string
+}
+
+func repositorySignatures(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) repoSignatures {
+	x := "*" + g.QualifiedGoIdent(message.GoIdent)
+	id := idType(g, message, opts)
+	var revision string
+	if opts.History {
+		revision = g.QualifiedGoIdent(depPackage.Ident("Revision")) + "[" + x + "]"
+	}
+	if opts.Versioned {
+		return repoSignatures{
+			id:       id,
+			version:  ", version int64",
+			get:      "(" + x + ", int64, error)",
+			update:   "(int64, error)",
+			patch:    "(" + x + ", int64, error)",
+			find:     "(" + x + ", " + id + ", int64, error)",
+			revision: revision,
+		}
+	}
+	return repoSignatures{
+		id:       id,
+		get:      "(" + x + ", error)",
+		update:   "error",
+		patch:    "(" + x + ", error)",
+		find:     "(" + x + ", " + id + ", error)",
+		revision: revision,
+	}
+}
+
+// generateMemoryRepository emits <Message>MemoryRepository, a
+// <Message>Repository keeping records in maps for tests and prototypes.
+func (p *Generator) generateMemoryRepository(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	name := message.GoIdent.GoName
+	repoName := name + "Repository"
+	memName := name + "MemoryRepository"
+	sig := repositorySignatures(g, message, opts)
+	trash := opts.SoftDelete && hasOperation(opts, dep.Operation_OPERATION_DELETE)
+	a := auditFields(message)
+	zero := idZero(g, message, opts)
+
+	ctxParam := "ctx " + g.QualifiedGoIdent(contextPackage.Ident("Context")) + ", "
+	tenantParam, tenant := ctxParam+"tenant string, ", "tenant"
+	if opts.Global {
+		tenantParam, tenant = ctxParam, `""`
+	}
+	records := "map[string]map[" + sig.id + "]*" + g.QualifiedGoIdent(message.GoIdent)
+
+	g.P("// ", memName, " is a ", repoName, " keeping records in memory, safe for")
+	g.P("// concurrent use. Records are copied on the way in and out.")
+	g.P("type ", memName, " struct {")
+	g.P("   mu      ", syncPackage.Ident("RWMutex"))
+	if !mintsID(opts) {
+		g.P("   lastID  int64")
+	}
+	g.P("   tenants ", records)
+	if opts.Versioned {
+		g.P("   // versions holds the version of every record by tenant and id")
+		g.P("   versions map[string]map[", sig.id, "]int64")
+	}
+	if trash {
+		g.P("   // deleted holds the records in the trash")
+		g.P("   deleted ", records)
+	}
+	if opts.History {
+		g.P("   // history holds the revisions of every record by tenant and id")
+		g.P("   history map[string]map[", sig.id, "][]", sig.revision)
+	}
+	g.P("}")
+	g.P("")
+	g.P("// New", memName, " returns an empty ", memName)
+	g.P("func New", memName, "() *", memName, " {")
+	fields := "tenants: make(" + records + ")"
+	if opts.Versioned {
+		fields += ", versions: make(map[string]map[" + sig.id + "]int64)"
+	}
+	if trash {
+		fields += ", deleted: make(" + records + ")"
+	}
+	if opts.History {
+		fields += ", history: make(map[string]map[" + sig.id + "][]" + sig.revision + ")"
+	}
+	g.P("   return &", memName, "{", fields, "}")
+	g.P("}")
+	g.P("")
+	g.P("var _ ", repoName, " = (*", memName, ")(nil)")
+	g.P("")
+	g.P("// lookup returns the stored record, the lock has to be held")
+	g.P("func (r *", memName, ") lookup(tenant string, id ", sig.id, ") (*", message.GoIdent, ", error) {")
+	g.P("   x, ok := r.tenants[tenant][id]")
+	g.P("   if !ok {")
+	g.P("       return nil, ", depPackage.Ident("ErrNotFound"))
+	g.P("   }")
+	g.P("   return x, nil")
+	g.P("}")
+	g.P("")
+	if opts.Versioned {
+		g.P("// checkVersion fails with dep.ErrConflict when the record is not at version,")
+		g.P("// the lock has to be held")
+		g.P("func (r *", memName, ") checkVersion(tenant string, id ", sig.id, ", version int64) error {")
+		g.P("   if version != 0 && version != r.versions[tenant][id] {")
+		g.P("       return ", depPackage.Ident("ErrConflict"))
+		g.P("   }")
+		g.P("   return nil")
+		g.P("}")
+		g.P("")
+	}
+
+	clone := func(v string) string {
+		return g.QualifiedGoIdent(protoPackage.Ident("Clone")) + "(" + v + ").(*" + g.QualifiedGoIdent(message.GoIdent) + ")"
+	}
+
+	// record is the statement adding the revision of a write leaving the
+	// record at value to its history.
+	record := func(operation, value string) {
+		if opts.History {
+			g.P("   r.record(ctx, ", tenant, ", id, ", depPackage.Ident(operation), ", ", value, ")")
+		}
+	}
+	if opts.History {
+		g.P("// record adds the revision of a write leaving the record at value, nil when it")
+		g.P("// is gone, to its history. The lock has to be held")
+		g.P("func (r *", memName, ") record(ctx ", contextPackage.Ident("Context"), ", tenant string, id ", sig.id, ", operation string, value *", message.GoIdent, ") {")
+		g.P("   revisions := r.history[tenant][id]")
+		g.P("   revision := ", sig.revision, "{")
+		g.P("       Revision:  int64(len(revisions) + 1),")
+		g.P("       Operation: operation,")
+		g.P("       Actor:     ", depPackage.Ident("Actor"), "(ctx),")
+		g.P("       ChangedAt: ", timePackage.Ident("Now"), "(),")
+		g.P("       New:       ", clone("value"), ",")
+		g.P("   }")
+		g.P("   if len(revisions) > 0 {")
+		g.P("       revision.Old = revisions[len(revisions)-1].New")
+		g.P("   }")
+		g.P("   if r.history[tenant] == nil {")
+		g.P("       r.history[tenant] = make(map[", sig.id, "][]", sig.revision, ")")
+		g.P("   }")
+		g.P("   r.history[tenant][id] = append(revisions, revision)")
+		g.P("}")
+		g.P("")
+	}
+
+	// list emits a method listing the records in the given map.
+	recordType := g.QualifiedGoIdent(depPackage.Ident("Record")) + "[*" + g.QualifiedGoIdent(message.GoIdent) + ", " + sig.id + "]"
+	list := func(method, field string) {
+		g.P("func (r *", memName, ") ", method, "(", tenantParam, "opts ", depPackage.Ident("ListOptions"), ") (*", depPackage.Ident("Page"), "[*", message.GoIdent, ", ", sig.id, "], error) {")
+		g.P("   q, err := ", lowerFirst(name), "ListSchema.Query(opts)")
+		g.P("   if err != nil {")
+		g.P("       return nil, err")
+		g.P("   }")
+		g.P("")
+		g.P("   r.mu.RLock()")
+		g.P("   defer r.mu.RUnlock()")
+		g.P("")
+		g.P("   records := make([]", recordType, ", 0, len(r.", field, "[", tenant, "]))")
+		g.P("   for id, x := range r.", field, "[", tenant, "] {")
+		g.P("       records = append(records, ", recordType, "{ID: id, Value: ", clone("x"), "})")
+		g.P("   }")
+		g.P("   return ", depPackage.Ident("ListRecords"), "(q, records), nil")
+		g.P("}")
+		g.P("")
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_LIST) {
+		list("List", "tenants")
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_GET) && opts.Versioned {
+		g.P("func (r *", memName, ") Get(", tenantParam, "id ", sig.id, ") (*", message.GoIdent, ", int64, error) {")
+		g.P("   r.mu.RLock()")
+		g.P("   defer r.mu.RUnlock()")
+		g.P("")
+		g.P("   x, err := r.lookup(", tenant, ", id)")
+		g.P("   if err != nil {")
+		g.P("       return nil, 0, err")
+		g.P("   }")
+		g.P("   return ", clone("x"), ", r.versions[", tenant, "][id], nil")
+		g.P("}")
+		g.P("")
+	} else if hasOperation(opts, dep.Operation_OPERATION_GET) {
+		g.P("func (r *", memName, ") Get(", tenantParam, "id ", sig.id, ") (*", message.GoIdent, ", error) {")
+		g.P("   r.mu.RLock()")
+		g.P("   defer r.mu.RUnlock()")
+		g.P("")
+		g.P("   x, err := r.lookup(", tenant, ", id)")
+		g.P("   if err != nil {")
+		g.P("       return nil, err")
+		g.P("   }")
+		g.P("   return ", clone("x"), ", nil")
+		g.P("}")
+		g.P("")
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_GET) {
+		generateMemoryFinders(g, message, opts, memName, tenantParam, tenant, clone)
+	}
+	generateMemoryTaken(g, message, opts, memName)
+	if hasOperation(opts, dep.Operation_OPERATION_CREATE) {
+		g.P("func (r *", memName, ") Create(", tenantParam, "data *", message.GoIdent, ") (", sig.id, ", error) {")
+		if a.any() {
+			g.P("   data.stamp(ctx, true)")
+		}
+		g.P("   if err := data.Validate(); err != nil {")
+		g.P("       return ", zero, ", err")
+		g.P("   }")
+		generateNewID(g, message, opts, zero)
+		g.P("")
+		g.P("   r.mu.Lock()")
+		g.P("   defer r.mu.Unlock()")
+		g.P("")
+		if opts.IdStrategy == dep.IdStrategy_ID_STRATEGY_NATURAL_KEY {
+			exists := "_, ok := r.tenants[" + tenant + "][id]; ok"
+			if trash {
+				exists = "_, ok := r.tenants[" + tenant + "][id]; ok || r.deleted[" + tenant + "][id] != nil"
+			}
+			g.P("   if ", exists, " {")
+			g.P("       return ", zero, ", &", depPackage.Ident("AlreadyExistsError"), "{Fields: []string{", strconv.Quote(opts.IdField), "}}")
+			g.P("   }")
+		}
+		// Serial ids are taken once the record is known to be stored, so a
+		// failed create does not use one up.
+		if mintsID(opts) {
+			generateMemoryTakenCheck(g, message, opts, tenant, "id", "data", zero+", ")
+		} else {
+			generateMemoryTakenCheck(g, message, opts, tenant, zero, "data", zero+", ")
+			g.P("   r.lastID++")
+			g.P("   id := r.lastID")
+		}
+		g.P("   if r.tenants[", tenant, "] == nil {")
+		g.P("       r.tenants[", tenant, "] = make(map[", sig.id, "]*", message.GoIdent, ")")
+		g.P("   }")
+		g.P("   r.tenants[", tenant, "][id] = ", clone("data"))
+		if opts.Versioned {
+			g.P("   if r.versions[", tenant, "] == nil {")
+			g.P("       r.versions[", tenant, "] = make(map[", sig.id, "]int64)")
+			g.P("   }")
+			g.P("   r.versions[", tenant, "][id] = 1")
+		}
+		record("RevisionCreate", "r.tenants["+tenant+"][id]")
+		g.P("   return id, nil")
+		g.P("}")
+		g.P("")
+	}
+	// stored is the record an Update replaces, it keeps the audit fields
+	// recording the creation.
+	stored := "_"
+	if len(a.created()) > 0 {
+		stored = "stored"
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_UPDATE) && opts.Versioned {
+		g.P("func (r *", memName, ") Update(", tenantParam, "id ", sig.id, ", version int64, data *", message.GoIdent, ") (int64, error) {")
+		generateKeepKey(g, message, opts)
+		if a.any() {
+			g.P("   data.stamp(ctx, false)")
+		}
+		g.P("   if err := data.Validate(); err != nil {")
+		g.P("       return 0, err")
+		g.P("   }")
+		g.P("")
+		g.P("   r.mu.Lock()")
+		g.P("   defer r.mu.Unlock()")
+		g.P("")
+		g.P("   ", stored, ", err := r.lookup(", tenant, ", id)")
+		g.P("   if err != nil {")
+		g.P("       return 0, err")
+		g.P("   }")
+		g.P("   if err := r.checkVersion(", tenant, ", id, version); err != nil {")
+		g.P("       return 0, err")
+		g.P("   }")
+		generateMemoryTakenCheck(g, message, opts, tenant, "id", "data", "0, ")
+		generateMemoryStore(g, a, tenant, clone("data"))
+		g.P("   r.versions[", tenant, "][id]++")
+		record("RevisionUpdate", "r.tenants["+tenant+"][id]")
+		g.P("   return r.versions[", tenant, "][id], nil")
+		g.P("}")
+		g.P("")
+		g.P("func (r *", memName, ") Patch(", tenantParam, "id ", sig.id, ", version int64, data *", message.GoIdent, ", mask *", fieldmaskpbPackage.Ident("FieldMask"), ") (*", message.GoIdent, ", int64, error) {")
+		g.P("   paths, err := ", depPackage.Ident("FieldMaskPaths"), "(data, mask)")
+		g.P("   if err != nil {")
+		g.P("       return nil, 0, err")
+		g.P("   }")
+		generateKeepKey(g, message, opts)
+		if a.any() {
+			g.P("   data.stamp(ctx, false)")
+			generateStampPaths(g, a)
+		}
+		g.P("   if err := ", depPackage.Ident("MaskedErrors"), "(data.Validate(), paths); err != nil {")
+		g.P("       return nil, 0, err")
+		g.P("   }")
+		g.P("")
+		g.P("   r.mu.Lock()")
+		g.P("   defer r.mu.Unlock()")
+		g.P("")
+		g.P("   x, err := r.lookup(", tenant, ", id)")
+		g.P("   if err != nil {")
+		g.P("       return nil, 0, err")
+		g.P("   }")
+		g.P("   if err := r.checkVersion(", tenant, ", id, version); err != nil {")
+		g.P("       return nil, 0, err")
+		g.P("   }")
+		generateMemoryPatch(g, message, opts, tenant, clone("x"), "nil, 0, ")
+		g.P("   r.versions[", tenant, "][id]++")
+		record("RevisionPatch", "x")
+		g.P("   return ", clone("x"), ", r.versions[", tenant, "][id], nil")
+		g.P("}")
+		g.P("")
+	} else if hasOperation(opts, dep.Operation_OPERATION_UPDATE) {
+		g.P("func (r *", memName, ") Update(", tenantParam, "id ", sig.id, ", data *", message.GoIdent, ") error {")
+		generateKeepKey(g, message, opts)
+		if a.any() {
+			g.P("   data.stamp(ctx, false)")
+		}
+		g.P("   if err := data.Validate(); err != nil {")
+		g.P("       return err")
+		g.P("   }")
+		g.P("")
+		g.P("   r.mu.Lock()")
+		g.P("   defer r.mu.Unlock()")
+		g.P("")
+		g.P("   ", stored, ", err := r.lookup(", tenant, ", id)")
+		g.P("   if err != nil {")
+		g.P("       return err")
+		g.P("   }")
+		generateMemoryTakenCheck(g, message, opts, tenant, "id", "data", "")
+		generateMemoryStore(g, a, tenant, clone("data"))
+		record("RevisionUpdate", "r.tenants["+tenant+"][id]")
+		g.P("   return nil")
+		g.P("}")
+		g.P("")
+		g.P("func (r *", memName, ") Patch(", tenantParam, "id ", sig.id, ", data *", message.GoIdent, ", mask *", fieldmaskpbPackage.Ident("FieldMask"), ") (*", message.GoIdent, ", error) {")
+		g.P("   paths, err := ", depPackage.Ident("FieldMaskPaths"), "(data, mask)")
+		g.P("   if err != nil {")
+		g.P("       return nil, err")
+		g.P("   }")
+		generateKeepKey(g, message, opts)
+		if a.any() {
+			g.P("   data.stamp(ctx, false)")
+			generateStampPaths(g, a)
+		}
+		g.P("   if err := ", depPackage.Ident("MaskedErrors"), "(data.Validate(), paths); err != nil {")
+		g.P("       return nil, err")
+		g.P("   }")
+		g.P("")
+		g.P("   r.mu.Lock()")
+		g.P("   defer r.mu.Unlock()")
+		g.P("")
+		g.P("   x, err := r.lookup(", tenant, ", id)")
+		g.P("   if err != nil {")
+		g.P("       return nil, err")
+		g.P("   }")
+		generateMemoryPatch(g, message, opts, tenant, clone("x"), "nil, ")
+		record("RevisionPatch", "x")
+		g.P("   return ", clone("x"), ", nil")
+		g.P("}")
+		g.P("")
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_DELETE) {
+		g.P("func (r *", memName, ") Delete(", tenantParam, "id ", sig.id, sig.version, ") error {")
+		g.P("   r.mu.Lock()")
+		g.P("   defer r.mu.Unlock()")
+		g.P("")
+		if trash {
+			g.P("   x, err := r.lookup(", tenant, ", id)")
+			g.P("   if err != nil {")
+			g.P("       return err")
+			g.P("   }")
+		} else {
+			g.P("   if _, err := r.lookup(", tenant, ", id); err != nil {")
+			g.P("       return err")
+			g.P("   }")
+		}
+		if opts.Versioned {
+			g.P("   if err := r.checkVersion(", tenant, ", id, version); err != nil {")
+			g.P("       return err")
+			g.P("   }")
+			if !trash {
+				g.P("   delete(r.versions[", tenant, "], id)")
+			}
+		}
+		if trash {
+			g.P("   if r.deleted[", tenant, "] == nil {")
+			g.P("       r.deleted[", tenant, "] = make(map[", sig.id, "]*", message.GoIdent, ")")
+			g.P("   }")
+			g.P("   r.deleted[", tenant, "][id] = x")
+		}
+		g.P("   delete(r.tenants[", tenant, "], id)")
+		record("RevisionDelete", "nil")
+		g.P("   return nil")
+		g.P("}")
+		g.P("")
+	}
+	if trash && hasOperation(opts, dep.Operation_OPERATION_LIST) {
+		list("ListDeleted", "deleted")
+	}
+	if trash {
+		g.P("// trashed returns a record in the trash, the lock has to be held")
+		g.P("func (r *", memName, ") trashed(tenant string, id ", sig.id, ") (*", message.GoIdent, ", error) {")
+		g.P("   x, ok := r.deleted[tenant][id]")
+		g.P("   if !ok {")
+		g.P("       return nil, ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   return x, nil")
+		g.P("}")
+		g.P("")
+		g.P("func (r *", memName, ") Restore(", tenantParam, "id ", sig.id, ") error {")
+		g.P("   r.mu.Lock()")
+		g.P("   defer r.mu.Unlock()")
+		g.P("")
+		g.P("   x, err := r.trashed(", tenant, ", id)")
+		g.P("   if err != nil {")
+		g.P("       return err")
+		g.P("   }")
+		generateMemoryTakenCheck(g, message, opts, tenant, "id", "x", "")
+		g.P("   if r.tenants[", tenant, "] == nil {")
+		g.P("       r.tenants[", tenant, "] = make(map[", sig.id, "]*", message.GoIdent, ")")
+		g.P("   }")
+		g.P("   r.tenants[", tenant, "][id] = x")
+		g.P("   delete(r.deleted[", tenant, "], id)")
+		record("RevisionRestore", "x")
+		g.P("   return nil")
+		g.P("}")
+		g.P("")
+		g.P("func (r *", memName, ") Purge(", tenantParam, "id ", sig.id, ") error {")
+		g.P("   r.mu.Lock()")
+		g.P("   defer r.mu.Unlock()")
+		g.P("")
+		g.P("   if _, err := r.trashed(", tenant, ", id); err != nil {")
+		g.P("       return err")
+		g.P("   }")
+		if opts.Versioned {
+			g.P("   delete(r.versions[", tenant, "], id)")
+		}
+		g.P("   delete(r.deleted[", tenant, "], id)")
+		record("RevisionPurge", "nil")
+		g.P("   return nil")
+		g.P("}")
+		g.P("")
+	}
+	if opts.History {
+		g.P("func (r *", memName, ") History(", tenantParam, "id ", sig.id, ") ([]", sig.revision, ", error) {")
+		g.P("   r.mu.RLock()")
+		g.P("   defer r.mu.RUnlock()")
+		g.P("")
+		g.P("   revisions, ok := r.history[", tenant, "][id]")
+		g.P("   if !ok {")
+		g.P("       return nil, ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   ret := make([]", sig.revision, ", len(revisions))")
+		g.P("   for i, revision := range revisions {")
+		g.P("       revision.Old, revision.New = ", clone("revision.Old"), ", ", clone("revision.New"))
+		g.P("       ret[i] = revision")
+		g.P("   }")
+		g.P("   return ret, nil")
+		g.P("}")
+		g.P("")
+		g.P("func (r *", memName, ") GetAtRevision(", tenantParam, "id ", sig.id, ", revision int64) (*", message.GoIdent, ", error) {")
+		g.P("   r.mu.RLock()")
+		g.P("   defer r.mu.RUnlock()")
+		g.P("")
+		g.P("   revisions := r.history[", tenant, "][id]")
+		g.P("   if revision < 1 || revision > int64(len(revisions)) || revisions[revision-1].New == nil {")
+		g.P("       return nil, ", depPackage.Ident("ErrNotFound"))
+		g.P("   }")
+		g.P("   return ", clone("revisions[revision-1].New"), ", nil")
+		g.P("}")
+		g.P("")
+	}
+}
+
+// generateMemoryStore emits the statements of a memory Update storing value,
+// a copy of data, as the record at id. The audit fields recording the
+// creation are taken from the stored record into data first, so it is left
+// holding the record as stored, like the SQL Update leaves it.
+func generateMemoryStore(g *protogen.GeneratedFile, a audit, tenant, value string) {
+	for _, field := range a.created() {
+		g.P("   data.", field.GoName, " = stored.", field.GoName)
+	}
+	g.P("   r.tenants[", tenant, "][id] = ", value)
+}
+
+// generateMemoryPatch emits the statements of a memory Patch applying data to
+// the record x at id. The patch is applied to value, a copy of x, which only
+// replaces it when it is valid as a whole and its unique fields are not taken.
+func generateMemoryPatch(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions, tenant, value, results string) {
+	g.P("   x = ", value)
+	g.P("   ", depPackage.Ident("ApplyFieldMask"), "(x, data, paths)")
+	g.P("   if err := x.Validate(); err != nil {")
+	g.P("       return ", results, "err")
+	g.P("   }")
+	generateMemoryTakenCheck(g, message, opts, tenant, "id", "x", results)
+	g.P("   r.tenants[", tenant, "][id] = x")
+}
@@ -0,0 +1,541 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"protoc-gen-go-dep/dep"
+)
+
+// generateSchemaFile writes the schema backing the resources of file next
+// to the generated Go, <name>.pb.dep.sql. It is safe to apply more than
+// once, the shared Postgres routines are replaced by every file.
+func (p *Generator) generateSchemaFile(file *protogen.File, resources []*protogen.Message) {
+	s := p.plugin.NewGeneratedFile(file.GeneratedFilenamePrefix+".pb.dep.sql", "")
+
+	s.P("-- Code generated by protoc-gen-go-dep. DO NOT EDIT.")
+	s.P("-- source: ", file.Desc.Path())
+	for _, message := range resources {
+		opts := resourceOptions(message)
+		table := sqlIdent(opts.Table)
+
+		s.P("")
+
+		if opts.Storage == dep.Storage_STORAGE_COLUMNS {
+			s.P("-- ", message.GoIdent.GoName, " records, one column per field.")
+		} else {
+			s.P("-- ", message.GoIdent.GoName, " records, one document per row.")
+		}
+		if opts.Global {
+			s.P("-- Shared by all tenants, rows are stored with an empty tenant.")
+		}
+		defs := []string{p.idColumn(message, opts), "tenant TEXT NOT NULL"}
+		// added are the columns of options that can be turned on for an
+		// existing table, which CREATE TABLE IF NOT EXISTS leaves alone.
+		var added []string
+		if opts.SoftDelete {
+			if p.dialect == dialectSQLite {
+				defs = append(defs, "deleted_at DATETIME")
+			} else {
+				defs = append(defs, "deleted_at TIMESTAMPTZ")
+			}
+			added = append(added, defs[len(defs)-1])
+		}
+		if opts.Versioned {
+			if p.dialect == dialectSQLite {
+				defs = append(defs, "version INTEGER NOT NULL DEFAULT 1")
+			} else {
+				defs = append(defs, "version BIGINT NOT NULL DEFAULT 1")
+			}
+			added = append(added, defs[len(defs)-1])
+		}
+		if opts.Storage == dep.Storage_STORAGE_COLUMNS {
+			for _, c := range storageColumns(message, p.dialect) {
+				defs = append(defs, c.name+" "+c.sqlType)
+			}
+		} else if p.dialect == dialectSQLite {
+			defs = append(defs, "data TEXT NOT NULL")
+		} else {
+			defs = append(defs, "data JSONB NOT NULL")
+		}
+		if opts.IdStrategy == dep.IdStrategy_ID_STRATEGY_NATURAL_KEY {
+			defs = append(defs, "PRIMARY KEY (tenant, id)")
+		}
+		s.P("CREATE TABLE IF NOT EXISTS ", table, " (")
+		for i, def := range defs {
+			if i < len(defs)-1 {
+				def += ","
+			}
+			s.P("    ", def)
+		}
+		s.P(");")
+		// SQLite cannot add a column only when it is missing.
+		if p.dialect == dialectPostgres {
+			for _, def := range added {
+				s.P("ALTER TABLE ", table, " ADD COLUMN IF NOT EXISTS ", def, ";")
+			}
+		}
+		s.P("")
+		s.P("CREATE INDEX IF NOT EXISTS ", sqlIdent(opts.Table+"_tenant_idx"), " ON ", table, " (tenant);")
+		p.generateIndexes(s, message, opts)
+		if opts.SoftDelete && p.usesRoutines(opts) {
+			s.P("")
+			s.P("-- The routines writing ", table, " are passed its rows outside of the trash.")
+			s.P("CREATE OR REPLACE VIEW ", sqlIdent(opts.Table+"_live"), " AS SELECT * FROM ", table, " WHERE deleted_at IS NULL;")
+		}
+		if opts.History {
+			p.generateHistoryTable(s, message, opts)
+		}
+	}
+
+	usesRoutines, versioned, softDelete, keep, history := false, false, false, false, false
+	for _, message := range resources {
+		opts := resourceOptions(message)
+		usesRoutines = usesRoutines || p.usesRoutines(opts)
+		versioned = versioned || p.usesRoutines(opts) && opts.Versioned
+		softDelete = softDelete || p.usesRoutines(opts) && opts.SoftDelete
+		keep = keep || p.usesRoutines(opts) && hasOperation(opts, dep.Operation_OPERATION_UPDATE) && len(auditFields(message).created()) > 0
+		history = history || p.usesRoutines(opts) && opts.History
+	}
+	if !usesRoutines {
+		return
+	}
+
+	s.P("")
+	s.P("-- Routines called by the generated Go, shared by every resource. The table")
+	s.P("-- is passed by name, rows are only ever touched within the given tenant.")
+	s.P("-- p_id is of the type of the ids of the table, which create_data is passed")
+	s.P("-- a NULL of. The records are read by statements on the tables.")
+	s.P("")
+	s.P("-- create_data returns the id of the row, handed out by the table when p_id")
+	s.P("-- is NULL.")
+	s.P("CREATE OR REPLACE FUNCTION create_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_data JSONB)")
+	s.P("RETURNS ANYELEMENT")
+	s.P("LANGUAGE plpgsql AS $$")
+	s.P("DECLARE")
+	s.P("    v_id p_id%TYPE;")
+	s.P("BEGIN")
+	s.P("    IF p_id IS NULL THEN")
+	s.P("        EXECUTE format('INSERT INTO %I (tenant, data) VALUES ($1, $2) RETURNING id', p_table)")
+	s.P("            INTO v_id")
+	s.P("            USING p_tenant, p_data;")
+	s.P("    ELSE")
+	s.P("        EXECUTE format('INSERT INTO %I (tenant, data, id) VALUES ($1, $2, $3) RETURNING id', p_table)")
+	s.P("            INTO v_id")
+	s.P("            USING p_tenant, p_data, p_id;")
+	s.P("    END IF;")
+	s.P("    RETURN v_id;")
+	s.P("END")
+	s.P("$$;")
+	s.P("")
+	s.P("-- update_data and delete_data_by_id were procedures in earlier schemas, which")
+	s.P("-- CREATE OR REPLACE FUNCTION cannot replace.")
+	s.P("DROP PROCEDURE IF EXISTS update_data(TEXT, TEXT, ANYELEMENT, JSONB);")
+	s.P("DROP PROCEDURE IF EXISTS delete_data_by_id(TEXT, TEXT, ANYELEMENT);")
+	s.P("")
+	s.P("-- update_data returns the id of the row, NULL when there is no such row.")
+	s.P("CREATE OR REPLACE FUNCTION update_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_data JSONB)")
+	s.P("RETURNS ANYELEMENT")
+	s.P("LANGUAGE plpgsql AS $$")
+	s.P("DECLARE")
+	s.P("    v_id p_id%TYPE;")
+	s.P("BEGIN")
+	s.P("    EXECUTE format('UPDATE %I SET data = $3 WHERE tenant = $1 AND id = $2 RETURNING id', p_table)")
+	s.P("        INTO v_id")
+	s.P("        USING p_tenant, p_id, p_data;")
+	s.P("    RETURN v_id;")
+	s.P("END")
+	s.P("$$;")
+	s.P("")
+	s.P("-- jsonb_merge_patch applies an RFC 7396 merge patch, null members remove")
+	s.P("-- the keys they name and objects are merged recursively.")
+	s.P("CREATE OR REPLACE FUNCTION jsonb_merge_patch(p_target JSONB, p_patch JSONB)")
+	s.P("RETURNS JSONB")
+	s.P("LANGUAGE plpgsql IMMUTABLE AS $$")
+	s.P("DECLARE")
+	s.P("    v_key TEXT;")
+	s.P("    v_value JSONB;")
+	s.P("BEGIN")
+	s.P("    IF jsonb_typeof(p_patch) <> 'object' THEN")
+	s.P("        RETURN p_patch;")
+	s.P("    END IF;")
+	s.P("    IF p_target IS NULL OR jsonb_typeof(p_target) <> 'object' THEN")
+	s.P("        p_target := '{}';")
+	s.P("    END IF;")
+	s.P("    FOR v_key, v_value IN SELECT * FROM jsonb_each(p_patch) LOOP")
+	s.P("        IF jsonb_typeof(v_value) = 'null' THEN")
+	s.P("            p_target := p_target - v_key;")
+	s.P("        ELSE")
+	s.P("            p_target := jsonb_set(p_target, ARRAY[v_key], jsonb_merge_patch(p_target -> v_key, v_value));")
+	s.P("        END IF;")
+	s.P("    END LOOP;")
+	s.P("    RETURN p_target;")
+	s.P("END")
+	s.P("$$;")
+	s.P("")
+	s.P("-- patch_data returns the patched document, NULL when there is no such row.")
+	s.P("CREATE OR REPLACE FUNCTION patch_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_remove JSONB, p_store JSONB)")
+	s.P("RETURNS JSONB")
+	s.P("LANGUAGE plpgsql AS $$")
+	s.P("DECLARE")
+	s.P("    v_data JSONB;")
+	s.P("BEGIN")
+	s.P("    EXECUTE format('UPDATE %I SET data = jsonb_merge_patch(jsonb_merge_patch(data, $3), $4) WHERE tenant = $1 AND id = $2 RETURNING data', p_table)")
+	s.P("        INTO v_data")
+	s.P("        USING p_tenant, p_id, p_remove, p_store;")
+	s.P("    RETURN v_data;")
+	s.P("END")
+	s.P("$$;")
+	s.P("")
+	s.P("-- delete_data_by_id returns the id of the row, NULL when there is no such row.")
+	s.P("CREATE OR REPLACE FUNCTION delete_data_by_id(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT)")
+	s.P("RETURNS ANYELEMENT")
+	s.P("LANGUAGE plpgsql AS $$")
+	s.P("DECLARE")
+	s.P("    v_id p_id%TYPE;")
+	s.P("BEGIN")
+	s.P("    EXECUTE format('DELETE FROM %I WHERE tenant = $1 AND id = $2 RETURNING id', p_table)")
+	s.P("        INTO v_id")
+	s.P("        USING p_tenant, p_id;")
+	s.P("    RETURN v_id;")
+	s.P("END")
+	s.P("$$;")
+	if versioned {
+		generateVersionedRoutines(s)
+	}
+	if softDelete {
+		generateTrashRoutines(s, versioned)
+	}
+	if keep {
+		generateKeepRoutine(s)
+	}
+	if history {
+		generateHistoryRoutines(s)
+	}
+}
+
+// generateTrashRoutines emits the routines of soft deleted resources, which
+// work on the table itself. The other writes are passed the <table>_live
+// view. They return the id of the row they touched, NULL when there is none.
+func generateTrashRoutines(s *protogen.GeneratedFile, versioned bool) {
+	s.P("")
+	s.P("-- Routines of soft deleted resources, rows in the trash have a deleted_at.")
+	s.P("")
+	s.P("CREATE OR REPLACE FUNCTION trash_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT)")
+	s.P("RETURNS ANYELEMENT")
+	s.P("LANGUAGE plpgsql AS $$")
+	s.P("DECLARE")
+	s.P("    v_id p_id%TYPE;")
+	s.P("BEGIN")
+	s.P("    EXECUTE format('UPDATE %I SET deleted_at = CURRENT_TIMESTAMP WHERE tenant = $1 AND id = $2 AND deleted_at IS NULL RETURNING id', p_table)")
+	s.P("        INTO v_id")
+	s.P("        USING p_tenant, p_id;")
+	s.P("    RETURN v_id;")
+	s.P("END")
+	s.P("$$;")
+	if versioned {
+		s.P("")
+		s.P("-- trash_versioned_data returns the version of the row, which it keeps.")
+		s.P("CREATE OR REPLACE FUNCTION trash_versioned_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_version BIGINT)")
+		s.P("RETURNS BIGINT")
+		s.P("LANGUAGE plpgsql AS $$")
+		s.P("DECLARE")
+		s.P("    v_version BIGINT;")
+		s.P("BEGIN")
+		s.P("    EXECUTE format('UPDATE %I SET deleted_at = CURRENT_TIMESTAMP WHERE tenant = $1 AND id = $2 AND deleted_at IS NULL AND version = COALESCE(NULLIF($3, 0), version) RETURNING version', p_table)")
+		s.P("        INTO v_version")
+		s.P("        USING p_tenant, p_id, p_version;")
+		s.P("    RETURN v_version;")
+		s.P("END")
+		s.P("$$;")
+	}
+	s.P("")
+	s.P("CREATE OR REPLACE FUNCTION restore_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT)")
+	s.P("RETURNS ANYELEMENT")
+	s.P("LANGUAGE plpgsql AS $$")
+	s.P("DECLARE")
+	s.P("    v_id p_id%TYPE;")
+	s.P("BEGIN")
+	s.P("    EXECUTE format('UPDATE %I SET deleted_at = NULL WHERE tenant = $1 AND id = $2 AND deleted_at IS NOT NULL RETURNING id', p_table)")
+	s.P("        INTO v_id")
+	s.P("        USING p_tenant, p_id;")
+	s.P("    RETURN v_id;")
+	s.P("END")
+	s.P("$$;")
+	s.P("")
+	s.P("CREATE OR REPLACE FUNCTION purge_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT)")
+	s.P("RETURNS ANYELEMENT")
+	s.P("LANGUAGE plpgsql AS $$")
+	s.P("DECLARE")
+	s.P("    v_id p_id%TYPE;")
+	s.P("BEGIN")
+	s.P("    EXECUTE format('DELETE FROM %I WHERE tenant = $1 AND id = $2 AND deleted_at IS NOT NULL RETURNING id', p_table)")
+	s.P("        INTO v_id")
+	s.P("        USING p_tenant, p_id;")
+	s.P("    RETURN v_id;")
+	s.P("END")
+	s.P("$$;")
+}
+
+// generateVersionedRoutines emits the routines of versioned resources, whose
+// tables have a version column. Writes only touch the row while it is at
+// p_version, any version when it is 0, and bump it. They return NULL when
+// they touched nothing, the Go tells a missing row from a moved one.
+func generateVersionedRoutines(s *protogen.GeneratedFile) {
+	// versionCheck is the condition on the version, always the third parameter.
+	const versionCheck = "version = COALESCE(NULLIF($3, 0), version)"
+
+	s.P("")
+	s.P("-- Routines of versioned resources, their tables have a version column bumped")
+	s.P("-- by every write. A p_version of 0 matches any version.")
+	s.P("")
+	s.P("-- update_versioned_data returns the version stored.")
+	s.P("CREATE OR REPLACE FUNCTION update_versioned_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_version BIGINT, p_data JSONB)")
+	s.P("RETURNS BIGINT")
+	s.P("LANGUAGE plpgsql AS $$")
+	s.P("DECLARE")
+	s.P("    v_version BIGINT;")
+	s.P("BEGIN")
+	s.P("    EXECUTE format('UPDATE %I SET data = $4, version = version + 1 WHERE tenant = $1 AND id = $2 AND ", versionCheck, " RETURNING version', p_table)")
+	s.P("        INTO v_version")
+	s.P("        USING p_tenant, p_id, p_version, p_data;")
+	s.P("    RETURN v_version;")
+	s.P("END")
+	s.P("$$;")
+	s.P("")
+	s.P("CREATE OR REPLACE FUNCTION patch_versioned_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_version BIGINT, p_remove JSONB, p_store JSONB, OUT version BIGINT, OUT data JSONB)")
+	s.P("LANGUAGE plpgsql AS $$")
+	s.P("BEGIN")
+	s.P("    EXECUTE format('UPDATE %I SET data = jsonb_merge_patch(jsonb_merge_patch(data, $4), $5), version = version + 1 WHERE tenant = $1 AND id = $2 AND ", versionCheck, " RETURNING version, data', p_table)")
+	s.P("        INTO version, data")
+	s.P("        USING p_tenant, p_id, p_version, p_remove, p_store;")
+	s.P("END")
+	s.P("$$;")
+	s.P("")
+	s.P("CREATE OR REPLACE FUNCTION delete_versioned_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_version BIGINT)")
+	s.P("RETURNS BIGINT")
+	s.P("LANGUAGE plpgsql AS $$")
+	s.P("DECLARE")
+	s.P("    v_version BIGINT;")
+	s.P("BEGIN")
+	s.P("    EXECUTE format('DELETE FROM %I WHERE tenant = $1 AND id = $2 AND ", versionCheck, " RETURNING version', p_table)")
+	s.P("        INTO v_version")
+	s.P("        USING p_tenant, p_id, p_version;")
+	s.P("    RETURN v_version;")
+	s.P("END")
+	s.P("$$;")
+}
+
+// generateReadQueries emits the statements reading the records of a resource
+// going through the shared routines. Its writes call the routines, reads query
+// the table themselves, so the filter, order and page of a list and the
+// lookups of a single record can use its indexes.
+func (p *Generator) generateReadQueries(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	table := sqlIdent(opts.Table)
+	prefix := lowerFirst(message.GoIdent.GoName)
+	live, trash := "", ""
+	if opts.SoftDelete {
+		live, trash = " AND deleted_at IS NULL", " AND deleted_at IS NOT NULL"
+	}
+	selected := "data"
+	if opts.Versioned {
+		selected = "version, data"
+	}
+
+	g.P("// Statements reading ", message.GoIdent.GoName, ", its writes call the routines of the schema")
+	g.P("const (")
+	g.P("   ", prefix, "CountQuery = ", strconv.Quote("SELECT count(*) FROM "+table+" WHERE tenant = $1"+live))
+	g.P("   ", prefix, "ListQuery = ", strconv.Quote("SELECT id, data FROM "+table+" WHERE tenant = $1"+live))
+	if opts.SoftDelete {
+		g.P("   ", prefix, "DeletedCountQuery = ", strconv.Quote("SELECT count(*) FROM "+table+" WHERE tenant = $1"+trash))
+		g.P("   ", prefix, "DeletedListQuery = ", strconv.Quote("SELECT id, data FROM "+table+" WHERE tenant = $1"+trash))
+	}
+	g.P("   ", prefix, "GetQuery = ", strconv.Quote("SELECT "+selected+" FROM "+table+" WHERE tenant = $1 AND id = $2"+live))
+	if opts.Versioned {
+		g.P("   ", prefix, "ExistsQuery = ", strconv.Quote("SELECT count(*) FROM "+table+" WHERE tenant = $1 AND id = $2"+live))
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_GET) {
+		p.generateFinderQueries(g, message, opts, selected, live)
+	}
+	g.P(")")
+	g.P("")
+}
+
+// usesRoutines reports whether the methods of a resource call the shared
+// Postgres routines rather than statements of their own.
+func (p *Generator) usesRoutines(opts *dep.DepMessageOptions) bool {
+	return p.dialect == dialectPostgres && opts.Storage == dep.Storage_STORAGE_DOCUMENT
+}
+
+// placeholder is the n-th (from 1) query parameter in the dialect.
+func (p *Generator) placeholder(n int) string {
+	if p.dialect == dialectSQLite {
+		return "?"
+	}
+	return "$" + strconv.Itoa(n)
+}
+
+// versionCheck is the condition matching the row at the version passed as
+// the n-th parameter, or at any version when that is 0. The cast keeps
+// Postgres from inferring an integer from the 0.
+func (p *Generator) versionCheck(n int) string {
+	if p.dialect == dialectSQLite {
+		return "version = COALESCE(NULLIF(?, 0), version)"
+	}
+	return "version = COALESCE(NULLIF(" + p.placeholder(n) + "::bigint, 0), version)"
+}
+
+// generateQueries emits the statements used by the persistence methods of
+// a resource that does not go through the shared routines. The Update
+// statement takes the stored values first with SQLite, which numbers its
+// parameters in order of appearance, and last with Postgres. The version
+// checked by versioned writes is always their last parameter.
+func (p *Generator) generateQueries(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) {
+	table := sqlIdent(opts.Table)
+	prefix := lowerFirst(message.GoIdent.GoName)
+
+	if p.usesRoutines(opts) {
+		p.generateReadQueries(g, message, opts)
+		return
+	}
+
+	names := []string{"data"}
+	if opts.Storage == dep.Storage_STORAGE_COLUMNS {
+		names = nil
+		for _, c := range storageColumns(message, p.dialect) {
+			names = append(names, c.name)
+		}
+	}
+	columns := strings.Join(names, ", ")
+
+	// Update keeps the audit fields recording the creation as stored.
+	a := auditFields(message)
+	created := make(map[string]bool)
+	for _, field := range a.created() {
+		created[sqlIdent(fieldOptions(field).Column)] = true
+	}
+
+	values := make([]string, len(names))
+	assignments := make([]string, len(names))
+	for i, name := range names {
+		values[i] = p.placeholder(i + 2)
+		value := p.placeholder(i + 3)
+		if p.dialect == dialectSQLite {
+			value = p.placeholder(i + 1)
+		}
+		switch {
+		case opts.Storage == dep.Storage_STORAGE_COLUMNS && created[name]:
+			value = "COALESCE(" + name + ", " + value + ")"
+		case opts.Storage == dep.Storage_STORAGE_DOCUMENT && len(a.created()) > 0:
+			value = keepCreated(value, a)
+		}
+		assignments[i] = name + " = " + value
+	}
+	tenantID := "tenant = " + p.placeholder(1) + " AND id = " + p.placeholder(2)
+
+	// Soft deleted rows stay in the table, only the trash statements see them.
+	live, trash := "", ""
+	if opts.SoftDelete {
+		live, trash = " AND deleted_at IS NULL", " AND deleted_at IS NOT NULL"
+	}
+
+	// Versioned writes bump the version and only match the row at the
+	// version given last, any version when it is 0. Unversioned ones return
+	// the id, so a missing row shows as no rows either way.
+	selected, bump, check, returning := columns, "", "", " RETURNING id"
+	if opts.Versioned {
+		selected, bump, returning = "version, "+columns, ", version = version + 1", " RETURNING version"
+		check = " AND " + p.versionCheck(len(names)+3)
+	}
+
+	g.P("// Statements backing ", message.GoIdent.GoName, ", values follow the order of the fields")
+	g.P("const (")
+	g.P("   ", prefix, "CountQuery = ", strconv.Quote("SELECT count(*) FROM "+table+" WHERE tenant = "+p.placeholder(1)+live))
+	g.P("   ", prefix, "ListQuery = ", strconv.Quote("SELECT id, "+columns+" FROM "+table+" WHERE tenant = "+p.placeholder(1)+live))
+	if opts.SoftDelete {
+		g.P("   ", prefix, "DeletedCountQuery = ", strconv.Quote("SELECT count(*) FROM "+table+" WHERE tenant = "+p.placeholder(1)+trash))
+		g.P("   ", prefix, "DeletedListQuery = ", strconv.Quote("SELECT id, "+columns+" FROM "+table+" WHERE tenant = "+p.placeholder(1)+trash))
+	}
+	g.P("   ", prefix, "GetQuery = ", strconv.Quote("SELECT "+selected+" FROM "+table+" WHERE "+tenantID+live))
+	// Minted ids are inserted after the values, serial ones are returned.
+	if mintsID(opts) {
+		g.P("   ", prefix, "InsertQuery = ", strconv.Quote("INSERT INTO "+table+" (tenant, "+columns+", id) VALUES ("+p.placeholder(1)+", "+strings.Join(values, ", ")+", "+p.placeholder(len(names)+2)+")"))
+	} else {
+		g.P("   ", prefix, "InsertQuery = ", strconv.Quote("INSERT INTO "+table+" (tenant, "+columns+") VALUES ("+p.placeholder(1)+", "+strings.Join(values, ", ")+") RETURNING id"))
+	}
+	g.P("   ", prefix, "UpdateQuery = ", strconv.Quote("UPDATE "+table+" SET "+strings.Join(assignments, ", ")+bump+" WHERE "+tenantID+live+check+returning))
+	if opts.Storage == dep.Storage_STORAGE_COLUMNS {
+		// Patch fills in the SET list of the columns it stores.
+		where := "tenant = " + p.placeholder(1) + " AND id = " + p.placeholder(2) + live
+		if opts.Versioned {
+			where += " AND " + p.versionCheck(3)
+		}
+		g.P("   ", prefix, "PatchQuery = ", strconv.Quote("UPDATE "+table+" SET %s"+bump+" WHERE "+where+" RETURNING "+selected))
+	} else {
+		where := "tenant = " + p.placeholder(3) + " AND id = " + p.placeholder(4) + live
+		if opts.Versioned {
+			where += " AND " + p.versionCheck(5)
+		}
+		g.P("   ", prefix, "PatchQuery = ", strconv.Quote("UPDATE "+table+" SET data = json_patch(json_patch(data, "+p.placeholder(1)+"), "+p.placeholder(2)+")"+bump+" WHERE "+where+" RETURNING "+selected))
+	}
+	deleteQuery := "DELETE FROM " + table + " WHERE " + tenantID
+	if opts.SoftDelete {
+		deleteQuery = "UPDATE " + table + " SET deleted_at = CURRENT_TIMESTAMP WHERE " + tenantID + live
+	}
+	if opts.Versioned {
+		g.P("   ", prefix, "DeleteQuery = ", strconv.Quote(deleteQuery+" AND "+p.versionCheck(3)+returning))
+		g.P("   ", prefix, "ExistsQuery = ", strconv.Quote("SELECT count(*) FROM "+table+" WHERE "+tenantID+live))
+	} else {
+		g.P("   ", prefix, "DeleteQuery = ", strconv.Quote(deleteQuery+returning))
+	}
+	if opts.SoftDelete {
+		g.P("   ", prefix, "RestoreQuery = ", strconv.Quote("UPDATE "+table+" SET deleted_at = NULL WHERE "+tenantID+trash+" RETURNING id"))
+		g.P("   ", prefix, "PurgeQuery = ", strconv.Quote("DELETE FROM "+table+" WHERE "+tenantID+trash+" RETURNING id"))
+	}
+	if hasOperation(opts, dep.Operation_OPERATION_GET) {
+		p.generateFinderQueries(g, message, opts, selected, live)
+	}
+	if opts.History {
+		p.generateHistoryQueries(g, message, opts)
+	}
+	g.P(")")
+	g.P("")
+	if opts.Storage == dep.Storage_STORAGE_COLUMNS {
+		g.P("// ", prefix, "Columns names the columns of ", message.GoIdent.GoName, " in the order of the fields")
+		g.P("var ", prefix, "Columns = []string{", quoteAll(names), "}")
+		g.P("")
+	}
+}
+
+// plainIdent matches identifiers Postgres takes without quoting.
+var plainIdent = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// reservedWords are the keywords likely to be picked as table or column
+// names, they have to be quoted.
+var reservedWords = map[string]bool{
+	"all": true, "check": true, "column": true, "default": true, "from": true,
+	"group": true, "limit": true, "offset": true, "order": true, "select": true,
+	"table": true, "to": true, "user": true, "where": true,
+}
+
+// sqlIdent quotes name for use as an identifier when it has to be, matching
+// what format('%I') does in the routines.
+func sqlIdent(name string) string {
+	if plainIdent.MatchString(name) && !reservedWords[name] {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// routineTable is the relation passed to the routines reading and writing
+// the records of a resource, the view of the rows outside of the trash for
+// soft deleted resources.
+func routineTable(opts *dep.DepMessageOptions) string {
+	if opts.SoftDelete {
+		return `x.TableName() + "_live"`
+	}
+	return "x.TableName()"
+}
@@ -0,0 +1,440 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v4.23.4
+// source: constraints.proto
+
+package constraints
+
+import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	_ "protoc-gen-go-dep/dep"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Plan int32
+
+const (
+	Plan_PLAN_UNSPECIFIED Plan = 0
+	Plan_PLAN_FREE        Plan = 1
+	Plan_PLAN_PRO         Plan = 2
+)
+
+// Enum value maps for Plan.
+var (
+	Plan_name = map[int32]string{
+		0: "PLAN_UNSPECIFIED",
+		1: "PLAN_FREE",
+		2: "PLAN_PRO",
+	}
+	Plan_value = map[string]int32{
+		"PLAN_UNSPECIFIED": 0,
+		"PLAN_FREE":        1,
+		"PLAN_PRO":         2,
+	}
+)
+
+func (x Plan) Enum() *Plan {
+	p := new(Plan)
+	*p = x
+	return p
+}
+
+func (x Plan) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Plan) Descriptor() protoreflect.EnumDescriptor {
+	return file_constraints_proto_enumTypes[0].Descriptor()
+}
+
+func (Plan) Type() protoreflect.EnumType {
+	return &file_constraints_proto_enumTypes[0]
+}
+
+func (x Plan) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Plan.Descriptor instead.
+func (Plan) EnumDescriptor() ([]byte, []int) {
+	return file_constraints_proto_rawDescGZIP(), []int{0}
+}
+
+// Signup sets constraints through dep options.
+type Signup struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Email     string            `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Handle    string            `protobuf:"bytes,2,opt,name=handle,proto3" json:"handle,omitempty"`
+	Website   string            `protobuf:"bytes,3,opt,name=website,proto3" json:"website,omitempty"`
+	Age       int32             `protobuf:"varint,4,opt,name=age,proto3" json:"age,omitempty"`
+	Score     float64           `protobuf:"fixed64,5,opt,name=score,proto3" json:"score,omitempty"`
+	Plan      Plan              `protobuf:"varint,6,opt,name=plan,proto3,enum=constraints.Plan" json:"plan,omitempty"`
+	Interests []string          `protobuf:"bytes,7,rep,name=interests,proto3" json:"interests,omitempty"`
+	Avatar    []byte            `protobuf:"bytes,8,opt,name=avatar,proto3" json:"avatar,omitempty"`
+	Labels    map[string]string `protobuf:"bytes,9,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *Signup) Reset() {
+	*x = Signup{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_constraints_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Signup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Signup) ProtoMessage() {}
+
+func (x *Signup) ProtoReflect() protoreflect.Message {
+	mi := &file_constraints_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Signup.ProtoReflect.Descriptor instead.
+func (*Signup) Descriptor() ([]byte, []int) {
+	return file_constraints_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Signup) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Signup) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *Signup) GetWebsite() string {
+	if x != nil {
+		return x.Website
+	}
+	return ""
+}
+
+func (x *Signup) GetAge() int32 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+func (x *Signup) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Signup) GetPlan() Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return Plan_PLAN_UNSPECIFIED
+}
+
+func (x *Signup) GetInterests() []string {
+	if x != nil {
+		return x.Interests
+	}
+	return nil
+}
+
+func (x *Signup) GetAvatar() []byte {
+	if x != nil {
+		return x.Avatar
+	}
+	return nil
+}
+
+func (x *Signup) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+// Profile sets the same kind of constraints through buf.validate.
+type Profile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Email     string   `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Handle    string   `protobuf:"bytes,2,opt,name=handle,proto3" json:"handle,omitempty"`
+	Website   string   `protobuf:"bytes,3,opt,name=website,proto3" json:"website,omitempty"`
+	Age       int32    `protobuf:"varint,4,opt,name=age,proto3" json:"age,omitempty"`
+	Score     float64  `protobuf:"fixed64,5,opt,name=score,proto3" json:"score,omitempty"`
+	Plan      Plan     `protobuf:"varint,6,opt,name=plan,proto3,enum=constraints.Plan" json:"plan,omitempty"`
+	Interests []string `protobuf:"bytes,7,rep,name=interests,proto3" json:"interests,omitempty"`
+	// dep options win over buf.validate.
+	Bio string `protobuf:"bytes,8,opt,name=bio,proto3" json:"bio,omitempty"`
+}
+
+func (x *Profile) Reset() {
+	*x = Profile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_constraints_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Profile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Profile) ProtoMessage() {}
+
+func (x *Profile) ProtoReflect() protoreflect.Message {
+	mi := &file_constraints_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Profile.ProtoReflect.Descriptor instead.
+func (*Profile) Descriptor() ([]byte, []int) {
+	return file_constraints_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Profile) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Profile) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *Profile) GetWebsite() string {
+	if x != nil {
+		return x.Website
+	}
+	return ""
+}
+
+func (x *Profile) GetAge() int32 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+func (x *Profile) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Profile) GetPlan() Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return Plan_PLAN_UNSPECIFIED
+}
+
+func (x *Profile) GetInterests() []string {
+	if x != nil {
+		return x.Interests
+	}
+	return nil
+}
+
+func (x *Profile) GetBio() string {
+	if x != nil {
+		return x.Bio
+	}
+	return ""
+}
+
+var File_constraints_proto protoreflect.FileDescriptor
+
+var file_constraints_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x63, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x74, 0x73, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x63, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x74, 0x73,
+	0x1a, 0x09, 0x64, 0x65, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1b, 0x62, 0x75, 0x66,
+	0x2f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x2f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61,
+	0x74, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xce, 0x03, 0x0a, 0x06, 0x53, 0x69, 0x67,
+	0x6e, 0x75, 0x70, 0x12, 0x21, 0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x42, 0x0b, 0xa2, 0xf9, 0x2b, 0x07, 0x08, 0x01, 0x58, 0xfe, 0x01, 0x68, 0x01, 0x52,
+	0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x2e, 0x0a, 0x06, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x16, 0xa2, 0xf9, 0x2b, 0x12, 0x50, 0x03, 0x58, 0x14,
+	0x62, 0x0c, 0x5e, 0x5b, 0x61, 0x2d, 0x7a, 0x30, 0x2d, 0x39, 0x5f, 0x5d, 0x2b, 0x24, 0x52, 0x06,
+	0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x07, 0x77, 0x65, 0x62, 0x73, 0x69, 0x74,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x42, 0x06, 0xa2, 0xf9, 0x2b, 0x02, 0x70, 0x01, 0x52,
+	0x07, 0x77, 0x65, 0x62, 0x73, 0x69, 0x74, 0x65, 0x12, 0x29, 0x0a, 0x03, 0x61, 0x67, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x42, 0x17, 0xa2, 0xf9, 0x2b, 0x13, 0x79, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x2a, 0x40, 0x81, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x60, 0x40, 0x52, 0x03,
+	0x61, 0x67, 0x65, 0x12, 0x23, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x01, 0x42, 0x0d, 0xa2, 0xf9, 0x2b, 0x09, 0x79, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xe0,
+	0x3f, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x2e, 0x0a, 0x04, 0x70, 0x6c, 0x61, 0x6e,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x63, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x61,
+	0x69, 0x6e, 0x74, 0x73, 0x2e, 0x50, 0x6c, 0x61, 0x6e, 0x42, 0x07, 0xa2, 0xf9, 0x2b, 0x03, 0x88,
+	0x01, 0x01, 0x52, 0x04, 0x70, 0x6c, 0x61, 0x6e, 0x12, 0x2a, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x65, 0x73, 0x74, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x42, 0x0c, 0xa2, 0xf9, 0x2b,
+	0x08, 0x58, 0x20, 0x90, 0x01, 0x01, 0x98, 0x01, 0x05, 0x52, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x65, 0x73, 0x74, 0x73, 0x12, 0x20, 0x0a, 0x06, 0x61, 0x76, 0x61, 0x74, 0x61, 0x72, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x0c, 0x42, 0x08, 0xa2, 0xf9, 0x2b, 0x04, 0x58, 0x80, 0x80, 0x04, 0x52, 0x06,
+	0x61, 0x76, 0x61, 0x74, 0x61, 0x72, 0x12, 0x40, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73,
+	0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x63, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x61,
+	0x69, 0x6e, 0x74, 0x73, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x75, 0x70, 0x2e, 0x4c, 0x61, 0x62, 0x65,
+	0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x42, 0x07, 0xa2, 0xf9, 0x2b, 0x03, 0x98, 0x01, 0x0a,
+	0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61, 0x62, 0x65,
+	0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x3a, 0x04, 0x9a, 0xf9, 0x2b, 0x00, 0x22, 0xc7, 0x02, 0x0a, 0x07, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x42, 0x0a, 0xba, 0x48, 0x07, 0xc8, 0x01, 0x01, 0x72, 0x02, 0x60, 0x01,
+	0x52, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x2f, 0x0a, 0x06, 0x68, 0x61, 0x6e, 0x64, 0x6c,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x17, 0xba, 0x48, 0x14, 0x72, 0x12, 0x10, 0x03,
+	0x18, 0x14, 0x32, 0x0c, 0x5e, 0x5b, 0x61, 0x2d, 0x7a, 0x30, 0x2d, 0x39, 0x5f, 0x5d, 0x2b, 0x24,
+	0x52, 0x06, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x22, 0x0a, 0x07, 0x77, 0x65, 0x62, 0x73,
+	0x69, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xba, 0x48, 0x05, 0x72, 0x03,
+	0x88, 0x01, 0x01, 0x52, 0x07, 0x77, 0x65, 0x62, 0x73, 0x69, 0x74, 0x65, 0x12, 0x1c, 0x0a, 0x03,
+	0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x42, 0x0a, 0xba, 0x48, 0x07, 0x1a, 0x05,
+	0x18, 0x82, 0x01, 0x20, 0x0c, 0x52, 0x03, 0x61, 0x67, 0x65, 0x12, 0x24, 0x0a, 0x05, 0x73, 0x63,
+	0x6f, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x42, 0x0e, 0xba, 0x48, 0x0b, 0x12, 0x09,
+	0x29, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xe0, 0x3f, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65,
+	0x12, 0x2f, 0x0a, 0x04, 0x70, 0x6c, 0x61, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11,
+	0x2e, 0x63, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x74, 0x73, 0x2e, 0x50, 0x6c, 0x61,
+	0x6e, 0x42, 0x08, 0xba, 0x48, 0x05, 0x82, 0x01, 0x02, 0x10, 0x01, 0x52, 0x04, 0x70, 0x6c, 0x61,
+	0x6e, 0x12, 0x28, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x73, 0x18, 0x07,
+	0x20, 0x03, 0x28, 0x09, 0x42, 0x0a, 0xba, 0x48, 0x07, 0x92, 0x01, 0x04, 0x08, 0x01, 0x10, 0x05,
+	0x52, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x73, 0x12, 0x20, 0x0a, 0x03, 0x62,
+	0x69, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x42, 0x0e, 0xba, 0x48, 0x05, 0x72, 0x03, 0x18,
+	0xf4, 0x03, 0xa2, 0xf9, 0x2b, 0x02, 0x58, 0x64, 0x52, 0x03, 0x62, 0x69, 0x6f, 0x3a, 0x04, 0x9a,
+	0xf9, 0x2b, 0x00, 0x2a, 0x39, 0x0a, 0x04, 0x50, 0x6c, 0x61, 0x6e, 0x12, 0x14, 0x0a, 0x10, 0x50,
+	0x4c, 0x41, 0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x0d, 0x0a, 0x09, 0x50, 0x4c, 0x41, 0x4e, 0x5f, 0x46, 0x52, 0x45, 0x45, 0x10, 0x01,
+	0x12, 0x0c, 0x0a, 0x08, 0x50, 0x4c, 0x41, 0x4e, 0x5f, 0x50, 0x52, 0x4f, 0x10, 0x02, 0x42, 0x3e,
+	0x5a, 0x3c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d, 0x67, 0x65, 0x6e, 0x2d, 0x67, 0x6f, 0x2d,
+	0x64, 0x65, 0x70, 0x2f, 0x63, 0x6d, 0x64, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d, 0x67,
+	0x65, 0x6e, 0x2d, 0x67, 0x6f, 0x2d, 0x64, 0x65, 0x70, 0x2f, 0x74, 0x65, 0x73, 0x74, 0x64, 0x61,
+	0x74, 0x61, 0x2f, 0x63, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x74, 0x73, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_constraints_proto_rawDescOnce sync.Once
+	file_constraints_proto_rawDescData = file_constraints_proto_rawDesc
+)
+
+func file_constraints_proto_rawDescGZIP() []byte {
+	file_constraints_proto_rawDescOnce.Do(func() {
+		file_constraints_proto_rawDescData = protoimpl.X.CompressGZIP(file_constraints_proto_rawDescData)
+	})
+	return file_constraints_proto_rawDescData
+}
+
+var file_constraints_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_constraints_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_constraints_proto_goTypes = []interface{}{
+	(Plan)(0),       // 0: constraints.Plan
+	(*Signup)(nil),  // 1: constraints.Signup
+	(*Profile)(nil), // 2: constraints.Profile
+	nil,             // 3: constraints.Signup.LabelsEntry
+}
+var file_constraints_proto_depIdxs = []int32{
+	0, // 0: constraints.Signup.plan:type_name -> constraints.Plan
+	3, // 1: constraints.Signup.labels:type_name -> constraints.Signup.LabelsEntry
+	0, // 2: constraints.Profile.plan:type_name -> constraints.Plan
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_constraints_proto_init() }
+func file_constraints_proto_init() {
+	if File_constraints_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_constraints_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Signup); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_constraints_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Profile); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_constraints_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_constraints_proto_goTypes,
+		DependencyIndexes: file_constraints_proto_depIdxs,
+		EnumInfos:         file_constraints_proto_enumTypes,
+		MessageInfos:      file_constraints_proto_msgTypes,
+	}.Build()
+	File_constraints_proto = out.File
+	file_constraints_proto_rawDesc = nil
+	file_constraints_proto_goTypes = nil
+	file_constraints_proto_depIdxs = nil
+}
@@ -0,0 +1,1883 @@
+// Code generated by protoc-gen-go-dep. DO NOT EDIT.
+// source: constraints.proto
+
+package constraints
+
+import (
+	context "context"
+	sql "database/sql"
+	driver "database/sql/driver"
+	base64 "encoding/base64"
+	json "encoding/json"
+	errors "errors"
+	fmt "fmt"
+	chi "github.com/go-chi/chi/v5"
+	protojson "google.golang.org/protobuf/encoding/protojson"
+	proto "google.golang.org/protobuf/proto"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	template "html/template"
+	io "io"
+	mime "mime"
+	http "net/http"
+	mail "net/mail"
+	url "net/url"
+	path "path"
+	dep "protoc-gen-go-dep/dep"
+	regexp "regexp"
+	strconv "strconv"
+	strings "strings"
+	sync "sync"
+	utf8 "unicode/utf8"
+)
+
+// DBTX is what the persistence methods need from database/sql, it is
+// satisfied by both *sql.DB and *sql.Tx
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// WithTx runs fn in a transaction begun on db, it is committed when fn
+// returns nil and rolled back otherwise
+func WithTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rolling back a committed transaction does nothing, this covers
+	// both an error and a panic in fn
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// begin starts a transaction on db for a write made of several statements, or
+// returns db when it is a transaction already. end commits the transaction it
+// began when err is nil and rolls it back otherwise, returning err. Within a
+// transaction of the caller rolling back is left to them
+func begin(ctx context.Context, db DBTX) (DBTX, func(err error) error, error) {
+	b, ok := db.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return db, func(err error) error { return err }, nil
+	}
+	tx, err := b.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, func(err error) error {
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	}, nil
+}
+
+// SignupHandler serves the http routes of Signup
+type SignupHandler struct {
+	Repo SignupRepository
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// NewSignupHandler returns a SignupHandler backed by repo
+func NewSignupHandler(repo SignupRepository) *SignupHandler {
+	return &SignupHandler{Repo: repo}
+}
+
+func (h *SignupHandler) tenant(req *http.Request) string {
+	if h.Tenant != nil {
+		return h.Tenant(req)
+	}
+	return chi.URLParam(req, "tenant")
+}
+
+// id reads the {id} url parameter, false when it holds no valid id
+func (h *SignupHandler) id(req *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(req, "id"), 10, 64)
+	return id, err == nil
+}
+
+// Statements reading Signup, its writes call the routines of the schema
+const (
+	signupCountQuery = "SELECT count(*) FROM signup WHERE tenant = $1"
+	signupListQuery  = "SELECT id, data FROM signup WHERE tenant = $1"
+	signupGetQuery   = "SELECT data FROM signup WHERE tenant = $1 AND id = $2"
+)
+
+// signupListSchema holds the fields List can filter and order by
+var signupListSchema = &dep.Schema{
+	Message: new(Signup),
+	Dialect: dep.Postgres,
+}
+
+// List function returns the page of these objects opts selects
+func (x *Signup) List(ctx context.Context, db DBTX, tenant string, opts dep.ListOptions) (*dep.Page[*Signup, int64], error) {
+	q, err := signupListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Signup, int64])
+	query, args := q.Count(signupCountQuery, tenant)
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(signupListQuery, tenant)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		row := new(Signup)
+		var id int64
+
+		err := rows.Scan(&id, row)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Items = append(ret.Items, dep.Record[*Signup, int64]{ID: id, Value: row})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// Get function acquires a single record based on ID in database
+func (x *Signup) Get(ctx context.Context, db DBTX, tenant string, id int64) error {
+	return db.QueryRowContext(ctx, signupGetQuery, tenant, id).Scan(x)
+}
+
+// Create function will create a new object of this type and return its ID
+func (x *Signup) Create(ctx context.Context, db DBTX, tenant string, data *Signup) (int64, error) {
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err := db.QueryRowContext(ctx, "SELECT create_data($1, $2, NULL::bigint, $3)", tenant, x.TableName(), data).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Update function will replace the object stored at the given ID
+func (x *Signup) Update(ctx context.Context, db DBTX, tenant string, id int64, data *Signup) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	var found int64
+	return db.QueryRowContext(ctx, "SELECT id FROM update_data($1, $2, $3::bigint, $4) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id, data).Scan(&found)
+}
+
+// Patch function stores the fields of data named by mask in the object at the
+// given ID, leaving the others as they are, and reads the result into x
+// The result is validated as a whole, the patch is rolled back when it is not valid.
+func (x *Signup) Patch(ctx context.Context, db DBTX, tenant string, id int64, data *Signup, mask *fieldmaskpb.FieldMask) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.patch(ctx, tx, tenant, id, data, mask)
+	if err == nil {
+		err = x.Validate()
+	}
+	return end(err)
+}
+
+// patch makes the writes of Patch in the transaction it began
+func (x *Signup) patch(ctx context.Context, db DBTX, tenant string, id int64, data *Signup, mask *fieldmaskpb.FieldMask) error {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return err
+	}
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return err
+	}
+
+	// The first patch removes the masked fields, the second stores the ones
+	// data has, so messages, lists and maps are replaced rather than merged.
+	remove, store, err := dep.MergePatches(data, paths)
+	if err != nil {
+		return err
+	}
+
+	return db.QueryRowContext(ctx, "SELECT data FROM patch_data($1, $2, $3::bigint, $4, $5) AS data WHERE data IS NOT NULL",
+		tenant, x.TableName(), id, remove, store).Scan(x)
+}
+
+// Delete function will... well delete the object at given ID
+func (x *Signup) Delete(ctx context.Context, db DBTX, tenant string, id int64) error {
+	var found int64
+	return db.QueryRowContext(ctx, "SELECT id FROM delete_data_by_id($1, $2, $3::bigint) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id).Scan(&found)
+}
+
+// SignupRepository stores Signup records. Get, Update, Patch and Delete return
+// dep.ErrNotFound for unknown ids, Patch returns the record as stored.
+type SignupRepository interface {
+	List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Signup, int64], error)
+	Get(ctx context.Context, tenant string, id int64) (*Signup, error)
+	Create(ctx context.Context, tenant string, data *Signup) (int64, error)
+	Update(ctx context.Context, tenant string, id int64, data *Signup) error
+	Patch(ctx context.Context, tenant string, id int64, data *Signup, mask *fieldmaskpb.FieldMask) (*Signup, error)
+	Delete(ctx context.Context, tenant string, id int64) error
+}
+
+// SignupSQLRepository is the SignupRepository backed by the Signup persistence methods
+type SignupSQLRepository struct {
+	DB DBTX
+}
+
+// NewSignupSQLRepository returns a SignupSQLRepository using db
+func NewSignupSQLRepository(db DBTX) *SignupSQLRepository {
+	return &SignupSQLRepository{DB: db}
+}
+
+var _ SignupRepository = (*SignupSQLRepository)(nil)
+
+func (r *SignupSQLRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Signup, int64], error) {
+	return new(Signup).List(ctx, r.DB, tenant, opts)
+}
+
+func (r *SignupSQLRepository) Get(ctx context.Context, tenant string, id int64) (*Signup, error) {
+	x := new(Signup)
+	err := x.Get(ctx, r.DB, tenant, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func (r *SignupSQLRepository) Create(ctx context.Context, tenant string, data *Signup) (int64, error) {
+	return data.Create(ctx, r.DB, tenant, data)
+}
+
+func (r *SignupSQLRepository) Update(ctx context.Context, tenant string, id int64, data *Signup) error {
+	err := data.Update(ctx, r.DB, tenant, id, data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+func (r *SignupSQLRepository) Patch(ctx context.Context, tenant string, id int64, data *Signup, mask *fieldmaskpb.FieldMask) (*Signup, error) {
+	x := new(Signup)
+	err := x.Patch(ctx, r.DB, tenant, id, data, mask)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func (r *SignupSQLRepository) Delete(ctx context.Context, tenant string, id int64) error {
+	err := new(Signup).Delete(ctx, r.DB, tenant, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+// SignupMemoryRepository is a SignupRepository keeping records in memory, safe for
+// concurrent use. Records are copied on the way in and out.
+type SignupMemoryRepository struct {
+	mu      sync.RWMutex
+	lastID  int64
+	tenants map[string]map[int64]*Signup
+}
+
+// NewSignupMemoryRepository returns an empty SignupMemoryRepository
+func NewSignupMemoryRepository() *SignupMemoryRepository {
+	return &SignupMemoryRepository{tenants: make(map[string]map[int64]*Signup)}
+}
+
+var _ SignupRepository = (*SignupMemoryRepository)(nil)
+
+// lookup returns the stored record, the lock has to be held
+func (r *SignupMemoryRepository) lookup(tenant string, id int64) (*Signup, error) {
+	x, ok := r.tenants[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+func (r *SignupMemoryRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Signup, int64], error) {
+	q, err := signupListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Signup, int64], 0, len(r.tenants[tenant]))
+	for id, x := range r.tenants[tenant] {
+		records = append(records, dep.Record[*Signup, int64]{ID: id, Value: proto.Clone(x).(*Signup)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+func (r *SignupMemoryRepository) Get(ctx context.Context, tenant string, id int64) (*Signup, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(x).(*Signup), nil
+}
+
+func (r *SignupMemoryRepository) Create(ctx context.Context, tenant string, data *Signup) (int64, error) {
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	id := r.lastID
+	if r.tenants[tenant] == nil {
+		r.tenants[tenant] = make(map[int64]*Signup)
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Signup)
+	return id, nil
+}
+
+func (r *SignupMemoryRepository) Update(ctx context.Context, tenant string, id int64, data *Signup) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.lookup(tenant, id)
+	if err != nil {
+		return err
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Signup)
+	return nil
+}
+
+func (r *SignupMemoryRepository) Patch(ctx context.Context, tenant string, id int64, data *Signup, mask *fieldmaskpb.FieldMask) (*Signup, error) {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return nil, err
+	}
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	x = proto.Clone(x).(*Signup)
+	dep.ApplyFieldMask(x, data, paths)
+	if err := x.Validate(); err != nil {
+		return nil, err
+	}
+	r.tenants[tenant][id] = x
+	return proto.Clone(x).(*Signup), nil
+}
+
+func (r *SignupMemoryRepository) Delete(ctx context.Context, tenant string, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.lookup(tenant, id); err != nil {
+		return err
+	}
+	delete(r.tenants[tenant], id)
+	return nil
+}
+
+// ListHandler renders the page of objects selected by the page_size, page_token,
+// skip, filter and order_by query parameters
+func (h *SignupHandler) ListHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.List(req.Context(), h.tenant(req), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// GetHandler renders the object at the {id} url parameter
+func (h *SignupHandler) GetHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// CreateHandler stores the object sent in the request body, the Location header
+// of the response names its url
+func (h *SignupHandler) CreateHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Signup)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.Repo.Create(req.Context(), h.tenant(req), x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", path.Join(req.URL.Path, fmt.Sprint(id)))
+	h.render(w, req, http.StatusCreated, x)
+}
+
+// UpdateHandler replaces the object at the {id} url parameter with the request body
+func (h *SignupHandler) UpdateHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Signup)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.Repo.Update(req.Context(), h.tenant(req), id, x)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// PatchHandler stores the fields of the request body named by its mask in the object
+// at the {id} url parameter and renders the result
+func (h *SignupHandler) PatchHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Signup)
+	mask, err := h.decodePatch(req, x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.Patch(req.Context(), h.tenant(req), id, x, mask)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	var invalid dep.ValidationErrors
+	if errors.Is(err, dep.ErrInvalidArgument) || errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, ret)
+}
+
+// DeleteHandler deletes the object at the {id} url parameter
+func (h *SignupHandler) DeleteHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	err := h.Repo.Delete(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FormHandler renders the form for a new object, or for editing the object at the {id} url parameter
+func (h *SignupHandler) FormHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Signup)
+	if chi.URLParam(req, "id") != "" {
+		id, ok := h.id(req)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		found, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+		if errors.Is(err, dep.ErrNotFound) {
+			http.NotFound(w, req)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		x = found
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := x.RenderForm(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// decode reads the object from a protojson body, skipping unknown fields, or from
+// a submitted form
+func (h *SignupHandler) decode(req *http.Request, x *Signup) error {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		if _, err := x.HandleForm(req); err != nil {
+			return err
+		}
+		// HandleForm only validates the fields the form submitted
+		return x.Validate()
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, x); err != nil {
+		return err
+	}
+
+	return x.Validate()
+}
+
+// decodePatch reads the fields to patch from a submitted form, masking the ones it
+// holds, or from a protojson body masked by the update_mask query parameter or by
+// the fields present in the body, which may not name unknown fields
+func (h *SignupHandler) decodePatch(req *http.Request, x *Signup) (*fieldmaskpb.FieldMask, error) {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		return x.HandleForm(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(body, x); err != nil {
+		return nil, err
+	}
+
+	return dep.ParseFieldMask(req.URL.Query(), body)
+}
+
+// render writes the object as protojson, or as html to htmx requests
+func (h *SignupHandler) render(w http.ResponseWriter, req *http.Request, status int, x *Signup) {
+	if req.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		x.RenderView(w)
+		return
+	}
+
+	jsonData, err := protojson.Marshal(x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
+
+// Routes returns a chi.Router with the Signup endpoints that can be mounted to a parent router
+func (h *SignupHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListHandler)
+	r.Post("/", h.CreateHandler)
+	r.Get("/new", h.FormHandler)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.GetHandler)
+		r.Put("/", h.UpdateHandler)
+		r.Patch("/", h.PatchHandler)
+		r.Get("/edit", h.FormHandler)
+		r.Delete("/", h.DeleteHandler)
+	})
+
+	return r
+}
+
+// A simple function to handle a htmx form and populate the struct, returning
+// the mask of the fields the form submitted. Values that fail to parse are
+// collected per field before the submitted fields are validated.
+func (x *Signup) HandleForm(req *http.Request) (*fieldmaskpb.FieldMask, error) {
+	if err := req.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return nil, err
+	}
+
+	errs := make(dep.ValidationErrors)
+	x.Email = req.FormValue("Signup__Email")
+	x.Handle = req.FormValue("Signup__Handle")
+	x.Website = req.FormValue("Signup__Website")
+	if v := req.FormValue("Signup__Age"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 32); err != nil {
+			errs.Add("age", "must be a whole number")
+		} else {
+			value := int32(n)
+			x.Age = value
+		}
+	}
+	if v := req.FormValue("Signup__Score"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err != nil {
+			errs.Add("score", "must be a number")
+		} else {
+			value := float64(n)
+			x.Score = value
+		}
+	}
+	if v := req.FormValue("Signup__Plan"); v != "" {
+		// Either the name or the number of a defined enum value
+		if n, ok := Plan_value[v]; ok {
+			value := Plan(n)
+			x.Plan = value
+		} else if n, err := strconv.ParseInt(v, 10, 32); err == nil && Plan_name[int32(n)] != "" {
+			value := Plan(n)
+			x.Plan = value
+		} else {
+			errs.Add("plan", "must be one of the defined values")
+		}
+	}
+	if _, ok := req.Form["Signup__Interests"]; ok {
+		x.Interests = nil
+		// One item per line
+		for _, v := range strings.Split(req.FormValue("Signup__Interests"), "\n") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			value := v
+			x.Interests = append(x.Interests, value)
+		}
+	}
+	if file, _, err := req.FormFile("Signup__Avatar"); err == nil {
+		value, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			errs.Add("avatar", "could not be read")
+		} else {
+			x.Avatar = value
+		}
+	} else if v := req.FormValue("Signup__Avatar"); v != "" {
+		if value, err := base64.StdEncoding.DecodeString(v); err != nil {
+			errs.Add("avatar", "must be base64 encoded")
+		} else {
+			x.Avatar = value
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	mask := new(fieldmaskpb.FieldMask)
+	if dep.FormHas(req.Form, "Signup__Email") {
+		mask.Paths = append(mask.Paths, "email")
+	}
+	if dep.FormHas(req.Form, "Signup__Handle") {
+		mask.Paths = append(mask.Paths, "handle")
+	}
+	if dep.FormHas(req.Form, "Signup__Website") {
+		mask.Paths = append(mask.Paths, "website")
+	}
+	if dep.FormHas(req.Form, "Signup__Age") {
+		mask.Paths = append(mask.Paths, "age")
+	}
+	if dep.FormHas(req.Form, "Signup__Score") {
+		mask.Paths = append(mask.Paths, "score")
+	}
+	if dep.FormHas(req.Form, "Signup__Plan") {
+		mask.Paths = append(mask.Paths, "plan")
+	}
+	if dep.FormHas(req.Form, "Signup__Interests") {
+		mask.Paths = append(mask.Paths, "interests")
+	}
+	if req.FormValue("Signup__Avatar") != "" || req.MultipartForm != nil && len(req.MultipartForm.File["Signup__Avatar"]) > 0 {
+		mask.Paths = append(mask.Paths, "avatar")
+	}
+	return mask, dep.MaskedErrors(x.Validate(), mask.Paths)
+}
+
+var signupViewTemplate = template.Must(template.New("view").Parse(`
+<p class="w-16">
+  <span>Email</span>
+  <span> {{ .Email }} </span>
+</p>
+<p class="w-16">
+  <span>Handle</span>
+  <span> {{ .Handle }} </span>
+</p>
+<p class="w-16">
+  <span>Website</span>
+  <span> {{ .Website }} </span>
+</p>
+<p class="w-16">
+  <span>Age</span>
+  <span> {{ .Age }} </span>
+</p>
+<p class="w-16">
+  <span>Score</span>
+  <span> {{ .Score }} </span>
+</p>
+<p class="w-16">
+  <span>Plan</span>
+  <span> {{ .Plan }} </span>
+</p>
+<p class="w-16">
+  <span>Interests</span>
+  <span> {{ .Interests }} </span>
+</p>
+<p class="w-16">
+  <span>Avatar</span>
+  <span> {{ .Avatar }} </span>
+</p>
+<p class="w-16">
+  <span>Labels</span>
+  <span> {{ .Labels }} </span>
+</p>
+`))
+
+// RenderView will take in a http writer and object to render the view
+func (x *Signup) RenderView(w http.ResponseWriter) error {
+	return signupViewTemplate.Execute(w, x)
+}
+
+var signupFormTemplate = template.Must(template.New("form").Parse(`{{ with .Value }}
+<label class="w-16">
+  <span>Email</span>
+  <input type="email" name="Signup__Email" value="{{ .Email }}" required maxlength="254">
+  {{ with index $.Errors "email" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Handle</span>
+  <input type="text" name="Signup__Handle" value="{{ .Handle }}" minlength="3" maxlength="20">
+  {{ with index $.Errors "handle" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Website</span>
+  <input type="url" name="Signup__Website" value="{{ .Website }}">
+  {{ with index $.Errors "website" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Age</span>
+  <input type="number" name="Signup__Age" value="{{ .Age }}" min="13" max="130">
+  {{ with index $.Errors "age" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Score</span>
+  <input type="number" name="Signup__Score" value="{{ .Score }}" min="0.5">
+  {{ with index $.Errors "score" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Plan</span>
+  <select name="Signup__Plan">
+    <option value="PLAN_UNSPECIFIED"{{ if eq (print .Plan) "PLAN_UNSPECIFIED" }} selected{{ end }}>PLAN_UNSPECIFIED</option>
+    <option value="PLAN_FREE"{{ if eq (print .Plan) "PLAN_FREE" }} selected{{ end }}>PLAN_FREE</option>
+    <option value="PLAN_PRO"{{ if eq (print .Plan) "PLAN_PRO" }} selected{{ end }}>PLAN_PRO</option>
+  </select>
+  {{ with index $.Errors "plan" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Interests</span>
+  <textarea name="Signup__Interests">{{ range $i, $v := .Interests }}{{ if $i }}&#10;{{ end }}{{ $v }}{{ end }}</textarea>
+  {{ with index $.Errors "interests" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Avatar</span>
+  <input type="file" name="Signup__Avatar">
+  {{ with index $.Errors "avatar" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+{{ end }}`))
+
+// RenderForm will take in a http writer and render a htmx form for the object
+func (x *Signup) RenderForm(w http.ResponseWriter) error {
+	return x.RenderFormErrors(w, nil)
+}
+
+// RenderFormErrors renders the htmx form for the object with errs shown next to
+// the inputs of their fields
+func (x *Signup) RenderFormErrors(w http.ResponseWriter, errs dep.ValidationErrors) error {
+	return signupFormTemplate.Execute(w, dep.FormData{Value: x, Errors: errs})
+}
+
+var signupHandlePattern = regexp.MustCompile("^[a-z0-9_]+$")
+
+// Validate checks the constraints declared on the fields of Signup
+func (x *Signup) Validate() error {
+	errs := make(dep.ValidationErrors)
+	if x.Email == "" {
+		errs.Add("email", "is required")
+	}
+	if utf8.RuneCountInString(x.GetEmail()) > 254 {
+		errs.Add("email", "must be at most 254 characters")
+	}
+	if x.GetEmail() != "" {
+		if addr, err := mail.ParseAddress(x.GetEmail()); err != nil || addr.Address != x.GetEmail() {
+			errs.Add("email", "must be an email address")
+		}
+	}
+	if x.GetHandle() != "" && utf8.RuneCountInString(x.GetHandle()) < 3 {
+		errs.Add("handle", "must be at least 3 characters")
+	}
+	if utf8.RuneCountInString(x.GetHandle()) > 20 {
+		errs.Add("handle", "must be at most 20 characters")
+	}
+	if x.GetHandle() != "" && !signupHandlePattern.MatchString(x.GetHandle()) {
+		errs.Add("handle", "has an invalid format")
+	}
+	if x.GetWebsite() != "" {
+		if u, err := url.Parse(x.GetWebsite()); err != nil || !u.IsAbs() || u.Host == "" {
+			errs.Add("website", "must be a URL")
+		}
+	}
+	if float64(x.GetAge()) < 13 {
+		errs.Add("age", "must be at least 13")
+	}
+	if float64(x.GetAge()) > 130 {
+		errs.Add("age", "must be at most 130")
+	}
+	if float64(x.GetScore()) < 0.5 {
+		errs.Add("score", "must be at least 0.5")
+	}
+	if _, ok := Plan_name[int32(x.GetPlan())]; !ok {
+		errs.Add("plan", "must be a defined value")
+	}
+	if len(x.Interests) < 1 {
+		errs.Add("interests", "must have at least 1 items")
+	}
+	if len(x.Interests) > 5 {
+		errs.Add("interests", "must have at most 5 items")
+	}
+	for _, v := range x.Interests {
+		if utf8.RuneCountInString(v) > 32 {
+			errs.Add("interests", "must be at most 32 characters")
+		}
+	}
+	if len(x.GetAvatar()) > 65536 {
+		errs.Add("avatar", "must be at most 65536 bytes")
+	}
+	if len(x.Labels) > 10 {
+		errs.Add("labels", "must have at most 10 items")
+	}
+	return errs.Err()
+}
+
+// TableName returns the name of the table backing Signup
+func (*Signup) TableName() string {
+	return "signup"
+}
+
+// Scan implements sql.Scanner, reading x from the protojson document in a
+// JSONB column. Unknown fields are discarded so removed fields do not break reads.
+func (x *Signup) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		proto.Reset(x)
+		return nil
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into Signup", src)
+	}
+
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, x)
+}
+
+// Value implements driver.Valuer, storing x as a protojson document
+func (x *Signup) Value() (driver.Value, error) {
+	if x == nil {
+		return nil, nil
+	}
+
+	data, err := protojson.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drivers send []byte as bytea, JSONB wants text.
+	return string(data), nil
+}
+
+// ProfileHandler serves the http routes of Profile
+type ProfileHandler struct {
+	Repo ProfileRepository
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// NewProfileHandler returns a ProfileHandler backed by repo
+func NewProfileHandler(repo ProfileRepository) *ProfileHandler {
+	return &ProfileHandler{Repo: repo}
+}
+
+func (h *ProfileHandler) tenant(req *http.Request) string {
+	if h.Tenant != nil {
+		return h.Tenant(req)
+	}
+	return chi.URLParam(req, "tenant")
+}
+
+// id reads the {id} url parameter, false when it holds no valid id
+func (h *ProfileHandler) id(req *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(req, "id"), 10, 64)
+	return id, err == nil
+}
+
+// Statements reading Profile, its writes call the routines of the schema
+const (
+	profileCountQuery = "SELECT count(*) FROM profile WHERE tenant = $1"
+	profileListQuery  = "SELECT id, data FROM profile WHERE tenant = $1"
+	profileGetQuery   = "SELECT data FROM profile WHERE tenant = $1 AND id = $2"
+)
+
+// profileListSchema holds the fields List can filter and order by
+var profileListSchema = &dep.Schema{
+	Message: new(Profile),
+	Dialect: dep.Postgres,
+}
+
+// List function returns the page of these objects opts selects
+func (x *Profile) List(ctx context.Context, db DBTX, tenant string, opts dep.ListOptions) (*dep.Page[*Profile, int64], error) {
+	q, err := profileListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Profile, int64])
+	query, args := q.Count(profileCountQuery, tenant)
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(profileListQuery, tenant)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		row := new(Profile)
+		var id int64
+
+		err := rows.Scan(&id, row)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Items = append(ret.Items, dep.Record[*Profile, int64]{ID: id, Value: row})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// Get function acquires a single record based on ID in database
+func (x *Profile) Get(ctx context.Context, db DBTX, tenant string, id int64) error {
+	return db.QueryRowContext(ctx, profileGetQuery, tenant, id).Scan(x)
+}
+
+// Create function will create a new object of this type and return its ID
+func (x *Profile) Create(ctx context.Context, db DBTX, tenant string, data *Profile) (int64, error) {
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err := db.QueryRowContext(ctx, "SELECT create_data($1, $2, NULL::bigint, $3)", tenant, x.TableName(), data).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Update function will replace the object stored at the given ID
+func (x *Profile) Update(ctx context.Context, db DBTX, tenant string, id int64, data *Profile) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	var found int64
+	return db.QueryRowContext(ctx, "SELECT id FROM update_data($1, $2, $3::bigint, $4) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id, data).Scan(&found)
+}
+
+// Patch function stores the fields of data named by mask in the object at the
+// given ID, leaving the others as they are, and reads the result into x
+// The result is validated as a whole, the patch is rolled back when it is not valid.
+func (x *Profile) Patch(ctx context.Context, db DBTX, tenant string, id int64, data *Profile, mask *fieldmaskpb.FieldMask) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.patch(ctx, tx, tenant, id, data, mask)
+	if err == nil {
+		err = x.Validate()
+	}
+	return end(err)
+}
+
+// patch makes the writes of Patch in the transaction it began
+func (x *Profile) patch(ctx context.Context, db DBTX, tenant string, id int64, data *Profile, mask *fieldmaskpb.FieldMask) error {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return err
+	}
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return err
+	}
+
+	// The first patch removes the masked fields, the second stores the ones
+	// data has, so messages, lists and maps are replaced rather than merged.
+	remove, store, err := dep.MergePatches(data, paths)
+	if err != nil {
+		return err
+	}
+
+	return db.QueryRowContext(ctx, "SELECT data FROM patch_data($1, $2, $3::bigint, $4, $5) AS data WHERE data IS NOT NULL",
+		tenant, x.TableName(), id, remove, store).Scan(x)
+}
+
+// Delete function will... well delete the object at given ID
+func (x *Profile) Delete(ctx context.Context, db DBTX, tenant string, id int64) error {
+	var found int64
+	return db.QueryRowContext(ctx, "SELECT id FROM delete_data_by_id($1, $2, $3::bigint) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id).Scan(&found)
+}
+
+// ProfileRepository stores Profile records. Get, Update, Patch and Delete return
+// dep.ErrNotFound for unknown ids, Patch returns the record as stored.
+type ProfileRepository interface {
+	List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Profile, int64], error)
+	Get(ctx context.Context, tenant string, id int64) (*Profile, error)
+	Create(ctx context.Context, tenant string, data *Profile) (int64, error)
+	Update(ctx context.Context, tenant string, id int64, data *Profile) error
+	Patch(ctx context.Context, tenant string, id int64, data *Profile, mask *fieldmaskpb.FieldMask) (*Profile, error)
+	Delete(ctx context.Context, tenant string, id int64) error
+}
+
+// ProfileSQLRepository is the ProfileRepository backed by the Profile persistence methods
+type ProfileSQLRepository struct {
+	DB DBTX
+}
+
+// NewProfileSQLRepository returns a ProfileSQLRepository using db
+func NewProfileSQLRepository(db DBTX) *ProfileSQLRepository {
+	return &ProfileSQLRepository{DB: db}
+}
+
+var _ ProfileRepository = (*ProfileSQLRepository)(nil)
+
+func (r *ProfileSQLRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Profile, int64], error) {
+	return new(Profile).List(ctx, r.DB, tenant, opts)
+}
+
+func (r *ProfileSQLRepository) Get(ctx context.Context, tenant string, id int64) (*Profile, error) {
+	x := new(Profile)
+	err := x.Get(ctx, r.DB, tenant, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func (r *ProfileSQLRepository) Create(ctx context.Context, tenant string, data *Profile) (int64, error) {
+	return data.Create(ctx, r.DB, tenant, data)
+}
+
+func (r *ProfileSQLRepository) Update(ctx context.Context, tenant string, id int64, data *Profile) error {
+	err := data.Update(ctx, r.DB, tenant, id, data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+func (r *ProfileSQLRepository) Patch(ctx context.Context, tenant string, id int64, data *Profile, mask *fieldmaskpb.FieldMask) (*Profile, error) {
+	x := new(Profile)
+	err := x.Patch(ctx, r.DB, tenant, id, data, mask)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func (r *ProfileSQLRepository) Delete(ctx context.Context, tenant string, id int64) error {
+	err := new(Profile).Delete(ctx, r.DB, tenant, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+// ProfileMemoryRepository is a ProfileRepository keeping records in memory, safe for
+// concurrent use. Records are copied on the way in and out.
+type ProfileMemoryRepository struct {
+	mu      sync.RWMutex
+	lastID  int64
+	tenants map[string]map[int64]*Profile
+}
+
+// NewProfileMemoryRepository returns an empty ProfileMemoryRepository
+func NewProfileMemoryRepository() *ProfileMemoryRepository {
+	return &ProfileMemoryRepository{tenants: make(map[string]map[int64]*Profile)}
+}
+
+var _ ProfileRepository = (*ProfileMemoryRepository)(nil)
+
+// lookup returns the stored record, the lock has to be held
+func (r *ProfileMemoryRepository) lookup(tenant string, id int64) (*Profile, error) {
+	x, ok := r.tenants[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+func (r *ProfileMemoryRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Profile, int64], error) {
+	q, err := profileListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Profile, int64], 0, len(r.tenants[tenant]))
+	for id, x := range r.tenants[tenant] {
+		records = append(records, dep.Record[*Profile, int64]{ID: id, Value: proto.Clone(x).(*Profile)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+func (r *ProfileMemoryRepository) Get(ctx context.Context, tenant string, id int64) (*Profile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(x).(*Profile), nil
+}
+
+func (r *ProfileMemoryRepository) Create(ctx context.Context, tenant string, data *Profile) (int64, error) {
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	id := r.lastID
+	if r.tenants[tenant] == nil {
+		r.tenants[tenant] = make(map[int64]*Profile)
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Profile)
+	return id, nil
+}
+
+func (r *ProfileMemoryRepository) Update(ctx context.Context, tenant string, id int64, data *Profile) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.lookup(tenant, id)
+	if err != nil {
+		return err
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Profile)
+	return nil
+}
+
+func (r *ProfileMemoryRepository) Patch(ctx context.Context, tenant string, id int64, data *Profile, mask *fieldmaskpb.FieldMask) (*Profile, error) {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return nil, err
+	}
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	x = proto.Clone(x).(*Profile)
+	dep.ApplyFieldMask(x, data, paths)
+	if err := x.Validate(); err != nil {
+		return nil, err
+	}
+	r.tenants[tenant][id] = x
+	return proto.Clone(x).(*Profile), nil
+}
+
+func (r *ProfileMemoryRepository) Delete(ctx context.Context, tenant string, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.lookup(tenant, id); err != nil {
+		return err
+	}
+	delete(r.tenants[tenant], id)
+	return nil
+}
+
+// ListHandler renders the page of objects selected by the page_size, page_token,
+// skip, filter and order_by query parameters
+func (h *ProfileHandler) ListHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.List(req.Context(), h.tenant(req), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// GetHandler renders the object at the {id} url parameter
+func (h *ProfileHandler) GetHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// CreateHandler stores the object sent in the request body, the Location header
+// of the response names its url
+func (h *ProfileHandler) CreateHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Profile)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.Repo.Create(req.Context(), h.tenant(req), x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", path.Join(req.URL.Path, fmt.Sprint(id)))
+	h.render(w, req, http.StatusCreated, x)
+}
+
+// UpdateHandler replaces the object at the {id} url parameter with the request body
+func (h *ProfileHandler) UpdateHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Profile)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.Repo.Update(req.Context(), h.tenant(req), id, x)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// PatchHandler stores the fields of the request body named by its mask in the object
+// at the {id} url parameter and renders the result
+func (h *ProfileHandler) PatchHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Profile)
+	mask, err := h.decodePatch(req, x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.Patch(req.Context(), h.tenant(req), id, x, mask)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	var invalid dep.ValidationErrors
+	if errors.Is(err, dep.ErrInvalidArgument) || errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, ret)
+}
+
+// DeleteHandler deletes the object at the {id} url parameter
+func (h *ProfileHandler) DeleteHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	err := h.Repo.Delete(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FormHandler renders the form for a new object, or for editing the object at the {id} url parameter
+func (h *ProfileHandler) FormHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Profile)
+	if chi.URLParam(req, "id") != "" {
+		id, ok := h.id(req)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		found, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+		if errors.Is(err, dep.ErrNotFound) {
+			http.NotFound(w, req)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		x = found
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := x.RenderForm(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// decode reads the object from a protojson body, skipping unknown fields, or from
+// a submitted form
+func (h *ProfileHandler) decode(req *http.Request, x *Profile) error {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		if _, err := x.HandleForm(req); err != nil {
+			return err
+		}
+		// HandleForm only validates the fields the form submitted
+		return x.Validate()
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, x); err != nil {
+		return err
+	}
+
+	return x.Validate()
+}
+
+// decodePatch reads the fields to patch from a submitted form, masking the ones it
+// holds, or from a protojson body masked by the update_mask query parameter or by
+// the fields present in the body, which may not name unknown fields
+func (h *ProfileHandler) decodePatch(req *http.Request, x *Profile) (*fieldmaskpb.FieldMask, error) {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		return x.HandleForm(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(body, x); err != nil {
+		return nil, err
+	}
+
+	return dep.ParseFieldMask(req.URL.Query(), body)
+}
+
+// render writes the object as protojson, or as html to htmx requests
+func (h *ProfileHandler) render(w http.ResponseWriter, req *http.Request, status int, x *Profile) {
+	if req.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		x.RenderView(w)
+		return
+	}
+
+	jsonData, err := protojson.Marshal(x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
+
+// Routes returns a chi.Router with the Profile endpoints that can be mounted to a parent router
+func (h *ProfileHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListHandler)
+	r.Post("/", h.CreateHandler)
+	r.Get("/new", h.FormHandler)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.GetHandler)
+		r.Put("/", h.UpdateHandler)
+		r.Patch("/", h.PatchHandler)
+		r.Get("/edit", h.FormHandler)
+		r.Delete("/", h.DeleteHandler)
+	})
+
+	return r
+}
+
+// A simple function to handle a htmx form and populate the struct, returning
+// the mask of the fields the form submitted. Values that fail to parse are
+// collected per field before the submitted fields are validated.
+func (x *Profile) HandleForm(req *http.Request) (*fieldmaskpb.FieldMask, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	errs := make(dep.ValidationErrors)
+	x.Email = req.FormValue("Profile__Email")
+	x.Handle = req.FormValue("Profile__Handle")
+	x.Website = req.FormValue("Profile__Website")
+	if v := req.FormValue("Profile__Age"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 32); err != nil {
+			errs.Add("age", "must be a whole number")
+		} else {
+			value := int32(n)
+			x.Age = value
+		}
+	}
+	if v := req.FormValue("Profile__Score"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err != nil {
+			errs.Add("score", "must be a number")
+		} else {
+			value := float64(n)
+			x.Score = value
+		}
+	}
+	if v := req.FormValue("Profile__Plan"); v != "" {
+		// Either the name or the number of a defined enum value
+		if n, ok := Plan_value[v]; ok {
+			value := Plan(n)
+			x.Plan = value
+		} else if n, err := strconv.ParseInt(v, 10, 32); err == nil && Plan_name[int32(n)] != "" {
+			value := Plan(n)
+			x.Plan = value
+		} else {
+			errs.Add("plan", "must be one of the defined values")
+		}
+	}
+	if _, ok := req.Form["Profile__Interests"]; ok {
+		x.Interests = nil
+		// One item per line
+		for _, v := range strings.Split(req.FormValue("Profile__Interests"), "\n") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			value := v
+			x.Interests = append(x.Interests, value)
+		}
+	}
+	x.Bio = req.FormValue("Profile__Bio")
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	mask := new(fieldmaskpb.FieldMask)
+	if dep.FormHas(req.Form, "Profile__Email") {
+		mask.Paths = append(mask.Paths, "email")
+	}
+	if dep.FormHas(req.Form, "Profile__Handle") {
+		mask.Paths = append(mask.Paths, "handle")
+	}
+	if dep.FormHas(req.Form, "Profile__Website") {
+		mask.Paths = append(mask.Paths, "website")
+	}
+	if dep.FormHas(req.Form, "Profile__Age") {
+		mask.Paths = append(mask.Paths, "age")
+	}
+	if dep.FormHas(req.Form, "Profile__Score") {
+		mask.Paths = append(mask.Paths, "score")
+	}
+	if dep.FormHas(req.Form, "Profile__Plan") {
+		mask.Paths = append(mask.Paths, "plan")
+	}
+	if dep.FormHas(req.Form, "Profile__Interests") {
+		mask.Paths = append(mask.Paths, "interests")
+	}
+	if dep.FormHas(req.Form, "Profile__Bio") {
+		mask.Paths = append(mask.Paths, "bio")
+	}
+	return mask, dep.MaskedErrors(x.Validate(), mask.Paths)
+}
+
+var profileViewTemplate = template.Must(template.New("view").Parse(`
+<p class="w-16">
+  <span>Email</span>
+  <span> {{ .Email }} </span>
+</p>
+<p class="w-16">
+  <span>Handle</span>
+  <span> {{ .Handle }} </span>
+</p>
+<p class="w-16">
+  <span>Website</span>
+  <span> {{ .Website }} </span>
+</p>
+<p class="w-16">
+  <span>Age</span>
+  <span> {{ .Age }} </span>
+</p>
+<p class="w-16">
+  <span>Score</span>
+  <span> {{ .Score }} </span>
+</p>
+<p class="w-16">
+  <span>Plan</span>
+  <span> {{ .Plan }} </span>
+</p>
+<p class="w-16">
+  <span>Interests</span>
+  <span> {{ .Interests }} </span>
+</p>
+<p class="w-16">
+  <span>Bio</span>
+  <span> {{ .Bio }} </span>
+</p>
+`))
+
+// RenderView will take in a http writer and object to render the view
+func (x *Profile) RenderView(w http.ResponseWriter) error {
+	return profileViewTemplate.Execute(w, x)
+}
+
+var profileFormTemplate = template.Must(template.New("form").Parse(`{{ with .Value }}
+<label class="w-16">
+  <span>Email</span>
+  <input type="email" name="Profile__Email" value="{{ .Email }}" required>
+  {{ with index $.Errors "email" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Handle</span>
+  <input type="text" name="Profile__Handle" value="{{ .Handle }}" minlength="3" maxlength="20">
+  {{ with index $.Errors "handle" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Website</span>
+  <input type="url" name="Profile__Website" value="{{ .Website }}">
+  {{ with index $.Errors "website" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Age</span>
+  <input type="number" name="Profile__Age" value="{{ .Age }}" min="13" max="130">
+  {{ with index $.Errors "age" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Score</span>
+  <input type="number" name="Profile__Score" value="{{ .Score }}" min="0.5">
+  {{ with index $.Errors "score" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Plan</span>
+  <select name="Profile__Plan">
+    <option value="PLAN_UNSPECIFIED"{{ if eq (print .Plan) "PLAN_UNSPECIFIED" }} selected{{ end }}>PLAN_UNSPECIFIED</option>
+    <option value="PLAN_FREE"{{ if eq (print .Plan) "PLAN_FREE" }} selected{{ end }}>PLAN_FREE</option>
+    <option value="PLAN_PRO"{{ if eq (print .Plan) "PLAN_PRO" }} selected{{ end }}>PLAN_PRO</option>
+  </select>
+  {{ with index $.Errors "plan" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Interests</span>
+  <textarea name="Profile__Interests">{{ range $i, $v := .Interests }}{{ if $i }}&#10;{{ end }}{{ $v }}{{ end }}</textarea>
+  {{ with index $.Errors "interests" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Bio</span>
+  <input type="text" name="Profile__Bio" value="{{ .Bio }}" maxlength="100">
+  {{ with index $.Errors "bio" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+{{ end }}`))
+
+// RenderForm will take in a http writer and render a htmx form for the object
+func (x *Profile) RenderForm(w http.ResponseWriter) error {
+	return x.RenderFormErrors(w, nil)
+}
+
+// RenderFormErrors renders the htmx form for the object with errs shown next to
+// the inputs of their fields
+func (x *Profile) RenderFormErrors(w http.ResponseWriter, errs dep.ValidationErrors) error {
+	return profileFormTemplate.Execute(w, dep.FormData{Value: x, Errors: errs})
+}
+
+var profileHandlePattern = regexp.MustCompile("^[a-z0-9_]+$")
+
+// Validate checks the constraints declared on the fields of Profile
+func (x *Profile) Validate() error {
+	errs := make(dep.ValidationErrors)
+	if x.Email == "" {
+		errs.Add("email", "is required")
+	}
+	if x.GetEmail() != "" {
+		if addr, err := mail.ParseAddress(x.GetEmail()); err != nil || addr.Address != x.GetEmail() {
+			errs.Add("email", "must be an email address")
+		}
+	}
+	if x.GetHandle() != "" && utf8.RuneCountInString(x.GetHandle()) < 3 {
+		errs.Add("handle", "must be at least 3 characters")
+	}
+	if utf8.RuneCountInString(x.GetHandle()) > 20 {
+		errs.Add("handle", "must be at most 20 characters")
+	}
+	if x.GetHandle() != "" && !profileHandlePattern.MatchString(x.GetHandle()) {
+		errs.Add("handle", "has an invalid format")
+	}
+	if x.GetWebsite() != "" {
+		if u, err := url.Parse(x.GetWebsite()); err != nil || !u.IsAbs() || u.Host == "" {
+			errs.Add("website", "must be a URL")
+		}
+	}
+	if float64(x.GetAge()) < 13 {
+		errs.Add("age", "must be at least 13")
+	}
+	if float64(x.GetAge()) > 130 {
+		errs.Add("age", "must be at most 130")
+	}
+	if float64(x.GetScore()) < 0.5 {
+		errs.Add("score", "must be at least 0.5")
+	}
+	if _, ok := Plan_name[int32(x.GetPlan())]; !ok {
+		errs.Add("plan", "must be a defined value")
+	}
+	if len(x.Interests) < 1 {
+		errs.Add("interests", "must have at least 1 items")
+	}
+	if len(x.Interests) > 5 {
+		errs.Add("interests", "must have at most 5 items")
+	}
+	if utf8.RuneCountInString(x.GetBio()) > 100 {
+		errs.Add("bio", "must be at most 100 characters")
+	}
+	return errs.Err()
+}
+
+// TableName returns the name of the table backing Profile
+func (*Profile) TableName() string {
+	return "profile"
+}
+
+// Scan implements sql.Scanner, reading x from the protojson document in a
+// JSONB column. Unknown fields are discarded so removed fields do not break reads.
+func (x *Profile) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		proto.Reset(x)
+		return nil
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into Profile", src)
+	}
+
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, x)
+}
+
+// Value implements driver.Valuer, storing x as a protojson document
+func (x *Profile) Value() (driver.Value, error) {
+	if x == nil {
+		return nil, nil
+	}
+
+	data, err := protojson.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drivers send []byte as bytea, JSONB wants text.
+	return string(data), nil
+}
+
+// Deps holds what the handlers of the resources in constraints.proto need
+type Deps struct {
+	DB DBTX
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// RegisterAll mounts the routes of every resource in constraints.proto on r
+func RegisterAll(r chi.Router, deps Deps) {
+	r.Mount("/signup", (&SignupHandler{Repo: NewSignupSQLRepository(deps.DB), Tenant: deps.Tenant}).Routes())
+	r.Mount("/profile", (&ProfileHandler{Repo: NewProfileSQLRepository(deps.DB), Tenant: deps.Tenant}).Routes())
+}
@@ -0,0 +1,119 @@
+-- Code generated by protoc-gen-go-dep. DO NOT EDIT.
+-- source: constraints.proto
+
+-- Signup records, one document per row.
+CREATE TABLE IF NOT EXISTS signup (
+    id BIGSERIAL PRIMARY KEY,
+    tenant TEXT NOT NULL,
+    data JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS signup_tenant_idx ON signup (tenant);
+
+-- Profile records, one document per row.
+CREATE TABLE IF NOT EXISTS profile (
+    id BIGSERIAL PRIMARY KEY,
+    tenant TEXT NOT NULL,
+    data JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS profile_tenant_idx ON profile (tenant);
+
+-- Routines called by the generated Go, shared by every resource. The table
+-- is passed by name, rows are only ever touched within the given tenant.
+-- p_id is of the type of the ids of the table, which create_data is passed
+-- a NULL of. The records are read by statements on the tables.
+
+-- create_data returns the id of the row, handed out by the table when p_id
+-- is NULL.
+CREATE OR REPLACE FUNCTION create_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_data JSONB)
+RETURNS ANYELEMENT
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_id p_id%TYPE;
+BEGIN
+    IF p_id IS NULL THEN
+        EXECUTE format('INSERT INTO %I (tenant, data) VALUES ($1, $2) RETURNING id', p_table)
+            INTO v_id
+            USING p_tenant, p_data;
+    ELSE
+        EXECUTE format('INSERT INTO %I (tenant, data, id) VALUES ($1, $2, $3) RETURNING id', p_table)
+            INTO v_id
+            USING p_tenant, p_data, p_id;
+    END IF;
+    RETURN v_id;
+END
+$$;
+
+-- update_data and delete_data_by_id were procedures in earlier schemas, which
+-- CREATE OR REPLACE FUNCTION cannot replace.
+DROP PROCEDURE IF EXISTS update_data(TEXT, TEXT, ANYELEMENT, JSONB);
+DROP PROCEDURE IF EXISTS delete_data_by_id(TEXT, TEXT, ANYELEMENT);
+
+-- update_data returns the id of the row, NULL when there is no such row.
+CREATE OR REPLACE FUNCTION update_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_data JSONB)
+RETURNS ANYELEMENT
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_id p_id%TYPE;
+BEGIN
+    EXECUTE format('UPDATE %I SET data = $3 WHERE tenant = $1 AND id = $2 RETURNING id', p_table)
+        INTO v_id
+        USING p_tenant, p_id, p_data;
+    RETURN v_id;
+END
+$$;
+
+-- jsonb_merge_patch applies an RFC 7396 merge patch, null members remove
+-- the keys they name and objects are merged recursively.
+CREATE OR REPLACE FUNCTION jsonb_merge_patch(p_target JSONB, p_patch JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql IMMUTABLE AS $$
+DECLARE
+    v_key TEXT;
+    v_value JSONB;
+BEGIN
+    IF jsonb_typeof(p_patch) <> 'object' THEN
+        RETURN p_patch;
+    END IF;
+    IF p_target IS NULL OR jsonb_typeof(p_target) <> 'object' THEN
+        p_target := '{}';
+    END IF;
+    FOR v_key, v_value IN SELECT * FROM jsonb_each(p_patch) LOOP
+        IF jsonb_typeof(v_value) = 'null' THEN
+            p_target := p_target - v_key;
+        ELSE
+            p_target := jsonb_set(p_target, ARRAY[v_key], jsonb_merge_patch(p_target -> v_key, v_value));
+        END IF;
+    END LOOP;
+    RETURN p_target;
+END
+$$;
+
+-- patch_data returns the patched document, NULL when there is no such row.
+CREATE OR REPLACE FUNCTION patch_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_remove JSONB, p_store JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_data JSONB;
+BEGIN
+    EXECUTE format('UPDATE %I SET data = jsonb_merge_patch(jsonb_merge_patch(data, $3), $4) WHERE tenant = $1 AND id = $2 RETURNING data', p_table)
+        INTO v_data
+        USING p_tenant, p_id, p_remove, p_store;
+    RETURN v_data;
+END
+$$;
+
+-- delete_data_by_id returns the id of the row, NULL when there is no such row.
+CREATE OR REPLACE FUNCTION delete_data_by_id(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT)
+RETURNS ANYELEMENT
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_id p_id%TYPE;
+BEGIN
+    EXECUTE format('DELETE FROM %I WHERE tenant = $1 AND id = $2 RETURNING id', p_table)
+        INTO v_id
+        USING p_tenant, p_id;
+    RETURN v_id;
+END
+$$;
@@ -0,0 +1,288 @@
+-- Code generated by protoc-gen-go-dep. DO NOT EDIT.
+-- source: hello.proto
+
+-- Hello records, one document per row.
+CREATE TABLE IF NOT EXISTS hellos (
+    id BIGSERIAL PRIMARY KEY,
+    tenant TEXT NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    version BIGINT NOT NULL DEFAULT 1,
+    data JSONB NOT NULL
+);
+ALTER TABLE hellos ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE hellos ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS hellos_tenant_idx ON hellos (tenant);
+CREATE UNIQUE INDEX IF NOT EXISTS hellos_email_key ON hellos (tenant, (NULLIF(COALESCE(data->>'email', ''), ''))) WHERE deleted_at IS NULL;
+
+-- The routines writing hellos are passed its rows outside of the trash.
+CREATE OR REPLACE VIEW hellos_live AS SELECT * FROM hellos WHERE deleted_at IS NULL;
+
+-- Revisions of hellos, the values before and after every write.
+CREATE TABLE IF NOT EXISTS hellos_history (
+    tenant TEXT NOT NULL,
+    id BIGINT NOT NULL,
+    revision BIGINT NOT NULL,
+    operation TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    old_data JSONB,
+    new_data JSONB,
+    PRIMARY KEY (tenant, id, revision)
+);
+
+-- Note records, one document per row.
+CREATE TABLE IF NOT EXISTS note (
+    id UUID PRIMARY KEY,
+    tenant TEXT NOT NULL,
+    version BIGINT NOT NULL DEFAULT 1,
+    data JSONB NOT NULL
+);
+ALTER TABLE note ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS note_tenant_idx ON note (tenant);
+
+-- Revisions of note, the values before and after every write.
+CREATE TABLE IF NOT EXISTS note_history (
+    tenant TEXT NOT NULL,
+    id UUID NOT NULL,
+    revision BIGINT NOT NULL,
+    operation TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    old_data JSONB,
+    new_data JSONB,
+    PRIMARY KEY (tenant, id, revision)
+);
+
+-- Routines called by the generated Go, shared by every resource. The table
+-- is passed by name, rows are only ever touched within the given tenant.
+-- p_id is of the type of the ids of the table, which create_data is passed
+-- a NULL of. The records are read by statements on the tables.
+
+-- create_data returns the id of the row, handed out by the table when p_id
+-- is NULL.
+CREATE OR REPLACE FUNCTION create_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_data JSONB)
+RETURNS ANYELEMENT
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_id p_id%TYPE;
+BEGIN
+    IF p_id IS NULL THEN
+        EXECUTE format('INSERT INTO %I (tenant, data) VALUES ($1, $2) RETURNING id', p_table)
+            INTO v_id
+            USING p_tenant, p_data;
+    ELSE
+        EXECUTE format('INSERT INTO %I (tenant, data, id) VALUES ($1, $2, $3) RETURNING id', p_table)
+            INTO v_id
+            USING p_tenant, p_data, p_id;
+    END IF;
+    RETURN v_id;
+END
+$$;
+
+-- update_data and delete_data_by_id were procedures in earlier schemas, which
+-- CREATE OR REPLACE FUNCTION cannot replace.
+DROP PROCEDURE IF EXISTS update_data(TEXT, TEXT, ANYELEMENT, JSONB);
+DROP PROCEDURE IF EXISTS delete_data_by_id(TEXT, TEXT, ANYELEMENT);
+
+-- update_data returns the id of the row, NULL when there is no such row.
+CREATE OR REPLACE FUNCTION update_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_data JSONB)
+RETURNS ANYELEMENT
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_id p_id%TYPE;
+BEGIN
+    EXECUTE format('UPDATE %I SET data = $3 WHERE tenant = $1 AND id = $2 RETURNING id', p_table)
+        INTO v_id
+        USING p_tenant, p_id, p_data;
+    RETURN v_id;
+END
+$$;
+
+-- jsonb_merge_patch applies an RFC 7396 merge patch, null members remove
+-- the keys they name and objects are merged recursively.
+CREATE OR REPLACE FUNCTION jsonb_merge_patch(p_target JSONB, p_patch JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql IMMUTABLE AS $$
+DECLARE
+    v_key TEXT;
+    v_value JSONB;
+BEGIN
+    IF jsonb_typeof(p_patch) <> 'object' THEN
+        RETURN p_patch;
+    END IF;
+    IF p_target IS NULL OR jsonb_typeof(p_target) <> 'object' THEN
+        p_target := '{}';
+    END IF;
+    FOR v_key, v_value IN SELECT * FROM jsonb_each(p_patch) LOOP
+        IF jsonb_typeof(v_value) = 'null' THEN
+            p_target := p_target - v_key;
+        ELSE
+            p_target := jsonb_set(p_target, ARRAY[v_key], jsonb_merge_patch(p_target -> v_key, v_value));
+        END IF;
+    END LOOP;
+    RETURN p_target;
+END
+$$;
+
+-- patch_data returns the patched document, NULL when there is no such row.
+CREATE OR REPLACE FUNCTION patch_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_remove JSONB, p_store JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_data JSONB;
+BEGIN
+    EXECUTE format('UPDATE %I SET data = jsonb_merge_patch(jsonb_merge_patch(data, $3), $4) WHERE tenant = $1 AND id = $2 RETURNING data', p_table)
+        INTO v_data
+        USING p_tenant, p_id, p_remove, p_store;
+    RETURN v_data;
+END
+$$;
+
+-- delete_data_by_id returns the id of the row, NULL when there is no such row.
+CREATE OR REPLACE FUNCTION delete_data_by_id(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT)
+RETURNS ANYELEMENT
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_id p_id%TYPE;
+BEGIN
+    EXECUTE format('DELETE FROM %I WHERE tenant = $1 AND id = $2 RETURNING id', p_table)
+        INTO v_id
+        USING p_tenant, p_id;
+    RETURN v_id;
+END
+$$;
+
+-- Routines of versioned resources, their tables have a version column bumped
+-- by every write. A p_version of 0 matches any version.
+
+-- update_versioned_data returns the version stored.
+CREATE OR REPLACE FUNCTION update_versioned_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_version BIGINT, p_data JSONB)
+RETURNS BIGINT
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_version BIGINT;
+BEGIN
+    EXECUTE format('UPDATE %I SET data = $4, version = version + 1 WHERE tenant = $1 AND id = $2 AND version = COALESCE(NULLIF($3, 0), version) RETURNING version', p_table)
+        INTO v_version
+        USING p_tenant, p_id, p_version, p_data;
+    RETURN v_version;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION patch_versioned_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_version BIGINT, p_remove JSONB, p_store JSONB, OUT version BIGINT, OUT data JSONB)
+LANGUAGE plpgsql AS $$
+BEGIN
+    EXECUTE format('UPDATE %I SET data = jsonb_merge_patch(jsonb_merge_patch(data, $4), $5), version = version + 1 WHERE tenant = $1 AND id = $2 AND version = COALESCE(NULLIF($3, 0), version) RETURNING version, data', p_table)
+        INTO version, data
+        USING p_tenant, p_id, p_version, p_remove, p_store;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION delete_versioned_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_version BIGINT)
+RETURNS BIGINT
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_version BIGINT;
+BEGIN
+    EXECUTE format('DELETE FROM %I WHERE tenant = $1 AND id = $2 AND version = COALESCE(NULLIF($3, 0), version) RETURNING version', p_table)
+        INTO v_version
+        USING p_tenant, p_id, p_version;
+    RETURN v_version;
+END
+$$;
+
+-- Routines of soft deleted resources, rows in the trash have a deleted_at.
+
+CREATE OR REPLACE FUNCTION trash_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT)
+RETURNS ANYELEMENT
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_id p_id%TYPE;
+BEGIN
+    EXECUTE format('UPDATE %I SET deleted_at = CURRENT_TIMESTAMP WHERE tenant = $1 AND id = $2 AND deleted_at IS NULL RETURNING id', p_table)
+        INTO v_id
+        USING p_tenant, p_id;
+    RETURN v_id;
+END
+$$;
+
+-- trash_versioned_data returns the version of the row, which it keeps.
+CREATE OR REPLACE FUNCTION trash_versioned_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_version BIGINT)
+RETURNS BIGINT
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_version BIGINT;
+BEGIN
+    EXECUTE format('UPDATE %I SET deleted_at = CURRENT_TIMESTAMP WHERE tenant = $1 AND id = $2 AND deleted_at IS NULL AND version = COALESCE(NULLIF($3, 0), version) RETURNING version', p_table)
+        INTO v_version
+        USING p_tenant, p_id, p_version;
+    RETURN v_version;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION restore_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT)
+RETURNS ANYELEMENT
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_id p_id%TYPE;
+BEGIN
+    EXECUTE format('UPDATE %I SET deleted_at = NULL WHERE tenant = $1 AND id = $2 AND deleted_at IS NOT NULL RETURNING id', p_table)
+        INTO v_id
+        USING p_tenant, p_id;
+    RETURN v_id;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION purge_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT)
+RETURNS ANYELEMENT
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_id p_id%TYPE;
+BEGIN
+    EXECUTE format('DELETE FROM %I WHERE tenant = $1 AND id = $2 AND deleted_at IS NOT NULL RETURNING id', p_table)
+        INTO v_id
+        USING p_tenant, p_id;
+    RETURN v_id;
+END
+$$;
+
+-- keep_data returns p_data with the keys in p_keep as the stored row has them,
+-- so an update cannot change them. Keys the row lacks are removed.
+CREATE OR REPLACE FUNCTION keep_data(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_data JSONB, p_keep TEXT[])
+RETURNS JSONB
+LANGUAGE plpgsql STABLE AS $$
+DECLARE
+    v_kept JSONB;
+BEGIN
+    EXECUTE format('SELECT jsonb_object_agg(k, data -> k) FROM %I, unnest($3) AS k WHERE tenant = $1 AND id = $2', p_table)
+        INTO v_kept
+        USING p_tenant, p_id, p_keep;
+    RETURN jsonb_merge_patch(p_data, COALESCE(v_kept, '{}'));
+END
+$$;
+
+-- Routines of resources keeping a history, <table>_history holds a revision
+-- for every write to a row of <table>.
+
+-- record_revision appends the revision of a write leaving the row at p_data,
+-- NULL when it is gone. The insert numbers it after the last revision, whose
+-- value is the one before. It is called in the transaction of the write, the
+-- lock the write holds on the row keeps others from taking the same number.
+CREATE OR REPLACE PROCEDURE record_revision(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT, p_operation TEXT, p_actor TEXT, p_data JSONB)
+LANGUAGE plpgsql AS $$
+BEGIN
+    EXECUTE format('INSERT INTO %1$I (tenant, id, revision, operation, actor, old_data, new_data) SELECT $1, $2, COALESCE(MAX(revision), 0) + 1, $3, $4, (SELECT new_data FROM %1$I WHERE tenant = $1 AND id = $2 ORDER BY revision DESC LIMIT 1), $5 FROM %1$I WHERE tenant = $1 AND id = $2', p_table || '_history')
+        USING p_tenant, p_id, p_operation, p_actor, p_data;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION list_revisions(p_tenant TEXT, p_table TEXT, p_id ANYELEMENT)
+RETURNS TABLE (revision BIGINT, operation TEXT, actor TEXT, changed_at TIMESTAMPTZ, old_data JSONB, new_data JSONB)
+LANGUAGE plpgsql STABLE AS $$
+BEGIN
+    RETURN QUERY EXECUTE format('SELECT revision, operation, actor, changed_at, old_data, new_data FROM %I WHERE tenant = $1 AND id = $2 ORDER BY revision', p_table || '_history')
+        USING p_tenant, p_id;
+END
+$$;
@@ -0,0 +1,3369 @@
+// Code generated by protoc-gen-go-dep. DO NOT EDIT.
+// source: options.proto
+
+package options
+
+import (
+	context "context"
+	sql "database/sql"
+	driver "database/sql/driver"
+	base64 "encoding/base64"
+	json "encoding/json"
+	errors "errors"
+	fmt "fmt"
+	chi "github.com/go-chi/chi/v5"
+	uuid "github.com/google/uuid"
+	protojson "google.golang.org/protobuf/encoding/protojson"
+	proto "google.golang.org/protobuf/proto"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	template "html/template"
+	io "io"
+	mime "mime"
+	http "net/http"
+	path "path"
+	dep "protoc-gen-go-dep/dep"
+	strconv "strconv"
+	strings "strings"
+	sync "sync"
+	time "time"
+)
+
+// DBTX is what the persistence methods need from database/sql, it is
+// satisfied by both *sql.DB and *sql.Tx
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// WithTx runs fn in a transaction begun on db, it is committed when fn
+// returns nil and rolled back otherwise
+func WithTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rolling back a committed transaction does nothing, this covers
+	// both an error and a panic in fn
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// begin starts a transaction on db for a write made of several statements, or
+// returns db when it is a transaction already. end commits the transaction it
+// began when err is nil and rolls it back otherwise, returning err. Within a
+// transaction of the caller rolling back is left to them
+func begin(ctx context.Context, db DBTX) (DBTX, func(err error) error, error) {
+	b, ok := db.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return db, func(err error) error { return err }, nil
+	}
+	tx, err := b.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, func(err error) error {
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	}, nil
+}
+
+// LegacyHandler serves the http routes of Legacy
+type LegacyHandler struct {
+	Repo LegacyRepository
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// NewLegacyHandler returns a LegacyHandler backed by repo
+func NewLegacyHandler(repo LegacyRepository) *LegacyHandler {
+	return &LegacyHandler{Repo: repo}
+}
+
+func (h *LegacyHandler) tenant(req *http.Request) string {
+	if h.Tenant != nil {
+		return h.Tenant(req)
+	}
+	return chi.URLParam(req, "tenant")
+}
+
+// id reads the {id} url parameter, false when it holds no valid id
+func (h *LegacyHandler) id(req *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(req, "id"), 10, 64)
+	return id, err == nil
+}
+
+// Statements reading Legacy, its writes call the routines of the schema
+const (
+	legacyCountQuery = "SELECT count(*) FROM legacy WHERE tenant = $1"
+	legacyListQuery  = "SELECT id, data FROM legacy WHERE tenant = $1"
+	legacyGetQuery   = "SELECT data FROM legacy WHERE tenant = $1 AND id = $2"
+)
+
+// legacyListSchema holds the fields List can filter and order by
+var legacyListSchema = &dep.Schema{
+	Message: new(Legacy),
+	Dialect: dep.Postgres,
+}
+
+// List function returns the page of these objects opts selects
+func (x *Legacy) List(ctx context.Context, db DBTX, tenant string, opts dep.ListOptions) (*dep.Page[*Legacy, int64], error) {
+	q, err := legacyListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Legacy, int64])
+	query, args := q.Count(legacyCountQuery, tenant)
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(legacyListQuery, tenant)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		row := new(Legacy)
+		var id int64
+
+		err := rows.Scan(&id, row)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Items = append(ret.Items, dep.Record[*Legacy, int64]{ID: id, Value: row})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// Get function acquires a single record based on ID in database
+func (x *Legacy) Get(ctx context.Context, db DBTX, tenant string, id int64) error {
+	return db.QueryRowContext(ctx, legacyGetQuery, tenant, id).Scan(x)
+}
+
+// Create function will create a new object of this type and return its ID
+func (x *Legacy) Create(ctx context.Context, db DBTX, tenant string, data *Legacy) (int64, error) {
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err := db.QueryRowContext(ctx, "SELECT create_data($1, $2, NULL::bigint, $3)", tenant, x.TableName(), data).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Update function will replace the object stored at the given ID
+func (x *Legacy) Update(ctx context.Context, db DBTX, tenant string, id int64, data *Legacy) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	var found int64
+	return db.QueryRowContext(ctx, "SELECT id FROM update_data($1, $2, $3::bigint, $4) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id, data).Scan(&found)
+}
+
+// Patch function stores the fields of data named by mask in the object at the
+// given ID, leaving the others as they are, and reads the result into x
+// The result is validated as a whole, the patch is rolled back when it is not valid.
+func (x *Legacy) Patch(ctx context.Context, db DBTX, tenant string, id int64, data *Legacy, mask *fieldmaskpb.FieldMask) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.patch(ctx, tx, tenant, id, data, mask)
+	if err == nil {
+		err = x.Validate()
+	}
+	return end(err)
+}
+
+// patch makes the writes of Patch in the transaction it began
+func (x *Legacy) patch(ctx context.Context, db DBTX, tenant string, id int64, data *Legacy, mask *fieldmaskpb.FieldMask) error {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return err
+	}
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return err
+	}
+
+	// The first patch removes the masked fields, the second stores the ones
+	// data has, so messages, lists and maps are replaced rather than merged.
+	remove, store, err := dep.MergePatches(data, paths)
+	if err != nil {
+		return err
+	}
+
+	return db.QueryRowContext(ctx, "SELECT data FROM patch_data($1, $2, $3::bigint, $4, $5) AS data WHERE data IS NOT NULL",
+		tenant, x.TableName(), id, remove, store).Scan(x)
+}
+
+// Delete function will... well delete the object at given ID
+func (x *Legacy) Delete(ctx context.Context, db DBTX, tenant string, id int64) error {
+	var found int64
+	return db.QueryRowContext(ctx, "SELECT id FROM delete_data_by_id($1, $2, $3::bigint) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id).Scan(&found)
+}
+
+// LegacyRepository stores Legacy records. Get, Update, Patch and Delete return
+// dep.ErrNotFound for unknown ids, Patch returns the record as stored.
+type LegacyRepository interface {
+	List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Legacy, int64], error)
+	Get(ctx context.Context, tenant string, id int64) (*Legacy, error)
+	Create(ctx context.Context, tenant string, data *Legacy) (int64, error)
+	Update(ctx context.Context, tenant string, id int64, data *Legacy) error
+	Patch(ctx context.Context, tenant string, id int64, data *Legacy, mask *fieldmaskpb.FieldMask) (*Legacy, error)
+	Delete(ctx context.Context, tenant string, id int64) error
+}
+
+// LegacySQLRepository is the LegacyRepository backed by the Legacy persistence methods
+type LegacySQLRepository struct {
+	DB DBTX
+}
+
+// NewLegacySQLRepository returns a LegacySQLRepository using db
+func NewLegacySQLRepository(db DBTX) *LegacySQLRepository {
+	return &LegacySQLRepository{DB: db}
+}
+
+var _ LegacyRepository = (*LegacySQLRepository)(nil)
+
+func (r *LegacySQLRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Legacy, int64], error) {
+	return new(Legacy).List(ctx, r.DB, tenant, opts)
+}
+
+func (r *LegacySQLRepository) Get(ctx context.Context, tenant string, id int64) (*Legacy, error) {
+	x := new(Legacy)
+	err := x.Get(ctx, r.DB, tenant, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func (r *LegacySQLRepository) Create(ctx context.Context, tenant string, data *Legacy) (int64, error) {
+	return data.Create(ctx, r.DB, tenant, data)
+}
+
+func (r *LegacySQLRepository) Update(ctx context.Context, tenant string, id int64, data *Legacy) error {
+	err := data.Update(ctx, r.DB, tenant, id, data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+func (r *LegacySQLRepository) Patch(ctx context.Context, tenant string, id int64, data *Legacy, mask *fieldmaskpb.FieldMask) (*Legacy, error) {
+	x := new(Legacy)
+	err := x.Patch(ctx, r.DB, tenant, id, data, mask)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func (r *LegacySQLRepository) Delete(ctx context.Context, tenant string, id int64) error {
+	err := new(Legacy).Delete(ctx, r.DB, tenant, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+// LegacyMemoryRepository is a LegacyRepository keeping records in memory, safe for
+// concurrent use. Records are copied on the way in and out.
+type LegacyMemoryRepository struct {
+	mu      sync.RWMutex
+	lastID  int64
+	tenants map[string]map[int64]*Legacy
+}
+
+// NewLegacyMemoryRepository returns an empty LegacyMemoryRepository
+func NewLegacyMemoryRepository() *LegacyMemoryRepository {
+	return &LegacyMemoryRepository{tenants: make(map[string]map[int64]*Legacy)}
+}
+
+var _ LegacyRepository = (*LegacyMemoryRepository)(nil)
+
+// lookup returns the stored record, the lock has to be held
+func (r *LegacyMemoryRepository) lookup(tenant string, id int64) (*Legacy, error) {
+	x, ok := r.tenants[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+func (r *LegacyMemoryRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Legacy, int64], error) {
+	q, err := legacyListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Legacy, int64], 0, len(r.tenants[tenant]))
+	for id, x := range r.tenants[tenant] {
+		records = append(records, dep.Record[*Legacy, int64]{ID: id, Value: proto.Clone(x).(*Legacy)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+func (r *LegacyMemoryRepository) Get(ctx context.Context, tenant string, id int64) (*Legacy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(x).(*Legacy), nil
+}
+
+func (r *LegacyMemoryRepository) Create(ctx context.Context, tenant string, data *Legacy) (int64, error) {
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	id := r.lastID
+	if r.tenants[tenant] == nil {
+		r.tenants[tenant] = make(map[int64]*Legacy)
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Legacy)
+	return id, nil
+}
+
+func (r *LegacyMemoryRepository) Update(ctx context.Context, tenant string, id int64, data *Legacy) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.lookup(tenant, id)
+	if err != nil {
+		return err
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Legacy)
+	return nil
+}
+
+func (r *LegacyMemoryRepository) Patch(ctx context.Context, tenant string, id int64, data *Legacy, mask *fieldmaskpb.FieldMask) (*Legacy, error) {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return nil, err
+	}
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	x = proto.Clone(x).(*Legacy)
+	dep.ApplyFieldMask(x, data, paths)
+	if err := x.Validate(); err != nil {
+		return nil, err
+	}
+	r.tenants[tenant][id] = x
+	return proto.Clone(x).(*Legacy), nil
+}
+
+func (r *LegacyMemoryRepository) Delete(ctx context.Context, tenant string, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.lookup(tenant, id); err != nil {
+		return err
+	}
+	delete(r.tenants[tenant], id)
+	return nil
+}
+
+// ListHandler renders the page of objects selected by the page_size, page_token,
+// skip, filter and order_by query parameters
+func (h *LegacyHandler) ListHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.List(req.Context(), h.tenant(req), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// GetHandler renders the object at the {id} url parameter
+func (h *LegacyHandler) GetHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// CreateHandler stores the object sent in the request body, the Location header
+// of the response names its url
+func (h *LegacyHandler) CreateHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Legacy)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.Repo.Create(req.Context(), h.tenant(req), x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", path.Join(req.URL.Path, fmt.Sprint(id)))
+	h.render(w, req, http.StatusCreated, x)
+}
+
+// UpdateHandler replaces the object at the {id} url parameter with the request body
+func (h *LegacyHandler) UpdateHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Legacy)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.Repo.Update(req.Context(), h.tenant(req), id, x)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// PatchHandler stores the fields of the request body named by its mask in the object
+// at the {id} url parameter and renders the result
+func (h *LegacyHandler) PatchHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Legacy)
+	mask, err := h.decodePatch(req, x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.Patch(req.Context(), h.tenant(req), id, x, mask)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	var invalid dep.ValidationErrors
+	if errors.Is(err, dep.ErrInvalidArgument) || errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, ret)
+}
+
+// DeleteHandler deletes the object at the {id} url parameter
+func (h *LegacyHandler) DeleteHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	err := h.Repo.Delete(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FormHandler renders the form for a new object, or for editing the object at the {id} url parameter
+func (h *LegacyHandler) FormHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Legacy)
+	if chi.URLParam(req, "id") != "" {
+		id, ok := h.id(req)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		found, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+		if errors.Is(err, dep.ErrNotFound) {
+			http.NotFound(w, req)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		x = found
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := x.RenderForm(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// decode reads the object from a protojson body, skipping unknown fields, or from
+// a submitted form
+func (h *LegacyHandler) decode(req *http.Request, x *Legacy) error {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		if _, err := x.HandleForm(req); err != nil {
+			return err
+		}
+		// HandleForm only validates the fields the form submitted
+		return x.Validate()
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, x); err != nil {
+		return err
+	}
+
+	return x.Validate()
+}
+
+// decodePatch reads the fields to patch from a submitted form, masking the ones it
+// holds, or from a protojson body masked by the update_mask query parameter or by
+// the fields present in the body, which may not name unknown fields
+func (h *LegacyHandler) decodePatch(req *http.Request, x *Legacy) (*fieldmaskpb.FieldMask, error) {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		return x.HandleForm(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(body, x); err != nil {
+		return nil, err
+	}
+
+	return dep.ParseFieldMask(req.URL.Query(), body)
+}
+
+// render writes the object as protojson, or as html to htmx requests
+func (h *LegacyHandler) render(w http.ResponseWriter, req *http.Request, status int, x *Legacy) {
+	if req.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		x.RenderView(w)
+		return
+	}
+
+	jsonData, err := protojson.Marshal(x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
+
+// Routes returns a chi.Router with the Legacy endpoints that can be mounted to a parent router
+func (h *LegacyHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListHandler)
+	r.Post("/", h.CreateHandler)
+	r.Get("/new", h.FormHandler)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.GetHandler)
+		r.Put("/", h.UpdateHandler)
+		r.Patch("/", h.PatchHandler)
+		r.Get("/edit", h.FormHandler)
+		r.Delete("/", h.DeleteHandler)
+	})
+
+	return r
+}
+
+// A simple function to handle a htmx form and populate the struct, returning
+// the mask of the fields the form submitted. Values that fail to parse are
+// collected per field before the submitted fields are validated.
+func (x *Legacy) HandleForm(req *http.Request) (*fieldmaskpb.FieldMask, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	errs := make(dep.ValidationErrors)
+	x.Title = req.FormValue("Legacy__Title")
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	mask := new(fieldmaskpb.FieldMask)
+	if dep.FormHas(req.Form, "Legacy__Title") {
+		mask.Paths = append(mask.Paths, "title")
+	}
+	return mask, dep.MaskedErrors(x.Validate(), mask.Paths)
+}
+
+var legacyViewTemplate = template.Must(template.New("view").Parse(`
+<p class="w-16">
+  <span>Title</span>
+  <span> {{ .Title }} </span>
+</p>
+`))
+
+// RenderView will take in a http writer and object to render the view
+func (x *Legacy) RenderView(w http.ResponseWriter) error {
+	return legacyViewTemplate.Execute(w, x)
+}
+
+var legacyFormTemplate = template.Must(template.New("form").Parse(`{{ with .Value }}
+<label class="w-16">
+  <span>Title</span>
+  <input type="text" name="Legacy__Title" value="{{ .Title }}">
+  {{ with index $.Errors "title" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+{{ end }}`))
+
+// RenderForm will take in a http writer and render a htmx form for the object
+func (x *Legacy) RenderForm(w http.ResponseWriter) error {
+	return x.RenderFormErrors(w, nil)
+}
+
+// RenderFormErrors renders the htmx form for the object with errs shown next to
+// the inputs of their fields
+func (x *Legacy) RenderFormErrors(w http.ResponseWriter, errs dep.ValidationErrors) error {
+	return legacyFormTemplate.Execute(w, dep.FormData{Value: x, Errors: errs})
+}
+
+// Validate checks the constraints declared on the fields of Legacy
+func (x *Legacy) Validate() error {
+	errs := make(dep.ValidationErrors)
+	return errs.Err()
+}
+
+// TableName returns the name of the table backing Legacy
+func (*Legacy) TableName() string {
+	return "legacy"
+}
+
+// Scan implements sql.Scanner, reading x from the protojson document in a
+// JSONB column. Unknown fields are discarded so removed fields do not break reads.
+func (x *Legacy) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		proto.Reset(x)
+		return nil
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into Legacy", src)
+	}
+
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, x)
+}
+
+// Value implements driver.Valuer, storing x as a protojson document
+func (x *Legacy) Value() (driver.Value, error) {
+	if x == nil {
+		return nil, nil
+	}
+
+	data, err := protojson.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drivers send []byte as bytea, JSONB wants text.
+	return string(data), nil
+}
+
+// CountryHandler serves the http routes of Country
+type CountryHandler struct {
+	Repo CountryRepository
+}
+
+// NewCountryHandler returns a CountryHandler backed by repo
+func NewCountryHandler(repo CountryRepository) *CountryHandler {
+	return &CountryHandler{Repo: repo}
+}
+
+// id reads the {id} url parameter, false when it holds no valid id
+func (h *CountryHandler) id(req *http.Request) (string, bool) {
+	id := chi.URLParam(req, "id")
+	return id, id != ""
+}
+
+// Statements reading Country, its writes call the routines of the schema
+const (
+	countryCountQuery = "SELECT count(*) FROM country WHERE tenant = $1"
+	countryListQuery  = "SELECT id, data FROM country WHERE tenant = $1"
+	countryGetQuery   = "SELECT data FROM country WHERE tenant = $1 AND id = $2"
+)
+
+// countryUniqueKeys are the unique indexes of Country, for dep.AlreadyExists
+var countryUniqueKeys = []dep.UniqueKey{
+	{
+		Index:   "country_pkey",
+		Columns: "country.tenant, country.id",
+		Fields:  []string{"code"},
+	},
+}
+
+// countryListSchema holds the fields List can filter and order by
+var countryListSchema = &dep.Schema{
+	Message: new(Country),
+	Dialect: dep.Postgres,
+	ID:      "",
+}
+
+// List function returns the page of these objects opts selects
+func (x *Country) List(ctx context.Context, db DBTX, opts dep.ListOptions) (*dep.Page[*Country, string], error) {
+	q, err := countryListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Country, string])
+	query, args := q.Count(countryCountQuery, "")
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(countryListQuery, "")
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		row := new(Country)
+		var id string
+
+		err := rows.Scan(&id, row)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Items = append(ret.Items, dep.Record[*Country, string]{ID: id, Value: row})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// Get function acquires a single record based on ID in database
+func (x *Country) Get(ctx context.Context, db DBTX, id string) error {
+	return db.QueryRowContext(ctx, countryGetQuery, "", id).Scan(x)
+}
+
+// CountryRepository stores Country records. Get, Update, Patch and Delete return
+// dep.ErrNotFound for unknown ids, Patch returns the record as stored.
+//
+// The writes fail with dep.ErrAlreadyExists, an *dep.AlreadyExistsError naming the
+// fields, when another record of the tenant holds the values of a unique index.
+type CountryRepository interface {
+	List(ctx context.Context, opts dep.ListOptions) (*dep.Page[*Country, string], error)
+	Get(ctx context.Context, id string) (*Country, error)
+}
+
+// CountrySQLRepository is the CountryRepository backed by the Country persistence methods
+type CountrySQLRepository struct {
+	DB DBTX
+}
+
+// NewCountrySQLRepository returns a CountrySQLRepository using db
+func NewCountrySQLRepository(db DBTX) *CountrySQLRepository {
+	return &CountrySQLRepository{DB: db}
+}
+
+var _ CountryRepository = (*CountrySQLRepository)(nil)
+
+func (r *CountrySQLRepository) List(ctx context.Context, opts dep.ListOptions) (*dep.Page[*Country, string], error) {
+	return new(Country).List(ctx, r.DB, opts)
+}
+
+func (r *CountrySQLRepository) Get(ctx context.Context, id string) (*Country, error) {
+	x := new(Country)
+	err := x.Get(ctx, r.DB, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// CountryMemoryRepository is a CountryRepository keeping records in memory, safe for
+// concurrent use. Records are copied on the way in and out.
+type CountryMemoryRepository struct {
+	mu      sync.RWMutex
+	tenants map[string]map[string]*Country
+}
+
+// NewCountryMemoryRepository returns an empty CountryMemoryRepository
+func NewCountryMemoryRepository() *CountryMemoryRepository {
+	return &CountryMemoryRepository{tenants: make(map[string]map[string]*Country)}
+}
+
+var _ CountryRepository = (*CountryMemoryRepository)(nil)
+
+// lookup returns the stored record, the lock has to be held
+func (r *CountryMemoryRepository) lookup(tenant string, id string) (*Country, error) {
+	x, ok := r.tenants[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+func (r *CountryMemoryRepository) List(ctx context.Context, opts dep.ListOptions) (*dep.Page[*Country, string], error) {
+	q, err := countryListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Country, string], 0, len(r.tenants[""]))
+	for id, x := range r.tenants[""] {
+		records = append(records, dep.Record[*Country, string]{ID: id, Value: proto.Clone(x).(*Country)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+func (r *CountryMemoryRepository) Get(ctx context.Context, id string) (*Country, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	x, err := r.lookup("", id)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(x).(*Country), nil
+}
+
+// ListHandler renders the page of objects selected by the page_size, page_token,
+// skip, filter and order_by query parameters
+func (h *CountryHandler) ListHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.List(req.Context(), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// GetHandler renders the object at the {id} url parameter
+func (h *CountryHandler) GetHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, err := h.Repo.Get(req.Context(), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// render writes the object as protojson, or as html to htmx requests
+func (h *CountryHandler) render(w http.ResponseWriter, req *http.Request, status int, x *Country) {
+	jsonData, err := protojson.Marshal(x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
+
+// Routes returns a chi.Router with the Country endpoints that can be mounted to a parent router
+func (h *CountryHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListHandler)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.GetHandler)
+	})
+
+	return r
+}
+
+// Validate checks the constraints declared on the fields of Country
+func (x *Country) Validate() error {
+	errs := make(dep.ValidationErrors)
+	if x.Code == "" {
+		errs.Add("code", "is required")
+	}
+	if strings.Contains(x.Code, "/") {
+		errs.Add("code", "cannot contain /")
+	}
+	return errs.Err()
+}
+
+// TableName returns the name of the table backing Country
+func (*Country) TableName() string {
+	return "country"
+}
+
+// Scan implements sql.Scanner, reading x from the protojson document in a
+// JSONB column. Unknown fields are discarded so removed fields do not break reads.
+func (x *Country) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		proto.Reset(x)
+		return nil
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into Country", src)
+	}
+
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, x)
+}
+
+// Value implements driver.Valuer, storing x as a protojson document
+func (x *Country) Value() (driver.Value, error) {
+	if x == nil {
+		return nil, nil
+	}
+
+	data, err := protojson.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drivers send []byte as bytea, JSONB wants text.
+	return string(data), nil
+}
+
+// TagHandler serves the http routes of Tag
+type TagHandler struct {
+	Repo TagRepository
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// NewTagHandler returns a TagHandler backed by repo
+func NewTagHandler(repo TagRepository) *TagHandler {
+	return &TagHandler{Repo: repo}
+}
+
+func (h *TagHandler) tenant(req *http.Request) string {
+	if h.Tenant != nil {
+		return h.Tenant(req)
+	}
+	return chi.URLParam(req, "tenant")
+}
+
+// id reads the {id} url parameter, false when it holds no valid id
+func (h *TagHandler) id(req *http.Request) (string, bool) {
+	id := chi.URLParam(req, "id")
+	return id, id != ""
+}
+
+// Statements reading Tag, its writes call the routines of the schema
+const (
+	tagCountQuery        = "SELECT count(*) FROM tag WHERE tenant = $1 AND deleted_at IS NULL"
+	tagListQuery         = "SELECT id, data FROM tag WHERE tenant = $1 AND deleted_at IS NULL"
+	tagDeletedCountQuery = "SELECT count(*) FROM tag WHERE tenant = $1 AND deleted_at IS NOT NULL"
+	tagDeletedListQuery  = "SELECT id, data FROM tag WHERE tenant = $1 AND deleted_at IS NOT NULL"
+	tagGetQuery          = "SELECT data FROM tag WHERE tenant = $1 AND id = $2 AND deleted_at IS NULL"
+)
+
+// tagUniqueKeys are the unique indexes of Tag, for dep.AlreadyExists
+var tagUniqueKeys = []dep.UniqueKey{
+	{
+		Index:   "tag_pkey",
+		Columns: "tag.tenant, tag.id",
+		Fields:  []string{"label"},
+	},
+}
+
+// tagListSchema holds the fields List can filter and order by
+var tagListSchema = &dep.Schema{
+	Message: new(Tag),
+	Dialect: dep.Postgres,
+	ID:      "",
+}
+
+// List function returns the page of these objects opts selects
+func (x *Tag) List(ctx context.Context, db DBTX, tenant string, opts dep.ListOptions) (*dep.Page[*Tag, string], error) {
+	q, err := tagListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Tag, string])
+	query, args := q.Count(tagCountQuery, tenant)
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(tagListQuery, tenant)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		row := new(Tag)
+		var id string
+
+		err := rows.Scan(&id, row)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Items = append(ret.Items, dep.Record[*Tag, string]{ID: id, Value: row})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// ListDeleted function returns the page of these objects in the trash opts selects
+func (x *Tag) ListDeleted(ctx context.Context, db DBTX, tenant string, opts dep.ListOptions) (*dep.Page[*Tag, string], error) {
+	q, err := tagListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Tag, string])
+	query, args := q.Count(tagDeletedCountQuery, tenant)
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(tagDeletedListQuery, tenant)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		row := new(Tag)
+		var id string
+
+		err := rows.Scan(&id, row)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Items = append(ret.Items, dep.Record[*Tag, string]{ID: id, Value: row})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// Get function acquires a single record based on ID in database
+func (x *Tag) Get(ctx context.Context, db DBTX, tenant string, id string) error {
+	return db.QueryRowContext(ctx, tagGetQuery, tenant, id).Scan(x)
+}
+
+// Create function will create a new object of this type and return its ID
+func (x *Tag) Create(ctx context.Context, db DBTX, tenant string, data *Tag) (string, error) {
+	if err := data.Validate(); err != nil {
+		return "", err
+	}
+
+	id := data.Label
+	_, err := db.ExecContext(ctx, "SELECT create_data($1, $2, $3::text, $4)", tenant, x.TableName(), id, data)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Update function will replace the object stored at the given ID
+func (x *Tag) Update(ctx context.Context, db DBTX, tenant string, id string, data *Tag) error {
+	data.Label = id
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	var found string
+	return db.QueryRowContext(ctx, "SELECT id FROM update_data($1, $2, $3::text, $4) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName()+"_live", id, data).Scan(&found)
+}
+
+// Patch function stores the fields of data named by mask in the object at the
+// given ID, leaving the others as they are, and reads the result into x
+// The result is validated as a whole, the patch is rolled back when it is not valid.
+func (x *Tag) Patch(ctx context.Context, db DBTX, tenant string, id string, data *Tag, mask *fieldmaskpb.FieldMask) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.patch(ctx, tx, tenant, id, data, mask)
+	if err == nil {
+		err = x.Validate()
+	}
+	return end(err)
+}
+
+// patch makes the writes of Patch in the transaction it began
+func (x *Tag) patch(ctx context.Context, db DBTX, tenant string, id string, data *Tag, mask *fieldmaskpb.FieldMask) error {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return err
+	}
+	data.Label = id
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return err
+	}
+
+	// The first patch removes the masked fields, the second stores the ones
+	// data has, so messages, lists and maps are replaced rather than merged.
+	remove, store, err := dep.MergePatches(data, paths)
+	if err != nil {
+		return err
+	}
+
+	return db.QueryRowContext(ctx, "SELECT data FROM patch_data($1, $2, $3::text, $4, $5) AS data WHERE data IS NOT NULL",
+		tenant, x.TableName()+"_live", id, remove, store).Scan(x)
+}
+
+// Delete function will move the object at given ID to the trash
+func (x *Tag) Delete(ctx context.Context, db DBTX, tenant string, id string) error {
+	var found string
+	return db.QueryRowContext(ctx, "SELECT id FROM trash_data($1, $2, $3::text) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id).Scan(&found)
+}
+
+// Restore function brings the object at the given ID back from the trash
+func (x *Tag) Restore(ctx context.Context, db DBTX, tenant string, id string) error {
+	var found string
+	return db.QueryRowContext(ctx, "SELECT id FROM restore_data($1, $2, $3::text) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id).Scan(&found)
+}
+
+// Purge function deletes the object at the given ID from the trash for good
+func (x *Tag) Purge(ctx context.Context, db DBTX, tenant string, id string) error {
+	var found string
+	return db.QueryRowContext(ctx, "SELECT id FROM purge_data($1, $2, $3::text) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id).Scan(&found)
+}
+
+// TagRepository stores Tag records. Get, Update, Patch and Delete return
+// dep.ErrNotFound for unknown ids, Patch returns the record as stored.
+//
+// Delete moves records to a trash the other methods do not see, ListDeleted lists
+// it. Restore and Purge return dep.ErrNotFound for ids not in the trash.
+//
+// The writes fail with dep.ErrAlreadyExists, an *dep.AlreadyExistsError naming the
+// fields, when another record of the tenant holds the values of a unique index.
+type TagRepository interface {
+	List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Tag, string], error)
+	Get(ctx context.Context, tenant string, id string) (*Tag, error)
+	Create(ctx context.Context, tenant string, data *Tag) (string, error)
+	Update(ctx context.Context, tenant string, id string, data *Tag) error
+	Patch(ctx context.Context, tenant string, id string, data *Tag, mask *fieldmaskpb.FieldMask) (*Tag, error)
+	Delete(ctx context.Context, tenant string, id string) error
+	ListDeleted(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Tag, string], error)
+	Restore(ctx context.Context, tenant string, id string) error
+	Purge(ctx context.Context, tenant string, id string) error
+}
+
+// TagSQLRepository is the TagRepository backed by the Tag persistence methods
+type TagSQLRepository struct {
+	DB DBTX
+}
+
+// NewTagSQLRepository returns a TagSQLRepository using db
+func NewTagSQLRepository(db DBTX) *TagSQLRepository {
+	return &TagSQLRepository{DB: db}
+}
+
+var _ TagRepository = (*TagSQLRepository)(nil)
+
+func (r *TagSQLRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Tag, string], error) {
+	return new(Tag).List(ctx, r.DB, tenant, opts)
+}
+
+func (r *TagSQLRepository) Get(ctx context.Context, tenant string, id string) (*Tag, error) {
+	x := new(Tag)
+	err := x.Get(ctx, r.DB, tenant, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func (r *TagSQLRepository) Create(ctx context.Context, tenant string, data *Tag) (string, error) {
+	id, err := data.Create(ctx, r.DB, tenant, data)
+	return id, dep.AlreadyExists(err, tagUniqueKeys...)
+}
+
+func (r *TagSQLRepository) Update(ctx context.Context, tenant string, id string, data *Tag) error {
+	err := data.Update(ctx, r.DB, tenant, id, data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return dep.AlreadyExists(err, tagUniqueKeys...)
+}
+
+func (r *TagSQLRepository) Patch(ctx context.Context, tenant string, id string, data *Tag, mask *fieldmaskpb.FieldMask) (*Tag, error) {
+	x := new(Tag)
+	err := x.Patch(ctx, r.DB, tenant, id, data, mask)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, dep.AlreadyExists(err, tagUniqueKeys...)
+	}
+
+	return x, nil
+}
+
+func (r *TagSQLRepository) Delete(ctx context.Context, tenant string, id string) error {
+	err := new(Tag).Delete(ctx, r.DB, tenant, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+func (r *TagSQLRepository) ListDeleted(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Tag, string], error) {
+	return new(Tag).ListDeleted(ctx, r.DB, tenant, opts)
+}
+
+func (r *TagSQLRepository) Restore(ctx context.Context, tenant string, id string) error {
+	err := new(Tag).Restore(ctx, r.DB, tenant, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return dep.AlreadyExists(err, tagUniqueKeys...)
+}
+
+func (r *TagSQLRepository) Purge(ctx context.Context, tenant string, id string) error {
+	err := new(Tag).Purge(ctx, r.DB, tenant, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+// TagMemoryRepository is a TagRepository keeping records in memory, safe for
+// concurrent use. Records are copied on the way in and out.
+type TagMemoryRepository struct {
+	mu      sync.RWMutex
+	tenants map[string]map[string]*Tag
+	// deleted holds the records in the trash
+	deleted map[string]map[string]*Tag
+}
+
+// NewTagMemoryRepository returns an empty TagMemoryRepository
+func NewTagMemoryRepository() *TagMemoryRepository {
+	return &TagMemoryRepository{tenants: make(map[string]map[string]*Tag), deleted: make(map[string]map[string]*Tag)}
+}
+
+var _ TagRepository = (*TagMemoryRepository)(nil)
+
+// lookup returns the stored record, the lock has to be held
+func (r *TagMemoryRepository) lookup(tenant string, id string) (*Tag, error) {
+	x, ok := r.tenants[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+func (r *TagMemoryRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Tag, string], error) {
+	q, err := tagListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Tag, string], 0, len(r.tenants[tenant]))
+	for id, x := range r.tenants[tenant] {
+		records = append(records, dep.Record[*Tag, string]{ID: id, Value: proto.Clone(x).(*Tag)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+func (r *TagMemoryRepository) Get(ctx context.Context, tenant string, id string) (*Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(x).(*Tag), nil
+}
+
+func (r *TagMemoryRepository) Create(ctx context.Context, tenant string, data *Tag) (string, error) {
+	if err := data.Validate(); err != nil {
+		return "", err
+	}
+	id := data.Label
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tenants[tenant][id]; ok || r.deleted[tenant][id] != nil {
+		return "", &dep.AlreadyExistsError{Fields: []string{"label"}}
+	}
+	if r.tenants[tenant] == nil {
+		r.tenants[tenant] = make(map[string]*Tag)
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Tag)
+	return id, nil
+}
+
+func (r *TagMemoryRepository) Update(ctx context.Context, tenant string, id string, data *Tag) error {
+	data.Label = id
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.lookup(tenant, id)
+	if err != nil {
+		return err
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Tag)
+	return nil
+}
+
+func (r *TagMemoryRepository) Patch(ctx context.Context, tenant string, id string, data *Tag, mask *fieldmaskpb.FieldMask) (*Tag, error) {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return nil, err
+	}
+	data.Label = id
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	x = proto.Clone(x).(*Tag)
+	dep.ApplyFieldMask(x, data, paths)
+	if err := x.Validate(); err != nil {
+		return nil, err
+	}
+	r.tenants[tenant][id] = x
+	return proto.Clone(x).(*Tag), nil
+}
+
+func (r *TagMemoryRepository) Delete(ctx context.Context, tenant string, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return err
+	}
+	if r.deleted[tenant] == nil {
+		r.deleted[tenant] = make(map[string]*Tag)
+	}
+	r.deleted[tenant][id] = x
+	delete(r.tenants[tenant], id)
+	return nil
+}
+
+func (r *TagMemoryRepository) ListDeleted(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Tag, string], error) {
+	q, err := tagListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Tag, string], 0, len(r.deleted[tenant]))
+	for id, x := range r.deleted[tenant] {
+		records = append(records, dep.Record[*Tag, string]{ID: id, Value: proto.Clone(x).(*Tag)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+// trashed returns a record in the trash, the lock has to be held
+func (r *TagMemoryRepository) trashed(tenant string, id string) (*Tag, error) {
+	x, ok := r.deleted[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+func (r *TagMemoryRepository) Restore(ctx context.Context, tenant string, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.trashed(tenant, id)
+	if err != nil {
+		return err
+	}
+	if r.tenants[tenant] == nil {
+		r.tenants[tenant] = make(map[string]*Tag)
+	}
+	r.tenants[tenant][id] = x
+	delete(r.deleted[tenant], id)
+	return nil
+}
+
+func (r *TagMemoryRepository) Purge(ctx context.Context, tenant string, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.trashed(tenant, id); err != nil {
+		return err
+	}
+	delete(r.deleted[tenant], id)
+	return nil
+}
+
+// ListHandler renders the page of objects selected by the page_size, page_token,
+// skip, filter and order_by query parameters
+func (h *TagHandler) ListHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.List(req.Context(), h.tenant(req), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// ListDeletedHandler renders the page of objects in the trash selected by the
+// page_size, page_token, skip, filter and order_by query parameters
+func (h *TagHandler) ListDeletedHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.ListDeleted(req.Context(), h.tenant(req), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// GetHandler renders the object at the {id} url parameter
+func (h *TagHandler) GetHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// CreateHandler stores the object sent in the request body, the Location header
+// of the response names its url
+func (h *TagHandler) CreateHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Tag)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.Repo.Create(req.Context(), h.tenant(req), x)
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", path.Join(req.URL.Path, fmt.Sprint(id)))
+	h.render(w, req, http.StatusCreated, x)
+}
+
+// UpdateHandler replaces the object at the {id} url parameter with the request body
+func (h *TagHandler) UpdateHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Tag)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.Repo.Update(req.Context(), h.tenant(req), id, x)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// PatchHandler stores the fields of the request body named by its mask in the object
+// at the {id} url parameter and renders the result
+func (h *TagHandler) PatchHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Tag)
+	mask, err := h.decodePatch(req, x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.Patch(req.Context(), h.tenant(req), id, x, mask)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	var invalid dep.ValidationErrors
+	if errors.Is(err, dep.ErrInvalidArgument) || errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, ret)
+}
+
+// DeleteHandler moves the object at the {id} url parameter to the trash
+func (h *TagHandler) DeleteHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	err := h.Repo.Delete(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreHandler takes the object at the {id} url parameter back out of the trash
+func (h *TagHandler) RestoreHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	err := h.Repo.Restore(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PurgeHandler deletes the object at the {id} url parameter from the trash for good
+func (h *TagHandler) PurgeHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	err := h.Repo.Purge(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FormHandler renders the form for a new object, or for editing the object at the {id} url parameter
+func (h *TagHandler) FormHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Tag)
+	if chi.URLParam(req, "id") != "" {
+		id, ok := h.id(req)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		found, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+		if errors.Is(err, dep.ErrNotFound) {
+			http.NotFound(w, req)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		x = found
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := x.RenderForm(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// alreadyExists answers a write that found unique fields of x taken with a 409,
+// htmx requests get the form back with the fields marked
+func (h *TagHandler) alreadyExists(w http.ResponseWriter, req *http.Request, x *Tag, err error) {
+	if req.Header.Get("HX-Request") != "true" {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	var errs dep.ValidationErrors
+	var exists *dep.AlreadyExistsError
+	if errors.As(err, &exists) {
+		errs = exists.ValidationErrors()
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusConflict)
+	x.RenderFormErrors(w, errs)
+}
+
+// decode reads the object from a protojson body, skipping unknown fields, or from
+// a submitted form
+func (h *TagHandler) decode(req *http.Request, x *Tag) error {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		if _, err := x.HandleForm(req); err != nil {
+			return err
+		}
+		// HandleForm only validates the fields the form submitted
+		return x.Validate()
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, x); err != nil {
+		return err
+	}
+
+	return x.Validate()
+}
+
+// decodePatch reads the fields to patch from a submitted form, masking the ones it
+// holds, or from a protojson body masked by the update_mask query parameter or by
+// the fields present in the body, which may not name unknown fields
+func (h *TagHandler) decodePatch(req *http.Request, x *Tag) (*fieldmaskpb.FieldMask, error) {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		return x.HandleForm(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(body, x); err != nil {
+		return nil, err
+	}
+
+	return dep.ParseFieldMask(req.URL.Query(), body)
+}
+
+// render writes the object as protojson, or as html to htmx requests
+func (h *TagHandler) render(w http.ResponseWriter, req *http.Request, status int, x *Tag) {
+	if req.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		x.RenderView(w)
+		return
+	}
+
+	jsonData, err := protojson.Marshal(x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
+
+// Routes returns a chi.Router with the Tag endpoints that can be mounted to a parent router
+func (h *TagHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListHandler)
+	r.Post("/", h.CreateHandler)
+	r.Get("/new", h.FormHandler)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.GetHandler)
+		r.Put("/", h.UpdateHandler)
+		r.Patch("/", h.PatchHandler)
+		r.Get("/edit", h.FormHandler)
+		r.Delete("/", h.DeleteHandler)
+	})
+	r.Get("/deleted", h.ListDeletedHandler)
+	r.Route("/deleted/{id}", func(r chi.Router) {
+		r.Post("/restore", h.RestoreHandler)
+		r.Delete("/", h.PurgeHandler)
+	})
+
+	return r
+}
+
+// A simple function to handle a htmx form and populate the struct, returning
+// the mask of the fields the form submitted. Values that fail to parse are
+// collected per field before the submitted fields are validated.
+func (x *Tag) HandleForm(req *http.Request) (*fieldmaskpb.FieldMask, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	errs := make(dep.ValidationErrors)
+	x.Label = req.FormValue("Tag__Label")
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	mask := new(fieldmaskpb.FieldMask)
+	if dep.FormHas(req.Form, "Tag__Label") {
+		mask.Paths = append(mask.Paths, "label")
+	}
+	return mask, dep.MaskedErrors(x.Validate(), mask.Paths)
+}
+
+var tagViewTemplate = template.Must(template.New("view").Parse(`
+<p class="w-16">
+  <span>Label</span>
+  <span> {{ .Label }} </span>
+</p>
+`))
+
+// RenderView will take in a http writer and object to render the view
+func (x *Tag) RenderView(w http.ResponseWriter) error {
+	return tagViewTemplate.Execute(w, x)
+}
+
+var tagFormTemplate = template.Must(template.New("form").Parse(`{{ with .Value }}
+<label class="w-16">
+  <span>Label</span>
+  <input type="text" name="Tag__Label" value="{{ .Label }}" required>
+  {{ with index $.Errors "label" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+{{ end }}`))
+
+// RenderForm will take in a http writer and render a htmx form for the object
+func (x *Tag) RenderForm(w http.ResponseWriter) error {
+	return x.RenderFormErrors(w, nil)
+}
+
+// RenderFormErrors renders the htmx form for the object with errs shown next to
+// the inputs of their fields
+func (x *Tag) RenderFormErrors(w http.ResponseWriter, errs dep.ValidationErrors) error {
+	return tagFormTemplate.Execute(w, dep.FormData{Value: x, Errors: errs})
+}
+
+// Validate checks the constraints declared on the fields of Tag
+func (x *Tag) Validate() error {
+	errs := make(dep.ValidationErrors)
+	if x.Label == "" {
+		errs.Add("label", "is required")
+	}
+	switch x.Label {
+	case "new", "deleted":
+		errs.Add("label", "is reserved")
+	}
+	if strings.Contains(x.Label, "/") {
+		errs.Add("label", "cannot contain /")
+	}
+	return errs.Err()
+}
+
+// TableName returns the name of the table backing Tag
+func (*Tag) TableName() string {
+	return "tag"
+}
+
+// Scan implements sql.Scanner, reading x from the protojson document in a
+// JSONB column. Unknown fields are discarded so removed fields do not break reads.
+func (x *Tag) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		proto.Reset(x)
+		return nil
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into Tag", src)
+	}
+
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, x)
+}
+
+// Value implements driver.Valuer, storing x as a protojson document
+func (x *Tag) Value() (driver.Value, error) {
+	if x == nil {
+		return nil, nil
+	}
+
+	data, err := protojson.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drivers send []byte as bytea, JSONB wants text.
+	return string(data), nil
+}
+
+// AccountHandler serves the http routes of Account
+type AccountHandler struct {
+	Repo AccountRepository
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// NewAccountHandler returns a AccountHandler backed by repo
+func NewAccountHandler(repo AccountRepository) *AccountHandler {
+	return &AccountHandler{Repo: repo}
+}
+
+func (h *AccountHandler) tenant(req *http.Request) string {
+	if h.Tenant != nil {
+		return h.Tenant(req)
+	}
+	return chi.URLParam(req, "tenant")
+}
+
+// id reads the {id} url parameter, false when it holds no valid id
+func (h *AccountHandler) id(req *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	return id, err == nil
+}
+
+// Statements reading Account, its writes call the routines of the schema
+const (
+	accountCountQuery               = "SELECT count(*) FROM account WHERE tenant = $1"
+	accountListQuery                = "SELECT id, data FROM account WHERE tenant = $1"
+	accountGetQuery                 = "SELECT data FROM account WHERE tenant = $1 AND id = $2"
+	accountGetByNameQuery           = "SELECT id, data FROM account WHERE tenant = $1 AND NULLIF(COALESCE(data->>'name', ''), '') = $2"
+	accountGetByStatusAndSeatsQuery = "SELECT id, data FROM account WHERE tenant = $1 AND NULLIF(COALESCE(data->>'status', 'STATUS_UNSPECIFIED'), 'STATUS_UNSPECIFIED') = $2 AND NULLIF(COALESCE((data->>'seats')::numeric, 0), 0) = $3"
+)
+
+// accountUniqueKeys are the unique indexes of Account, for dep.AlreadyExists
+var accountUniqueKeys = []dep.UniqueKey{
+	{
+		Index:  "account_name_key",
+		Fields: []string{"name"},
+	},
+	{
+		Index:  "account_status_seats_key",
+		Fields: []string{"status", "seats"},
+	},
+}
+
+// accountListSchema holds the fields List can filter and order by
+var accountListSchema = &dep.Schema{
+	Message: new(Account),
+	Dialect: dep.Postgres,
+	Fields: []dep.ListField{
+		{Name: "balance", Expr: "COALESCE((data->>'balance')::numeric, 0)", Filter: true, Sort: true},
+		{Name: "status", Expr: "COALESCE(data->>'status', 'STATUS_UNSPECIFIED')", Filter: true, Sort: false},
+		{Name: "renewed_at", Expr: "COALESCE((data->>'renewedAt')::timestamptz, to_timestamp(0))", Filter: false, Sort: true},
+		{Name: "discount", Expr: "COALESCE((data->>'discount')::numeric, 0)", Filter: true, Sort: false},
+	},
+	ID: uuid.Nil,
+}
+
+// List function returns the page of these objects opts selects
+func (x *Account) List(ctx context.Context, db DBTX, tenant string, opts dep.ListOptions) (*dep.Page[*Account, uuid.UUID], error) {
+	q, err := accountListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Account, uuid.UUID])
+	query, args := q.Count(accountCountQuery, tenant)
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(accountListQuery, tenant)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		row := new(Account)
+		var id uuid.UUID
+
+		err := rows.Scan(&id, row)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Items = append(ret.Items, dep.Record[*Account, uuid.UUID]{ID: id, Value: row})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// Get function acquires a single record based on ID in database
+func (x *Account) Get(ctx context.Context, db DBTX, tenant string, id uuid.UUID) error {
+	return db.QueryRowContext(ctx, accountGetQuery, tenant, id).Scan(x)
+}
+
+// GetByName function acquires the record holding the given name
+// into x and returns its ID
+func (x *Account) GetByName(ctx context.Context, db DBTX, tenant string, name string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := db.QueryRowContext(ctx, accountGetByNameQuery, tenant, name).Scan(&id, x)
+	return id, err
+}
+
+// GetByStatusAndSeats function acquires the record holding the given status and seats
+// into x and returns its ID
+func (x *Account) GetByStatusAndSeats(ctx context.Context, db DBTX, tenant string, status Status, seats int32) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := db.QueryRowContext(ctx, accountGetByStatusAndSeatsQuery, tenant, status.String(), seats).Scan(&id, x)
+	return id, err
+}
+
+// Create function will create a new object of this type and return its ID
+func (x *Account) Create(ctx context.Context, db DBTX, tenant string, data *Account) (uuid.UUID, error) {
+	if err := data.Validate(); err != nil {
+		return uuid.Nil, err
+	}
+
+	id := uuid.New()
+	_, err := db.ExecContext(ctx, "SELECT create_data($1, $2, $3::uuid, $4)", tenant, x.TableName(), id, data)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+// Update function will replace the object stored at the given ID
+func (x *Account) Update(ctx context.Context, db DBTX, tenant string, id uuid.UUID, data *Account) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	var found uuid.UUID
+	return db.QueryRowContext(ctx, "SELECT id FROM update_data($1, $2, $3::uuid, $4) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id, data).Scan(&found)
+}
+
+// Patch function stores the fields of data named by mask in the object at the
+// given ID, leaving the others as they are, and reads the result into x
+// The result is validated as a whole, the patch is rolled back when it is not valid.
+func (x *Account) Patch(ctx context.Context, db DBTX, tenant string, id uuid.UUID, data *Account, mask *fieldmaskpb.FieldMask) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.patch(ctx, tx, tenant, id, data, mask)
+	if err == nil {
+		err = x.Validate()
+	}
+	return end(err)
+}
+
+// patch makes the writes of Patch in the transaction it began
+func (x *Account) patch(ctx context.Context, db DBTX, tenant string, id uuid.UUID, data *Account, mask *fieldmaskpb.FieldMask) error {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return err
+	}
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return err
+	}
+
+	// The first patch removes the masked fields, the second stores the ones
+	// data has, so messages, lists and maps are replaced rather than merged.
+	remove, store, err := dep.MergePatches(data, paths)
+	if err != nil {
+		return err
+	}
+
+	return db.QueryRowContext(ctx, "SELECT data FROM patch_data($1, $2, $3::uuid, $4, $5) AS data WHERE data IS NOT NULL",
+		tenant, x.TableName(), id, remove, store).Scan(x)
+}
+
+// Delete function will... well delete the object at given ID
+func (x *Account) Delete(ctx context.Context, db DBTX, tenant string, id uuid.UUID) error {
+	var found uuid.UUID
+	return db.QueryRowContext(ctx, "SELECT id FROM delete_data_by_id($1, $2, $3::uuid) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id).Scan(&found)
+}
+
+// AccountRepository stores Account records. Get, Update, Patch and Delete return
+// dep.ErrNotFound for unknown ids, Patch returns the record as stored.
+//
+// The writes fail with dep.ErrAlreadyExists, an *dep.AlreadyExistsError naming the
+// fields, when another record of the tenant holds the values of a unique index.
+// The GetBy methods return the record holding them, dep.ErrNotFound for none.
+type AccountRepository interface {
+	List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Account, uuid.UUID], error)
+	Get(ctx context.Context, tenant string, id uuid.UUID) (*Account, error)
+	GetByName(ctx context.Context, tenant string, name string) (*Account, uuid.UUID, error)
+	GetByStatusAndSeats(ctx context.Context, tenant string, status Status, seats int32) (*Account, uuid.UUID, error)
+	Create(ctx context.Context, tenant string, data *Account) (uuid.UUID, error)
+	Update(ctx context.Context, tenant string, id uuid.UUID, data *Account) error
+	Patch(ctx context.Context, tenant string, id uuid.UUID, data *Account, mask *fieldmaskpb.FieldMask) (*Account, error)
+	Delete(ctx context.Context, tenant string, id uuid.UUID) error
+}
+
+// AccountSQLRepository is the AccountRepository backed by the Account persistence methods
+type AccountSQLRepository struct {
+	DB DBTX
+}
+
+// NewAccountSQLRepository returns a AccountSQLRepository using db
+func NewAccountSQLRepository(db DBTX) *AccountSQLRepository {
+	return &AccountSQLRepository{DB: db}
+}
+
+var _ AccountRepository = (*AccountSQLRepository)(nil)
+
+func (r *AccountSQLRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Account, uuid.UUID], error) {
+	return new(Account).List(ctx, r.DB, tenant, opts)
+}
+
+func (r *AccountSQLRepository) Get(ctx context.Context, tenant string, id uuid.UUID) (*Account, error) {
+	x := new(Account)
+	err := x.Get(ctx, r.DB, tenant, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func (r *AccountSQLRepository) GetByName(ctx context.Context, tenant string, name string) (*Account, uuid.UUID, error) {
+	x := new(Account)
+	id, err := x.GetByName(ctx, r.DB, tenant, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, uuid.Nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	return x, id, nil
+}
+
+func (r *AccountSQLRepository) GetByStatusAndSeats(ctx context.Context, tenant string, status Status, seats int32) (*Account, uuid.UUID, error) {
+	x := new(Account)
+	id, err := x.GetByStatusAndSeats(ctx, r.DB, tenant, status, seats)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, uuid.Nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	return x, id, nil
+}
+
+func (r *AccountSQLRepository) Create(ctx context.Context, tenant string, data *Account) (uuid.UUID, error) {
+	id, err := data.Create(ctx, r.DB, tenant, data)
+	return id, dep.AlreadyExists(err, accountUniqueKeys...)
+}
+
+func (r *AccountSQLRepository) Update(ctx context.Context, tenant string, id uuid.UUID, data *Account) error {
+	err := data.Update(ctx, r.DB, tenant, id, data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return dep.AlreadyExists(err, accountUniqueKeys...)
+}
+
+func (r *AccountSQLRepository) Patch(ctx context.Context, tenant string, id uuid.UUID, data *Account, mask *fieldmaskpb.FieldMask) (*Account, error) {
+	x := new(Account)
+	err := x.Patch(ctx, r.DB, tenant, id, data, mask)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, dep.AlreadyExists(err, accountUniqueKeys...)
+	}
+
+	return x, nil
+}
+
+func (r *AccountSQLRepository) Delete(ctx context.Context, tenant string, id uuid.UUID) error {
+	err := new(Account).Delete(ctx, r.DB, tenant, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+// AccountMemoryRepository is a AccountRepository keeping records in memory, safe for
+// concurrent use. Records are copied on the way in and out.
+type AccountMemoryRepository struct {
+	mu      sync.RWMutex
+	tenants map[string]map[uuid.UUID]*Account
+}
+
+// NewAccountMemoryRepository returns an empty AccountMemoryRepository
+func NewAccountMemoryRepository() *AccountMemoryRepository {
+	return &AccountMemoryRepository{tenants: make(map[string]map[uuid.UUID]*Account)}
+}
+
+var _ AccountRepository = (*AccountMemoryRepository)(nil)
+
+// lookup returns the stored record, the lock has to be held
+func (r *AccountMemoryRepository) lookup(tenant string, id uuid.UUID) (*Account, error) {
+	x, ok := r.tenants[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+func (r *AccountMemoryRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Account, uuid.UUID], error) {
+	q, err := accountListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Account, uuid.UUID], 0, len(r.tenants[tenant]))
+	for id, x := range r.tenants[tenant] {
+		records = append(records, dep.Record[*Account, uuid.UUID]{ID: id, Value: proto.Clone(x).(*Account)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+func (r *AccountMemoryRepository) Get(ctx context.Context, tenant string, id uuid.UUID) (*Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(x).(*Account), nil
+}
+
+func (r *AccountMemoryRepository) GetByName(ctx context.Context, tenant string, name string) (*Account, uuid.UUID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for id, x := range r.tenants[tenant] {
+		if name != "" && x.GetName() == name {
+			return proto.Clone(x).(*Account), id, nil
+		}
+	}
+	return nil, uuid.Nil, dep.ErrNotFound
+}
+
+func (r *AccountMemoryRepository) GetByStatusAndSeats(ctx context.Context, tenant string, status Status, seats int32) (*Account, uuid.UUID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for id, x := range r.tenants[tenant] {
+		if status != 0 && seats != 0 && x.GetStatus() == status && x.GetSeats() == seats {
+			return proto.Clone(x).(*Account), id, nil
+		}
+	}
+	return nil, uuid.Nil, dep.ErrNotFound
+}
+
+// taken fails with dep.ErrAlreadyExists when a record other than the one at id
+// holds the unique fields of x, the lock has to be held. Zero values are not
+// taken, like the NULLs of the SQL indexes.
+func (r *AccountMemoryRepository) taken(tenant string, id uuid.UUID, x *Account) error {
+	for other, y := range r.tenants[tenant] {
+		if other == id {
+			continue
+		}
+		if x.GetName() != "" && x.GetName() == y.GetName() {
+			return &dep.AlreadyExistsError{Fields: []string{"name"}}
+		}
+		if x.GetStatus() != 0 && x.GetSeats() != 0 && x.GetStatus() == y.GetStatus() && x.GetSeats() == y.GetSeats() {
+			return &dep.AlreadyExistsError{Fields: []string{"status", "seats"}}
+		}
+	}
+	return nil
+}
+
+func (r *AccountMemoryRepository) Create(ctx context.Context, tenant string, data *Account) (uuid.UUID, error) {
+	if err := data.Validate(); err != nil {
+		return uuid.Nil, err
+	}
+	id := uuid.New()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.taken(tenant, id, data); err != nil {
+		return uuid.Nil, err
+	}
+	if r.tenants[tenant] == nil {
+		r.tenants[tenant] = make(map[uuid.UUID]*Account)
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Account)
+	return id, nil
+}
+
+func (r *AccountMemoryRepository) Update(ctx context.Context, tenant string, id uuid.UUID, data *Account) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.lookup(tenant, id)
+	if err != nil {
+		return err
+	}
+	if err := r.taken(tenant, id, data); err != nil {
+		return err
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Account)
+	return nil
+}
+
+func (r *AccountMemoryRepository) Patch(ctx context.Context, tenant string, id uuid.UUID, data *Account, mask *fieldmaskpb.FieldMask) (*Account, error) {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return nil, err
+	}
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	x = proto.Clone(x).(*Account)
+	dep.ApplyFieldMask(x, data, paths)
+	if err := x.Validate(); err != nil {
+		return nil, err
+	}
+	if err := r.taken(tenant, id, x); err != nil {
+		return nil, err
+	}
+	r.tenants[tenant][id] = x
+	return proto.Clone(x).(*Account), nil
+}
+
+func (r *AccountMemoryRepository) Delete(ctx context.Context, tenant string, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.lookup(tenant, id); err != nil {
+		return err
+	}
+	delete(r.tenants[tenant], id)
+	return nil
+}
+
+// ListHandler renders the page of objects selected by the page_size, page_token,
+// skip, filter and order_by query parameters
+func (h *AccountHandler) ListHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.List(req.Context(), h.tenant(req), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// GetHandler renders the object at the {id} url parameter
+func (h *AccountHandler) GetHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// CreateHandler stores the object sent in the request body, the Location header
+// of the response names its url
+func (h *AccountHandler) CreateHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Account)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.Repo.Create(req.Context(), h.tenant(req), x)
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", path.Join(req.URL.Path, fmt.Sprint(id)))
+	h.render(w, req, http.StatusCreated, x)
+}
+
+// UpdateHandler replaces the object at the {id} url parameter with the request body
+func (h *AccountHandler) UpdateHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Account)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.Repo.Update(req.Context(), h.tenant(req), id, x)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// PatchHandler stores the fields of the request body named by its mask in the object
+// at the {id} url parameter and renders the result
+func (h *AccountHandler) PatchHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Account)
+	mask, err := h.decodePatch(req, x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.Patch(req.Context(), h.tenant(req), id, x, mask)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	var invalid dep.ValidationErrors
+	if errors.Is(err, dep.ErrInvalidArgument) || errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, ret)
+}
+
+// DeleteHandler deletes the object at the {id} url parameter
+func (h *AccountHandler) DeleteHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	err := h.Repo.Delete(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FormHandler renders the form for a new object, or for editing the object at the {id} url parameter
+func (h *AccountHandler) FormHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Account)
+	if chi.URLParam(req, "id") != "" {
+		id, ok := h.id(req)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		found, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+		if errors.Is(err, dep.ErrNotFound) {
+			http.NotFound(w, req)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		x = found
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := x.RenderForm(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// alreadyExists answers a write that found unique fields of x taken with a 409,
+// htmx requests get the form back with the fields marked
+func (h *AccountHandler) alreadyExists(w http.ResponseWriter, req *http.Request, x *Account, err error) {
+	if req.Header.Get("HX-Request") != "true" {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	var errs dep.ValidationErrors
+	var exists *dep.AlreadyExistsError
+	if errors.As(err, &exists) {
+		errs = exists.ValidationErrors()
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusConflict)
+	x.RenderFormErrors(w, errs)
+}
+
+// decode reads the object from a protojson body, skipping unknown fields, or from
+// a submitted form
+func (h *AccountHandler) decode(req *http.Request, x *Account) error {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		if _, err := x.HandleForm(req); err != nil {
+			return err
+		}
+		// HandleForm only validates the fields the form submitted
+		return x.Validate()
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, x); err != nil {
+		return err
+	}
+
+	return x.Validate()
+}
+
+// decodePatch reads the fields to patch from a submitted form, masking the ones it
+// holds, or from a protojson body masked by the update_mask query parameter or by
+// the fields present in the body, which may not name unknown fields
+func (h *AccountHandler) decodePatch(req *http.Request, x *Account) (*fieldmaskpb.FieldMask, error) {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		return x.HandleForm(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(body, x); err != nil {
+		return nil, err
+	}
+
+	return dep.ParseFieldMask(req.URL.Query(), body)
+}
+
+// render writes the object as protojson, or as html to htmx requests
+func (h *AccountHandler) render(w http.ResponseWriter, req *http.Request, status int, x *Account) {
+	if req.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		x.RenderView(w)
+		return
+	}
+
+	jsonData, err := protojson.Marshal(x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
+
+// Routes returns a chi.Router with the Account endpoints that can be mounted to a parent router
+func (h *AccountHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListHandler)
+	r.Post("/", h.CreateHandler)
+	r.Get("/new", h.FormHandler)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.GetHandler)
+		r.Put("/", h.UpdateHandler)
+		r.Patch("/", h.PatchHandler)
+		r.Get("/edit", h.FormHandler)
+		r.Delete("/", h.DeleteHandler)
+	})
+
+	return r
+}
+
+// A simple function to handle a htmx form and populate the struct, returning
+// the mask of the fields the form submitted. Values that fail to parse are
+// collected per field before the submitted fields are validated.
+func (x *Account) HandleForm(req *http.Request) (*fieldmaskpb.FieldMask, error) {
+	if err := req.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return nil, err
+	}
+
+	errs := make(dep.ValidationErrors)
+	x.Name = req.FormValue("Account__Name")
+	if v := req.FormValue("Account__Seats"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 32); err != nil {
+			errs.Add("seats", "must be a whole number")
+		} else {
+			value := int32(n)
+			x.Seats = value
+		}
+	}
+	if v := req.FormValue("Account__Balance"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err != nil {
+			errs.Add("balance", "must be a whole number")
+		} else {
+			value := int64(n)
+			x.Balance = value
+		}
+	}
+	if v := req.FormValue("Account__Quota"); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 32); err != nil {
+			errs.Add("quota", "must be a positive whole number")
+		} else {
+			value := uint32(n)
+			x.Quota = value
+		}
+	}
+	if v := req.FormValue("Account__Ratio"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err != nil {
+			errs.Add("ratio", "must be a number")
+		} else {
+			value := float64(n)
+			x.Ratio = value
+		}
+	}
+	{
+		v := req.FormValue("Account__Active")
+		value := v != "" && v != "false" && v != "off" && v != "0"
+		x.Active = value
+	}
+	if v := req.FormValue("Account__Status"); v != "" {
+		// Either the name or the number of a defined enum value
+		if n, ok := Status_value[v]; ok {
+			value := Status(n)
+			x.Status = value
+		} else if n, err := strconv.ParseInt(v, 10, 32); err == nil && Status_name[int32(n)] != "" {
+			value := Status(n)
+			x.Status = value
+		} else {
+			errs.Add("status", "must be one of the defined values")
+		}
+	}
+	if file, _, err := req.FormFile("Account__Avatar"); err == nil {
+		value, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			errs.Add("avatar", "could not be read")
+		} else {
+			x.Avatar = value
+		}
+	} else if v := req.FormValue("Account__Avatar"); v != "" {
+		if value, err := base64.StdEncoding.DecodeString(v); err != nil {
+			errs.Add("avatar", "must be base64 encoded")
+		} else {
+			x.Avatar = value
+		}
+	}
+	if _, ok := req.Form["Account__Tags"]; ok {
+		x.Tags = nil
+		// One item per line
+		for _, v := range strings.Split(req.FormValue("Account__Tags"), "\n") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			value := v
+			x.Tags = append(x.Tags, value)
+		}
+	}
+	for key := range req.Form {
+		if strings.HasPrefix(key, "Account__Address.") {
+			if x.Address == nil {
+				x.Address = new(Address)
+			}
+			break
+		}
+	}
+	if x.Address != nil {
+		x.Address.Street = req.FormValue("Account__Address.Street")
+		x.Address.City = req.FormValue("Account__Address.City")
+	}
+	if v := req.FormValue("Account__Nickname"); v != "" {
+		value := v
+		x.Nickname = &value
+	}
+	if v := req.FormValue("Account__Phone"); v != "" {
+		value := v
+		x.Contact = &Account_Phone{Phone: value}
+	}
+	if v := req.FormValue("Account__RenewedAt"); v != "" {
+		var parsed bool
+		for _, layout := range []string{"2006-01-02T15:04:05Z07:00", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				value := timestamppb.New(t)
+				x.RenewedAt = value
+				parsed = true
+				break
+			}
+		}
+		if !parsed {
+			errs.Add("renewed_at", "must be a date")
+		}
+	}
+	if v := req.FormValue("Account__StartedOn"); v != "" {
+		var parsed bool
+		for _, layout := range []string{"2006-01-02T15:04:05Z07:00", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				value := timestamppb.New(t)
+				x.StartedOn = value
+				parsed = true
+				break
+			}
+		}
+		if !parsed {
+			errs.Add("started_on", "must be a date")
+		}
+	}
+	if _, ok := req.Form["Account__Scores"]; ok {
+		x.Scores = nil
+		// One item per line
+		for _, v := range strings.Split(req.FormValue("Account__Scores"), "\n") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			if n, err := strconv.ParseInt(v, 10, 64); err != nil {
+				errs.Add("scores", "must be a whole number")
+			} else {
+				value := int64(n)
+				x.Scores = append(x.Scores, value)
+			}
+		}
+	}
+	if v := req.FormValue("Account__Discount"); v != "" {
+		if n, err := strconv.ParseFloat(v, 32); err != nil {
+			errs.Add("discount", "must be a number")
+		} else {
+			value := float32(n)
+			x.Discount = &value
+		}
+	}
+	if _, ok := req.Form["Account__History"]; ok {
+		x.History = nil
+		for _, v := range req.Form["Account__History"] {
+			// Either the name or the number of a defined enum value
+			if n, ok := Status_value[v]; ok {
+				value := Status(n)
+				x.History = append(x.History, value)
+			} else if n, err := strconv.ParseInt(v, 10, 32); err == nil && Status_name[int32(n)] != "" {
+				value := Status(n)
+				x.History = append(x.History, value)
+			} else {
+				errs.Add("history", "must be one of the defined values")
+			}
+		}
+	}
+	x.Pin = req.FormValue("Account__Pin")
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	mask := new(fieldmaskpb.FieldMask)
+	if dep.FormHas(req.Form, "Account__Name") {
+		mask.Paths = append(mask.Paths, "name")
+	}
+	if dep.FormHas(req.Form, "Account__Seats") {
+		mask.Paths = append(mask.Paths, "seats")
+	}
+	if dep.FormHas(req.Form, "Account__Balance") {
+		mask.Paths = append(mask.Paths, "balance")
+	}
+	if dep.FormHas(req.Form, "Account__Quota") {
+		mask.Paths = append(mask.Paths, "quota")
+	}
+	if dep.FormHas(req.Form, "Account__Ratio") {
+		mask.Paths = append(mask.Paths, "ratio")
+	}
+	if dep.FormHas(req.Form, "Account__Active") {
+		mask.Paths = append(mask.Paths, "active")
+	}
+	if dep.FormHas(req.Form, "Account__Status") {
+		mask.Paths = append(mask.Paths, "status")
+	}
+	if req.FormValue("Account__Avatar") != "" || req.MultipartForm != nil && len(req.MultipartForm.File["Account__Avatar"]) > 0 {
+		mask.Paths = append(mask.Paths, "avatar")
+	}
+	if dep.FormHas(req.Form, "Account__Tags") {
+		mask.Paths = append(mask.Paths, "tags")
+	}
+	if dep.FormHas(req.Form, "Account__Address") {
+		mask.Paths = append(mask.Paths, "address")
+	}
+	if dep.FormHas(req.Form, "Account__Nickname") {
+		mask.Paths = append(mask.Paths, "nickname")
+	}
+	if dep.FormHas(req.Form, "Account__Phone") {
+		mask.Paths = append(mask.Paths, "phone")
+	}
+	if dep.FormHas(req.Form, "Account__RenewedAt") {
+		mask.Paths = append(mask.Paths, "renewed_at")
+	}
+	if dep.FormHas(req.Form, "Account__StartedOn") {
+		mask.Paths = append(mask.Paths, "started_on")
+	}
+	if dep.FormHas(req.Form, "Account__Scores") {
+		mask.Paths = append(mask.Paths, "scores")
+	}
+	if dep.FormHas(req.Form, "Account__Discount") {
+		mask.Paths = append(mask.Paths, "discount")
+	}
+	if dep.FormHas(req.Form, "Account__History") {
+		mask.Paths = append(mask.Paths, "history")
+	}
+	if req.FormValue("Account__Pin") != "" {
+		mask.Paths = append(mask.Paths, "pin")
+	}
+	return mask, dep.MaskedErrors(x.Validate(), mask.Paths)
+}
+
+var accountViewTemplate = template.Must(template.New("view").Parse(`
+<p class="w-16">
+  <span>Id</span>
+  <span> {{ .Id }} </span>
+</p>
+<p class="w-16">
+  <span>Name</span>
+  <span> {{ .Name }} </span>
+</p>
+<p class="w-16">
+  <span>Seats</span>
+  <span> {{ .Seats }} </span>
+</p>
+<p class="w-16">
+  <span>Balance</span>
+  <span> {{ .Balance }} </span>
+</p>
+<p class="w-16">
+  <span>Quota</span>
+  <span> {{ .Quota }} </span>
+</p>
+<p class="w-16">
+  <span>Ratio</span>
+  <span> {{ .Ratio }} </span>
+</p>
+<p class="w-16">
+  <span>Active</span>
+  <span> {{ .Active }} </span>
+</p>
+<p class="w-16">
+  <span>Status</span>
+  <span> {{ .Status }} </span>
+</p>
+<p class="w-16">
+  <span>Avatar</span>
+  <span> {{ .Avatar }} </span>
+</p>
+<p class="w-16">
+  <span>Tags</span>
+  <span> {{ .Tags }} </span>
+</p>
+<p class="w-16">
+  <span>Address</span>
+  <span> {{ .Address }} </span>
+</p>
+<p class="w-16">
+  <span>Nickname</span>
+  <span> {{ .GetNickname }} </span>
+</p>
+<p class="w-16">
+  <span>Phone</span>
+  <span> {{ .GetPhone }} </span>
+</p>
+<p class="w-16">
+  <span>Office</span>
+  <span> {{ .GetOffice }} </span>
+</p>
+<p class="w-16">
+  <span>Labels</span>
+  <span> {{ .Labels }} </span>
+</p>
+<p class="w-16">
+  <span>RenewedAt</span>
+  <span> {{ with .RenewedAt }}{{ .AsTime.Format "2006-01-02 15:04" }}{{ end }} </span>
+</p>
+<p class="w-16">
+  <span>StartedOn</span>
+  <span> {{ with .StartedOn }}{{ .AsTime.Format "2006-01-02 15:04" }}{{ end }} </span>
+</p>
+<p class="w-16">
+  <span>Scores</span>
+  <span> {{ .Scores }} </span>
+</p>
+<p class="w-16">
+  <span>Discount</span>
+  <span> {{ .GetDiscount }} </span>
+</p>
+<p class="w-16">
+  <span>History</span>
+  <span> {{ .History }} </span>
+</p>
+`))
+
+// RenderView will take in a http writer and object to render the view
+func (x *Account) RenderView(w http.ResponseWriter) error {
+	return accountViewTemplate.Execute(w, x)
+}
+
+var accountFormTemplate = template.Must(template.New("form").Parse(`{{ with .Value }}
+<label class="w-16">
+  <span>Id</span>
+  <input type="text" name="Account__Id" value="{{ .Id }}" disabled>
+  {{ with index $.Errors "id" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Name</span>
+  <input type="text" name="Account__Name" value="{{ .Name }}" required>
+  {{ with index $.Errors "name" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Seats</span>
+  <input type="number" name="Account__Seats" value="{{ .Seats }}" required>
+  {{ with index $.Errors "seats" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Balance</span>
+  <input type="number" name="Account__Balance" value="{{ .Balance }}">
+  {{ with index $.Errors "balance" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Quota</span>
+  <input type="number" name="Account__Quota" value="{{ .Quota }}">
+  {{ with index $.Errors "quota" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Ratio</span>
+  <input type="number" name="Account__Ratio" value="{{ .Ratio }}">
+  {{ with index $.Errors "ratio" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Active</span>
+  <input type="checkbox" name="Account__Active" value="on"{{ if .Active }} checked{{ end }} required>
+  <input type="hidden" name="Account__Active" value="off">
+  {{ with index $.Errors "active" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Status</span>
+  <select name="Account__Status" required>
+    <option value="STATUS_UNSPECIFIED"{{ if eq (print .Status) "STATUS_UNSPECIFIED" }} selected{{ end }}>STATUS_UNSPECIFIED</option>
+    <option value="STATUS_ACTIVE"{{ if eq (print .Status) "STATUS_ACTIVE" }} selected{{ end }}>STATUS_ACTIVE</option>
+    <option value="STATUS_ARCHIVED"{{ if eq (print .Status) "STATUS_ARCHIVED" }} selected{{ end }}>STATUS_ARCHIVED</option>
+  </select>
+  {{ with index $.Errors "status" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Avatar</span>
+  <input type="file" name="Account__Avatar">
+  {{ with index $.Errors "avatar" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Tags</span>
+  <textarea name="Account__Tags" required>{{ range $i, $v := .Tags }}{{ if $i }}&#10;{{ end }}{{ $v }}{{ end }}</textarea>
+  {{ with index $.Errors "tags" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<fieldset>
+  <legend>Address</legend>
+<label class="w-16">
+  <span>Street</span>
+  <input type="text" name="Account__Address.Street" value="{{ with .Address }}{{ .Street }}{{ end }}">
+  {{ with index $.Errors "address.street" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>City</span>
+  <input type="text" name="Account__Address.City" value="{{ with .Address }}{{ .City }}{{ end }}">
+  {{ with index $.Errors "address.city" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+</fieldset>
+<label class="w-16">
+  <span>Nickname</span>
+  <input type="text" name="Account__Nickname" value="{{ .GetNickname }}" required>
+  {{ with index $.Errors "nickname" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Phone</span>
+  <input type="text" name="Account__Phone" value="{{ .GetPhone }}" required>
+  {{ with index $.Errors "phone" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>RenewedAt</span>
+  <input type="datetime-local" name="Account__RenewedAt" value="{{ with .RenewedAt }}{{ .AsTime.Format "2006-01-02T15:04:05" }}{{ end }}" step="1">
+  {{ with index $.Errors "renewed_at" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>StartedOn</span>
+  <input type="date" name="Account__StartedOn" value="{{ with .StartedOn }}{{ .AsTime.Format "2006-01-02" }}{{ end }}">
+  {{ with index $.Errors "started_on" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Scores</span>
+  <textarea name="Account__Scores">{{ range $i, $v := .Scores }}{{ if $i }}&#10;{{ end }}{{ $v }}{{ end }}</textarea>
+  {{ with index $.Errors "scores" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Discount</span>
+  <input type="number" name="Account__Discount" value="{{ .GetDiscount }}">
+  {{ with index $.Errors "discount" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>History</span>
+  <select name="Account__History" multiple>
+    <option value="STATUS_UNSPECIFIED"{{ range .History }}{{ if eq (print .) "STATUS_UNSPECIFIED" }} selected{{ end }}{{ end }}>STATUS_UNSPECIFIED</option>
+    <option value="STATUS_ACTIVE"{{ range .History }}{{ if eq (print .) "STATUS_ACTIVE" }} selected{{ end }}{{ end }}>STATUS_ACTIVE</option>
+    <option value="STATUS_ARCHIVED"{{ range .History }}{{ if eq (print .) "STATUS_ARCHIVED" }} selected{{ end }}{{ end }}>STATUS_ARCHIVED</option>
+  </select>
+  {{ with index $.Errors "history" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Pin</span>
+  <input type="password" name="Account__Pin">
+  {{ with index $.Errors "pin" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+{{ end }}`))
+
+// RenderForm will take in a http writer and render a htmx form for the object
+func (x *Account) RenderForm(w http.ResponseWriter) error {
+	return x.RenderFormErrors(w, nil)
+}
+
+// RenderFormErrors renders the htmx form for the object with errs shown next to
+// the inputs of their fields
+func (x *Account) RenderFormErrors(w http.ResponseWriter, errs dep.ValidationErrors) error {
+	return accountFormTemplate.Execute(w, dep.FormData{Value: x, Errors: errs})
+}
+
+// Validate checks the constraints declared on the fields of Account
+func (x *Account) Validate() error {
+	errs := make(dep.ValidationErrors)
+	if x.Name == "" {
+		errs.Add("name", "is required")
+	}
+	if x.Seats == 0 {
+		errs.Add("seats", "is required")
+	}
+	if !x.Active {
+		errs.Add("active", "is required")
+	}
+	if x.Status == 0 {
+		errs.Add("status", "is required")
+	}
+	if len(x.Tags) == 0 {
+		errs.Add("tags", "is required")
+	}
+	if x.Address == nil {
+		errs.Add("address", "is required")
+	}
+	if x.Nickname == nil {
+		errs.Add("nickname", "is required")
+	}
+	if x.GetPhone() == "" {
+		errs.Add("phone", "is required")
+	}
+	return errs.Err()
+}
+
+// TableName returns the name of the table backing Account
+func (*Account) TableName() string {
+	return "account"
+}
+
+// Scan implements sql.Scanner, reading x from the protojson document in a
+// JSONB column. Unknown fields are discarded so removed fields do not break reads.
+func (x *Account) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		proto.Reset(x)
+		return nil
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into Account", src)
+	}
+
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, x)
+}
+
+// Value implements driver.Valuer, storing x as a protojson document
+func (x *Account) Value() (driver.Value, error) {
+	if x == nil {
+		return nil, nil
+	}
+
+	data, err := protojson.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drivers send []byte as bytea, JSONB wants text.
+	return string(data), nil
+}
+
+// Deps holds what the handlers of the resources in options.proto need
+type Deps struct {
+	DB DBTX
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// RegisterAll mounts the routes of every resource in options.proto on r
+func RegisterAll(r chi.Router, deps Deps) {
+	r.Mount("/legacy", (&LegacyHandler{Repo: NewLegacySQLRepository(deps.DB), Tenant: deps.Tenant}).Routes())
+	r.Mount("/country", NewCountryHandler(NewCountrySQLRepository(deps.DB)).Routes())
+	r.Mount("/tag", (&TagHandler{Repo: NewTagSQLRepository(deps.DB), Tenant: deps.Tenant}).Routes())
+	r.Mount("/account", (&AccountHandler{Repo: NewAccountSQLRepository(deps.DB), Tenant: deps.Tenant}).Routes())
+}
@@ -0,0 +1,2543 @@
+// Code generated by protoc-gen-go-dep. DO NOT EDIT.
+// source: hello.proto
+
+package hello
+
+import (
+	context "context"
+	driver "database/sql/driver"
+	json "encoding/json"
+	errors "errors"
+	fmt "fmt"
+	chi "github.com/go-chi/chi/v5"
+	uuid "github.com/google/uuid"
+	v5 "github.com/jackc/pgx/v5"
+	pgconn "github.com/jackc/pgx/v5/pgconn"
+	protojson "google.golang.org/protobuf/encoding/protojson"
+	proto "google.golang.org/protobuf/proto"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	template "html/template"
+	io "io"
+	mime "mime"
+	http "net/http"
+	path "path"
+	dep "protoc-gen-go-dep/dep"
+	strconv "strconv"
+	sync "sync"
+	time "time"
+)
+
+// DBTX is what the persistence methods need from pgx, it is satisfied by
+// *pgxpool.Pool, *pgx.Conn and pgx.Tx alike
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (v5.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) v5.Row
+}
+
+// WithTx runs fn in a transaction begun on db, it is committed when fn
+// returns nil and rolled back otherwise
+func WithTx(ctx context.Context, db interface {
+	Begin(context.Context) (v5.Tx, error)
+}, fn func(tx v5.Tx) error) error {
+	return v5.BeginFunc(ctx, db, fn)
+}
+
+// begin starts a transaction on db for a write made of several statements, a
+// savepoint when db is a transaction already. end commits it when err is nil
+// and rolls it back otherwise, returning err
+func begin(ctx context.Context, db DBTX) (DBTX, func(err error) error, error) {
+	b, ok := db.(interface {
+		Begin(context.Context) (v5.Tx, error)
+	})
+	if !ok {
+		return db, func(err error) error { return err }, nil
+	}
+	tx, err := b.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, func(err error) error {
+		if err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+		return tx.Commit(ctx)
+	}, nil
+}
+
+// HelloHandler serves the http routes of Hello
+type HelloHandler struct {
+	Repo HelloRepository
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// NewHelloHandler returns a HelloHandler backed by repo
+func NewHelloHandler(repo HelloRepository) *HelloHandler {
+	return &HelloHandler{Repo: repo}
+}
+
+func (h *HelloHandler) tenant(req *http.Request) string {
+	if h.Tenant != nil {
+		return h.Tenant(req)
+	}
+	return chi.URLParam(req, "tenant")
+}
+
+// id reads the {id} url parameter, false when it holds no valid id
+func (h *HelloHandler) id(req *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(req, "id"), 10, 64)
+	return id, err == nil
+}
+
+// Statements reading Hello, its writes call the routines of the schema
+const (
+	helloCountQuery        = "SELECT count(*) FROM hellos WHERE tenant = $1 AND deleted_at IS NULL"
+	helloListQuery         = "SELECT id, data FROM hellos WHERE tenant = $1 AND deleted_at IS NULL"
+	helloDeletedCountQuery = "SELECT count(*) FROM hellos WHERE tenant = $1 AND deleted_at IS NOT NULL"
+	helloDeletedListQuery  = "SELECT id, data FROM hellos WHERE tenant = $1 AND deleted_at IS NOT NULL"
+	helloGetQuery          = "SELECT version, data FROM hellos WHERE tenant = $1 AND id = $2 AND deleted_at IS NULL"
+	helloExistsQuery       = "SELECT count(*) FROM hellos WHERE tenant = $1 AND id = $2 AND deleted_at IS NULL"
+	helloGetByEmailQuery   = "SELECT id, version, data FROM hellos WHERE tenant = $1 AND NULLIF(COALESCE(data->>'email', ''), '') = $2 AND deleted_at IS NULL"
+)
+
+// helloUniqueKeys are the unique indexes of Hello, for dep.AlreadyExists
+var helloUniqueKeys = []dep.UniqueKey{
+	{
+		Index:  "hellos_email_key",
+		Fields: []string{"email"},
+	},
+}
+
+// stamp fills in the audit fields of x from the time and the actor of ctx. The ones
+// recording the creation are cleared unless created is set, the writes after
+// Create keep them as stored.
+func (x *Hello) stamp(ctx context.Context, created bool) {
+	now := time.Now()
+	actor := dep.Actor(ctx)
+	x.UpdatedAt = timestamppb.New(now)
+	x.UpdatedBy = actor
+	if created {
+		x.CreatedAt = timestamppb.New(now)
+		x.CreatedBy = actor
+	} else {
+		x.CreatedAt = nil
+		x.CreatedBy = ""
+	}
+}
+
+// helloListSchema holds the fields List can filter and order by
+var helloListSchema = &dep.Schema{
+	Message: new(Hello),
+	Dialect: dep.Postgres,
+	Fields: []dep.ListField{
+		{Name: "email", Expr: "COALESCE(data->>'email', '')", Filter: true, Sort: false},
+		{Name: "name", Expr: "COALESCE(data->>'name', '')", Filter: true, Sort: true},
+		{Name: "created_at", Expr: "COALESCE((data->>'createdAt')::timestamptz, to_timestamp(0))", Filter: false, Sort: true},
+	},
+}
+
+// List function returns the page of these objects opts selects
+func (x *Hello) List(ctx context.Context, db DBTX, tenant string, opts dep.ListOptions) (*dep.Page[*Hello, int64], error) {
+	q, err := helloListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Hello, int64])
+	query, args := q.Count(helloCountQuery, tenant)
+	if err := db.QueryRow(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(helloListQuery, tenant)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Items, err = v5.CollectRows(rows, func(rows v5.CollectableRow) (dep.Record[*Hello, int64], error) {
+		row := new(Hello)
+		var id int64
+		err := rows.Scan(&id, row)
+		return dep.Record[*Hello, int64]{ID: id, Value: row}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// ListDeleted function returns the page of these objects in the trash opts selects
+func (x *Hello) ListDeleted(ctx context.Context, db DBTX, tenant string, opts dep.ListOptions) (*dep.Page[*Hello, int64], error) {
+	q, err := helloListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Hello, int64])
+	query, args := q.Count(helloDeletedCountQuery, tenant)
+	if err := db.QueryRow(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(helloDeletedListQuery, tenant)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Items, err = v5.CollectRows(rows, func(rows v5.CollectableRow) (dep.Record[*Hello, int64], error) {
+		row := new(Hello)
+		var id int64
+		err := rows.Scan(&id, row)
+		return dep.Record[*Hello, int64]{ID: id, Value: row}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// Get function acquires a single record based on ID in database and returns its version
+func (x *Hello) Get(ctx context.Context, db DBTX, tenant string, id int64) (int64, error) {
+	var version int64
+	err := db.QueryRow(ctx, helloGetQuery, tenant, id).Scan(&version, x)
+	return version, err
+}
+
+// GetByEmail function acquires the record holding the given email
+// into x and returns its ID and version
+func (x *Hello) GetByEmail(ctx context.Context, db DBTX, tenant string, email string) (int64, int64, error) {
+	var id int64
+	var version int64
+	err := db.QueryRow(ctx, helloGetByEmailQuery, tenant, email).Scan(&id, &version, x)
+	return id, version, err
+}
+
+// Create function will create a new object of this type and return its ID,
+// filling in the audit fields of data
+// The object and its first revision are stored in one transaction.
+func (x *Hello) Create(ctx context.Context, db DBTX, tenant string, data *Hello) (int64, error) {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	id, err := x.create(ctx, tx, tenant, data)
+	return id, end(err)
+}
+
+// create makes the writes of Create in the transaction it began
+func (x *Hello) create(ctx context.Context, db DBTX, tenant string, data *Hello) (int64, error) {
+	data.stamp(ctx, true)
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err := db.QueryRow(ctx, "SELECT create_data($1, $2, NULL::bigint, $3)", tenant, x.TableName(), data).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	if err := x.record(ctx, db, tenant, id, dep.RevisionCreate, data); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Update function will replace the object stored at the given ID while it is at
+// version, any version when it is 0, and returns the version it stored
+// The fields recording its creation are kept and read back into data, the other
+// audit fields of data filled in.
+// The object and its revision are stored in one transaction.
+func (x *Hello) Update(ctx context.Context, db DBTX, tenant string, id int64, version int64, data *Hello) (int64, error) {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	stored, err := x.update(ctx, tx, tenant, id, version, data)
+	return stored, end(err)
+}
+
+// update makes the writes of Update in the transaction it began
+func (x *Hello) update(ctx context.Context, db DBTX, tenant string, id int64, version int64, data *Hello) (int64, error) {
+	data.stamp(ctx, false)
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	var stored int64
+	err := db.QueryRow(ctx, "SELECT version FROM update_versioned_data($1, $2, $3::bigint, $4, keep_data($1, $2, $3, $5, '{createdAt,createdBy}')) AS version WHERE version IS NOT NULL",
+		tenant, x.TableName()+"_live", id, version, data).Scan(&stored)
+	if err != nil {
+		return 0, x.conflict(ctx, db, tenant, id, version, err)
+	}
+	current := new(Hello)
+	if _, err := current.Get(ctx, db, tenant, id); err != nil {
+		return 0, err
+	}
+	data.CreatedAt = current.CreatedAt
+	data.CreatedBy = current.CreatedBy
+	if err := x.record(ctx, db, tenant, id, dep.RevisionUpdate, current); err != nil {
+		return 0, err
+	}
+	return stored, nil
+}
+
+// Patch function stores the fields of data named by mask in the object at the
+// given ID while it is at version, any version when it is 0, leaving the others
+// as they are, reads the result into x and returns the version it stored
+// The result is validated as a whole, the patch is rolled back when it is not valid.
+// The write and its revision are made in one transaction.
+func (x *Hello) Patch(ctx context.Context, db DBTX, tenant string, id int64, version int64, data *Hello, mask *fieldmaskpb.FieldMask) (int64, error) {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	stored, err := x.patch(ctx, tx, tenant, id, version, data, mask)
+	if err == nil {
+		err = x.Validate()
+	}
+	return stored, end(err)
+}
+
+// patch makes the writes of Patch in the transaction it began
+func (x *Hello) patch(ctx context.Context, db DBTX, tenant string, id int64, version int64, data *Hello, mask *fieldmaskpb.FieldMask) (int64, error) {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return 0, err
+	}
+	data.stamp(ctx, false)
+	paths = dep.StampPaths(paths, []string{"created_at", "created_by"}, []string{"updated_at", "updated_by"})
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return 0, err
+	}
+
+	// The first patch removes the masked fields, the second stores the ones
+	// data has, so messages, lists and maps are replaced rather than merged.
+	remove, store, err := dep.MergePatches(data, paths)
+	if err != nil {
+		return 0, err
+	}
+
+	var stored int64
+	err = db.QueryRow(ctx, "SELECT version, data FROM patch_versioned_data($1, $2, $3::bigint, $4, $5, $6) WHERE version IS NOT NULL",
+		tenant, x.TableName()+"_live", id, version, remove, store).Scan(&stored, x)
+	if err != nil {
+		return 0, x.conflict(ctx, db, tenant, id, version, err)
+	}
+	if err := x.record(ctx, db, tenant, id, dep.RevisionPatch, x); err != nil {
+		return 0, err
+	}
+	return stored, nil
+}
+
+// Delete function will move the object at given ID to the trash while it is at
+// version, any version when it is 0
+// The write and its revision are made in one transaction.
+func (x *Hello) Delete(ctx context.Context, db DBTX, tenant string, id int64, version int64) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.delete(ctx, tx, tenant, id, version)
+	return end(err)
+}
+
+// delete makes the writes of Delete in the transaction it began
+func (x *Hello) delete(ctx context.Context, db DBTX, tenant string, id int64, version int64) error {
+	var stored int64
+	err := db.QueryRow(ctx, "SELECT version FROM trash_versioned_data($1, $2, $3::bigint, $4) AS version WHERE version IS NOT NULL",
+		tenant, x.TableName(), id, version).Scan(&stored)
+	if err != nil {
+		return x.conflict(ctx, db, tenant, id, version, err)
+	}
+	return x.record(ctx, db, tenant, id, dep.RevisionDelete, nil)
+}
+
+// Restore function brings the object at the given ID back from the trash
+// The write and its revision are made in one transaction.
+func (x *Hello) Restore(ctx context.Context, db DBTX, tenant string, id int64) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.restore(ctx, tx, tenant, id)
+	return end(err)
+}
+
+// restore makes the writes of Restore in the transaction it began
+func (x *Hello) restore(ctx context.Context, db DBTX, tenant string, id int64) error {
+	var found int64
+	err := db.QueryRow(ctx, "SELECT id FROM restore_data($1, $2, $3::bigint) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id).Scan(&found)
+	if err != nil {
+		return err
+	}
+	current := new(Hello)
+	if _, err := current.Get(ctx, db, tenant, id); err != nil {
+		return err
+	}
+	return x.record(ctx, db, tenant, id, dep.RevisionRestore, current)
+}
+
+// Purge function deletes the object at the given ID from the trash for good
+// The write and its revision are made in one transaction.
+func (x *Hello) Purge(ctx context.Context, db DBTX, tenant string, id int64) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.purge(ctx, tx, tenant, id)
+	return end(err)
+}
+
+// purge makes the writes of Purge in the transaction it began
+func (x *Hello) purge(ctx context.Context, db DBTX, tenant string, id int64) error {
+	var found int64
+	err := db.QueryRow(ctx, "SELECT id FROM purge_data($1, $2, $3::bigint) AS id WHERE id IS NOT NULL",
+		tenant, x.TableName(), id).Scan(&found)
+	if err != nil {
+		return err
+	}
+	return x.record(ctx, db, tenant, id, dep.RevisionPurge, nil)
+}
+
+// conflict tells a record at another version from a missing one after a write
+// checking version found no row, returning dep.ErrConflict for the former
+func (x *Hello) conflict(ctx context.Context, db DBTX, tenant string, id int64, version int64, err error) error {
+	if version == 0 || !errors.Is(err, v5.ErrNoRows) {
+		return err
+	}
+
+	var n int
+	if err := db.QueryRow(ctx, helloExistsQuery, tenant, id).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return err
+	}
+	return dep.ErrConflict
+}
+
+// record appends the revision of a write to the history of the object at the
+// given ID, value is the object as the write left it, nil when it is gone. It
+// has to run in the transaction of the write, whose lock on the row keeps
+// concurrent writes from taking the same revision
+func (x *Hello) record(ctx context.Context, db DBTX, tenant string, id int64, operation string, value *Hello) error {
+	data, err := dep.MarshalDocument(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, "CALL record_revision($1, $2, $3::bigint, $4, $5, $6)",
+		tenant, x.TableName(), id, operation, dep.Actor(ctx), data)
+	return err
+}
+
+// History function returns the revisions of the object at the given ID, oldest first
+func (x *Hello) History(ctx context.Context, db DBTX, tenant string, id int64) ([]dep.Revision[*Hello], error) {
+	rows, err := db.Query(ctx, "SELECT revision, operation, actor, changed_at, old_data, new_data FROM list_revisions($1, $2, $3::bigint)",
+		tenant, x.TableName(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ret []dep.Revision[*Hello]
+	for rows.Next() {
+		r, err := dep.ScanRevision[*Hello](rows)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, r)
+	}
+	return ret, rows.Err()
+}
+
+// GetAtRevision function reads the object at the given ID as the given revision
+// left it into x, there is no row for revisions that removed it
+func (x *Hello) GetAtRevision(ctx context.Context, db DBTX, tenant string, id int64, revision int64) error {
+	var data []byte
+	err := db.QueryRow(ctx, "SELECT new_data FROM list_revisions($1, $2, $3::bigint) WHERE revision = $4 AND new_data IS NOT NULL",
+		tenant, x.TableName(), id, revision).Scan(&data)
+	if err != nil {
+		return err
+	}
+	return dep.UnmarshalDocument(data, x)
+}
+
+// HelloRepository stores Hello records. Get, Update, Patch and Delete return
+// dep.ErrNotFound for unknown ids, Patch returns the record as stored.
+//
+// Records are versioned, from 1 on every write. Get returns the version and the
+// writes the one stored, Update, Patch and Delete fail with dep.ErrConflict when
+// the record is not at the version they are given, 0 skips the check.
+//
+// Delete moves records to a trash the other methods do not see, ListDeleted lists
+// it. Restore and Purge return dep.ErrNotFound for ids not in the trash.
+//
+// The writes fill in the audit fields of data from the time and the actor of ctx
+// (see dep.WithActor), Update and Patch keep the ones recording the creation.
+// Update copies those into data, leaving it as stored.
+//
+// The writes fail with dep.ErrAlreadyExists, an *dep.AlreadyExistsError naming the
+// fields, when another record of the tenant holds the values of a unique index.
+// The GetBy methods return the record holding them, dep.ErrNotFound for none.
+//
+// Every write adds a revision to the history of the record, History returns them
+// oldest first and GetAtRevision the record as one of them left it. Both return
+// dep.ErrNotFound for ids without a history and revisions that removed the record.
+type HelloRepository interface {
+	List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Hello, int64], error)
+	Get(ctx context.Context, tenant string, id int64) (*Hello, int64, error)
+	GetByEmail(ctx context.Context, tenant string, email string) (*Hello, int64, int64, error)
+	Create(ctx context.Context, tenant string, data *Hello) (int64, error)
+	Update(ctx context.Context, tenant string, id int64, version int64, data *Hello) (int64, error)
+	Patch(ctx context.Context, tenant string, id int64, version int64, data *Hello, mask *fieldmaskpb.FieldMask) (*Hello, int64, error)
+	Delete(ctx context.Context, tenant string, id int64, version int64) error
+	ListDeleted(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Hello, int64], error)
+	Restore(ctx context.Context, tenant string, id int64) error
+	Purge(ctx context.Context, tenant string, id int64) error
+	History(ctx context.Context, tenant string, id int64) ([]dep.Revision[*Hello], error)
+	GetAtRevision(ctx context.Context, tenant string, id int64, revision int64) (*Hello, error)
+}
+
+// HelloSQLRepository is the HelloRepository backed by the Hello persistence methods
+type HelloSQLRepository struct {
+	DB DBTX
+}
+
+// NewHelloSQLRepository returns a HelloSQLRepository using db
+func NewHelloSQLRepository(db DBTX) *HelloSQLRepository {
+	return &HelloSQLRepository{DB: db}
+}
+
+var _ HelloRepository = (*HelloSQLRepository)(nil)
+
+func (r *HelloSQLRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Hello, int64], error) {
+	return new(Hello).List(ctx, r.DB, tenant, opts)
+}
+
+func (r *HelloSQLRepository) Get(ctx context.Context, tenant string, id int64) (*Hello, int64, error) {
+	x := new(Hello)
+	version, err := x.Get(ctx, r.DB, tenant, id)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, 0, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return x, version, nil
+}
+
+func (r *HelloSQLRepository) GetByEmail(ctx context.Context, tenant string, email string) (*Hello, int64, int64, error) {
+	x := new(Hello)
+	id, version, err := x.GetByEmail(ctx, r.DB, tenant, email)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, 0, 0, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return x, id, version, nil
+}
+
+func (r *HelloSQLRepository) Create(ctx context.Context, tenant string, data *Hello) (int64, error) {
+	id, err := data.Create(ctx, r.DB, tenant, data)
+	return id, dep.AlreadyExists(err, helloUniqueKeys...)
+}
+
+func (r *HelloSQLRepository) Update(ctx context.Context, tenant string, id int64, version int64, data *Hello) (int64, error) {
+	stored, err := data.Update(ctx, r.DB, tenant, id, version, data)
+	if errors.Is(err, v5.ErrNoRows) {
+		return 0, dep.ErrNotFound
+	}
+	return stored, dep.AlreadyExists(err, helloUniqueKeys...)
+}
+
+func (r *HelloSQLRepository) Patch(ctx context.Context, tenant string, id int64, version int64, data *Hello, mask *fieldmaskpb.FieldMask) (*Hello, int64, error) {
+	x := new(Hello)
+	stored, err := x.Patch(ctx, r.DB, tenant, id, version, data, mask)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, 0, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, dep.AlreadyExists(err, helloUniqueKeys...)
+	}
+
+	return x, stored, nil
+}
+
+func (r *HelloSQLRepository) Delete(ctx context.Context, tenant string, id int64, version int64) error {
+	err := new(Hello).Delete(ctx, r.DB, tenant, id, version)
+	if errors.Is(err, v5.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+func (r *HelloSQLRepository) ListDeleted(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Hello, int64], error) {
+	return new(Hello).ListDeleted(ctx, r.DB, tenant, opts)
+}
+
+func (r *HelloSQLRepository) Restore(ctx context.Context, tenant string, id int64) error {
+	err := new(Hello).Restore(ctx, r.DB, tenant, id)
+	if errors.Is(err, v5.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return dep.AlreadyExists(err, helloUniqueKeys...)
+}
+
+func (r *HelloSQLRepository) Purge(ctx context.Context, tenant string, id int64) error {
+	err := new(Hello).Purge(ctx, r.DB, tenant, id)
+	if errors.Is(err, v5.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+func (r *HelloSQLRepository) History(ctx context.Context, tenant string, id int64) ([]dep.Revision[*Hello], error) {
+	revisions, err := new(Hello).History(ctx, r.DB, tenant, id)
+	if err == nil && len(revisions) == 0 {
+		return nil, dep.ErrNotFound
+	}
+	return revisions, err
+}
+
+func (r *HelloSQLRepository) GetAtRevision(ctx context.Context, tenant string, id int64, revision int64) (*Hello, error) {
+	x := new(Hello)
+	err := x.GetAtRevision(ctx, r.DB, tenant, id, revision)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// HelloMemoryRepository is a HelloRepository keeping records in memory, safe for
+// concurrent use. Records are copied on the way in and out.
+type HelloMemoryRepository struct {
+	mu      sync.RWMutex
+	lastID  int64
+	tenants map[string]map[int64]*Hello
+	// versions holds the version of every record by tenant and id
+	versions map[string]map[int64]int64
+	// deleted holds the records in the trash
+	deleted map[string]map[int64]*Hello
+	// history holds the revisions of every record by tenant and id
+	history map[string]map[int64][]dep.Revision[*Hello]
+}
+
+// NewHelloMemoryRepository returns an empty HelloMemoryRepository
+func NewHelloMemoryRepository() *HelloMemoryRepository {
+	return &HelloMemoryRepository{tenants: make(map[string]map[int64]*Hello), versions: make(map[string]map[int64]int64), deleted: make(map[string]map[int64]*Hello), history: make(map[string]map[int64][]dep.Revision[*Hello])}
+}
+
+var _ HelloRepository = (*HelloMemoryRepository)(nil)
+
+// lookup returns the stored record, the lock has to be held
+func (r *HelloMemoryRepository) lookup(tenant string, id int64) (*Hello, error) {
+	x, ok := r.tenants[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+// checkVersion fails with dep.ErrConflict when the record is not at version,
+// the lock has to be held
+func (r *HelloMemoryRepository) checkVersion(tenant string, id int64, version int64) error {
+	if version != 0 && version != r.versions[tenant][id] {
+		return dep.ErrConflict
+	}
+	return nil
+}
+
+// record adds the revision of a write leaving the record at value, nil when it
+// is gone, to its history. The lock has to be held
+func (r *HelloMemoryRepository) record(ctx context.Context, tenant string, id int64, operation string, value *Hello) {
+	revisions := r.history[tenant][id]
+	revision := dep.Revision[*Hello]{
+		Revision:  int64(len(revisions) + 1),
+		Operation: operation,
+		Actor:     dep.Actor(ctx),
+		ChangedAt: time.Now(),
+		New:       proto.Clone(value).(*Hello),
+	}
+	if len(revisions) > 0 {
+		revision.Old = revisions[len(revisions)-1].New
+	}
+	if r.history[tenant] == nil {
+		r.history[tenant] = make(map[int64][]dep.Revision[*Hello])
+	}
+	r.history[tenant][id] = append(revisions, revision)
+}
+
+func (r *HelloMemoryRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Hello, int64], error) {
+	q, err := helloListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Hello, int64], 0, len(r.tenants[tenant]))
+	for id, x := range r.tenants[tenant] {
+		records = append(records, dep.Record[*Hello, int64]{ID: id, Value: proto.Clone(x).(*Hello)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+func (r *HelloMemoryRepository) Get(ctx context.Context, tenant string, id int64) (*Hello, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return proto.Clone(x).(*Hello), r.versions[tenant][id], nil
+}
+
+func (r *HelloMemoryRepository) GetByEmail(ctx context.Context, tenant string, email string) (*Hello, int64, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for id, x := range r.tenants[tenant] {
+		if email != "" && x.GetEmail() == email {
+			return proto.Clone(x).(*Hello), id, r.versions[tenant][id], nil
+		}
+	}
+	return nil, 0, 0, dep.ErrNotFound
+}
+
+// taken fails with dep.ErrAlreadyExists when a record other than the one at id
+// holds the unique fields of x, the lock has to be held. Zero values are not
+// taken, like the NULLs of the SQL indexes.
+func (r *HelloMemoryRepository) taken(tenant string, id int64, x *Hello) error {
+	for other, y := range r.tenants[tenant] {
+		if other == id {
+			continue
+		}
+		if x.GetEmail() != "" && x.GetEmail() == y.GetEmail() {
+			return &dep.AlreadyExistsError{Fields: []string{"email"}}
+		}
+	}
+	return nil
+}
+
+func (r *HelloMemoryRepository) Create(ctx context.Context, tenant string, data *Hello) (int64, error) {
+	data.stamp(ctx, true)
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.taken(tenant, 0, data); err != nil {
+		return 0, err
+	}
+	r.lastID++
+	id := r.lastID
+	if r.tenants[tenant] == nil {
+		r.tenants[tenant] = make(map[int64]*Hello)
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Hello)
+	if r.versions[tenant] == nil {
+		r.versions[tenant] = make(map[int64]int64)
+	}
+	r.versions[tenant][id] = 1
+	r.record(ctx, tenant, id, dep.RevisionCreate, r.tenants[tenant][id])
+	return id, nil
+}
+
+func (r *HelloMemoryRepository) Update(ctx context.Context, tenant string, id int64, version int64, data *Hello) (int64, error) {
+	data.stamp(ctx, false)
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.lookup(tenant, id)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.checkVersion(tenant, id, version); err != nil {
+		return 0, err
+	}
+	if err := r.taken(tenant, id, data); err != nil {
+		return 0, err
+	}
+	data.CreatedAt = stored.CreatedAt
+	data.CreatedBy = stored.CreatedBy
+	r.tenants[tenant][id] = proto.Clone(data).(*Hello)
+	r.versions[tenant][id]++
+	r.record(ctx, tenant, id, dep.RevisionUpdate, r.tenants[tenant][id])
+	return r.versions[tenant][id], nil
+}
+
+func (r *HelloMemoryRepository) Patch(ctx context.Context, tenant string, id int64, version int64, data *Hello, mask *fieldmaskpb.FieldMask) (*Hello, int64, error) {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return nil, 0, err
+	}
+	data.stamp(ctx, false)
+	paths = dep.StampPaths(paths, []string{"created_at", "created_by"}, []string{"updated_at", "updated_by"})
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := r.checkVersion(tenant, id, version); err != nil {
+		return nil, 0, err
+	}
+	x = proto.Clone(x).(*Hello)
+	dep.ApplyFieldMask(x, data, paths)
+	if err := x.Validate(); err != nil {
+		return nil, 0, err
+	}
+	if err := r.taken(tenant, id, x); err != nil {
+		return nil, 0, err
+	}
+	r.tenants[tenant][id] = x
+	r.versions[tenant][id]++
+	r.record(ctx, tenant, id, dep.RevisionPatch, x)
+	return proto.Clone(x).(*Hello), r.versions[tenant][id], nil
+}
+
+func (r *HelloMemoryRepository) Delete(ctx context.Context, tenant string, id int64, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return err
+	}
+	if err := r.checkVersion(tenant, id, version); err != nil {
+		return err
+	}
+	if r.deleted[tenant] == nil {
+		r.deleted[tenant] = make(map[int64]*Hello)
+	}
+	r.deleted[tenant][id] = x
+	delete(r.tenants[tenant], id)
+	r.record(ctx, tenant, id, dep.RevisionDelete, nil)
+	return nil
+}
+
+func (r *HelloMemoryRepository) ListDeleted(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Hello, int64], error) {
+	q, err := helloListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Hello, int64], 0, len(r.deleted[tenant]))
+	for id, x := range r.deleted[tenant] {
+		records = append(records, dep.Record[*Hello, int64]{ID: id, Value: proto.Clone(x).(*Hello)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+// trashed returns a record in the trash, the lock has to be held
+func (r *HelloMemoryRepository) trashed(tenant string, id int64) (*Hello, error) {
+	x, ok := r.deleted[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+func (r *HelloMemoryRepository) Restore(ctx context.Context, tenant string, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.trashed(tenant, id)
+	if err != nil {
+		return err
+	}
+	if err := r.taken(tenant, id, x); err != nil {
+		return err
+	}
+	if r.tenants[tenant] == nil {
+		r.tenants[tenant] = make(map[int64]*Hello)
+	}
+	r.tenants[tenant][id] = x
+	delete(r.deleted[tenant], id)
+	r.record(ctx, tenant, id, dep.RevisionRestore, x)
+	return nil
+}
+
+func (r *HelloMemoryRepository) Purge(ctx context.Context, tenant string, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.trashed(tenant, id); err != nil {
+		return err
+	}
+	delete(r.versions[tenant], id)
+	delete(r.deleted[tenant], id)
+	r.record(ctx, tenant, id, dep.RevisionPurge, nil)
+	return nil
+}
+
+func (r *HelloMemoryRepository) History(ctx context.Context, tenant string, id int64) ([]dep.Revision[*Hello], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions, ok := r.history[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	ret := make([]dep.Revision[*Hello], len(revisions))
+	for i, revision := range revisions {
+		revision.Old, revision.New = proto.Clone(revision.Old).(*Hello), proto.Clone(revision.New).(*Hello)
+		ret[i] = revision
+	}
+	return ret, nil
+}
+
+func (r *HelloMemoryRepository) GetAtRevision(ctx context.Context, tenant string, id int64, revision int64) (*Hello, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := r.history[tenant][id]
+	if revision < 1 || revision > int64(len(revisions)) || revisions[revision-1].New == nil {
+		return nil, dep.ErrNotFound
+	}
+	return proto.Clone(revisions[revision-1].New).(*Hello), nil
+}
+
+// ListHandler renders the page of objects selected by the page_size, page_token,
+// skip, filter and order_by query parameters
+func (h *HelloHandler) ListHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.List(req.Context(), h.tenant(req), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// ListDeletedHandler renders the page of objects in the trash selected by the
+// page_size, page_token, skip, filter and order_by query parameters
+func (h *HelloHandler) ListDeletedHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.ListDeleted(req.Context(), h.tenant(req), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// GetHandler renders the object at the {id} url parameter with its version as the
+// ETag, or answers a 304 when If-None-Match names that version
+func (h *HelloHandler) GetHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, version, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", dep.ETag(version))
+	if dep.NoneMatch(req, version) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.render(w, req, http.StatusOK, x)
+}
+
+// CreateHandler stores the object sent in the request body, the Location header
+// of the response names its url
+func (h *HelloHandler) CreateHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Hello)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.Repo.Create(req.Context(), h.tenant(req), x)
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", path.Join(req.URL.Path, fmt.Sprint(id)))
+	// Records start at version 1
+	w.Header().Set("ETag", dep.ETag(1))
+	h.render(w, req, http.StatusCreated, x)
+}
+
+// UpdateHandler replaces the object at the {id} url parameter with the request body
+// when it is at the version If-Match names
+func (h *HelloHandler) UpdateHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Hello)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := dep.IfMatch(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err = h.Repo.Update(req.Context(), h.tenant(req), id, version, x)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", dep.ETag(version))
+	h.render(w, req, http.StatusOK, x)
+}
+
+// PatchHandler stores the fields of the request body named by its mask in the object
+// at the {id} url parameter, when it is at the version If-Match names, and renders
+// the result
+func (h *HelloHandler) PatchHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Hello)
+	mask, err := h.decodePatch(req, x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := dep.IfMatch(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, version, err := h.Repo.Patch(req.Context(), h.tenant(req), id, version, x, mask)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	var invalid dep.ValidationErrors
+	if errors.Is(err, dep.ErrInvalidArgument) || errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", dep.ETag(version))
+	h.render(w, req, http.StatusOK, ret)
+}
+
+// DeleteHandler moves the object at the {id} url parameter to the trash when it
+// is at the version If-Match names
+func (h *HelloHandler) DeleteHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	version, err := dep.IfMatch(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.Repo.Delete(req.Context(), h.tenant(req), id, version)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreHandler takes the object at the {id} url parameter back out of the trash
+func (h *HelloHandler) RestoreHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	err := h.Repo.Restore(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PurgeHandler deletes the object at the {id} url parameter from the trash for good
+func (h *HelloHandler) PurgeHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	err := h.Repo.Purge(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HistoryHandler renders the revisions of the object at the {id} url parameter,
+// as a table of the fields each one changed to htmx requests
+func (h *HelloHandler) HistoryHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	revisions, err := h.Repo.History(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		dep.RenderHistory(w, revisions)
+		return
+	}
+
+	// Revisions encode their values with protojson
+	jsonData, err := json.Marshal(revisions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// RevisionHandler renders the object at the {id} url parameter as the revision in
+// the {revision} url parameter left it
+func (h *HelloHandler) RevisionHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	revision, err := strconv.ParseInt(chi.URLParam(req, "revision"), 10, 64)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, err := h.Repo.GetAtRevision(req.Context(), h.tenant(req), id, revision)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// FormHandler renders the form for a new object, or for editing the object at the {id} url parameter
+func (h *HelloHandler) FormHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Hello)
+	var version int64
+	if chi.URLParam(req, "id") != "" {
+		id, ok := h.id(req)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		found, v, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+		if errors.Is(err, dep.ErrNotFound) {
+			http.NotFound(w, req)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		x, version = found, v
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if version != 0 {
+		// htmx sends no If-Match, the form carries the version it shows instead
+		fmt.Fprintf(w, "<input type=\"hidden\" name=\"%s\" value=\"%d\">\n", dep.VersionFormValue, version)
+	}
+	if err := x.RenderForm(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// alreadyExists answers a write that found unique fields of x taken with a 409,
+// htmx requests get the form back with the fields marked
+func (h *HelloHandler) alreadyExists(w http.ResponseWriter, req *http.Request, x *Hello, err error) {
+	if req.Header.Get("HX-Request") != "true" {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	var errs dep.ValidationErrors
+	var exists *dep.AlreadyExistsError
+	if errors.As(err, &exists) {
+		errs = exists.ValidationErrors()
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusConflict)
+	if version, err := dep.IfMatch(req); err == nil && version > 0 {
+		// The form keeps the version it was submitted with
+		fmt.Fprintf(w, "<input type=\"hidden\" name=\"%s\" value=\"%d\">\n", dep.VersionFormValue, version)
+	}
+	x.RenderFormErrors(w, errs)
+}
+
+// decode reads the object from a protojson body, skipping unknown fields, or from
+// a submitted form
+func (h *HelloHandler) decode(req *http.Request, x *Hello) error {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		if _, err := x.HandleForm(req); err != nil {
+			return err
+		}
+		// HandleForm only validates the fields the form submitted
+		return x.Validate()
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, x); err != nil {
+		return err
+	}
+
+	return x.Validate()
+}
+
+// decodePatch reads the fields to patch from a submitted form, masking the ones it
+// holds, or from a protojson body masked by the update_mask query parameter or by
+// the fields present in the body, which may not name unknown fields
+func (h *HelloHandler) decodePatch(req *http.Request, x *Hello) (*fieldmaskpb.FieldMask, error) {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		return x.HandleForm(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(body, x); err != nil {
+		return nil, err
+	}
+
+	return dep.ParseFieldMask(req.URL.Query(), body)
+}
+
+// render writes the object as protojson, or as html to htmx requests
+func (h *HelloHandler) render(w http.ResponseWriter, req *http.Request, status int, x *Hello) {
+	if req.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		x.RenderView(w)
+		return
+	}
+
+	jsonData, err := protojson.Marshal(x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
+
+// Routes returns a chi.Router with the Hello endpoints that can be mounted to a parent router
+func (h *HelloHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListHandler)
+	r.Post("/", h.CreateHandler)
+	r.Get("/new", h.FormHandler)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.GetHandler)
+		r.Put("/", h.UpdateHandler)
+		r.Patch("/", h.PatchHandler)
+		r.Get("/edit", h.FormHandler)
+		r.Delete("/", h.DeleteHandler)
+		r.Get("/history", h.HistoryHandler)
+		r.Get("/history/{revision}", h.RevisionHandler)
+	})
+	r.Get("/deleted", h.ListDeletedHandler)
+	r.Route("/deleted/{id}", func(r chi.Router) {
+		r.Post("/restore", h.RestoreHandler)
+		r.Delete("/", h.PurgeHandler)
+	})
+
+	return r
+}
+
+// A simple function to handle a htmx form and populate the struct, returning
+// the mask of the fields the form submitted. Values that fail to parse are
+// collected per field before the submitted fields are validated.
+func (x *Hello) HandleForm(req *http.Request) (*fieldmaskpb.FieldMask, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	errs := make(dep.ValidationErrors)
+	x.Email = req.FormValue("Hello__Email")
+	x.Name = req.FormValue("Hello__Name")
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	mask := new(fieldmaskpb.FieldMask)
+	if dep.FormHas(req.Form, "Hello__Email") {
+		mask.Paths = append(mask.Paths, "email")
+	}
+	if dep.FormHas(req.Form, "Hello__Name") {
+		mask.Paths = append(mask.Paths, "name")
+	}
+	return mask, dep.MaskedErrors(x.Validate(), mask.Paths)
+}
+
+var helloViewTemplate = template.Must(template.New("view").Parse(`
+<p class="w-16">
+  <span>Email</span>
+  <span> {{ .Email }} </span>
+</p>
+<p class="w-16">
+  <span>Full name</span>
+  <span> {{ .Name }} </span>
+</p>
+<p class="w-16">
+  <span>CreatedAt</span>
+  <span> {{ with .CreatedAt }}{{ .AsTime.Format "2006-01-02 15:04" }}{{ end }} </span>
+</p>
+<p class="w-16">
+  <span>UpdatedAt</span>
+  <span> {{ with .UpdatedAt }}{{ .AsTime.Format "2006-01-02 15:04" }}{{ end }} </span>
+</p>
+<p class="w-16">
+  <span>CreatedBy</span>
+  <span> {{ .CreatedBy }} </span>
+</p>
+<p class="w-16">
+  <span>UpdatedBy</span>
+  <span> {{ .UpdatedBy }} </span>
+</p>
+`))
+
+// RenderView will take in a http writer and object to render the view
+func (x *Hello) RenderView(w http.ResponseWriter) error {
+	return helloViewTemplate.Execute(w, x)
+}
+
+var helloFormTemplate = template.Must(template.New("form").Parse(`{{ with .Value }}
+<label class="w-16">
+  <span>Email</span>
+  <input type="email" name="Hello__Email" value="{{ .Email }}" required placeholder="you@example.com">
+  {{ with index $.Errors "email" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Full name</span>
+  <input type="text" name="Hello__Name" value="{{ .Name }}">
+  {{ with index $.Errors "name" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>CreatedAt</span>
+  <input type="datetime-local" name="Hello__CreatedAt" value="{{ with .CreatedAt }}{{ .AsTime.Format "2006-01-02T15:04:05" }}{{ end }}" disabled step="1">
+  {{ with index $.Errors "created_at" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>UpdatedAt</span>
+  <input type="datetime-local" name="Hello__UpdatedAt" value="{{ with .UpdatedAt }}{{ .AsTime.Format "2006-01-02T15:04:05" }}{{ end }}" disabled step="1">
+  {{ with index $.Errors "updated_at" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>CreatedBy</span>
+  <input type="text" name="Hello__CreatedBy" value="{{ .CreatedBy }}" disabled>
+  {{ with index $.Errors "created_by" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>UpdatedBy</span>
+  <input type="text" name="Hello__UpdatedBy" value="{{ .UpdatedBy }}" disabled>
+  {{ with index $.Errors "updated_by" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+{{ end }}`))
+
+// RenderForm will take in a http writer and render a htmx form for the object
+func (x *Hello) RenderForm(w http.ResponseWriter) error {
+	return x.RenderFormErrors(w, nil)
+}
+
+// RenderFormErrors renders the htmx form for the object with errs shown next to
+// the inputs of their fields
+func (x *Hello) RenderFormErrors(w http.ResponseWriter, errs dep.ValidationErrors) error {
+	return helloFormTemplate.Execute(w, dep.FormData{Value: x, Errors: errs})
+}
+
+// Validate checks the constraints declared on the fields of Hello
+func (x *Hello) Validate() error {
+	errs := make(dep.ValidationErrors)
+	if x.Email == "" {
+		errs.Add("email", "is required")
+	}
+	return errs.Err()
+}
+
+// TableName returns the name of the table backing Hello
+func (*Hello) TableName() string {
+	return "hellos"
+}
+
+// Scan implements sql.Scanner, reading x from the protojson document in a
+// JSONB column. Unknown fields are discarded so removed fields do not break reads.
+func (x *Hello) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		proto.Reset(x)
+		return nil
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into Hello", src)
+	}
+
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, x)
+}
+
+// Value implements driver.Valuer, storing x as a protojson document
+func (x *Hello) Value() (driver.Value, error) {
+	if x == nil {
+		return nil, nil
+	}
+
+	data, err := protojson.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drivers send []byte as bytea, JSONB wants text.
+	return string(data), nil
+}
+
+// NoteHandler serves the http routes of Note
+type NoteHandler struct {
+	Repo NoteRepository
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// NewNoteHandler returns a NoteHandler backed by repo
+func NewNoteHandler(repo NoteRepository) *NoteHandler {
+	return &NoteHandler{Repo: repo}
+}
+
+func (h *NoteHandler) tenant(req *http.Request) string {
+	if h.Tenant != nil {
+		return h.Tenant(req)
+	}
+	return chi.URLParam(req, "tenant")
+}
+
+// id reads the {id} url parameter, false when it holds no valid id
+func (h *NoteHandler) id(req *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(req, "id"))
+	return id, err == nil
+}
+
+// Statements reading Note, its writes call the routines of the schema
+const (
+	noteCountQuery  = "SELECT count(*) FROM note WHERE tenant = $1"
+	noteListQuery   = "SELECT id, data FROM note WHERE tenant = $1"
+	noteGetQuery    = "SELECT version, data FROM note WHERE tenant = $1 AND id = $2"
+	noteExistsQuery = "SELECT count(*) FROM note WHERE tenant = $1 AND id = $2"
+)
+
+// noteListSchema holds the fields List can filter and order by
+var noteListSchema = &dep.Schema{
+	Message: new(Note),
+	Dialect: dep.Postgres,
+	Fields: []dep.ListField{
+		{Name: "text", Expr: "COALESCE(data->>'text', '')", Filter: true, Sort: false},
+	},
+	ID: uuid.Nil,
+}
+
+// List function returns the page of these objects opts selects
+func (x *Note) List(ctx context.Context, db DBTX, tenant string, opts dep.ListOptions) (*dep.Page[*Note, uuid.UUID], error) {
+	q, err := noteListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Note, uuid.UUID])
+	query, args := q.Count(noteCountQuery, tenant)
+	if err := db.QueryRow(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(noteListQuery, tenant)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Items, err = v5.CollectRows(rows, func(rows v5.CollectableRow) (dep.Record[*Note, uuid.UUID], error) {
+		row := new(Note)
+		var id uuid.UUID
+		err := rows.Scan(&id, row)
+		return dep.Record[*Note, uuid.UUID]{ID: id, Value: row}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// Get function acquires a single record based on ID in database and returns its version
+func (x *Note) Get(ctx context.Context, db DBTX, tenant string, id uuid.UUID) (int64, error) {
+	var version int64
+	err := db.QueryRow(ctx, noteGetQuery, tenant, id).Scan(&version, x)
+	return version, err
+}
+
+// Create function will create a new object of this type and return its ID
+// The object and its first revision are stored in one transaction.
+func (x *Note) Create(ctx context.Context, db DBTX, tenant string, data *Note) (uuid.UUID, error) {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	id, err := x.create(ctx, tx, tenant, data)
+	return id, end(err)
+}
+
+// create makes the writes of Create in the transaction it began
+func (x *Note) create(ctx context.Context, db DBTX, tenant string, data *Note) (uuid.UUID, error) {
+	if err := data.Validate(); err != nil {
+		return uuid.Nil, err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.Nil, err
+	}
+	_, err = db.Exec(ctx, "SELECT create_data($1, $2, $3::uuid, $4)", tenant, x.TableName(), id, data)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := x.record(ctx, db, tenant, id, dep.RevisionCreate, data); err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+// Update function will replace the object stored at the given ID while it is at
+// version, any version when it is 0, and returns the version it stored
+// The object and its revision are stored in one transaction.
+func (x *Note) Update(ctx context.Context, db DBTX, tenant string, id uuid.UUID, version int64, data *Note) (int64, error) {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	stored, err := x.update(ctx, tx, tenant, id, version, data)
+	return stored, end(err)
+}
+
+// update makes the writes of Update in the transaction it began
+func (x *Note) update(ctx context.Context, db DBTX, tenant string, id uuid.UUID, version int64, data *Note) (int64, error) {
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	var stored int64
+	err := db.QueryRow(ctx, "SELECT version FROM update_versioned_data($1, $2, $3::uuid, $4, $5) AS version WHERE version IS NOT NULL",
+		tenant, x.TableName(), id, version, data).Scan(&stored)
+	if err != nil {
+		return 0, x.conflict(ctx, db, tenant, id, version, err)
+	}
+	current := new(Note)
+	if _, err := current.Get(ctx, db, tenant, id); err != nil {
+		return 0, err
+	}
+	if err := x.record(ctx, db, tenant, id, dep.RevisionUpdate, current); err != nil {
+		return 0, err
+	}
+	return stored, nil
+}
+
+// Patch function stores the fields of data named by mask in the object at the
+// given ID while it is at version, any version when it is 0, leaving the others
+// as they are, reads the result into x and returns the version it stored
+// The result is validated as a whole, the patch is rolled back when it is not valid.
+// The write and its revision are made in one transaction.
+func (x *Note) Patch(ctx context.Context, db DBTX, tenant string, id uuid.UUID, version int64, data *Note, mask *fieldmaskpb.FieldMask) (int64, error) {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	stored, err := x.patch(ctx, tx, tenant, id, version, data, mask)
+	if err == nil {
+		err = x.Validate()
+	}
+	return stored, end(err)
+}
+
+// patch makes the writes of Patch in the transaction it began
+func (x *Note) patch(ctx context.Context, db DBTX, tenant string, id uuid.UUID, version int64, data *Note, mask *fieldmaskpb.FieldMask) (int64, error) {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return 0, err
+	}
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return 0, err
+	}
+
+	// The first patch removes the masked fields, the second stores the ones
+	// data has, so messages, lists and maps are replaced rather than merged.
+	remove, store, err := dep.MergePatches(data, paths)
+	if err != nil {
+		return 0, err
+	}
+
+	var stored int64
+	err = db.QueryRow(ctx, "SELECT version, data FROM patch_versioned_data($1, $2, $3::uuid, $4, $5, $6) WHERE version IS NOT NULL",
+		tenant, x.TableName(), id, version, remove, store).Scan(&stored, x)
+	if err != nil {
+		return 0, x.conflict(ctx, db, tenant, id, version, err)
+	}
+	if err := x.record(ctx, db, tenant, id, dep.RevisionPatch, x); err != nil {
+		return 0, err
+	}
+	return stored, nil
+}
+
+// Delete function will delete the object at given ID while it is at version, any
+// version when it is 0
+// The write and its revision are made in one transaction.
+func (x *Note) Delete(ctx context.Context, db DBTX, tenant string, id uuid.UUID, version int64) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.delete(ctx, tx, tenant, id, version)
+	return end(err)
+}
+
+// delete makes the writes of Delete in the transaction it began
+func (x *Note) delete(ctx context.Context, db DBTX, tenant string, id uuid.UUID, version int64) error {
+	var stored int64
+	err := db.QueryRow(ctx, "SELECT version FROM delete_versioned_data($1, $2, $3::uuid, $4) AS version WHERE version IS NOT NULL",
+		tenant, x.TableName(), id, version).Scan(&stored)
+	if err != nil {
+		return x.conflict(ctx, db, tenant, id, version, err)
+	}
+	return x.record(ctx, db, tenant, id, dep.RevisionDelete, nil)
+}
+
+// conflict tells a record at another version from a missing one after a write
+// checking version found no row, returning dep.ErrConflict for the former
+func (x *Note) conflict(ctx context.Context, db DBTX, tenant string, id uuid.UUID, version int64, err error) error {
+	if version == 0 || !errors.Is(err, v5.ErrNoRows) {
+		return err
+	}
+
+	var n int
+	if err := db.QueryRow(ctx, noteExistsQuery, tenant, id).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return err
+	}
+	return dep.ErrConflict
+}
+
+// record appends the revision of a write to the history of the object at the
+// given ID, value is the object as the write left it, nil when it is gone. It
+// has to run in the transaction of the write, whose lock on the row keeps
+// concurrent writes from taking the same revision
+func (x *Note) record(ctx context.Context, db DBTX, tenant string, id uuid.UUID, operation string, value *Note) error {
+	data, err := dep.MarshalDocument(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, "CALL record_revision($1, $2, $3::uuid, $4, $5, $6)",
+		tenant, x.TableName(), id, operation, dep.Actor(ctx), data)
+	return err
+}
+
+// History function returns the revisions of the object at the given ID, oldest first
+func (x *Note) History(ctx context.Context, db DBTX, tenant string, id uuid.UUID) ([]dep.Revision[*Note], error) {
+	rows, err := db.Query(ctx, "SELECT revision, operation, actor, changed_at, old_data, new_data FROM list_revisions($1, $2, $3::uuid)",
+		tenant, x.TableName(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ret []dep.Revision[*Note]
+	for rows.Next() {
+		r, err := dep.ScanRevision[*Note](rows)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, r)
+	}
+	return ret, rows.Err()
+}
+
+// GetAtRevision function reads the object at the given ID as the given revision
+// left it into x, there is no row for revisions that removed it
+func (x *Note) GetAtRevision(ctx context.Context, db DBTX, tenant string, id uuid.UUID, revision int64) error {
+	var data []byte
+	err := db.QueryRow(ctx, "SELECT new_data FROM list_revisions($1, $2, $3::uuid) WHERE revision = $4 AND new_data IS NOT NULL",
+		tenant, x.TableName(), id, revision).Scan(&data)
+	if err != nil {
+		return err
+	}
+	return dep.UnmarshalDocument(data, x)
+}
+
+// NoteRepository stores Note records. Get, Update, Patch and Delete return
+// dep.ErrNotFound for unknown ids, Patch returns the record as stored.
+//
+// Records are versioned, from 1 on every write. Get returns the version and the
+// writes the one stored, Update, Patch and Delete fail with dep.ErrConflict when
+// the record is not at the version they are given, 0 skips the check.
+//
+// Every write adds a revision to the history of the record, History returns them
+// oldest first and GetAtRevision the record as one of them left it. Both return
+// dep.ErrNotFound for ids without a history and revisions that removed the record.
+type NoteRepository interface {
+	List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Note, uuid.UUID], error)
+	Get(ctx context.Context, tenant string, id uuid.UUID) (*Note, int64, error)
+	Create(ctx context.Context, tenant string, data *Note) (uuid.UUID, error)
+	Update(ctx context.Context, tenant string, id uuid.UUID, version int64, data *Note) (int64, error)
+	Patch(ctx context.Context, tenant string, id uuid.UUID, version int64, data *Note, mask *fieldmaskpb.FieldMask) (*Note, int64, error)
+	Delete(ctx context.Context, tenant string, id uuid.UUID, version int64) error
+	History(ctx context.Context, tenant string, id uuid.UUID) ([]dep.Revision[*Note], error)
+	GetAtRevision(ctx context.Context, tenant string, id uuid.UUID, revision int64) (*Note, error)
+}
+
+// NoteSQLRepository is the NoteRepository backed by the Note persistence methods
+type NoteSQLRepository struct {
+	DB DBTX
+}
+
+// NewNoteSQLRepository returns a NoteSQLRepository using db
+func NewNoteSQLRepository(db DBTX) *NoteSQLRepository {
+	return &NoteSQLRepository{DB: db}
+}
+
+var _ NoteRepository = (*NoteSQLRepository)(nil)
+
+func (r *NoteSQLRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Note, uuid.UUID], error) {
+	return new(Note).List(ctx, r.DB, tenant, opts)
+}
+
+func (r *NoteSQLRepository) Get(ctx context.Context, tenant string, id uuid.UUID) (*Note, int64, error) {
+	x := new(Note)
+	version, err := x.Get(ctx, r.DB, tenant, id)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, 0, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return x, version, nil
+}
+
+func (r *NoteSQLRepository) Create(ctx context.Context, tenant string, data *Note) (uuid.UUID, error) {
+	return data.Create(ctx, r.DB, tenant, data)
+}
+
+func (r *NoteSQLRepository) Update(ctx context.Context, tenant string, id uuid.UUID, version int64, data *Note) (int64, error) {
+	stored, err := data.Update(ctx, r.DB, tenant, id, version, data)
+	if errors.Is(err, v5.ErrNoRows) {
+		return 0, dep.ErrNotFound
+	}
+	return stored, err
+}
+
+func (r *NoteSQLRepository) Patch(ctx context.Context, tenant string, id uuid.UUID, version int64, data *Note, mask *fieldmaskpb.FieldMask) (*Note, int64, error) {
+	x := new(Note)
+	stored, err := x.Patch(ctx, r.DB, tenant, id, version, data, mask)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, 0, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return x, stored, nil
+}
+
+func (r *NoteSQLRepository) Delete(ctx context.Context, tenant string, id uuid.UUID, version int64) error {
+	err := new(Note).Delete(ctx, r.DB, tenant, id, version)
+	if errors.Is(err, v5.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+func (r *NoteSQLRepository) History(ctx context.Context, tenant string, id uuid.UUID) ([]dep.Revision[*Note], error) {
+	revisions, err := new(Note).History(ctx, r.DB, tenant, id)
+	if err == nil && len(revisions) == 0 {
+		return nil, dep.ErrNotFound
+	}
+	return revisions, err
+}
+
+func (r *NoteSQLRepository) GetAtRevision(ctx context.Context, tenant string, id uuid.UUID, revision int64) (*Note, error) {
+	x := new(Note)
+	err := x.GetAtRevision(ctx, r.DB, tenant, id, revision)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// NoteMemoryRepository is a NoteRepository keeping records in memory, safe for
+// concurrent use. Records are copied on the way in and out.
+type NoteMemoryRepository struct {
+	mu      sync.RWMutex
+	tenants map[string]map[uuid.UUID]*Note
+	// versions holds the version of every record by tenant and id
+	versions map[string]map[uuid.UUID]int64
+	// history holds the revisions of every record by tenant and id
+	history map[string]map[uuid.UUID][]dep.Revision[*Note]
+}
+
+// NewNoteMemoryRepository returns an empty NoteMemoryRepository
+func NewNoteMemoryRepository() *NoteMemoryRepository {
+	return &NoteMemoryRepository{tenants: make(map[string]map[uuid.UUID]*Note), versions: make(map[string]map[uuid.UUID]int64), history: make(map[string]map[uuid.UUID][]dep.Revision[*Note])}
+}
+
+var _ NoteRepository = (*NoteMemoryRepository)(nil)
+
+// lookup returns the stored record, the lock has to be held
+func (r *NoteMemoryRepository) lookup(tenant string, id uuid.UUID) (*Note, error) {
+	x, ok := r.tenants[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+// checkVersion fails with dep.ErrConflict when the record is not at version,
+// the lock has to be held
+func (r *NoteMemoryRepository) checkVersion(tenant string, id uuid.UUID, version int64) error {
+	if version != 0 && version != r.versions[tenant][id] {
+		return dep.ErrConflict
+	}
+	return nil
+}
+
+// record adds the revision of a write leaving the record at value, nil when it
+// is gone, to its history. The lock has to be held
+func (r *NoteMemoryRepository) record(ctx context.Context, tenant string, id uuid.UUID, operation string, value *Note) {
+	revisions := r.history[tenant][id]
+	revision := dep.Revision[*Note]{
+		Revision:  int64(len(revisions) + 1),
+		Operation: operation,
+		Actor:     dep.Actor(ctx),
+		ChangedAt: time.Now(),
+		New:       proto.Clone(value).(*Note),
+	}
+	if len(revisions) > 0 {
+		revision.Old = revisions[len(revisions)-1].New
+	}
+	if r.history[tenant] == nil {
+		r.history[tenant] = make(map[uuid.UUID][]dep.Revision[*Note])
+	}
+	r.history[tenant][id] = append(revisions, revision)
+}
+
+func (r *NoteMemoryRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Note, uuid.UUID], error) {
+	q, err := noteListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Note, uuid.UUID], 0, len(r.tenants[tenant]))
+	for id, x := range r.tenants[tenant] {
+		records = append(records, dep.Record[*Note, uuid.UUID]{ID: id, Value: proto.Clone(x).(*Note)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+func (r *NoteMemoryRepository) Get(ctx context.Context, tenant string, id uuid.UUID) (*Note, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return proto.Clone(x).(*Note), r.versions[tenant][id], nil
+}
+
+func (r *NoteMemoryRepository) Create(ctx context.Context, tenant string, data *Note) (uuid.UUID, error) {
+	if err := data.Validate(); err != nil {
+		return uuid.Nil, err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tenants[tenant] == nil {
+		r.tenants[tenant] = make(map[uuid.UUID]*Note)
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Note)
+	if r.versions[tenant] == nil {
+		r.versions[tenant] = make(map[uuid.UUID]int64)
+	}
+	r.versions[tenant][id] = 1
+	r.record(ctx, tenant, id, dep.RevisionCreate, r.tenants[tenant][id])
+	return id, nil
+}
+
+func (r *NoteMemoryRepository) Update(ctx context.Context, tenant string, id uuid.UUID, version int64, data *Note) (int64, error) {
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.lookup(tenant, id)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.checkVersion(tenant, id, version); err != nil {
+		return 0, err
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Note)
+	r.versions[tenant][id]++
+	r.record(ctx, tenant, id, dep.RevisionUpdate, r.tenants[tenant][id])
+	return r.versions[tenant][id], nil
+}
+
+func (r *NoteMemoryRepository) Patch(ctx context.Context, tenant string, id uuid.UUID, version int64, data *Note, mask *fieldmaskpb.FieldMask) (*Note, int64, error) {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := r.checkVersion(tenant, id, version); err != nil {
+		return nil, 0, err
+	}
+	x = proto.Clone(x).(*Note)
+	dep.ApplyFieldMask(x, data, paths)
+	if err := x.Validate(); err != nil {
+		return nil, 0, err
+	}
+	r.tenants[tenant][id] = x
+	r.versions[tenant][id]++
+	r.record(ctx, tenant, id, dep.RevisionPatch, x)
+	return proto.Clone(x).(*Note), r.versions[tenant][id], nil
+}
+
+func (r *NoteMemoryRepository) Delete(ctx context.Context, tenant string, id uuid.UUID, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.lookup(tenant, id); err != nil {
+		return err
+	}
+	if err := r.checkVersion(tenant, id, version); err != nil {
+		return err
+	}
+	delete(r.versions[tenant], id)
+	delete(r.tenants[tenant], id)
+	r.record(ctx, tenant, id, dep.RevisionDelete, nil)
+	return nil
+}
+
+func (r *NoteMemoryRepository) History(ctx context.Context, tenant string, id uuid.UUID) ([]dep.Revision[*Note], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions, ok := r.history[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	ret := make([]dep.Revision[*Note], len(revisions))
+	for i, revision := range revisions {
+		revision.Old, revision.New = proto.Clone(revision.Old).(*Note), proto.Clone(revision.New).(*Note)
+		ret[i] = revision
+	}
+	return ret, nil
+}
+
+func (r *NoteMemoryRepository) GetAtRevision(ctx context.Context, tenant string, id uuid.UUID, revision int64) (*Note, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := r.history[tenant][id]
+	if revision < 1 || revision > int64(len(revisions)) || revisions[revision-1].New == nil {
+		return nil, dep.ErrNotFound
+	}
+	return proto.Clone(revisions[revision-1].New).(*Note), nil
+}
+
+// ListHandler renders the page of objects selected by the page_size, page_token,
+// skip, filter and order_by query parameters
+func (h *NoteHandler) ListHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.List(req.Context(), h.tenant(req), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// GetHandler renders the object at the {id} url parameter with its version as the
+// ETag, or answers a 304 when If-None-Match names that version
+func (h *NoteHandler) GetHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, version, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", dep.ETag(version))
+	if dep.NoneMatch(req, version) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.render(w, req, http.StatusOK, x)
+}
+
+// CreateHandler stores the object sent in the request body, the Location header
+// of the response names its url
+func (h *NoteHandler) CreateHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Note)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.Repo.Create(req.Context(), h.tenant(req), x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", path.Join(req.URL.Path, fmt.Sprint(id)))
+	// Records start at version 1
+	w.Header().Set("ETag", dep.ETag(1))
+	h.render(w, req, http.StatusCreated, x)
+}
+
+// UpdateHandler replaces the object at the {id} url parameter with the request body
+// when it is at the version If-Match names
+func (h *NoteHandler) UpdateHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Note)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := dep.IfMatch(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err = h.Repo.Update(req.Context(), h.tenant(req), id, version, x)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", dep.ETag(version))
+	h.render(w, req, http.StatusOK, x)
+}
+
+// PatchHandler stores the fields of the request body named by its mask in the object
+// at the {id} url parameter, when it is at the version If-Match names, and renders
+// the result
+func (h *NoteHandler) PatchHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Note)
+	mask, err := h.decodePatch(req, x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := dep.IfMatch(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, version, err := h.Repo.Patch(req.Context(), h.tenant(req), id, version, x, mask)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	var invalid dep.ValidationErrors
+	if errors.Is(err, dep.ErrInvalidArgument) || errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", dep.ETag(version))
+	h.render(w, req, http.StatusOK, ret)
+}
+
+// DeleteHandler deletes the object at the {id} url parameter when it is at the
+// version If-Match names
+func (h *NoteHandler) DeleteHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	version, err := dep.IfMatch(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.Repo.Delete(req.Context(), h.tenant(req), id, version)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HistoryHandler renders the revisions of the object at the {id} url parameter
+func (h *NoteHandler) HistoryHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	revisions, err := h.Repo.History(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Revisions encode their values with protojson
+	jsonData, err := json.Marshal(revisions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// RevisionHandler renders the object at the {id} url parameter as the revision in
+// the {revision} url parameter left it
+func (h *NoteHandler) RevisionHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	revision, err := strconv.ParseInt(chi.URLParam(req, "revision"), 10, 64)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, err := h.Repo.GetAtRevision(req.Context(), h.tenant(req), id, revision)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// decode reads the object from a protojson body, skipping unknown fields, or from
+// a submitted form
+func (h *NoteHandler) decode(req *http.Request, x *Note) error {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, x); err != nil {
+		return err
+	}
+
+	return x.Validate()
+}
+
+// decodePatch reads the fields to patch from a submitted form, masking the ones it
+// holds, or from a protojson body masked by the update_mask query parameter or by
+// the fields present in the body, which may not name unknown fields
+func (h *NoteHandler) decodePatch(req *http.Request, x *Note) (*fieldmaskpb.FieldMask, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(body, x); err != nil {
+		return nil, err
+	}
+
+	return dep.ParseFieldMask(req.URL.Query(), body)
+}
+
+// render writes the object as protojson, or as html to htmx requests
+func (h *NoteHandler) render(w http.ResponseWriter, req *http.Request, status int, x *Note) {
+	jsonData, err := protojson.Marshal(x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
+
+// Routes returns a chi.Router with the Note endpoints that can be mounted to a parent router
+func (h *NoteHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListHandler)
+	r.Post("/", h.CreateHandler)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.GetHandler)
+		r.Put("/", h.UpdateHandler)
+		r.Patch("/", h.PatchHandler)
+		r.Delete("/", h.DeleteHandler)
+		r.Get("/history", h.HistoryHandler)
+		r.Get("/history/{revision}", h.RevisionHandler)
+	})
+
+	return r
+}
+
+// Validate checks the constraints declared on the fields of Note
+func (x *Note) Validate() error {
+	errs := make(dep.ValidationErrors)
+	if x.Text == "" {
+		errs.Add("text", "is required")
+	}
+	return errs.Err()
+}
+
+// TableName returns the name of the table backing Note
+func (*Note) TableName() string {
+	return "note"
+}
+
+// Scan implements sql.Scanner, reading x from the protojson document in a
+// JSONB column. Unknown fields are discarded so removed fields do not break reads.
+func (x *Note) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		proto.Reset(x)
+		return nil
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into Note", src)
+	}
+
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, x)
+}
+
+// Value implements driver.Valuer, storing x as a protojson document
+func (x *Note) Value() (driver.Value, error) {
+	if x == nil {
+		return nil, nil
+	}
+
+	data, err := protojson.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drivers send []byte as bytea, JSONB wants text.
+	return string(data), nil
+}
+
+// Deps holds what the handlers of the resources in hello.proto need
+type Deps struct {
+	DB DBTX
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// RegisterAll mounts the routes of every resource in hello.proto on r
+func RegisterAll(r chi.Router, deps Deps) {
+	r.Mount("/hellos", (&HelloHandler{Repo: NewHelloSQLRepository(deps.DB), Tenant: deps.Tenant}).Routes())
+	r.Mount("/note", (&NoteHandler{Repo: NewNoteSQLRepository(deps.DB), Tenant: deps.Tenant}).Routes())
+}
@@ -0,0 +1,3527 @@
+// Code generated by protoc-gen-go-dep. DO NOT EDIT.
+// source: columns.proto
+
+package columns
+
+import (
+	context "context"
+	base64 "encoding/base64"
+	json "encoding/json"
+	errors "errors"
+	fmt "fmt"
+	chi "github.com/go-chi/chi/v5"
+	v5 "github.com/jackc/pgx/v5"
+	pgconn "github.com/jackc/pgx/v5/pgconn"
+	v2 "github.com/oklog/ulid/v2"
+	protojson "google.golang.org/protobuf/encoding/protojson"
+	proto "google.golang.org/protobuf/proto"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	template "html/template"
+	io "io"
+	mime "mime"
+	http "net/http"
+	path "path"
+	dep "protoc-gen-go-dep/dep"
+	strconv "strconv"
+	strings "strings"
+	sync "sync"
+	time "time"
+)
+
+// DBTX is what the persistence methods need from pgx, it is satisfied by
+// *pgxpool.Pool, *pgx.Conn and pgx.Tx alike
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (v5.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) v5.Row
+}
+
+// WithTx runs fn in a transaction begun on db, it is committed when fn
+// returns nil and rolled back otherwise
+func WithTx(ctx context.Context, db interface {
+	Begin(context.Context) (v5.Tx, error)
+}, fn func(tx v5.Tx) error) error {
+	return v5.BeginFunc(ctx, db, fn)
+}
+
+// begin starts a transaction on db for a write made of several statements, a
+// savepoint when db is a transaction already. end commits it when err is nil
+// and rolls it back otherwise, returning err
+func begin(ctx context.Context, db DBTX) (DBTX, func(err error) error, error) {
+	b, ok := db.(interface {
+		Begin(context.Context) (v5.Tx, error)
+	})
+	if !ok {
+		return db, func(err error) error { return err }, nil
+	}
+	tx, err := b.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, func(err error) error {
+		if err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+		return tx.Commit(ctx)
+	}, nil
+}
+
+// OrderHandler serves the http routes of Order
+type OrderHandler struct {
+	Repo OrderRepository
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// NewOrderHandler returns a OrderHandler backed by repo
+func NewOrderHandler(repo OrderRepository) *OrderHandler {
+	return &OrderHandler{Repo: repo}
+}
+
+func (h *OrderHandler) tenant(req *http.Request) string {
+	if h.Tenant != nil {
+		return h.Tenant(req)
+	}
+	return chi.URLParam(req, "tenant")
+}
+
+// id reads the {id} url parameter, false when it holds no valid id
+func (h *OrderHandler) id(req *http.Request) (v2.ULID, bool) {
+	id, err := v2.ParseStrict(chi.URLParam(req, "id"))
+	return id, err == nil
+}
+
+// Statements backing Order, values follow the order of the fields
+const (
+	orderCountQuery                  = "SELECT count(*) FROM \"order\" WHERE tenant = $1 AND deleted_at IS NULL"
+	orderListQuery                   = "SELECT id, customer_name, count, total, weight, serial, discount, rate, paid, receipt, priority, placed_at, first_line, tags, scores, flags, lines, totals, note, escalation, address, speed, pickup_at, parcel, label, locker, dock, created_at, updated_by, batch, parts, stock, levels, deadlines FROM \"order\" WHERE tenant = $1 AND deleted_at IS NULL"
+	orderDeletedCountQuery           = "SELECT count(*) FROM \"order\" WHERE tenant = $1 AND deleted_at IS NOT NULL"
+	orderDeletedListQuery            = "SELECT id, customer_name, count, total, weight, serial, discount, rate, paid, receipt, priority, placed_at, first_line, tags, scores, flags, lines, totals, note, escalation, address, speed, pickup_at, parcel, label, locker, dock, created_at, updated_by, batch, parts, stock, levels, deadlines FROM \"order\" WHERE tenant = $1 AND deleted_at IS NOT NULL"
+	orderGetQuery                    = "SELECT version, customer_name, count, total, weight, serial, discount, rate, paid, receipt, priority, placed_at, first_line, tags, scores, flags, lines, totals, note, escalation, address, speed, pickup_at, parcel, label, locker, dock, created_at, updated_by, batch, parts, stock, levels, deadlines FROM \"order\" WHERE tenant = $1 AND id = $2 AND deleted_at IS NULL"
+	orderInsertQuery                 = "INSERT INTO \"order\" (tenant, customer_name, count, total, weight, serial, discount, rate, paid, receipt, priority, placed_at, first_line, tags, scores, flags, lines, totals, note, escalation, address, speed, pickup_at, parcel, label, locker, dock, created_at, updated_by, batch, parts, stock, levels, deadlines, id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35)"
+	orderUpdateQuery                 = "UPDATE \"order\" SET customer_name = $3, count = $4, total = $5, weight = $6, serial = $7, discount = $8, rate = $9, paid = $10, receipt = $11, priority = $12, placed_at = $13, first_line = $14, tags = $15, scores = $16, flags = $17, lines = $18, totals = $19, note = $20, escalation = $21, address = $22, speed = $23, pickup_at = $24, parcel = $25, label = $26, locker = $27, dock = $28, created_at = COALESCE(created_at, $29), updated_by = $30, batch = $31, parts = $32, stock = $33, levels = $34, deadlines = $35, version = version + 1 WHERE tenant = $1 AND id = $2 AND deleted_at IS NULL AND version = COALESCE(NULLIF($36::bigint, 0), version) RETURNING version"
+	orderPatchQuery                  = "UPDATE \"order\" SET %s, version = version + 1 WHERE tenant = $1 AND id = $2 AND deleted_at IS NULL AND version = COALESCE(NULLIF($3::bigint, 0), version) RETURNING version, customer_name, count, total, weight, serial, discount, rate, paid, receipt, priority, placed_at, first_line, tags, scores, flags, lines, totals, note, escalation, address, speed, pickup_at, parcel, label, locker, dock, created_at, updated_by, batch, parts, stock, levels, deadlines"
+	orderDeleteQuery                 = "UPDATE \"order\" SET deleted_at = CURRENT_TIMESTAMP WHERE tenant = $1 AND id = $2 AND deleted_at IS NULL AND version = COALESCE(NULLIF($3::bigint, 0), version) RETURNING version"
+	orderExistsQuery                 = "SELECT count(*) FROM \"order\" WHERE tenant = $1 AND id = $2 AND deleted_at IS NULL"
+	orderRestoreQuery                = "UPDATE \"order\" SET deleted_at = NULL WHERE tenant = $1 AND id = $2 AND deleted_at IS NOT NULL RETURNING id"
+	orderPurgeQuery                  = "DELETE FROM \"order\" WHERE tenant = $1 AND id = $2 AND deleted_at IS NOT NULL RETURNING id"
+	orderGetByCustomerAndSerialQuery = "SELECT id, version, customer_name, count, total, weight, serial, discount, rate, paid, receipt, priority, placed_at, first_line, tags, scores, flags, lines, totals, note, escalation, address, speed, pickup_at, parcel, label, locker, dock, created_at, updated_by, batch, parts, stock, levels, deadlines FROM \"order\" WHERE tenant = $1 AND NULLIF(customer_name, '') = $2 AND NULLIF(serial, 0) = $3 AND deleted_at IS NULL"
+	orderRevisionInsertQuery         = "INSERT INTO order_history (tenant, id, revision, operation, actor, old_data, new_data) SELECT $1, $2, COALESCE(MAX(revision), 0) + 1, $3, $4, (SELECT new_data FROM order_history WHERE tenant = $1 AND id = $2 ORDER BY revision DESC LIMIT 1), $5::jsonb FROM order_history WHERE tenant = $1 AND id = $2"
+	orderHistoryQuery                = "SELECT revision, operation, actor, changed_at, old_data, new_data FROM order_history WHERE tenant = $1 AND id = $2 ORDER BY revision"
+	orderRevisionQuery               = "SELECT new_data FROM order_history WHERE tenant = $1 AND id = $2 AND revision = $3 AND new_data IS NOT NULL"
+)
+
+// orderColumns names the columns of Order in the order of the fields
+var orderColumns = []string{"customer_name", "count", "total", "weight", "serial", "discount", "rate", "paid", "receipt", "priority", "placed_at", "first_line", "tags", "scores", "flags", "lines", "totals", "note", "escalation", "address", "speed", "pickup_at", "parcel", "label", "locker", "dock", "created_at", "updated_by", "batch", "parts", "stock", "levels", "deadlines"}
+
+// orderUniqueKeys are the unique indexes of Order, for dep.AlreadyExists
+var orderUniqueKeys = []dep.UniqueKey{
+	{
+		Index:  "order_customer_serial_key",
+		Fields: []string{"customer", "serial"},
+	},
+}
+
+// columnValues returns the values of the columns backing x in field order
+func (x *Order) columnValues() ([]any, error) {
+	values := make([]any, 0, 33)
+	values = append(values, x.Customer)
+	values = append(values, x.Count)
+	values = append(values, x.Total)
+	values = append(values, x.Weight)
+	values = append(values, strconv.FormatUint(x.Serial, 10))
+	values = append(values, x.Discount)
+	values = append(values, x.Rate)
+	values = append(values, x.Paid)
+	values = append(values, x.Receipt)
+	values = append(values, x.Priority.String())
+	if t := x.GetPlacedAt(); t != nil {
+		values = append(values, t.AsTime())
+	} else {
+		values = append(values, nil)
+	}
+	if m := x.GetFirstLine(); m != nil {
+		b, err := protojson.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(b))
+	} else {
+		values = append(values, nil)
+	}
+	values = append(values, x.Tags)
+	values = append(values, x.Scores)
+	{
+		names := make([]string, len(x.Flags))
+		for i, v := range x.Flags {
+			names[i] = v.String()
+		}
+		values = append(values, names)
+	}
+	{
+		items := make([]json.RawMessage, 0, len(x.Lines))
+		for _, m := range x.Lines {
+			b, err := protojson.Marshal(m)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, b)
+		}
+		b, err := json.Marshal(items)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(b))
+	}
+	if b, err := json.Marshal(x.Totals); err != nil {
+		return nil, err
+	} else {
+		values = append(values, string(b))
+	}
+	values = append(values, x.Note)
+	if x.Escalation != nil {
+		values = append(values, x.Escalation.String())
+	} else {
+		values = append(values, nil)
+	}
+	if v, ok := x.Delivery.(*Order_Address); ok {
+		values = append(values, v.Address)
+	} else {
+		values = append(values, nil)
+	}
+	if v, ok := x.Delivery.(*Order_Speed); ok {
+		values = append(values, v.Speed.String())
+	} else {
+		values = append(values, nil)
+	}
+	if t := x.GetPickupAt(); t != nil {
+		values = append(values, t.AsTime())
+	} else {
+		values = append(values, nil)
+	}
+	if m := x.GetParcel(); m != nil {
+		b, err := protojson.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(b))
+	} else {
+		values = append(values, nil)
+	}
+	if v, ok := x.Delivery.(*Order_Label); ok {
+		values = append(values, v.Label)
+	} else {
+		values = append(values, nil)
+	}
+	if v, ok := x.Delivery.(*Order_Locker); ok {
+		values = append(values, v.Locker)
+	} else {
+		values = append(values, nil)
+	}
+	if v, ok := x.Delivery.(*Order_Dock); ok {
+		values = append(values, strconv.FormatUint(v.Dock, 10))
+	} else {
+		values = append(values, nil)
+	}
+	if t := x.GetCreatedAt(); t != nil {
+		values = append(values, t.AsTime())
+	} else {
+		values = append(values, nil)
+	}
+	values = append(values, x.UpdatedBy)
+	if x.Batch != nil {
+		values = append(values, strconv.FormatUint(*x.Batch, 10))
+	} else {
+		values = append(values, nil)
+	}
+	values = append(values, x.Parts)
+	{
+		items := make(map[string]json.RawMessage, len(x.Stock))
+		for k, m := range x.Stock {
+			b, err := protojson.Marshal(m)
+			if err != nil {
+				return nil, err
+			}
+			items[k] = b
+		}
+		b, err := json.Marshal(items)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(b))
+	}
+	{
+		names := make(map[string]string, len(x.Levels))
+		for k, v := range x.Levels {
+			names[k] = v.String()
+		}
+		b, err := json.Marshal(names)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(b))
+	}
+	{
+		items := make(map[int32]json.RawMessage, len(x.Deadlines))
+		for k, m := range x.Deadlines {
+			b, err := protojson.Marshal(m)
+			if err != nil {
+				return nil, err
+			}
+			items[k] = b
+		}
+		b, err := json.Marshal(items)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(b))
+	}
+
+	return values, nil
+}
+
+// scanColumns scans a row holding dest followed by the columns backing x,
+// replacing what x held
+func (x *Order) scanColumns(row interface{ Scan(...any) error }, dest ...any) error {
+	proto.Reset(x)
+	var (
+		serialColumn     string
+		priorityColumn   string
+		placedAtColumn   *time.Time
+		firstLineColumn  []byte
+		flagsColumn      []string
+		linesColumn      []byte
+		totalsColumn     []byte
+		escalationColumn *string
+		addressColumn    *string
+		speedColumn      *string
+		pickupAtColumn   *time.Time
+		parcelColumn     []byte
+		labelColumn      []byte
+		lockerColumn     *int64
+		dockColumn       *string
+		createdAtColumn  *time.Time
+		batchColumn      *string
+		stockColumn      []byte
+		levelsColumn     []byte
+		deadlinesColumn  []byte
+	)
+	dest = append(dest,
+		&x.Customer,
+		&x.Count,
+		&x.Total,
+		&x.Weight,
+		&serialColumn,
+		&x.Discount,
+		&x.Rate,
+		&x.Paid,
+		&x.Receipt,
+		&priorityColumn,
+		&placedAtColumn,
+		&firstLineColumn,
+		&x.Tags,
+		&x.Scores,
+		&flagsColumn,
+		&linesColumn,
+		&totalsColumn,
+		&x.Note,
+		&escalationColumn,
+		&addressColumn,
+		&speedColumn,
+		&pickupAtColumn,
+		&parcelColumn,
+		&labelColumn,
+		&lockerColumn,
+		&dockColumn,
+		&createdAtColumn,
+		&x.UpdatedBy,
+		&batchColumn,
+		&x.Parts,
+		&stockColumn,
+		&levelsColumn,
+		&deadlinesColumn,
+	)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	if v, err := strconv.ParseUint(serialColumn, 10, 64); err != nil {
+		return err
+	} else {
+		x.Serial = v
+	}
+	if n, err := dep.EnumNumber(Priority_value, priorityColumn); err != nil {
+		return err
+	} else {
+		x.Priority = Priority(n)
+	}
+	if placedAtColumn != nil {
+		x.PlacedAt = timestamppb.New(*placedAtColumn)
+	}
+	if firstLineColumn != nil {
+		m := new(Line)
+		if err := protojson.Unmarshal(firstLineColumn, m); err != nil {
+			return err
+		}
+		x.FirstLine = m
+	}
+	x.Flags = nil
+	for _, name := range flagsColumn {
+		n, err := dep.EnumNumber(Priority_value, name)
+		if err != nil {
+			return err
+		}
+		x.Flags = append(x.Flags, Priority(n))
+	}
+	var linesColumnItems []json.RawMessage
+	if err := json.Unmarshal(linesColumn, &linesColumnItems); err != nil {
+		return err
+	}
+	x.Lines = nil
+	for _, item := range linesColumnItems {
+		m := new(Line)
+		if err := protojson.Unmarshal(item, m); err != nil {
+			return err
+		}
+		x.Lines = append(x.Lines, m)
+	}
+	if err := json.Unmarshal(totalsColumn, &x.Totals); err != nil {
+		return err
+	}
+	if escalationColumn != nil {
+		n, err := dep.EnumNumber(Priority_value, *escalationColumn)
+		if err != nil {
+			return err
+		}
+		v := Priority(n)
+		x.Escalation = &v
+	}
+	if addressColumn != nil {
+		x.Delivery = &Order_Address{Address: *addressColumn}
+	}
+	if speedColumn != nil {
+		n, err := dep.EnumNumber(Priority_value, *speedColumn)
+		if err != nil {
+			return err
+		}
+		v := Priority(n)
+		x.Delivery = &Order_Speed{Speed: v}
+	}
+	if pickupAtColumn != nil {
+		x.Delivery = &Order_PickupAt{PickupAt: timestamppb.New(*pickupAtColumn)}
+	}
+	if parcelColumn != nil {
+		m := new(Line)
+		if err := protojson.Unmarshal(parcelColumn, m); err != nil {
+			return err
+		}
+		x.Delivery = &Order_Parcel{Parcel: m}
+	}
+	if labelColumn != nil {
+		x.Delivery = &Order_Label{Label: labelColumn}
+	}
+	if lockerColumn != nil {
+		x.Delivery = &Order_Locker{Locker: *lockerColumn}
+	}
+	if dockColumn != nil {
+		v, err := strconv.ParseUint(*dockColumn, 10, 64)
+		if err != nil {
+			return err
+		}
+		x.Delivery = &Order_Dock{Dock: v}
+	}
+	if createdAtColumn != nil {
+		x.CreatedAt = timestamppb.New(*createdAtColumn)
+	}
+	if batchColumn != nil {
+		v, err := strconv.ParseUint(*batchColumn, 10, 64)
+		if err != nil {
+			return err
+		}
+		x.Batch = &v
+	}
+	var stockColumnItems map[string]json.RawMessage
+	if err := json.Unmarshal(stockColumn, &stockColumnItems); err != nil {
+		return err
+	}
+	x.Stock = make(map[string]*Line, len(stockColumnItems))
+	for k, item := range stockColumnItems {
+		m := new(Line)
+		if err := protojson.Unmarshal(item, m); err != nil {
+			return err
+		}
+		x.Stock[k] = m
+	}
+	var levelsColumnItems map[string]string
+	if err := json.Unmarshal(levelsColumn, &levelsColumnItems); err != nil {
+		return err
+	}
+	x.Levels = make(map[string]Priority, len(levelsColumnItems))
+	for k, item := range levelsColumnItems {
+		n, err := dep.EnumNumber(Priority_value, item)
+		if err != nil {
+			return err
+		}
+		x.Levels[k] = Priority(n)
+	}
+	var deadlinesColumnItems map[int32]json.RawMessage
+	if err := json.Unmarshal(deadlinesColumn, &deadlinesColumnItems); err != nil {
+		return err
+	}
+	x.Deadlines = make(map[int32]*timestamppb.Timestamp, len(deadlinesColumnItems))
+	for k, item := range deadlinesColumnItems {
+		m := new(timestamppb.Timestamp)
+		if err := protojson.Unmarshal(item, m); err != nil {
+			return err
+		}
+		x.Deadlines[k] = m
+	}
+
+	return nil
+}
+
+// stamp fills in the audit fields of x from the time and the actor of ctx. The ones
+// recording the creation are cleared unless created is set, the writes after
+// Create keep them as stored.
+func (x *Order) stamp(ctx context.Context, created bool) {
+	now := time.Now()
+	actor := dep.Actor(ctx)
+	x.UpdatedBy = actor
+	if created {
+		x.CreatedAt = timestamppb.New(now)
+	} else {
+		x.CreatedAt = nil
+	}
+}
+
+// orderListSchema holds the fields List can filter and order by
+var orderListSchema = &dep.Schema{
+	Message: new(Order),
+	Dialect: dep.Postgres,
+	Fields: []dep.ListField{
+		{Name: "customer", Expr: "customer_name", Filter: true, Sort: true},
+		{Name: "total", Expr: "total", Filter: false, Sort: true},
+		{Name: "serial", Expr: "serial", Filter: true, Sort: true},
+		{Name: "rate", Expr: "rate", Filter: true, Sort: false},
+		{Name: "paid", Expr: "paid", Filter: true, Sort: false},
+		{Name: "priority", Expr: "priority", Filter: true, Sort: true},
+		{Name: "placed_at", Expr: "COALESCE(placed_at, to_timestamp(0))", Filter: true, Sort: true},
+		{Name: "note", Expr: "COALESCE(note, '')", Filter: true, Sort: false},
+	},
+	ID: v2.ULID{},
+}
+
+// List function returns the page of these objects opts selects
+func (x *Order) List(ctx context.Context, db DBTX, tenant string, opts dep.ListOptions) (*dep.Page[*Order, v2.ULID], error) {
+	q, err := orderListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Order, v2.ULID])
+	query, args := q.Count(orderCountQuery, tenant)
+	if err := db.QueryRow(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(orderListQuery, tenant)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Items, err = v5.CollectRows(rows, func(rows v5.CollectableRow) (dep.Record[*Order, v2.ULID], error) {
+		row := new(Order)
+		var id v2.ULID
+		err := row.scanColumns(rows, &id)
+		return dep.Record[*Order, v2.ULID]{ID: id, Value: row}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// ListDeleted function returns the page of these objects in the trash opts selects
+func (x *Order) ListDeleted(ctx context.Context, db DBTX, tenant string, opts dep.ListOptions) (*dep.Page[*Order, v2.ULID], error) {
+	q, err := orderListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Order, v2.ULID])
+	query, args := q.Count(orderDeletedCountQuery, tenant)
+	if err := db.QueryRow(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(orderDeletedListQuery, tenant)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Items, err = v5.CollectRows(rows, func(rows v5.CollectableRow) (dep.Record[*Order, v2.ULID], error) {
+		row := new(Order)
+		var id v2.ULID
+		err := row.scanColumns(rows, &id)
+		return dep.Record[*Order, v2.ULID]{ID: id, Value: row}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// Get function acquires a single record based on ID in database and returns its version
+func (x *Order) Get(ctx context.Context, db DBTX, tenant string, id v2.ULID) (int64, error) {
+	var version int64
+	err := x.scanColumns(db.QueryRow(ctx, orderGetQuery, tenant, id), &version)
+	return version, err
+}
+
+// GetByCustomerAndSerial function acquires the record holding the given customer and serial
+// into x and returns its ID and version
+func (x *Order) GetByCustomerAndSerial(ctx context.Context, db DBTX, tenant string, customer string, serial uint64) (v2.ULID, int64, error) {
+	var id v2.ULID
+	var version int64
+	err := x.scanColumns(db.QueryRow(ctx, orderGetByCustomerAndSerialQuery, tenant, customer, strconv.FormatUint(serial, 10)), &id, &version)
+	return id, version, err
+}
+
+// Create function will create a new object of this type and return its ID,
+// filling in the audit fields of data
+// The object and its first revision are stored in one transaction.
+func (x *Order) Create(ctx context.Context, db DBTX, tenant string, data *Order) (v2.ULID, error) {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return v2.ULID{}, err
+	}
+	id, err := x.create(ctx, tx, tenant, data)
+	return id, end(err)
+}
+
+// create makes the writes of Create in the transaction it began
+func (x *Order) create(ctx context.Context, db DBTX, tenant string, data *Order) (v2.ULID, error) {
+	data.stamp(ctx, true)
+	if err := data.Validate(); err != nil {
+		return v2.ULID{}, err
+	}
+
+	id := v2.Make()
+	values, err := data.columnValues()
+	if err != nil {
+		return v2.ULID{}, err
+	}
+
+	_, err = db.Exec(ctx, orderInsertQuery, append(append([]any{tenant}, values...), id)...)
+	if err != nil {
+		return v2.ULID{}, err
+	}
+	if err := x.record(ctx, db, tenant, id, dep.RevisionCreate, data); err != nil {
+		return v2.ULID{}, err
+	}
+	return id, nil
+}
+
+// Update function will replace the object stored at the given ID while it is at
+// version, any version when it is 0, and returns the version it stored
+// The fields recording its creation are kept and read back into data, the other
+// audit fields of data filled in.
+// The object and its revision are stored in one transaction.
+func (x *Order) Update(ctx context.Context, db DBTX, tenant string, id v2.ULID, version int64, data *Order) (int64, error) {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	stored, err := x.update(ctx, tx, tenant, id, version, data)
+	return stored, end(err)
+}
+
+// update makes the writes of Update in the transaction it began
+func (x *Order) update(ctx context.Context, db DBTX, tenant string, id v2.ULID, version int64, data *Order) (int64, error) {
+	data.stamp(ctx, false)
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	values, err := data.columnValues()
+	if err != nil {
+		return 0, err
+	}
+
+	var stored int64
+	err = db.QueryRow(ctx, orderUpdateQuery, append(append([]any{tenant, id}, values...), version)...).Scan(&stored)
+	if err != nil {
+		return 0, x.conflict(ctx, db, tenant, id, version, err)
+	}
+	current := new(Order)
+	if _, err := current.Get(ctx, db, tenant, id); err != nil {
+		return 0, err
+	}
+	data.CreatedAt = current.CreatedAt
+	if err := x.record(ctx, db, tenant, id, dep.RevisionUpdate, current); err != nil {
+		return 0, err
+	}
+	return stored, nil
+}
+
+// Patch function stores the fields of data named by mask in the object at the
+// given ID while it is at version, any version when it is 0, leaving the others
+// as they are, reads the result into x and returns the version it stored
+// The result is validated as a whole, the patch is rolled back when it is not valid.
+// The write and its revision are made in one transaction.
+func (x *Order) Patch(ctx context.Context, db DBTX, tenant string, id v2.ULID, version int64, data *Order, mask *fieldmaskpb.FieldMask) (int64, error) {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	stored, err := x.patch(ctx, tx, tenant, id, version, data, mask)
+	if err == nil {
+		err = x.Validate()
+	}
+	return stored, end(err)
+}
+
+// patch makes the writes of Patch in the transaction it began
+func (x *Order) patch(ctx context.Context, db DBTX, tenant string, id v2.ULID, version int64, data *Order, mask *fieldmaskpb.FieldMask) (int64, error) {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return 0, err
+	}
+	data.stamp(ctx, false)
+	paths = dep.StampPaths(paths, []string{"created_at"}, []string{"updated_by"})
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return 0, err
+	}
+
+	values, err := data.columnValues()
+	if err != nil {
+		return 0, err
+	}
+	set, values, err := dep.PatchColumns(dep.Postgres, 4, data, paths, orderColumns, values)
+	if err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf(orderPatchQuery, set)
+	var stored int64
+	err = x.scanColumns(db.QueryRow(ctx, query, append([]any{tenant, id, version}, values...)...), &stored)
+	if err != nil {
+		return 0, x.conflict(ctx, db, tenant, id, version, err)
+	}
+	if err := x.record(ctx, db, tenant, id, dep.RevisionPatch, x); err != nil {
+		return 0, err
+	}
+	return stored, nil
+}
+
+// Delete function will move the object at given ID to the trash while it is at
+// version, any version when it is 0
+// The write and its revision are made in one transaction.
+func (x *Order) Delete(ctx context.Context, db DBTX, tenant string, id v2.ULID, version int64) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.delete(ctx, tx, tenant, id, version)
+	return end(err)
+}
+
+// delete makes the writes of Delete in the transaction it began
+func (x *Order) delete(ctx context.Context, db DBTX, tenant string, id v2.ULID, version int64) error {
+	var stored int64
+	err := db.QueryRow(ctx, orderDeleteQuery, tenant, id, version).Scan(&stored)
+	if err != nil {
+		return x.conflict(ctx, db, tenant, id, version, err)
+	}
+	return x.record(ctx, db, tenant, id, dep.RevisionDelete, nil)
+}
+
+// Restore function brings the object at the given ID back from the trash
+// The write and its revision are made in one transaction.
+func (x *Order) Restore(ctx context.Context, db DBTX, tenant string, id v2.ULID) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.restore(ctx, tx, tenant, id)
+	return end(err)
+}
+
+// restore makes the writes of Restore in the transaction it began
+func (x *Order) restore(ctx context.Context, db DBTX, tenant string, id v2.ULID) error {
+	var found v2.ULID
+	err := db.QueryRow(ctx, orderRestoreQuery, tenant, id).Scan(&found)
+	if err != nil {
+		return err
+	}
+	current := new(Order)
+	if _, err := current.Get(ctx, db, tenant, id); err != nil {
+		return err
+	}
+	return x.record(ctx, db, tenant, id, dep.RevisionRestore, current)
+}
+
+// Purge function deletes the object at the given ID from the trash for good
+// The write and its revision are made in one transaction.
+func (x *Order) Purge(ctx context.Context, db DBTX, tenant string, id v2.ULID) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.purge(ctx, tx, tenant, id)
+	return end(err)
+}
+
+// purge makes the writes of Purge in the transaction it began
+func (x *Order) purge(ctx context.Context, db DBTX, tenant string, id v2.ULID) error {
+	var found v2.ULID
+	err := db.QueryRow(ctx, orderPurgeQuery, tenant, id).Scan(&found)
+	if err != nil {
+		return err
+	}
+	return x.record(ctx, db, tenant, id, dep.RevisionPurge, nil)
+}
+
+// conflict tells a record at another version from a missing one after a write
+// checking version found no row, returning dep.ErrConflict for the former
+func (x *Order) conflict(ctx context.Context, db DBTX, tenant string, id v2.ULID, version int64, err error) error {
+	if version == 0 || !errors.Is(err, v5.ErrNoRows) {
+		return err
+	}
+
+	var n int
+	if err := db.QueryRow(ctx, orderExistsQuery, tenant, id).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return err
+	}
+	return dep.ErrConflict
+}
+
+// record appends the revision of a write to the history of the object at the
+// given ID, value is the object as the write left it, nil when it is gone. It
+// has to run in the transaction of the write, whose lock on the row keeps
+// concurrent writes from taking the same revision
+func (x *Order) record(ctx context.Context, db DBTX, tenant string, id v2.ULID, operation string, value *Order) error {
+	data, err := dep.MarshalDocument(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, orderRevisionInsertQuery, tenant, id, operation, dep.Actor(ctx), data)
+	return err
+}
+
+// History function returns the revisions of the object at the given ID, oldest first
+func (x *Order) History(ctx context.Context, db DBTX, tenant string, id v2.ULID) ([]dep.Revision[*Order], error) {
+	rows, err := db.Query(ctx, orderHistoryQuery, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ret []dep.Revision[*Order]
+	for rows.Next() {
+		r, err := dep.ScanRevision[*Order](rows)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, r)
+	}
+	return ret, rows.Err()
+}
+
+// GetAtRevision function reads the object at the given ID as the given revision
+// left it into x, there is no row for revisions that removed it
+func (x *Order) GetAtRevision(ctx context.Context, db DBTX, tenant string, id v2.ULID, revision int64) error {
+	var data []byte
+	err := db.QueryRow(ctx, orderRevisionQuery, tenant, id, revision).Scan(&data)
+	if err != nil {
+		return err
+	}
+	return dep.UnmarshalDocument(data, x)
+}
+
+// OrderRepository stores Order records. Get, Update, Patch and Delete return
+// dep.ErrNotFound for unknown ids, Patch returns the record as stored.
+//
+// Records are versioned, from 1 on every write. Get returns the version and the
+// writes the one stored, Update, Patch and Delete fail with dep.ErrConflict when
+// the record is not at the version they are given, 0 skips the check.
+//
+// Delete moves records to a trash the other methods do not see, ListDeleted lists
+// it. Restore and Purge return dep.ErrNotFound for ids not in the trash.
+//
+// The writes fill in the audit fields of data from the time and the actor of ctx
+// (see dep.WithActor), Update and Patch keep the ones recording the creation.
+// Update copies those into data, leaving it as stored.
+//
+// The writes fail with dep.ErrAlreadyExists, an *dep.AlreadyExistsError naming the
+// fields, when another record of the tenant holds the values of a unique index.
+// The GetBy methods return the record holding them, dep.ErrNotFound for none.
+//
+// Every write adds a revision to the history of the record, History returns them
+// oldest first and GetAtRevision the record as one of them left it. Both return
+// dep.ErrNotFound for ids without a history and revisions that removed the record.
+type OrderRepository interface {
+	List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Order, v2.ULID], error)
+	Get(ctx context.Context, tenant string, id v2.ULID) (*Order, int64, error)
+	GetByCustomerAndSerial(ctx context.Context, tenant string, customer string, serial uint64) (*Order, v2.ULID, int64, error)
+	Create(ctx context.Context, tenant string, data *Order) (v2.ULID, error)
+	Update(ctx context.Context, tenant string, id v2.ULID, version int64, data *Order) (int64, error)
+	Patch(ctx context.Context, tenant string, id v2.ULID, version int64, data *Order, mask *fieldmaskpb.FieldMask) (*Order, int64, error)
+	Delete(ctx context.Context, tenant string, id v2.ULID, version int64) error
+	ListDeleted(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Order, v2.ULID], error)
+	Restore(ctx context.Context, tenant string, id v2.ULID) error
+	Purge(ctx context.Context, tenant string, id v2.ULID) error
+	History(ctx context.Context, tenant string, id v2.ULID) ([]dep.Revision[*Order], error)
+	GetAtRevision(ctx context.Context, tenant string, id v2.ULID, revision int64) (*Order, error)
+}
+
+// OrderSQLRepository is the OrderRepository backed by the Order persistence methods
+type OrderSQLRepository struct {
+	DB DBTX
+}
+
+// NewOrderSQLRepository returns a OrderSQLRepository using db
+func NewOrderSQLRepository(db DBTX) *OrderSQLRepository {
+	return &OrderSQLRepository{DB: db}
+}
+
+var _ OrderRepository = (*OrderSQLRepository)(nil)
+
+func (r *OrderSQLRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Order, v2.ULID], error) {
+	return new(Order).List(ctx, r.DB, tenant, opts)
+}
+
+func (r *OrderSQLRepository) Get(ctx context.Context, tenant string, id v2.ULID) (*Order, int64, error) {
+	x := new(Order)
+	version, err := x.Get(ctx, r.DB, tenant, id)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, 0, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return x, version, nil
+}
+
+func (r *OrderSQLRepository) GetByCustomerAndSerial(ctx context.Context, tenant string, customer string, serial uint64) (*Order, v2.ULID, int64, error) {
+	x := new(Order)
+	id, version, err := x.GetByCustomerAndSerial(ctx, r.DB, tenant, customer, serial)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, v2.ULID{}, 0, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, v2.ULID{}, 0, err
+	}
+
+	return x, id, version, nil
+}
+
+func (r *OrderSQLRepository) Create(ctx context.Context, tenant string, data *Order) (v2.ULID, error) {
+	id, err := data.Create(ctx, r.DB, tenant, data)
+	return id, dep.AlreadyExists(err, orderUniqueKeys...)
+}
+
+func (r *OrderSQLRepository) Update(ctx context.Context, tenant string, id v2.ULID, version int64, data *Order) (int64, error) {
+	stored, err := data.Update(ctx, r.DB, tenant, id, version, data)
+	if errors.Is(err, v5.ErrNoRows) {
+		return 0, dep.ErrNotFound
+	}
+	return stored, dep.AlreadyExists(err, orderUniqueKeys...)
+}
+
+func (r *OrderSQLRepository) Patch(ctx context.Context, tenant string, id v2.ULID, version int64, data *Order, mask *fieldmaskpb.FieldMask) (*Order, int64, error) {
+	x := new(Order)
+	stored, err := x.Patch(ctx, r.DB, tenant, id, version, data, mask)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, 0, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, dep.AlreadyExists(err, orderUniqueKeys...)
+	}
+
+	return x, stored, nil
+}
+
+func (r *OrderSQLRepository) Delete(ctx context.Context, tenant string, id v2.ULID, version int64) error {
+	err := new(Order).Delete(ctx, r.DB, tenant, id, version)
+	if errors.Is(err, v5.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+func (r *OrderSQLRepository) ListDeleted(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Order, v2.ULID], error) {
+	return new(Order).ListDeleted(ctx, r.DB, tenant, opts)
+}
+
+func (r *OrderSQLRepository) Restore(ctx context.Context, tenant string, id v2.ULID) error {
+	err := new(Order).Restore(ctx, r.DB, tenant, id)
+	if errors.Is(err, v5.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return dep.AlreadyExists(err, orderUniqueKeys...)
+}
+
+func (r *OrderSQLRepository) Purge(ctx context.Context, tenant string, id v2.ULID) error {
+	err := new(Order).Purge(ctx, r.DB, tenant, id)
+	if errors.Is(err, v5.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+func (r *OrderSQLRepository) History(ctx context.Context, tenant string, id v2.ULID) ([]dep.Revision[*Order], error) {
+	revisions, err := new(Order).History(ctx, r.DB, tenant, id)
+	if err == nil && len(revisions) == 0 {
+		return nil, dep.ErrNotFound
+	}
+	return revisions, err
+}
+
+func (r *OrderSQLRepository) GetAtRevision(ctx context.Context, tenant string, id v2.ULID, revision int64) (*Order, error) {
+	x := new(Order)
+	err := x.GetAtRevision(ctx, r.DB, tenant, id, revision)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// OrderMemoryRepository is a OrderRepository keeping records in memory, safe for
+// concurrent use. Records are copied on the way in and out.
+type OrderMemoryRepository struct {
+	mu      sync.RWMutex
+	tenants map[string]map[v2.ULID]*Order
+	// versions holds the version of every record by tenant and id
+	versions map[string]map[v2.ULID]int64
+	// deleted holds the records in the trash
+	deleted map[string]map[v2.ULID]*Order
+	// history holds the revisions of every record by tenant and id
+	history map[string]map[v2.ULID][]dep.Revision[*Order]
+}
+
+// NewOrderMemoryRepository returns an empty OrderMemoryRepository
+func NewOrderMemoryRepository() *OrderMemoryRepository {
+	return &OrderMemoryRepository{tenants: make(map[string]map[v2.ULID]*Order), versions: make(map[string]map[v2.ULID]int64), deleted: make(map[string]map[v2.ULID]*Order), history: make(map[string]map[v2.ULID][]dep.Revision[*Order])}
+}
+
+var _ OrderRepository = (*OrderMemoryRepository)(nil)
+
+// lookup returns the stored record, the lock has to be held
+func (r *OrderMemoryRepository) lookup(tenant string, id v2.ULID) (*Order, error) {
+	x, ok := r.tenants[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+// checkVersion fails with dep.ErrConflict when the record is not at version,
+// the lock has to be held
+func (r *OrderMemoryRepository) checkVersion(tenant string, id v2.ULID, version int64) error {
+	if version != 0 && version != r.versions[tenant][id] {
+		return dep.ErrConflict
+	}
+	return nil
+}
+
+// record adds the revision of a write leaving the record at value, nil when it
+// is gone, to its history. The lock has to be held
+func (r *OrderMemoryRepository) record(ctx context.Context, tenant string, id v2.ULID, operation string, value *Order) {
+	revisions := r.history[tenant][id]
+	revision := dep.Revision[*Order]{
+		Revision:  int64(len(revisions) + 1),
+		Operation: operation,
+		Actor:     dep.Actor(ctx),
+		ChangedAt: time.Now(),
+		New:       proto.Clone(value).(*Order),
+	}
+	if len(revisions) > 0 {
+		revision.Old = revisions[len(revisions)-1].New
+	}
+	if r.history[tenant] == nil {
+		r.history[tenant] = make(map[v2.ULID][]dep.Revision[*Order])
+	}
+	r.history[tenant][id] = append(revisions, revision)
+}
+
+func (r *OrderMemoryRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Order, v2.ULID], error) {
+	q, err := orderListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Order, v2.ULID], 0, len(r.tenants[tenant]))
+	for id, x := range r.tenants[tenant] {
+		records = append(records, dep.Record[*Order, v2.ULID]{ID: id, Value: proto.Clone(x).(*Order)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+func (r *OrderMemoryRepository) Get(ctx context.Context, tenant string, id v2.ULID) (*Order, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return proto.Clone(x).(*Order), r.versions[tenant][id], nil
+}
+
+func (r *OrderMemoryRepository) GetByCustomerAndSerial(ctx context.Context, tenant string, customer string, serial uint64) (*Order, v2.ULID, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for id, x := range r.tenants[tenant] {
+		if customer != "" && serial != 0 && x.GetCustomer() == customer && x.GetSerial() == serial {
+			return proto.Clone(x).(*Order), id, r.versions[tenant][id], nil
+		}
+	}
+	return nil, v2.ULID{}, 0, dep.ErrNotFound
+}
+
+// taken fails with dep.ErrAlreadyExists when a record other than the one at id
+// holds the unique fields of x, the lock has to be held. Zero values are not
+// taken, like the NULLs of the SQL indexes.
+func (r *OrderMemoryRepository) taken(tenant string, id v2.ULID, x *Order) error {
+	for other, y := range r.tenants[tenant] {
+		if other == id {
+			continue
+		}
+		if x.GetCustomer() != "" && x.GetSerial() != 0 && x.GetCustomer() == y.GetCustomer() && x.GetSerial() == y.GetSerial() {
+			return &dep.AlreadyExistsError{Fields: []string{"customer", "serial"}}
+		}
+	}
+	return nil
+}
+
+func (r *OrderMemoryRepository) Create(ctx context.Context, tenant string, data *Order) (v2.ULID, error) {
+	data.stamp(ctx, true)
+	if err := data.Validate(); err != nil {
+		return v2.ULID{}, err
+	}
+	id := v2.Make()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.taken(tenant, id, data); err != nil {
+		return v2.ULID{}, err
+	}
+	if r.tenants[tenant] == nil {
+		r.tenants[tenant] = make(map[v2.ULID]*Order)
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Order)
+	if r.versions[tenant] == nil {
+		r.versions[tenant] = make(map[v2.ULID]int64)
+	}
+	r.versions[tenant][id] = 1
+	r.record(ctx, tenant, id, dep.RevisionCreate, r.tenants[tenant][id])
+	return id, nil
+}
+
+func (r *OrderMemoryRepository) Update(ctx context.Context, tenant string, id v2.ULID, version int64, data *Order) (int64, error) {
+	data.stamp(ctx, false)
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.lookup(tenant, id)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.checkVersion(tenant, id, version); err != nil {
+		return 0, err
+	}
+	if err := r.taken(tenant, id, data); err != nil {
+		return 0, err
+	}
+	data.CreatedAt = stored.CreatedAt
+	r.tenants[tenant][id] = proto.Clone(data).(*Order)
+	r.versions[tenant][id]++
+	r.record(ctx, tenant, id, dep.RevisionUpdate, r.tenants[tenant][id])
+	return r.versions[tenant][id], nil
+}
+
+func (r *OrderMemoryRepository) Patch(ctx context.Context, tenant string, id v2.ULID, version int64, data *Order, mask *fieldmaskpb.FieldMask) (*Order, int64, error) {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return nil, 0, err
+	}
+	data.stamp(ctx, false)
+	paths = dep.StampPaths(paths, []string{"created_at"}, []string{"updated_by"})
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := r.checkVersion(tenant, id, version); err != nil {
+		return nil, 0, err
+	}
+	x = proto.Clone(x).(*Order)
+	dep.ApplyFieldMask(x, data, paths)
+	if err := x.Validate(); err != nil {
+		return nil, 0, err
+	}
+	if err := r.taken(tenant, id, x); err != nil {
+		return nil, 0, err
+	}
+	r.tenants[tenant][id] = x
+	r.versions[tenant][id]++
+	r.record(ctx, tenant, id, dep.RevisionPatch, x)
+	return proto.Clone(x).(*Order), r.versions[tenant][id], nil
+}
+
+func (r *OrderMemoryRepository) Delete(ctx context.Context, tenant string, id v2.ULID, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return err
+	}
+	if err := r.checkVersion(tenant, id, version); err != nil {
+		return err
+	}
+	if r.deleted[tenant] == nil {
+		r.deleted[tenant] = make(map[v2.ULID]*Order)
+	}
+	r.deleted[tenant][id] = x
+	delete(r.tenants[tenant], id)
+	r.record(ctx, tenant, id, dep.RevisionDelete, nil)
+	return nil
+}
+
+func (r *OrderMemoryRepository) ListDeleted(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Order, v2.ULID], error) {
+	q, err := orderListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Order, v2.ULID], 0, len(r.deleted[tenant]))
+	for id, x := range r.deleted[tenant] {
+		records = append(records, dep.Record[*Order, v2.ULID]{ID: id, Value: proto.Clone(x).(*Order)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+// trashed returns a record in the trash, the lock has to be held
+func (r *OrderMemoryRepository) trashed(tenant string, id v2.ULID) (*Order, error) {
+	x, ok := r.deleted[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+func (r *OrderMemoryRepository) Restore(ctx context.Context, tenant string, id v2.ULID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.trashed(tenant, id)
+	if err != nil {
+		return err
+	}
+	if err := r.taken(tenant, id, x); err != nil {
+		return err
+	}
+	if r.tenants[tenant] == nil {
+		r.tenants[tenant] = make(map[v2.ULID]*Order)
+	}
+	r.tenants[tenant][id] = x
+	delete(r.deleted[tenant], id)
+	r.record(ctx, tenant, id, dep.RevisionRestore, x)
+	return nil
+}
+
+func (r *OrderMemoryRepository) Purge(ctx context.Context, tenant string, id v2.ULID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.trashed(tenant, id); err != nil {
+		return err
+	}
+	delete(r.versions[tenant], id)
+	delete(r.deleted[tenant], id)
+	r.record(ctx, tenant, id, dep.RevisionPurge, nil)
+	return nil
+}
+
+func (r *OrderMemoryRepository) History(ctx context.Context, tenant string, id v2.ULID) ([]dep.Revision[*Order], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions, ok := r.history[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	ret := make([]dep.Revision[*Order], len(revisions))
+	for i, revision := range revisions {
+		revision.Old, revision.New = proto.Clone(revision.Old).(*Order), proto.Clone(revision.New).(*Order)
+		ret[i] = revision
+	}
+	return ret, nil
+}
+
+func (r *OrderMemoryRepository) GetAtRevision(ctx context.Context, tenant string, id v2.ULID, revision int64) (*Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := r.history[tenant][id]
+	if revision < 1 || revision > int64(len(revisions)) || revisions[revision-1].New == nil {
+		return nil, dep.ErrNotFound
+	}
+	return proto.Clone(revisions[revision-1].New).(*Order), nil
+}
+
+// ListHandler renders the page of objects selected by the page_size, page_token,
+// skip, filter and order_by query parameters
+func (h *OrderHandler) ListHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.List(req.Context(), h.tenant(req), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// ListDeletedHandler renders the page of objects in the trash selected by the
+// page_size, page_token, skip, filter and order_by query parameters
+func (h *OrderHandler) ListDeletedHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.ListDeleted(req.Context(), h.tenant(req), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// GetHandler renders the object at the {id} url parameter with its version as the
+// ETag, or answers a 304 when If-None-Match names that version
+func (h *OrderHandler) GetHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, version, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", dep.ETag(version))
+	if dep.NoneMatch(req, version) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.render(w, req, http.StatusOK, x)
+}
+
+// CreateHandler stores the object sent in the request body, the Location header
+// of the response names its url
+func (h *OrderHandler) CreateHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Order)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.Repo.Create(req.Context(), h.tenant(req), x)
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", path.Join(req.URL.Path, fmt.Sprint(id)))
+	// Records start at version 1
+	w.Header().Set("ETag", dep.ETag(1))
+	h.render(w, req, http.StatusCreated, x)
+}
+
+// UpdateHandler replaces the object at the {id} url parameter with the request body
+// when it is at the version If-Match names
+func (h *OrderHandler) UpdateHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Order)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := dep.IfMatch(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err = h.Repo.Update(req.Context(), h.tenant(req), id, version, x)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", dep.ETag(version))
+	h.render(w, req, http.StatusOK, x)
+}
+
+// PatchHandler stores the fields of the request body named by its mask in the object
+// at the {id} url parameter, when it is at the version If-Match names, and renders
+// the result
+func (h *OrderHandler) PatchHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Order)
+	mask, err := h.decodePatch(req, x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := dep.IfMatch(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, version, err := h.Repo.Patch(req.Context(), h.tenant(req), id, version, x, mask)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	var invalid dep.ValidationErrors
+	if errors.Is(err, dep.ErrInvalidArgument) || errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", dep.ETag(version))
+	h.render(w, req, http.StatusOK, ret)
+}
+
+// DeleteHandler moves the object at the {id} url parameter to the trash when it
+// is at the version If-Match names
+func (h *OrderHandler) DeleteHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	version, err := dep.IfMatch(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.Repo.Delete(req.Context(), h.tenant(req), id, version)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreHandler takes the object at the {id} url parameter back out of the trash
+func (h *OrderHandler) RestoreHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	err := h.Repo.Restore(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PurgeHandler deletes the object at the {id} url parameter from the trash for good
+func (h *OrderHandler) PurgeHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	err := h.Repo.Purge(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HistoryHandler renders the revisions of the object at the {id} url parameter,
+// as a table of the fields each one changed to htmx requests
+func (h *OrderHandler) HistoryHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	revisions, err := h.Repo.History(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		dep.RenderHistory(w, revisions)
+		return
+	}
+
+	// Revisions encode their values with protojson
+	jsonData, err := json.Marshal(revisions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// RevisionHandler renders the object at the {id} url parameter as the revision in
+// the {revision} url parameter left it
+func (h *OrderHandler) RevisionHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	revision, err := strconv.ParseInt(chi.URLParam(req, "revision"), 10, 64)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, err := h.Repo.GetAtRevision(req.Context(), h.tenant(req), id, revision)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// FormHandler renders the form for a new object, or for editing the object at the {id} url parameter
+func (h *OrderHandler) FormHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Order)
+	var version int64
+	if chi.URLParam(req, "id") != "" {
+		id, ok := h.id(req)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		found, v, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+		if errors.Is(err, dep.ErrNotFound) {
+			http.NotFound(w, req)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		x, version = found, v
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if version != 0 {
+		// htmx sends no If-Match, the form carries the version it shows instead
+		fmt.Fprintf(w, "<input type=\"hidden\" name=\"%s\" value=\"%d\">\n", dep.VersionFormValue, version)
+	}
+	if err := x.RenderForm(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// alreadyExists answers a write that found unique fields of x taken with a 409,
+// htmx requests get the form back with the fields marked
+func (h *OrderHandler) alreadyExists(w http.ResponseWriter, req *http.Request, x *Order, err error) {
+	if req.Header.Get("HX-Request") != "true" {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	var errs dep.ValidationErrors
+	var exists *dep.AlreadyExistsError
+	if errors.As(err, &exists) {
+		errs = exists.ValidationErrors()
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusConflict)
+	if version, err := dep.IfMatch(req); err == nil && version > 0 {
+		// The form keeps the version it was submitted with
+		fmt.Fprintf(w, "<input type=\"hidden\" name=\"%s\" value=\"%d\">\n", dep.VersionFormValue, version)
+	}
+	x.RenderFormErrors(w, errs)
+}
+
+// decode reads the object from a protojson body, skipping unknown fields, or from
+// a submitted form
+func (h *OrderHandler) decode(req *http.Request, x *Order) error {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		if _, err := x.HandleForm(req); err != nil {
+			return err
+		}
+		// HandleForm only validates the fields the form submitted
+		return x.Validate()
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, x); err != nil {
+		return err
+	}
+
+	return x.Validate()
+}
+
+// decodePatch reads the fields to patch from a submitted form, masking the ones it
+// holds, or from a protojson body masked by the update_mask query parameter or by
+// the fields present in the body, which may not name unknown fields
+func (h *OrderHandler) decodePatch(req *http.Request, x *Order) (*fieldmaskpb.FieldMask, error) {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		return x.HandleForm(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(body, x); err != nil {
+		return nil, err
+	}
+
+	return dep.ParseFieldMask(req.URL.Query(), body)
+}
+
+// render writes the object as protojson, or as html to htmx requests
+func (h *OrderHandler) render(w http.ResponseWriter, req *http.Request, status int, x *Order) {
+	if req.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		x.RenderView(w)
+		return
+	}
+
+	jsonData, err := protojson.Marshal(x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
+
+// Routes returns a chi.Router with the Order endpoints that can be mounted to a parent router
+func (h *OrderHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListHandler)
+	r.Post("/", h.CreateHandler)
+	r.Get("/new", h.FormHandler)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.GetHandler)
+		r.Put("/", h.UpdateHandler)
+		r.Patch("/", h.PatchHandler)
+		r.Get("/edit", h.FormHandler)
+		r.Delete("/", h.DeleteHandler)
+		r.Get("/history", h.HistoryHandler)
+		r.Get("/history/{revision}", h.RevisionHandler)
+	})
+	r.Get("/deleted", h.ListDeletedHandler)
+	r.Route("/deleted/{id}", func(r chi.Router) {
+		r.Post("/restore", h.RestoreHandler)
+		r.Delete("/", h.PurgeHandler)
+	})
+
+	return r
+}
+
+// A simple function to handle a htmx form and populate the struct, returning
+// the mask of the fields the form submitted. Values that fail to parse are
+// collected per field before the submitted fields are validated.
+func (x *Order) HandleForm(req *http.Request) (*fieldmaskpb.FieldMask, error) {
+	if err := req.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return nil, err
+	}
+
+	errs := make(dep.ValidationErrors)
+	x.Customer = req.FormValue("Order__Customer")
+	if v := req.FormValue("Order__Count"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 32); err != nil {
+			errs.Add("count", "must be a whole number")
+		} else {
+			value := int32(n)
+			x.Count = value
+		}
+	}
+	if v := req.FormValue("Order__Total"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err != nil {
+			errs.Add("total", "must be a whole number")
+		} else {
+			value := int64(n)
+			x.Total = value
+		}
+	}
+	if v := req.FormValue("Order__Weight"); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 32); err != nil {
+			errs.Add("weight", "must be a positive whole number")
+		} else {
+			value := uint32(n)
+			x.Weight = value
+		}
+	}
+	if v := req.FormValue("Order__Serial"); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err != nil {
+			errs.Add("serial", "must be a positive whole number")
+		} else {
+			value := uint64(n)
+			x.Serial = value
+		}
+	}
+	if v := req.FormValue("Order__Discount"); v != "" {
+		if n, err := strconv.ParseFloat(v, 32); err != nil {
+			errs.Add("discount", "must be a number")
+		} else {
+			value := float32(n)
+			x.Discount = value
+		}
+	}
+	if v := req.FormValue("Order__Rate"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err != nil {
+			errs.Add("rate", "must be a number")
+		} else {
+			value := float64(n)
+			x.Rate = value
+		}
+	}
+	{
+		v := req.FormValue("Order__Paid")
+		value := v != "" && v != "false" && v != "off" && v != "0"
+		x.Paid = value
+	}
+	if file, _, err := req.FormFile("Order__Receipt"); err == nil {
+		value, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			errs.Add("receipt", "could not be read")
+		} else {
+			x.Receipt = value
+		}
+	} else if v := req.FormValue("Order__Receipt"); v != "" {
+		if value, err := base64.StdEncoding.DecodeString(v); err != nil {
+			errs.Add("receipt", "must be base64 encoded")
+		} else {
+			x.Receipt = value
+		}
+	}
+	if v := req.FormValue("Order__Priority"); v != "" {
+		// Either the name or the number of a defined enum value
+		if n, ok := Priority_value[v]; ok {
+			value := Priority(n)
+			x.Priority = value
+		} else if n, err := strconv.ParseInt(v, 10, 32); err == nil && Priority_name[int32(n)] != "" {
+			value := Priority(n)
+			x.Priority = value
+		} else {
+			errs.Add("priority", "must be one of the defined values")
+		}
+	}
+	if v := req.FormValue("Order__PlacedAt"); v != "" {
+		var parsed bool
+		for _, layout := range []string{"2006-01-02T15:04:05Z07:00", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				value := timestamppb.New(t)
+				x.PlacedAt = value
+				parsed = true
+				break
+			}
+		}
+		if !parsed {
+			errs.Add("placed_at", "must be a date")
+		}
+	}
+	for key := range req.Form {
+		if strings.HasPrefix(key, "Order__FirstLine.") {
+			if x.FirstLine == nil {
+				x.FirstLine = new(Line)
+			}
+			break
+		}
+	}
+	if x.FirstLine != nil {
+		x.FirstLine.Sku = req.FormValue("Order__FirstLine.Sku")
+		if v := req.FormValue("Order__FirstLine.Quantity"); v != "" {
+			if n, err := strconv.ParseInt(v, 10, 32); err != nil {
+				errs.Add("first_line.quantity", "must be a whole number")
+			} else {
+				value := int32(n)
+				x.FirstLine.Quantity = value
+			}
+		}
+	}
+	if _, ok := req.Form["Order__Tags"]; ok {
+		x.Tags = nil
+		// One item per line
+		for _, v := range strings.Split(req.FormValue("Order__Tags"), "\n") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			value := v
+			x.Tags = append(x.Tags, value)
+		}
+	}
+	if _, ok := req.Form["Order__Scores"]; ok {
+		x.Scores = nil
+		// One item per line
+		for _, v := range strings.Split(req.FormValue("Order__Scores"), "\n") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			if n, err := strconv.ParseInt(v, 10, 32); err != nil {
+				errs.Add("scores", "must be a whole number")
+			} else {
+				value := int32(n)
+				x.Scores = append(x.Scores, value)
+			}
+		}
+	}
+	if _, ok := req.Form["Order__Flags"]; ok {
+		x.Flags = nil
+		// One item per line
+		for _, v := range strings.Split(req.FormValue("Order__Flags"), "\n") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			// Either the name or the number of a defined enum value
+			if n, ok := Priority_value[v]; ok {
+				value := Priority(n)
+				x.Flags = append(x.Flags, value)
+			} else if n, err := strconv.ParseInt(v, 10, 32); err == nil && Priority_name[int32(n)] != "" {
+				value := Priority(n)
+				x.Flags = append(x.Flags, value)
+			} else {
+				errs.Add("flags", "must be one of the defined values")
+			}
+		}
+	}
+	if v := req.FormValue("Order__Note"); v != "" {
+		value := v
+		x.Note = &value
+	}
+	if v := req.FormValue("Order__Escalation"); v != "" {
+		// Either the name or the number of a defined enum value
+		if n, ok := Priority_value[v]; ok {
+			value := Priority(n)
+			x.Escalation = &value
+		} else if n, err := strconv.ParseInt(v, 10, 32); err == nil && Priority_name[int32(n)] != "" {
+			value := Priority(n)
+			x.Escalation = &value
+		} else {
+			errs.Add("escalation", "must be one of the defined values")
+		}
+	}
+	if v := req.FormValue("Order__Address"); v != "" {
+		value := v
+		x.Delivery = &Order_Address{Address: value}
+	}
+	if v := req.FormValue("Order__Speed"); v != "" {
+		// Either the name or the number of a defined enum value
+		if n, ok := Priority_value[v]; ok {
+			value := Priority(n)
+			x.Delivery = &Order_Speed{Speed: value}
+		} else if n, err := strconv.ParseInt(v, 10, 32); err == nil && Priority_name[int32(n)] != "" {
+			value := Priority(n)
+			x.Delivery = &Order_Speed{Speed: value}
+		} else {
+			errs.Add("speed", "must be one of the defined values")
+		}
+	}
+	if v := req.FormValue("Order__PickupAt"); v != "" {
+		var parsed bool
+		for _, layout := range []string{"2006-01-02T15:04:05Z07:00", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				value := timestamppb.New(t)
+				x.Delivery = &Order_PickupAt{PickupAt: value}
+				parsed = true
+				break
+			}
+		}
+		if !parsed {
+			errs.Add("pickup_at", "must be a date")
+		}
+	}
+	if file, _, err := req.FormFile("Order__Label"); err == nil {
+		value, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			errs.Add("label", "could not be read")
+		} else {
+			x.Delivery = &Order_Label{Label: value}
+		}
+	} else if v := req.FormValue("Order__Label"); v != "" {
+		if value, err := base64.StdEncoding.DecodeString(v); err != nil {
+			errs.Add("label", "must be base64 encoded")
+		} else {
+			x.Delivery = &Order_Label{Label: value}
+		}
+	}
+	if v := req.FormValue("Order__Locker"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err != nil {
+			errs.Add("locker", "must be a whole number")
+		} else {
+			value := int64(n)
+			x.Delivery = &Order_Locker{Locker: value}
+		}
+	}
+	if v := req.FormValue("Order__Dock"); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err != nil {
+			errs.Add("dock", "must be a positive whole number")
+		} else {
+			value := uint64(n)
+			x.Delivery = &Order_Dock{Dock: value}
+		}
+	}
+	if v := req.FormValue("Order__Batch"); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err != nil {
+			errs.Add("batch", "must be a positive whole number")
+		} else {
+			value := uint64(n)
+			x.Batch = &value
+		}
+	}
+	if _, ok := req.Form["Order__Parts"]; ok {
+		x.Parts = nil
+		// One item per line
+		for _, v := range strings.Split(req.FormValue("Order__Parts"), "\n") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			if n, err := strconv.ParseUint(v, 10, 64); err != nil {
+				errs.Add("parts", "must be a positive whole number")
+			} else {
+				value := uint64(n)
+				x.Parts = append(x.Parts, value)
+			}
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	mask := new(fieldmaskpb.FieldMask)
+	if dep.FormHas(req.Form, "Order__Customer") {
+		mask.Paths = append(mask.Paths, "customer")
+	}
+	if dep.FormHas(req.Form, "Order__Count") {
+		mask.Paths = append(mask.Paths, "count")
+	}
+	if dep.FormHas(req.Form, "Order__Total") {
+		mask.Paths = append(mask.Paths, "total")
+	}
+	if dep.FormHas(req.Form, "Order__Weight") {
+		mask.Paths = append(mask.Paths, "weight")
+	}
+	if dep.FormHas(req.Form, "Order__Serial") {
+		mask.Paths = append(mask.Paths, "serial")
+	}
+	if dep.FormHas(req.Form, "Order__Discount") {
+		mask.Paths = append(mask.Paths, "discount")
+	}
+	if dep.FormHas(req.Form, "Order__Rate") {
+		mask.Paths = append(mask.Paths, "rate")
+	}
+	if dep.FormHas(req.Form, "Order__Paid") {
+		mask.Paths = append(mask.Paths, "paid")
+	}
+	if req.FormValue("Order__Receipt") != "" || req.MultipartForm != nil && len(req.MultipartForm.File["Order__Receipt"]) > 0 {
+		mask.Paths = append(mask.Paths, "receipt")
+	}
+	if dep.FormHas(req.Form, "Order__Priority") {
+		mask.Paths = append(mask.Paths, "priority")
+	}
+	if dep.FormHas(req.Form, "Order__PlacedAt") {
+		mask.Paths = append(mask.Paths, "placed_at")
+	}
+	if dep.FormHas(req.Form, "Order__FirstLine") {
+		mask.Paths = append(mask.Paths, "first_line")
+	}
+	if dep.FormHas(req.Form, "Order__Tags") {
+		mask.Paths = append(mask.Paths, "tags")
+	}
+	if dep.FormHas(req.Form, "Order__Scores") {
+		mask.Paths = append(mask.Paths, "scores")
+	}
+	if dep.FormHas(req.Form, "Order__Flags") {
+		mask.Paths = append(mask.Paths, "flags")
+	}
+	if dep.FormHas(req.Form, "Order__Note") {
+		mask.Paths = append(mask.Paths, "note")
+	}
+	if dep.FormHas(req.Form, "Order__Escalation") {
+		mask.Paths = append(mask.Paths, "escalation")
+	}
+	if dep.FormHas(req.Form, "Order__Address") {
+		mask.Paths = append(mask.Paths, "address")
+	}
+	if dep.FormHas(req.Form, "Order__Speed") {
+		mask.Paths = append(mask.Paths, "speed")
+	}
+	if dep.FormHas(req.Form, "Order__PickupAt") {
+		mask.Paths = append(mask.Paths, "pickup_at")
+	}
+	if req.FormValue("Order__Label") != "" || req.MultipartForm != nil && len(req.MultipartForm.File["Order__Label"]) > 0 {
+		mask.Paths = append(mask.Paths, "label")
+	}
+	if dep.FormHas(req.Form, "Order__Locker") {
+		mask.Paths = append(mask.Paths, "locker")
+	}
+	if dep.FormHas(req.Form, "Order__Dock") {
+		mask.Paths = append(mask.Paths, "dock")
+	}
+	if dep.FormHas(req.Form, "Order__Batch") {
+		mask.Paths = append(mask.Paths, "batch")
+	}
+	if dep.FormHas(req.Form, "Order__Parts") {
+		mask.Paths = append(mask.Paths, "parts")
+	}
+	return mask, dep.MaskedErrors(x.Validate(), mask.Paths)
+}
+
+var orderViewTemplate = template.Must(template.New("view").Parse(`
+<p class="w-16">
+  <span>Customer</span>
+  <span> {{ .Customer }} </span>
+</p>
+<p class="w-16">
+  <span>Count</span>
+  <span> {{ .Count }} </span>
+</p>
+<p class="w-16">
+  <span>Total</span>
+  <span> {{ .Total }} </span>
+</p>
+<p class="w-16">
+  <span>Weight</span>
+  <span> {{ .Weight }} </span>
+</p>
+<p class="w-16">
+  <span>Serial</span>
+  <span> {{ .Serial }} </span>
+</p>
+<p class="w-16">
+  <span>Discount</span>
+  <span> {{ .Discount }} </span>
+</p>
+<p class="w-16">
+  <span>Rate</span>
+  <span> {{ .Rate }} </span>
+</p>
+<p class="w-16">
+  <span>Paid</span>
+  <span> {{ .Paid }} </span>
+</p>
+<p class="w-16">
+  <span>Receipt</span>
+  <span> {{ .Receipt }} </span>
+</p>
+<p class="w-16">
+  <span>Priority</span>
+  <span> {{ .Priority }} </span>
+</p>
+<p class="w-16">
+  <span>PlacedAt</span>
+  <span> {{ with .PlacedAt }}{{ .AsTime.Format "2006-01-02 15:04" }}{{ end }} </span>
+</p>
+<p class="w-16">
+  <span>FirstLine</span>
+  <span> {{ .FirstLine }} </span>
+</p>
+<p class="w-16">
+  <span>Tags</span>
+  <span> {{ .Tags }} </span>
+</p>
+<p class="w-16">
+  <span>Scores</span>
+  <span> {{ .Scores }} </span>
+</p>
+<p class="w-16">
+  <span>Flags</span>
+  <span> {{ .Flags }} </span>
+</p>
+<p class="w-16">
+  <span>Lines</span>
+  <span> {{ .Lines }} </span>
+</p>
+<p class="w-16">
+  <span>Totals</span>
+  <span> {{ .Totals }} </span>
+</p>
+<p class="w-16">
+  <span>Note</span>
+  <span> {{ .GetNote }} </span>
+</p>
+<p class="w-16">
+  <span>Escalation</span>
+  <span> {{ .GetEscalation }} </span>
+</p>
+<p class="w-16">
+  <span>Address</span>
+  <span> {{ .GetAddress }} </span>
+</p>
+<p class="w-16">
+  <span>Speed</span>
+  <span> {{ .GetSpeed }} </span>
+</p>
+<p class="w-16">
+  <span>PickupAt</span>
+  <span> {{ with .PickupAt }}{{ .AsTime.Format "2006-01-02 15:04" }}{{ end }} </span>
+</p>
+<p class="w-16">
+  <span>Parcel</span>
+  <span> {{ .GetParcel }} </span>
+</p>
+<p class="w-16">
+  <span>Label</span>
+  <span> {{ .GetLabel }} </span>
+</p>
+<p class="w-16">
+  <span>Locker</span>
+  <span> {{ .GetLocker }} </span>
+</p>
+<p class="w-16">
+  <span>Dock</span>
+  <span> {{ .GetDock }} </span>
+</p>
+<p class="w-16">
+  <span>CreatedAt</span>
+  <span> {{ with .CreatedAt }}{{ .AsTime.Format "2006-01-02 15:04" }}{{ end }} </span>
+</p>
+<p class="w-16">
+  <span>UpdatedBy</span>
+  <span> {{ .UpdatedBy }} </span>
+</p>
+<p class="w-16">
+  <span>Batch</span>
+  <span> {{ .GetBatch }} </span>
+</p>
+<p class="w-16">
+  <span>Parts</span>
+  <span> {{ .Parts }} </span>
+</p>
+<p class="w-16">
+  <span>Stock</span>
+  <span> {{ .Stock }} </span>
+</p>
+<p class="w-16">
+  <span>Levels</span>
+  <span> {{ .Levels }} </span>
+</p>
+<p class="w-16">
+  <span>Deadlines</span>
+  <span> {{ .Deadlines }} </span>
+</p>
+`))
+
+// RenderView will take in a http writer and object to render the view
+func (x *Order) RenderView(w http.ResponseWriter) error {
+	return orderViewTemplate.Execute(w, x)
+}
+
+var orderFormTemplate = template.Must(template.New("form").Parse(`{{ with .Value }}
+<label class="w-16">
+  <span>Customer</span>
+  <input type="text" name="Order__Customer" value="{{ .Customer }}" required>
+  {{ with index $.Errors "customer" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Count</span>
+  <input type="number" name="Order__Count" value="{{ .Count }}">
+  {{ with index $.Errors "count" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Total</span>
+  <input type="number" name="Order__Total" value="{{ .Total }}">
+  {{ with index $.Errors "total" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Weight</span>
+  <input type="number" name="Order__Weight" value="{{ .Weight }}">
+  {{ with index $.Errors "weight" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Serial</span>
+  <input type="number" name="Order__Serial" value="{{ .Serial }}">
+  {{ with index $.Errors "serial" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Discount</span>
+  <input type="number" name="Order__Discount" value="{{ .Discount }}">
+  {{ with index $.Errors "discount" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Rate</span>
+  <input type="number" name="Order__Rate" value="{{ .Rate }}">
+  {{ with index $.Errors "rate" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Paid</span>
+  <input type="checkbox" name="Order__Paid" value="on"{{ if .Paid }} checked{{ end }}>
+  <input type="hidden" name="Order__Paid" value="off">
+  {{ with index $.Errors "paid" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Receipt</span>
+  <input type="file" name="Order__Receipt">
+  {{ with index $.Errors "receipt" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Priority</span>
+  <select name="Order__Priority">
+    <option value="PRIORITY_UNSPECIFIED"{{ if eq (print .Priority) "PRIORITY_UNSPECIFIED" }} selected{{ end }}>PRIORITY_UNSPECIFIED</option>
+    <option value="PRIORITY_LOW"{{ if eq (print .Priority) "PRIORITY_LOW" }} selected{{ end }}>PRIORITY_LOW</option>
+    <option value="PRIORITY_HIGH"{{ if eq (print .Priority) "PRIORITY_HIGH" }} selected{{ end }}>PRIORITY_HIGH</option>
+  </select>
+  {{ with index $.Errors "priority" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>PlacedAt</span>
+  <input type="datetime-local" name="Order__PlacedAt" value="{{ with .PlacedAt }}{{ .AsTime.Format "2006-01-02T15:04:05" }}{{ end }}" step="1">
+  {{ with index $.Errors "placed_at" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<fieldset>
+  <legend>FirstLine</legend>
+<label class="w-16">
+  <span>Sku</span>
+  <input type="text" name="Order__FirstLine.Sku" value="{{ with .FirstLine }}{{ .Sku }}{{ end }}">
+  {{ with index $.Errors "first_line.sku" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Quantity</span>
+  <input type="number" name="Order__FirstLine.Quantity" value="{{ with .FirstLine }}{{ .Quantity }}{{ end }}">
+  {{ with index $.Errors "first_line.quantity" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+</fieldset>
+<label class="w-16">
+  <span>Tags</span>
+  <textarea name="Order__Tags">{{ range $i, $v := .Tags }}{{ if $i }}&#10;{{ end }}{{ $v }}{{ end }}</textarea>
+  {{ with index $.Errors "tags" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Scores</span>
+  <textarea name="Order__Scores">{{ range $i, $v := .Scores }}{{ if $i }}&#10;{{ end }}{{ $v }}{{ end }}</textarea>
+  {{ with index $.Errors "scores" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Flags</span>
+  <textarea name="Order__Flags">{{ range $i, $v := .Flags }}{{ if $i }}&#10;{{ end }}{{ $v }}{{ end }}</textarea>
+  {{ with index $.Errors "flags" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Note</span>
+  <input type="text" name="Order__Note" value="{{ .GetNote }}">
+  {{ with index $.Errors "note" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Escalation</span>
+  <select name="Order__Escalation">
+    <option value="PRIORITY_UNSPECIFIED"{{ if eq (print .GetEscalation) "PRIORITY_UNSPECIFIED" }} selected{{ end }}>PRIORITY_UNSPECIFIED</option>
+    <option value="PRIORITY_LOW"{{ if eq (print .GetEscalation) "PRIORITY_LOW" }} selected{{ end }}>PRIORITY_LOW</option>
+    <option value="PRIORITY_HIGH"{{ if eq (print .GetEscalation) "PRIORITY_HIGH" }} selected{{ end }}>PRIORITY_HIGH</option>
+  </select>
+  {{ with index $.Errors "escalation" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Address</span>
+  <input type="text" name="Order__Address" value="{{ .GetAddress }}">
+  {{ with index $.Errors "address" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Speed</span>
+  <select name="Order__Speed">
+    <option value="PRIORITY_UNSPECIFIED"{{ if eq (print .GetSpeed) "PRIORITY_UNSPECIFIED" }} selected{{ end }}>PRIORITY_UNSPECIFIED</option>
+    <option value="PRIORITY_LOW"{{ if eq (print .GetSpeed) "PRIORITY_LOW" }} selected{{ end }}>PRIORITY_LOW</option>
+    <option value="PRIORITY_HIGH"{{ if eq (print .GetSpeed) "PRIORITY_HIGH" }} selected{{ end }}>PRIORITY_HIGH</option>
+  </select>
+  {{ with index $.Errors "speed" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>PickupAt</span>
+  <input type="datetime-local" name="Order__PickupAt" value="{{ with .GetPickupAt }}{{ .AsTime.Format "2006-01-02T15:04:05" }}{{ end }}" step="1">
+  {{ with index $.Errors "pickup_at" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Label</span>
+  <input type="file" name="Order__Label">
+  {{ with index $.Errors "label" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Locker</span>
+  <input type="number" name="Order__Locker" value="{{ .GetLocker }}">
+  {{ with index $.Errors "locker" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Dock</span>
+  <input type="number" name="Order__Dock" value="{{ .GetDock }}">
+  {{ with index $.Errors "dock" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>CreatedAt</span>
+  <input type="datetime-local" name="Order__CreatedAt" value="{{ with .CreatedAt }}{{ .AsTime.Format "2006-01-02T15:04:05" }}{{ end }}" disabled step="1">
+  {{ with index $.Errors "created_at" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>UpdatedBy</span>
+  <input type="text" name="Order__UpdatedBy" value="{{ .UpdatedBy }}" disabled>
+  {{ with index $.Errors "updated_by" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Batch</span>
+  <input type="number" name="Order__Batch" value="{{ .GetBatch }}">
+  {{ with index $.Errors "batch" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>Parts</span>
+  <textarea name="Order__Parts">{{ range $i, $v := .Parts }}{{ if $i }}&#10;{{ end }}{{ $v }}{{ end }}</textarea>
+  {{ with index $.Errors "parts" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+{{ end }}`))
+
+// RenderForm will take in a http writer and render a htmx form for the object
+func (x *Order) RenderForm(w http.ResponseWriter) error {
+	return x.RenderFormErrors(w, nil)
+}
+
+// RenderFormErrors renders the htmx form for the object with errs shown next to
+// the inputs of their fields
+func (x *Order) RenderFormErrors(w http.ResponseWriter, errs dep.ValidationErrors) error {
+	return orderFormTemplate.Execute(w, dep.FormData{Value: x, Errors: errs})
+}
+
+// Validate checks the constraints declared on the fields of Order
+func (x *Order) Validate() error {
+	errs := make(dep.ValidationErrors)
+	if x.Customer == "" {
+		errs.Add("customer", "is required")
+	}
+	return errs.Err()
+}
+
+// TableName returns the name of the table backing Order
+func (*Order) TableName() string {
+	return "order"
+}
+
+// WarehouseHandler serves the http routes of Warehouse
+type WarehouseHandler struct {
+	Repo WarehouseRepository
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// NewWarehouseHandler returns a WarehouseHandler backed by repo
+func NewWarehouseHandler(repo WarehouseRepository) *WarehouseHandler {
+	return &WarehouseHandler{Repo: repo}
+}
+
+func (h *WarehouseHandler) tenant(req *http.Request) string {
+	if h.Tenant != nil {
+		return h.Tenant(req)
+	}
+	return chi.URLParam(req, "tenant")
+}
+
+// id reads the {id} url parameter, false when it holds no valid id
+func (h *WarehouseHandler) id(req *http.Request) (int32, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(req, "id"), 10, 32)
+	return int32(id), err == nil
+}
+
+// Statements backing Warehouse, values follow the order of the fields
+const (
+	warehouseCountQuery          = "SELECT count(*) FROM warehouse WHERE tenant = $1"
+	warehouseListQuery           = "SELECT id, number, city FROM warehouse WHERE tenant = $1"
+	warehouseGetQuery            = "SELECT number, city FROM warehouse WHERE tenant = $1 AND id = $2"
+	warehouseInsertQuery         = "INSERT INTO warehouse (tenant, number, city, id) VALUES ($1, $2, $3, $4)"
+	warehouseUpdateQuery         = "UPDATE warehouse SET number = $3, city = $4 WHERE tenant = $1 AND id = $2 RETURNING id"
+	warehousePatchQuery          = "UPDATE warehouse SET %s WHERE tenant = $1 AND id = $2 RETURNING number, city"
+	warehouseDeleteQuery         = "DELETE FROM warehouse WHERE tenant = $1 AND id = $2 RETURNING id"
+	warehouseRevisionInsertQuery = "INSERT INTO warehouse_history (tenant, id, revision, operation, actor, old_data, new_data) SELECT $1, $2, COALESCE(MAX(revision), 0) + 1, $3, $4, (SELECT new_data FROM warehouse_history WHERE tenant = $1 AND id = $2 ORDER BY revision DESC LIMIT 1), $5::jsonb FROM warehouse_history WHERE tenant = $1 AND id = $2"
+	warehouseHistoryQuery        = "SELECT revision, operation, actor, changed_at, old_data, new_data FROM warehouse_history WHERE tenant = $1 AND id = $2 ORDER BY revision"
+	warehouseRevisionQuery       = "SELECT new_data FROM warehouse_history WHERE tenant = $1 AND id = $2 AND revision = $3 AND new_data IS NOT NULL"
+)
+
+// warehouseColumns names the columns of Warehouse in the order of the fields
+var warehouseColumns = []string{"number", "city"}
+
+// warehouseUniqueKeys are the unique indexes of Warehouse, for dep.AlreadyExists
+var warehouseUniqueKeys = []dep.UniqueKey{
+	{
+		Index:   "warehouse_pkey",
+		Columns: "warehouse.tenant, warehouse.id",
+		Fields:  []string{"number"},
+	},
+}
+
+// columnValues returns the values of the columns backing x in field order
+func (x *Warehouse) columnValues() ([]any, error) {
+	values := make([]any, 0, 2)
+	values = append(values, x.Number)
+	values = append(values, x.City)
+
+	return values, nil
+}
+
+// scanColumns scans a row holding dest followed by the columns backing x,
+// replacing what x held
+func (x *Warehouse) scanColumns(row interface{ Scan(...any) error }, dest ...any) error {
+	proto.Reset(x)
+	dest = append(dest,
+		&x.Number,
+		&x.City,
+	)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// warehouseListSchema holds the fields List can filter and order by
+var warehouseListSchema = &dep.Schema{
+	Message: new(Warehouse),
+	Dialect: dep.Postgres,
+	Fields: []dep.ListField{
+		{Name: "number", Expr: "number", Filter: false, Sort: true},
+		{Name: "city", Expr: "city", Filter: true, Sort: false},
+	},
+	ID: int32(0),
+}
+
+// List function returns the page of these objects opts selects
+func (x *Warehouse) List(ctx context.Context, db DBTX, tenant string, opts dep.ListOptions) (*dep.Page[*Warehouse, int32], error) {
+	q, err := warehouseListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := new(dep.Page[*Warehouse, int32])
+	query, args := q.Count(warehouseCountQuery, tenant)
+	if err := db.QueryRow(ctx, query, args...).Scan(&ret.TotalSize); err != nil {
+		return nil, err
+	}
+
+	query, args = q.Select(warehouseListQuery, tenant)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Items, err = v5.CollectRows(rows, func(rows v5.CollectableRow) (dep.Record[*Warehouse, int32], error) {
+		row := new(Warehouse)
+		var id int32
+		err := row.scanColumns(rows, &id)
+		return dep.Record[*Warehouse, int32]{ID: id, Value: row}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dep.Paginate(q, ret)
+	return ret, nil
+}
+
+// Get function acquires a single record based on ID in database
+func (x *Warehouse) Get(ctx context.Context, db DBTX, tenant string, id int32) error {
+	return x.scanColumns(db.QueryRow(ctx, warehouseGetQuery, tenant, id))
+}
+
+// Create function will create a new object of this type and return its ID
+// The object and its first revision are stored in one transaction.
+func (x *Warehouse) Create(ctx context.Context, db DBTX, tenant string, data *Warehouse) (int32, error) {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	id, err := x.create(ctx, tx, tenant, data)
+	return id, end(err)
+}
+
+// create makes the writes of Create in the transaction it began
+func (x *Warehouse) create(ctx context.Context, db DBTX, tenant string, data *Warehouse) (int32, error) {
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+
+	id := data.Number
+	values, err := data.columnValues()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = db.Exec(ctx, warehouseInsertQuery, append(append([]any{tenant}, values...), id)...)
+	if err != nil {
+		return 0, err
+	}
+	if err := x.record(ctx, db, tenant, id, dep.RevisionCreate, data); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Update function will replace the object stored at the given ID
+// The object and its revision are stored in one transaction.
+func (x *Warehouse) Update(ctx context.Context, db DBTX, tenant string, id int32, data *Warehouse) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.update(ctx, tx, tenant, id, data)
+	return end(err)
+}
+
+// update makes the writes of Update in the transaction it began
+func (x *Warehouse) update(ctx context.Context, db DBTX, tenant string, id int32, data *Warehouse) error {
+	data.Number = id
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	values, err := data.columnValues()
+	if err != nil {
+		return err
+	}
+
+	var found int32
+	err = db.QueryRow(ctx, warehouseUpdateQuery, append([]any{tenant, id}, values...)...).Scan(&found)
+	if err != nil {
+		return err
+	}
+	current := new(Warehouse)
+	if err := current.Get(ctx, db, tenant, id); err != nil {
+		return err
+	}
+	return x.record(ctx, db, tenant, id, dep.RevisionUpdate, current)
+}
+
+// Patch function stores the fields of data named by mask in the object at the
+// given ID, leaving the others as they are, and reads the result into x
+// The result is validated as a whole, the patch is rolled back when it is not valid.
+// The write and its revision are made in one transaction.
+func (x *Warehouse) Patch(ctx context.Context, db DBTX, tenant string, id int32, data *Warehouse, mask *fieldmaskpb.FieldMask) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.patch(ctx, tx, tenant, id, data, mask)
+	if err == nil {
+		err = x.Validate()
+	}
+	return end(err)
+}
+
+// patch makes the writes of Patch in the transaction it began
+func (x *Warehouse) patch(ctx context.Context, db DBTX, tenant string, id int32, data *Warehouse, mask *fieldmaskpb.FieldMask) error {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return err
+	}
+	data.Number = id
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return err
+	}
+
+	values, err := data.columnValues()
+	if err != nil {
+		return err
+	}
+	set, values, err := dep.PatchColumns(dep.Postgres, 3, data, paths, warehouseColumns, values)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(warehousePatchQuery, set)
+	err = x.scanColumns(db.QueryRow(ctx, query, append([]any{tenant, id}, values...)...))
+	if err != nil {
+		return err
+	}
+	return x.record(ctx, db, tenant, id, dep.RevisionPatch, x)
+}
+
+// Delete function will... well delete the object at given ID
+// The write and its revision are made in one transaction.
+func (x *Warehouse) Delete(ctx context.Context, db DBTX, tenant string, id int32) error {
+	tx, end, err := begin(ctx, db)
+	if err != nil {
+		return err
+	}
+	err = x.delete(ctx, tx, tenant, id)
+	return end(err)
+}
+
+// delete makes the writes of Delete in the transaction it began
+func (x *Warehouse) delete(ctx context.Context, db DBTX, tenant string, id int32) error {
+	var found int32
+	err := db.QueryRow(ctx, warehouseDeleteQuery, tenant, id).Scan(&found)
+	if err != nil {
+		return err
+	}
+	return x.record(ctx, db, tenant, id, dep.RevisionDelete, nil)
+}
+
+// record appends the revision of a write to the history of the object at the
+// given ID, value is the object as the write left it, nil when it is gone. It
+// has to run in the transaction of the write, whose lock on the row keeps
+// concurrent writes from taking the same revision
+func (x *Warehouse) record(ctx context.Context, db DBTX, tenant string, id int32, operation string, value *Warehouse) error {
+	data, err := dep.MarshalDocument(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, warehouseRevisionInsertQuery, tenant, id, operation, dep.Actor(ctx), data)
+	return err
+}
+
+// History function returns the revisions of the object at the given ID, oldest first
+func (x *Warehouse) History(ctx context.Context, db DBTX, tenant string, id int32) ([]dep.Revision[*Warehouse], error) {
+	rows, err := db.Query(ctx, warehouseHistoryQuery, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ret []dep.Revision[*Warehouse]
+	for rows.Next() {
+		r, err := dep.ScanRevision[*Warehouse](rows)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, r)
+	}
+	return ret, rows.Err()
+}
+
+// GetAtRevision function reads the object at the given ID as the given revision
+// left it into x, there is no row for revisions that removed it
+func (x *Warehouse) GetAtRevision(ctx context.Context, db DBTX, tenant string, id int32, revision int64) error {
+	var data []byte
+	err := db.QueryRow(ctx, warehouseRevisionQuery, tenant, id, revision).Scan(&data)
+	if err != nil {
+		return err
+	}
+	return dep.UnmarshalDocument(data, x)
+}
+
+// WarehouseRepository stores Warehouse records. Get, Update, Patch and Delete return
+// dep.ErrNotFound for unknown ids, Patch returns the record as stored.
+//
+// The writes fail with dep.ErrAlreadyExists, an *dep.AlreadyExistsError naming the
+// fields, when another record of the tenant holds the values of a unique index.
+//
+// Every write adds a revision to the history of the record, History returns them
+// oldest first and GetAtRevision the record as one of them left it. Both return
+// dep.ErrNotFound for ids without a history and revisions that removed the record.
+type WarehouseRepository interface {
+	List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Warehouse, int32], error)
+	Get(ctx context.Context, tenant string, id int32) (*Warehouse, error)
+	Create(ctx context.Context, tenant string, data *Warehouse) (int32, error)
+	Update(ctx context.Context, tenant string, id int32, data *Warehouse) error
+	Patch(ctx context.Context, tenant string, id int32, data *Warehouse, mask *fieldmaskpb.FieldMask) (*Warehouse, error)
+	Delete(ctx context.Context, tenant string, id int32) error
+	History(ctx context.Context, tenant string, id int32) ([]dep.Revision[*Warehouse], error)
+	GetAtRevision(ctx context.Context, tenant string, id int32, revision int64) (*Warehouse, error)
+}
+
+// WarehouseSQLRepository is the WarehouseRepository backed by the Warehouse persistence methods
+type WarehouseSQLRepository struct {
+	DB DBTX
+}
+
+// NewWarehouseSQLRepository returns a WarehouseSQLRepository using db
+func NewWarehouseSQLRepository(db DBTX) *WarehouseSQLRepository {
+	return &WarehouseSQLRepository{DB: db}
+}
+
+var _ WarehouseRepository = (*WarehouseSQLRepository)(nil)
+
+func (r *WarehouseSQLRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Warehouse, int32], error) {
+	return new(Warehouse).List(ctx, r.DB, tenant, opts)
+}
+
+func (r *WarehouseSQLRepository) Get(ctx context.Context, tenant string, id int32) (*Warehouse, error) {
+	x := new(Warehouse)
+	err := x.Get(ctx, r.DB, tenant, id)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func (r *WarehouseSQLRepository) Create(ctx context.Context, tenant string, data *Warehouse) (int32, error) {
+	id, err := data.Create(ctx, r.DB, tenant, data)
+	return id, dep.AlreadyExists(err, warehouseUniqueKeys...)
+}
+
+func (r *WarehouseSQLRepository) Update(ctx context.Context, tenant string, id int32, data *Warehouse) error {
+	err := data.Update(ctx, r.DB, tenant, id, data)
+	if errors.Is(err, v5.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return dep.AlreadyExists(err, warehouseUniqueKeys...)
+}
+
+func (r *WarehouseSQLRepository) Patch(ctx context.Context, tenant string, id int32, data *Warehouse, mask *fieldmaskpb.FieldMask) (*Warehouse, error) {
+	x := new(Warehouse)
+	err := x.Patch(ctx, r.DB, tenant, id, data, mask)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, dep.AlreadyExists(err, warehouseUniqueKeys...)
+	}
+
+	return x, nil
+}
+
+func (r *WarehouseSQLRepository) Delete(ctx context.Context, tenant string, id int32) error {
+	err := new(Warehouse).Delete(ctx, r.DB, tenant, id)
+	if errors.Is(err, v5.ErrNoRows) {
+		return dep.ErrNotFound
+	}
+	return err
+}
+
+func (r *WarehouseSQLRepository) History(ctx context.Context, tenant string, id int32) ([]dep.Revision[*Warehouse], error) {
+	revisions, err := new(Warehouse).History(ctx, r.DB, tenant, id)
+	if err == nil && len(revisions) == 0 {
+		return nil, dep.ErrNotFound
+	}
+	return revisions, err
+}
+
+func (r *WarehouseSQLRepository) GetAtRevision(ctx context.Context, tenant string, id int32, revision int64) (*Warehouse, error) {
+	x := new(Warehouse)
+	err := x.GetAtRevision(ctx, r.DB, tenant, id, revision)
+	if errors.Is(err, v5.ErrNoRows) {
+		return nil, dep.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// WarehouseMemoryRepository is a WarehouseRepository keeping records in memory, safe for
+// concurrent use. Records are copied on the way in and out.
+type WarehouseMemoryRepository struct {
+	mu      sync.RWMutex
+	tenants map[string]map[int32]*Warehouse
+	// history holds the revisions of every record by tenant and id
+	history map[string]map[int32][]dep.Revision[*Warehouse]
+}
+
+// NewWarehouseMemoryRepository returns an empty WarehouseMemoryRepository
+func NewWarehouseMemoryRepository() *WarehouseMemoryRepository {
+	return &WarehouseMemoryRepository{tenants: make(map[string]map[int32]*Warehouse), history: make(map[string]map[int32][]dep.Revision[*Warehouse])}
+}
+
+var _ WarehouseRepository = (*WarehouseMemoryRepository)(nil)
+
+// lookup returns the stored record, the lock has to be held
+func (r *WarehouseMemoryRepository) lookup(tenant string, id int32) (*Warehouse, error) {
+	x, ok := r.tenants[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	return x, nil
+}
+
+// record adds the revision of a write leaving the record at value, nil when it
+// is gone, to its history. The lock has to be held
+func (r *WarehouseMemoryRepository) record(ctx context.Context, tenant string, id int32, operation string, value *Warehouse) {
+	revisions := r.history[tenant][id]
+	revision := dep.Revision[*Warehouse]{
+		Revision:  int64(len(revisions) + 1),
+		Operation: operation,
+		Actor:     dep.Actor(ctx),
+		ChangedAt: time.Now(),
+		New:       proto.Clone(value).(*Warehouse),
+	}
+	if len(revisions) > 0 {
+		revision.Old = revisions[len(revisions)-1].New
+	}
+	if r.history[tenant] == nil {
+		r.history[tenant] = make(map[int32][]dep.Revision[*Warehouse])
+	}
+	r.history[tenant][id] = append(revisions, revision)
+}
+
+func (r *WarehouseMemoryRepository) List(ctx context.Context, tenant string, opts dep.ListOptions) (*dep.Page[*Warehouse, int32], error) {
+	q, err := warehouseListSchema.Query(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]dep.Record[*Warehouse, int32], 0, len(r.tenants[tenant]))
+	for id, x := range r.tenants[tenant] {
+		records = append(records, dep.Record[*Warehouse, int32]{ID: id, Value: proto.Clone(x).(*Warehouse)})
+	}
+	return dep.ListRecords(q, records), nil
+}
+
+func (r *WarehouseMemoryRepository) Get(ctx context.Context, tenant string, id int32) (*Warehouse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(x).(*Warehouse), nil
+}
+
+func (r *WarehouseMemoryRepository) Create(ctx context.Context, tenant string, data *Warehouse) (int32, error) {
+	if err := data.Validate(); err != nil {
+		return 0, err
+	}
+	id := data.Number
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tenants[tenant][id]; ok {
+		return 0, &dep.AlreadyExistsError{Fields: []string{"number"}}
+	}
+	if r.tenants[tenant] == nil {
+		r.tenants[tenant] = make(map[int32]*Warehouse)
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Warehouse)
+	r.record(ctx, tenant, id, dep.RevisionCreate, r.tenants[tenant][id])
+	return id, nil
+}
+
+func (r *WarehouseMemoryRepository) Update(ctx context.Context, tenant string, id int32, data *Warehouse) error {
+	data.Number = id
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.lookup(tenant, id)
+	if err != nil {
+		return err
+	}
+	r.tenants[tenant][id] = proto.Clone(data).(*Warehouse)
+	r.record(ctx, tenant, id, dep.RevisionUpdate, r.tenants[tenant][id])
+	return nil
+}
+
+func (r *WarehouseMemoryRepository) Patch(ctx context.Context, tenant string, id int32, data *Warehouse, mask *fieldmaskpb.FieldMask) (*Warehouse, error) {
+	paths, err := dep.FieldMaskPaths(data, mask)
+	if err != nil {
+		return nil, err
+	}
+	data.Number = id
+	if err := dep.MaskedErrors(data.Validate(), paths); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x, err := r.lookup(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	x = proto.Clone(x).(*Warehouse)
+	dep.ApplyFieldMask(x, data, paths)
+	if err := x.Validate(); err != nil {
+		return nil, err
+	}
+	r.tenants[tenant][id] = x
+	r.record(ctx, tenant, id, dep.RevisionPatch, x)
+	return proto.Clone(x).(*Warehouse), nil
+}
+
+func (r *WarehouseMemoryRepository) Delete(ctx context.Context, tenant string, id int32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.lookup(tenant, id); err != nil {
+		return err
+	}
+	delete(r.tenants[tenant], id)
+	r.record(ctx, tenant, id, dep.RevisionDelete, nil)
+	return nil
+}
+
+func (r *WarehouseMemoryRepository) History(ctx context.Context, tenant string, id int32) ([]dep.Revision[*Warehouse], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions, ok := r.history[tenant][id]
+	if !ok {
+		return nil, dep.ErrNotFound
+	}
+	ret := make([]dep.Revision[*Warehouse], len(revisions))
+	for i, revision := range revisions {
+		revision.Old, revision.New = proto.Clone(revision.Old).(*Warehouse), proto.Clone(revision.New).(*Warehouse)
+		ret[i] = revision
+	}
+	return ret, nil
+}
+
+func (r *WarehouseMemoryRepository) GetAtRevision(ctx context.Context, tenant string, id int32, revision int64) (*Warehouse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := r.history[tenant][id]
+	if revision < 1 || revision > int64(len(revisions)) || revisions[revision-1].New == nil {
+		return nil, dep.ErrNotFound
+	}
+	return proto.Clone(revisions[revision-1].New).(*Warehouse), nil
+}
+
+// ListHandler renders the page of objects selected by the page_size, page_token,
+// skip, filter and order_by query parameters
+func (h *WarehouseHandler) ListHandler(w http.ResponseWriter, req *http.Request) {
+	opts, err := dep.ParseListOptions(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.List(req.Context(), h.tenant(req), opts)
+	if errors.Is(err, dep.ErrInvalidArgument) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Records encode their values with protojson
+	jsonData, err := json.Marshal(ret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// GetHandler renders the object at the {id} url parameter
+func (h *WarehouseHandler) GetHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// CreateHandler stores the object sent in the request body, the Location header
+// of the response names its url
+func (h *WarehouseHandler) CreateHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Warehouse)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.Repo.Create(req.Context(), h.tenant(req), x)
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", path.Join(req.URL.Path, fmt.Sprint(id)))
+	h.render(w, req, http.StatusCreated, x)
+}
+
+// UpdateHandler replaces the object at the {id} url parameter with the request body
+func (h *WarehouseHandler) UpdateHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Warehouse)
+	if err := h.decode(req, x); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.Repo.Update(req.Context(), h.tenant(req), id, x)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// PatchHandler stores the fields of the request body named by its mask in the object
+// at the {id} url parameter and renders the result
+func (h *WarehouseHandler) PatchHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	x := new(Warehouse)
+	mask, err := h.decodePatch(req, x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := h.Repo.Patch(req.Context(), h.tenant(req), id, x, mask)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, dep.ErrAlreadyExists) {
+		h.alreadyExists(w, req, x, err)
+		return
+	}
+	var invalid dep.ValidationErrors
+	if errors.Is(err, dep.ErrInvalidArgument) || errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, ret)
+}
+
+// DeleteHandler deletes the object at the {id} url parameter
+func (h *WarehouseHandler) DeleteHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	err := h.Repo.Delete(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx only swaps the target on a 200
+	if req.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HistoryHandler renders the revisions of the object at the {id} url parameter,
+// as a table of the fields each one changed to htmx requests
+func (h *WarehouseHandler) HistoryHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	revisions, err := h.Repo.History(req.Context(), h.tenant(req), id)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		dep.RenderHistory(w, revisions)
+		return
+	}
+
+	// Revisions encode their values with protojson
+	jsonData, err := json.Marshal(revisions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+// RevisionHandler renders the object at the {id} url parameter as the revision in
+// the {revision} url parameter left it
+func (h *WarehouseHandler) RevisionHandler(w http.ResponseWriter, req *http.Request) {
+	id, ok := h.id(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	revision, err := strconv.ParseInt(chi.URLParam(req, "revision"), 10, 64)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	x, err := h.Repo.GetAtRevision(req.Context(), h.tenant(req), id, revision)
+	if errors.Is(err, dep.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.render(w, req, http.StatusOK, x)
+}
+
+// FormHandler renders the form for a new object, or for editing the object at the {id} url parameter
+func (h *WarehouseHandler) FormHandler(w http.ResponseWriter, req *http.Request) {
+	x := new(Warehouse)
+	if chi.URLParam(req, "id") != "" {
+		id, ok := h.id(req)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		found, err := h.Repo.Get(req.Context(), h.tenant(req), id)
+		if errors.Is(err, dep.ErrNotFound) {
+			http.NotFound(w, req)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		x = found
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := x.RenderForm(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// alreadyExists answers a write that found unique fields of x taken with a 409,
+// htmx requests get the form back with the fields marked
+func (h *WarehouseHandler) alreadyExists(w http.ResponseWriter, req *http.Request, x *Warehouse, err error) {
+	if req.Header.Get("HX-Request") != "true" {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	var errs dep.ValidationErrors
+	var exists *dep.AlreadyExistsError
+	if errors.As(err, &exists) {
+		errs = exists.ValidationErrors()
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusConflict)
+	x.RenderFormErrors(w, errs)
+}
+
+// decode reads the object from a protojson body, skipping unknown fields, or from
+// a submitted form
+func (h *WarehouseHandler) decode(req *http.Request, x *Warehouse) error {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		if _, err := x.HandleForm(req); err != nil {
+			return err
+		}
+		// HandleForm only validates the fields the form submitted
+		return x.Validate()
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, x); err != nil {
+		return err
+	}
+
+	return x.Validate()
+}
+
+// decodePatch reads the fields to patch from a submitted form, masking the ones it
+// holds, or from a protojson body masked by the update_mask query parameter or by
+// the fields present in the body, which may not name unknown fields
+func (h *WarehouseHandler) decodePatch(req *http.Request, x *Warehouse) (*fieldmaskpb.FieldMask, error) {
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct != "application/json" {
+		return x.HandleForm(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(body, x); err != nil {
+		return nil, err
+	}
+
+	return dep.ParseFieldMask(req.URL.Query(), body)
+}
+
+// render writes the object as protojson, or as html to htmx requests
+func (h *WarehouseHandler) render(w http.ResponseWriter, req *http.Request, status int, x *Warehouse) {
+	if req.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		x.RenderView(w)
+		return
+	}
+
+	jsonData, err := protojson.Marshal(x)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
+
+// Routes returns a chi.Router with the Warehouse endpoints that can be mounted to a parent router
+func (h *WarehouseHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListHandler)
+	r.Post("/", h.CreateHandler)
+	r.Get("/new", h.FormHandler)
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.GetHandler)
+		r.Put("/", h.UpdateHandler)
+		r.Patch("/", h.PatchHandler)
+		r.Get("/edit", h.FormHandler)
+		r.Delete("/", h.DeleteHandler)
+		r.Get("/history", h.HistoryHandler)
+		r.Get("/history/{revision}", h.RevisionHandler)
+	})
+
+	return r
+}
+
+// A simple function to handle a htmx form and populate the struct, returning
+// the mask of the fields the form submitted. Values that fail to parse are
+// collected per field before the submitted fields are validated.
+func (x *Warehouse) HandleForm(req *http.Request) (*fieldmaskpb.FieldMask, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	errs := make(dep.ValidationErrors)
+	if v := req.FormValue("Warehouse__Number"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 32); err != nil {
+			errs.Add("number", "must be a whole number")
+		} else {
+			value := int32(n)
+			x.Number = value
+		}
+	}
+	x.City = req.FormValue("Warehouse__City")
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	mask := new(fieldmaskpb.FieldMask)
+	if dep.FormHas(req.Form, "Warehouse__Number") {
+		mask.Paths = append(mask.Paths, "number")
+	}
+	if dep.FormHas(req.Form, "Warehouse__City") {
+		mask.Paths = append(mask.Paths, "city")
+	}
+	return mask, dep.MaskedErrors(x.Validate(), mask.Paths)
+}
+
+var warehouseViewTemplate = template.Must(template.New("view").Parse(`
+<p class="w-16">
+  <span>Number</span>
+  <span> {{ .Number }} </span>
+</p>
+<p class="w-16">
+  <span>City</span>
+  <span> {{ .City }} </span>
+</p>
+`))
+
+// RenderView will take in a http writer and object to render the view
+func (x *Warehouse) RenderView(w http.ResponseWriter) error {
+	return warehouseViewTemplate.Execute(w, x)
+}
+
+var warehouseFormTemplate = template.Must(template.New("form").Parse(`{{ with .Value }}
+<label class="w-16">
+  <span>Number</span>
+  <input type="number" name="Warehouse__Number" value="{{ .Number }}" required>
+  {{ with index $.Errors "number" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+<label class="w-16">
+  <span>City</span>
+  <input type="text" name="Warehouse__City" value="{{ .City }}">
+  {{ with index $.Errors "city" }}<small class="error">{{ . }}</small>{{ end }}
+</label>
+{{ end }}`))
+
+// RenderForm will take in a http writer and render a htmx form for the object
+func (x *Warehouse) RenderForm(w http.ResponseWriter) error {
+	return x.RenderFormErrors(w, nil)
+}
+
+// RenderFormErrors renders the htmx form for the object with errs shown next to
+// the inputs of their fields
+func (x *Warehouse) RenderFormErrors(w http.ResponseWriter, errs dep.ValidationErrors) error {
+	return warehouseFormTemplate.Execute(w, dep.FormData{Value: x, Errors: errs})
+}
+
+// Validate checks the constraints declared on the fields of Warehouse
+func (x *Warehouse) Validate() error {
+	errs := make(dep.ValidationErrors)
+	if x.Number == 0 {
+		errs.Add("number", "is required")
+	}
+	return errs.Err()
+}
+
+// TableName returns the name of the table backing Warehouse
+func (*Warehouse) TableName() string {
+	return "warehouse"
+}
+
+// Deps holds what the handlers of the resources in columns.proto need
+type Deps struct {
+	DB DBTX
+	// Tenant resolves the tenant of a request, by default the {tenant} url parameter
+	Tenant func(*http.Request) string
+}
+
+// RegisterAll mounts the routes of every resource in columns.proto on r
+func RegisterAll(r chi.Router, deps Deps) {
+	r.Mount("/order", (&OrderHandler{Repo: NewOrderSQLRepository(deps.DB), Tenant: deps.Tenant}).Routes())
+	r.Mount("/warehouse", (&WarehouseHandler{Repo: NewWarehouseSQLRepository(deps.DB), Tenant: deps.Tenant}).Routes())
+}
@@ -0,0 +1,88 @@
+-- Code generated by protoc-gen-go-dep. DO NOT EDIT.
+-- source: columns.proto
+
+-- Order records, one column per field.
+CREATE TABLE IF NOT EXISTS "order" (
+    id BYTEA PRIMARY KEY,
+    tenant TEXT NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    version BIGINT NOT NULL DEFAULT 1,
+    customer_name TEXT NOT NULL,
+    count INTEGER NOT NULL,
+    total BIGINT NOT NULL,
+    weight BIGINT NOT NULL,
+    serial NUMERIC(20,0) NOT NULL,
+    discount REAL NOT NULL,
+    rate DOUBLE PRECISION NOT NULL,
+    paid BOOLEAN NOT NULL,
+    receipt BYTEA,
+    priority TEXT NOT NULL,
+    placed_at TIMESTAMPTZ,
+    first_line JSONB,
+    tags TEXT[] NOT NULL,
+    scores INTEGER[] NOT NULL,
+    flags TEXT[] NOT NULL,
+    lines JSONB NOT NULL,
+    totals JSONB NOT NULL,
+    note TEXT,
+    escalation TEXT,
+    address TEXT,
+    speed TEXT,
+    pickup_at TIMESTAMPTZ,
+    parcel JSONB,
+    label BYTEA,
+    locker BIGINT,
+    dock NUMERIC(20,0),
+    created_at TIMESTAMPTZ,
+    updated_by TEXT NOT NULL,
+    batch NUMERIC(20,0),
+    parts NUMERIC(20,0)[] NOT NULL,
+    stock JSONB NOT NULL,
+    levels JSONB NOT NULL,
+    deadlines JSONB NOT NULL
+);
+ALTER TABLE "order" ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE "order" ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS order_tenant_idx ON "order" (tenant);
+CREATE INDEX IF NOT EXISTS order_total_idx ON "order" (tenant, total);
+CREATE UNIQUE INDEX IF NOT EXISTS order_customer_serial_key ON "order" (tenant, (NULLIF(customer_name, '')), (NULLIF(serial, 0))) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS order_paid_priority_idx ON "order" (tenant, paid, priority);
+
+-- Revisions of "order", the values before and after every write.
+CREATE TABLE IF NOT EXISTS order_history (
+    tenant TEXT NOT NULL,
+    id BYTEA NOT NULL,
+    revision BIGINT NOT NULL,
+    operation TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    old_data JSONB,
+    new_data JSONB,
+    PRIMARY KEY (tenant, id, revision)
+);
+
+-- Warehouse records, one column per field.
+CREATE TABLE IF NOT EXISTS warehouse (
+    id INTEGER NOT NULL,
+    tenant TEXT NOT NULL,
+    number INTEGER NOT NULL,
+    city TEXT NOT NULL,
+    PRIMARY KEY (tenant, id)
+);
+
+CREATE INDEX IF NOT EXISTS warehouse_tenant_idx ON warehouse (tenant);
+CREATE INDEX IF NOT EXISTS warehouse_city_idx ON warehouse (tenant, city);
+
+-- Revisions of warehouse, the values before and after every write.
+CREATE TABLE IF NOT EXISTS warehouse_history (
+    tenant TEXT NOT NULL,
+    id INTEGER NOT NULL,
+    revision BIGINT NOT NULL,
+    operation TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    old_data JSONB,
+    new_data JSONB,
+    PRIMARY KEY (tenant, id, revision)
+);
@@ -0,0 +1,50 @@
+-- Code generated by protoc-gen-go-dep. DO NOT EDIT.
+-- source: hello.proto
+
+-- Hello records, one document per row.
+CREATE TABLE IF NOT EXISTS hellos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant TEXT NOT NULL,
+    deleted_at DATETIME,
+    version INTEGER NOT NULL DEFAULT 1,
+    data TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS hellos_tenant_idx ON hellos (tenant);
+CREATE UNIQUE INDEX IF NOT EXISTS hellos_email_key ON hellos (tenant, (NULLIF(COALESCE(json_extract(data, '$.email'), ''), ''))) WHERE deleted_at IS NULL;
+
+-- Revisions of hellos, the values before and after every write.
+CREATE TABLE IF NOT EXISTS hellos_history (
+    tenant TEXT NOT NULL,
+    id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    operation TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    old_data TEXT,
+    new_data TEXT,
+    PRIMARY KEY (tenant, id, revision)
+);
+
+-- Note records, one document per row.
+CREATE TABLE IF NOT EXISTS note (
+    id TEXT PRIMARY KEY,
+    tenant TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    data TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS note_tenant_idx ON note (tenant);
+
+-- Revisions of note, the values before and after every write.
+CREATE TABLE IF NOT EXISTS note_history (
+    tenant TEXT NOT NULL,
+    id TEXT NOT NULL,
+    revision INTEGER NOT NULL,
+    operation TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    old_data TEXT,
+    new_data TEXT,
+    PRIMARY KEY (tenant, id, revision)
+);
@@ -0,0 +1,86 @@
+-- Code generated by protoc-gen-go-dep. DO NOT EDIT.
+-- source: columns.proto
+
+-- Order records, one column per field.
+CREATE TABLE IF NOT EXISTS "order" (
+    id BLOB PRIMARY KEY,
+    tenant TEXT NOT NULL,
+    deleted_at DATETIME,
+    version INTEGER NOT NULL DEFAULT 1,
+    customer_name TEXT NOT NULL,
+    count INTEGER NOT NULL,
+    total INTEGER NOT NULL,
+    weight INTEGER NOT NULL,
+    serial TEXT NOT NULL,
+    discount REAL NOT NULL,
+    rate REAL NOT NULL,
+    paid BOOLEAN NOT NULL,
+    receipt BLOB,
+    priority TEXT NOT NULL,
+    placed_at DATETIME,
+    first_line TEXT,
+    tags TEXT NOT NULL,
+    scores TEXT NOT NULL,
+    flags TEXT NOT NULL,
+    lines TEXT NOT NULL,
+    totals TEXT NOT NULL,
+    note TEXT,
+    escalation TEXT,
+    address TEXT,
+    speed TEXT,
+    pickup_at DATETIME,
+    parcel TEXT,
+    label BLOB,
+    locker INTEGER,
+    dock TEXT,
+    created_at DATETIME,
+    updated_by TEXT NOT NULL,
+    batch TEXT,
+    parts TEXT NOT NULL,
+    stock TEXT NOT NULL,
+    levels TEXT NOT NULL,
+    deadlines TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_tenant_idx ON "order" (tenant);
+CREATE INDEX IF NOT EXISTS order_total_idx ON "order" (tenant, total);
+CREATE UNIQUE INDEX IF NOT EXISTS order_customer_serial_key ON "order" (tenant, (NULLIF(customer_name, '')), (NULLIF(serial, '0'))) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS order_paid_priority_idx ON "order" (tenant, paid, priority);
+
+-- Revisions of "order", the values before and after every write.
+CREATE TABLE IF NOT EXISTS order_history (
+    tenant TEXT NOT NULL,
+    id BLOB NOT NULL,
+    revision INTEGER NOT NULL,
+    operation TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    old_data TEXT,
+    new_data TEXT,
+    PRIMARY KEY (tenant, id, revision)
+);
+
+-- Warehouse records, one column per field.
+CREATE TABLE IF NOT EXISTS warehouse (
+    id INTEGER NOT NULL,
+    tenant TEXT NOT NULL,
+    number INTEGER NOT NULL,
+    city TEXT NOT NULL,
+    PRIMARY KEY (tenant, id)
+);
+
+CREATE INDEX IF NOT EXISTS warehouse_tenant_idx ON warehouse (tenant);
+CREATE INDEX IF NOT EXISTS warehouse_city_idx ON warehouse (tenant, city);
+
+-- Revisions of warehouse, the values before and after every write.
+CREATE TABLE IF NOT EXISTS warehouse_history (
+    tenant TEXT NOT NULL,
+    id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    operation TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    old_data TEXT,
+    new_data TEXT,
+    PRIMARY KEY (tenant, id, revision)
+);
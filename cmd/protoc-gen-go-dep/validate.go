@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"protoc-gen-go-dep/dep"
+)
+
+// generateValidateFunction emits Validate, which checks every constraint in
+// the field options and collects the failures in a dep.ValidationErrors.
+func (p *Generator) generateValidateFunction(g *protogen.GeneratedFile, message *protogen.Message, opts *dep.DepMessageOptions) error {
+	for _, field := range message.Fields {
+		fieldOpts := fieldOptions(field)
+		if fieldOpts.Pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(fieldOpts.Pattern); err != nil {
+			return fmt.Errorf("%s: invalid pattern: %v", field.Desc.FullName(), err)
+		}
+		g.P("var ", patternName(message, field), " = ", regexpPackage.Ident("MustCompile"), "(", strconv.Quote(fieldOpts.Pattern), ")")
+		g.P("")
+	}
+
+	g.P("// Validate checks the constraints declared on the fields of ", message.GoIdent.GoName)
+	g.P("func (x *", message.GoIdent, ") Validate() error {")
+	g.P("   errs := make(", depPackage.Ident("ValidationErrors"), ")")
+	for _, field := range message.Fields {
+		fieldOpts := fieldOptions(field)
+		name := string(field.Desc.Name())
+
+		if fieldOpts.Required {
+			g.P("   if ", emptyCheck("x", field), " {")
+			g.P(`       errs.Add("`, name, `", "is required")`)
+			g.P("   }")
+		}
+		if keys := reservedKeys(message, opts); len(keys) > 0 && field == keyField(message, opts) {
+			quoted := make([]string, len(keys))
+			for i, key := range keys {
+				quoted[i] = strconv.Quote(key)
+			}
+			g.P("   switch x.", field.GoName, " {")
+			g.P("   case ", strings.Join(quoted, ", "), ":")
+			g.P(`       errs.Add("`, name, `", "is reserved")`)
+			g.P("   }")
+		}
+		if field == keyField(message, opts) && keyKind(field) == "string" {
+			g.P("   if ", stringsPackage.Ident("Contains"), "(x.", field.GoName, `, "/") {`)
+			g.P(`       errs.Add("`, name, `", "cannot contain /")`)
+			g.P("   }")
+		}
+
+		if field.Desc.IsList() || field.Desc.IsMap() {
+			if fieldOpts.MinItems > 0 {
+				g.P("   if len(x.", field.GoName, ") < ", fieldOpts.MinItems, " {")
+				g.P(`       errs.Add("`, name, `", "must have at least `, fieldOpts.MinItems, ` items")`)
+				g.P("   }")
+			}
+			if fieldOpts.MaxItems > 0 {
+				g.P("   if len(x.", field.GoName, ") > ", fieldOpts.MaxItems, " {")
+				g.P(`       errs.Add("`, name, `", "must have at most `, fieldOpts.MaxItems, ` items")`)
+				g.P("   }")
+			}
+			if field.Desc.IsList() && hasValueChecks(field, fieldOpts) {
+				g.P("   for _, v := range x.", field.GoName, " {")
+				generateValueChecks(g, field, fieldOpts, "v", patternName(message, field))
+				g.P("   }")
+			}
+			continue
+		}
+
+		generateValueChecks(g, field, fieldOpts, "x.Get"+field.GoName+"()", patternName(message, field))
+	}
+	g.P("   return errs.Err()")
+	g.P("}")
+	g.P("")
+
+	return nil
+}
+
+// hasValueChecks reports whether generateValueChecks emits anything for field.
+func hasValueChecks(field *protogen.Field, opts *dep.DepFieldOptions) bool {
+	switch field.Desc.Kind() {
+	case protoreflect.StringKind:
+		return opts.MinLen > 0 || opts.MaxLen > 0 || opts.Pattern != "" || opts.Email || opts.Url
+	case protoreflect.BytesKind:
+		return opts.MinLen > 0 || opts.MaxLen > 0
+	case protoreflect.EnumKind:
+		return opts.DefinedOnly
+	case protoreflect.BoolKind, protoreflect.MessageKind, protoreflect.GroupKind:
+		return false
+	}
+	return opts.Min != nil || opts.Max != nil
+}
+
+// generateValueChecks emits the checks on a single value v of field, for
+// repeated fields it is called once per item.
+func generateValueChecks(g *protogen.GeneratedFile, field *protogen.Field, opts *dep.DepFieldOptions, v string, pattern string) {
+	name := string(field.Desc.Name())
+	fail := func(msg string) {
+		g.P(`       errs.Add("`, name, `", `, strconv.Quote(msg), `)`)
+	}
+
+	switch field.Desc.Kind() {
+	case protoreflect.StringKind:
+		if opts.MinLen > 0 {
+			g.P("   if ", v, ` != "" && `, utf8Package.Ident("RuneCountInString"), "(", v, ") < ", opts.MinLen, " {")
+			fail(fmt.Sprintf("must be at least %d characters", opts.MinLen))
+			g.P("   }")
+		}
+		if opts.MaxLen > 0 {
+			g.P("   if ", utf8Package.Ident("RuneCountInString"), "(", v, ") > ", opts.MaxLen, " {")
+			fail(fmt.Sprintf("must be at most %d characters", opts.MaxLen))
+			g.P("   }")
+		}
+		if opts.Pattern != "" {
+			g.P("   if ", v, ` != "" && !`, pattern, ".MatchString(", v, ") {")
+			fail("has an invalid format")
+			g.P("   }")
+		}
+		if opts.Email {
+			g.P("   if ", v, ` != "" {`)
+			g.P("       if addr, err := ", mailPackage.Ident("ParseAddress"), "(", v, "); err != nil || addr.Address != ", v, " {")
+			fail("must be an email address")
+			g.P("       }")
+			g.P("   }")
+		}
+		if opts.Url {
+			g.P("   if ", v, ` != "" {`)
+			g.P("       if u, err := ", urlPackage.Ident("Parse"), "(", v, `); err != nil || !u.IsAbs() || u.Host == "" {`)
+			fail("must be a URL")
+			g.P("       }")
+			g.P("   }")
+		}
+
+	case protoreflect.BytesKind:
+		if opts.MinLen > 0 {
+			g.P("   if len(", v, ") > 0 && len(", v, ") < ", opts.MinLen, " {")
+			fail(fmt.Sprintf("must be at least %d bytes", opts.MinLen))
+			g.P("   }")
+		}
+		if opts.MaxLen > 0 {
+			g.P("   if len(", v, ") > ", opts.MaxLen, " {")
+			fail(fmt.Sprintf("must be at most %d bytes", opts.MaxLen))
+			g.P("   }")
+		}
+
+	case protoreflect.EnumKind:
+		if opts.DefinedOnly {
+			names := protogen.GoIdent{
+				GoName:       field.Enum.GoIdent.GoName + "_name",
+				GoImportPath: field.Enum.GoIdent.GoImportPath,
+			}
+			g.P("   if _, ok := ", names, "[int32(", v, ")]; !ok {")
+			fail("must be a defined value")
+			g.P("   }")
+		}
+
+	case protoreflect.BoolKind, protoreflect.MessageKind, protoreflect.GroupKind:
+
+	default:
+		if opts.Min != nil {
+			min := strconv.FormatFloat(opts.GetMin(), 'g', -1, 64)
+			g.P("   if float64(", v, ") < ", min, " {")
+			fail("must be at least " + min)
+			g.P("   }")
+		}
+		if opts.Max != nil {
+			max := strconv.FormatFloat(opts.GetMax(), 'g', -1, 64)
+			g.P("   if float64(", v, ") > ", max, " {")
+			fail("must be at most " + max)
+			g.P("   }")
+		}
+	}
+}
+
+// patternName is the package level variable holding the compiled pattern
+// of field.
+func patternName(message *protogen.Message, field *protogen.Field) string {
+	return lowerFirst(message.GoIdent.GoName) + field.GoName + "Pattern"
+}
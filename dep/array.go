@@ -0,0 +1,216 @@
+package dep
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ArrayElement lists the Go types repeated fields can hold in a Postgres
+// array column.
+type ArrayElement interface {
+	string | bool | int32 | int64 | uint32 | uint64 | float32 | float64 | []byte
+}
+
+// ArrayValue reads and writes the slice it points to as a one dimensional
+// Postgres array. It goes through the text form of arrays, so it works the
+// same with any driver.
+type ArrayValue[T ArrayElement] struct {
+	slice *[]T
+}
+
+// Array adapts the slice at p to an array column, the result can be passed
+// to Scan and Exec.
+func Array[T ArrayElement](p *[]T) *ArrayValue[T] {
+	return &ArrayValue[T]{slice: p}
+}
+
+// Value renders the slice as an array literal.
+func (a *ArrayValue[T]) Value() (driver.Value, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, v := range *a.slice {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(formatElement(v))
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+// Scan parses an array literal into the slice, NULL leaves it nil.
+func (a *ArrayValue[T]) Scan(src any) error {
+	var literal string
+	switch src := src.(type) {
+	case nil:
+		*a.slice = nil
+		return nil
+	case string:
+		literal = src
+	case []byte:
+		literal = string(src)
+	default:
+		return fmt.Errorf("dep: cannot scan %T into an array", src)
+	}
+
+	items, err := splitArray(literal)
+	if err != nil {
+		return err
+	}
+
+	slice := make([]T, len(items))
+	for i, item := range items {
+		if item == nil {
+			continue
+		}
+		if err := parseElement(*item, &slice[i]); err != nil {
+			return fmt.Errorf("dep: array element %d: %w", i, err)
+		}
+	}
+	*a.slice = slice
+	return nil
+}
+
+func formatElement[T ArrayElement](v T) string {
+	switch v := any(v).(type) {
+	case string:
+		return quoteElement(v)
+	case bool:
+		if v {
+			return "t"
+		}
+		return "f"
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return formatFloat(float64(v), 32)
+	case float64:
+		return formatFloat(v, 64)
+	case []byte:
+		return quoteElement(`\x` + hex.EncodeToString(v))
+	}
+	panic("unreachable")
+}
+
+func formatFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return strconv.FormatFloat(f, 'g', -1, bitSize)
+}
+
+func quoteElement(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+func parseElement[T ArrayElement](s string, dest *T) error {
+	var err error
+	switch d := any(dest).(type) {
+	case *string:
+		*d = s
+	case *bool:
+		*d, err = strconv.ParseBool(s)
+	case *int32:
+		var n int64
+		n, err = strconv.ParseInt(s, 10, 32)
+		*d = int32(n)
+	case *int64:
+		*d, err = strconv.ParseInt(s, 10, 64)
+	case *uint32:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, 32)
+		*d = uint32(n)
+	case *uint64:
+		*d, err = strconv.ParseUint(s, 10, 64)
+	case *float32:
+		var f float64
+		f, err = parseFloat(s, 32)
+		*d = float32(f)
+	case *float64:
+		*d, err = parseFloat(s, 64)
+	case *[]byte:
+		if !strings.HasPrefix(s, `\x`) {
+			return fmt.Errorf("bytea %q is not hex encoded", s)
+		}
+		*d, err = hex.DecodeString(s[2:])
+	}
+	return err
+}
+
+func parseFloat(s string, bitSize int) (float64, error) {
+	switch s {
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(s, bitSize)
+}
+
+// splitArray returns the unescaped elements of a one dimensional array
+// literal, nil for NULL elements.
+func splitArray(literal string) ([]*string, error) {
+	if len(literal) < 2 || literal[0] != '{' || literal[len(literal)-1] != '}' {
+		return nil, fmt.Errorf("dep: %q is not an array", literal)
+	}
+	body := literal[1 : len(literal)-1]
+	if body == "" {
+		return nil, nil
+	}
+
+	var items []*string
+	for i := 0; i <= len(body); {
+		var item strings.Builder
+		quoted := i < len(body) && body[i] == '"'
+		if quoted {
+			i++
+			for ; i < len(body) && body[i] != '"'; i++ {
+				if body[i] == '\\' {
+					i++
+				}
+				if i < len(body) {
+					item.WriteByte(body[i])
+				}
+			}
+			if i >= len(body) {
+				return nil, fmt.Errorf("dep: unterminated element in %q", literal)
+			}
+			i++
+		} else {
+			for ; i < len(body) && body[i] != ','; i++ {
+				if body[i] == '{' {
+					return nil, fmt.Errorf("dep: %q has more than one dimension", literal)
+				}
+				item.WriteByte(body[i])
+			}
+		}
+		if i < len(body) && body[i] != ',' {
+			return nil, fmt.Errorf("dep: unexpected %q in %q", body[i], literal)
+		}
+		i++
+
+		s := item.String()
+		if !quoted && strings.EqualFold(s, "NULL") {
+			items = append(items, nil)
+			continue
+		}
+		items = append(items, &s)
+	}
+	return items, nil
+}
@@ -0,0 +1,60 @@
+package dep
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestArrayRoundTrip(t *testing.T) {
+	roundTrip(t, []string{"a", `with "quotes"`, `back\slash`, "comma,", "", "NULL"})
+	roundTrip(t, []bool{true, false})
+	roundTrip(t, []int32{-1, 0, math.MaxInt32})
+	roundTrip(t, []int64{math.MinInt64, 42})
+	roundTrip(t, []uint32{0, math.MaxUint32})
+	roundTrip(t, []uint64{math.MaxUint64})
+	roundTrip(t, []float32{1.5, -0.25})
+	roundTrip(t, []float64{math.Inf(1), math.Inf(-1), 3.14})
+	roundTrip(t, [][]byte{{0x01, 0xff}, {}})
+	roundTrip(t, []string{})
+}
+
+func roundTrip[T ArrayElement](t *testing.T, in []T) {
+	t.Helper()
+
+	v, err := Array(&in).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out []T
+	if err := Array(&out).Scan([]byte(v.(string))); err != nil {
+		t.Fatalf("%s: %v", v, err)
+	}
+	if len(in) == 0 && len(out) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("%s: got %v, want %v", v, out, in)
+	}
+}
+
+func TestArrayScan(t *testing.T) {
+	var got []string
+	if err := Array(&got).Scan(`{plain,"quoted",NULL,"NULL"}`); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"plain", "quoted", "", "NULL"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if err := Array(&got).Scan(nil); err != nil || got != nil {
+		t.Errorf("NULL scanned to %q, %v", got, err)
+	}
+
+	for _, bad := range []string{"", "a,b", `{"open}`, "{{1},{2}}"} {
+		if err := Array(&got).Scan(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
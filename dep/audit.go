@@ -0,0 +1,42 @@
+package dep
+
+import (
+	"context"
+	"strings"
+)
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying actor, whoever makes the request.
+// The generated writes record it in the created_by and updated_by audit
+// fields, typically an authentication middleware sets it.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor WithActor stored in ctx, empty when there is none.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// StampPaths returns the paths a patch of an audited record stores: paths
+// without the ones into the audit fields named by created and updated, which
+// clients never write, followed by updated, which the write fills in.
+func StampPaths(paths, created, updated []string) []string {
+	audit := make(map[string]bool, len(created)+len(updated))
+	for _, name := range created {
+		audit[name] = true
+	}
+	for _, name := range updated {
+		audit[name] = true
+	}
+
+	ret := make([]string, 0, len(paths)+len(updated))
+	for _, path := range paths {
+		if name, _, _ := strings.Cut(path, "."); !audit[name] {
+			ret = append(ret, path)
+		}
+	}
+	return append(ret, updated...)
+}
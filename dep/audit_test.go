@@ -0,0 +1,25 @@
+package dep
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestActor(t *testing.T) {
+	ctx := context.Background()
+	if actor := Actor(ctx); actor != "" {
+		t.Errorf("no actor: got %q", actor)
+	}
+	if actor := Actor(WithActor(ctx, "ada")); actor != "ada" {
+		t.Errorf("got %q, want ada", actor)
+	}
+}
+
+func TestStampPaths(t *testing.T) {
+	paths := StampPaths([]string{"name", "created_at", "updated_by", "created_at.seconds", "email"},
+		[]string{"created_at", "created_by"}, []string{"updated_at", "updated_by"})
+	if want := []string{"name", "email", "updated_at", "updated_by"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got %v, want %v", paths, want)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
 	reflect "reflect"
+	sync "sync"
 )
 
 const (
@@ -20,6 +21,828 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type IdStrategy int32
+
+const (
+	IdStrategy_ID_STRATEGY_UNSPECIFIED IdStrategy = 0
+	// The database assigns ids from a sequence, they are int64.
+	IdStrategy_ID_STRATEGY_SERIAL IdStrategy = 1
+	// Create assigns a random uuid.UUID.
+	IdStrategy_ID_STRATEGY_UUID_V4 IdStrategy = 2
+	// Create assigns a uuid.UUID starting with the time, so ids sort by
+	// creation.
+	IdStrategy_ID_STRATEGY_UUID_V7 IdStrategy = 3
+	// Create assigns an ulid.ULID, which sort by creation as well.
+	IdStrategy_ID_STRATEGY_ULID IdStrategy = 4
+	// The id is the value of the field id_field names, a required string or
+	// integer field. It is unique within the tenant and never changes, writes
+	// keep the field at the id.
+	IdStrategy_ID_STRATEGY_NATURAL_KEY IdStrategy = 5
+)
+
+// Enum value maps for IdStrategy.
+var (
+	IdStrategy_name = map[int32]string{
+		0: "ID_STRATEGY_UNSPECIFIED",
+		1: "ID_STRATEGY_SERIAL",
+		2: "ID_STRATEGY_UUID_V4",
+		3: "ID_STRATEGY_UUID_V7",
+		4: "ID_STRATEGY_ULID",
+		5: "ID_STRATEGY_NATURAL_KEY",
+	}
+	IdStrategy_value = map[string]int32{
+		"ID_STRATEGY_UNSPECIFIED": 0,
+		"ID_STRATEGY_SERIAL":      1,
+		"ID_STRATEGY_UUID_V4":     2,
+		"ID_STRATEGY_UUID_V7":     3,
+		"ID_STRATEGY_ULID":        4,
+		"ID_STRATEGY_NATURAL_KEY": 5,
+	}
+)
+
+func (x IdStrategy) Enum() *IdStrategy {
+	p := new(IdStrategy)
+	*p = x
+	return p
+}
+
+func (x IdStrategy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IdStrategy) Descriptor() protoreflect.EnumDescriptor {
+	return file_dep_proto_enumTypes[0].Descriptor()
+}
+
+func (IdStrategy) Type() protoreflect.EnumType {
+	return &file_dep_proto_enumTypes[0]
+}
+
+func (x IdStrategy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IdStrategy.Descriptor instead.
+func (IdStrategy) EnumDescriptor() ([]byte, []int) {
+	return file_dep_proto_rawDescGZIP(), []int{0}
+}
+
+type Operation int32
+
+const (
+	Operation_OPERATION_UNSPECIFIED Operation = 0
+	Operation_OPERATION_LIST        Operation = 1
+	Operation_OPERATION_GET         Operation = 2
+	Operation_OPERATION_CREATE      Operation = 3
+	Operation_OPERATION_UPDATE      Operation = 4
+	Operation_OPERATION_DELETE      Operation = 5
+)
+
+// Enum value maps for Operation.
+var (
+	Operation_name = map[int32]string{
+		0: "OPERATION_UNSPECIFIED",
+		1: "OPERATION_LIST",
+		2: "OPERATION_GET",
+		3: "OPERATION_CREATE",
+		4: "OPERATION_UPDATE",
+		5: "OPERATION_DELETE",
+	}
+	Operation_value = map[string]int32{
+		"OPERATION_UNSPECIFIED": 0,
+		"OPERATION_LIST":        1,
+		"OPERATION_GET":         2,
+		"OPERATION_CREATE":      3,
+		"OPERATION_UPDATE":      4,
+		"OPERATION_DELETE":      5,
+	}
+)
+
+func (x Operation) Enum() *Operation {
+	p := new(Operation)
+	*p = x
+	return p
+}
+
+func (x Operation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Operation) Descriptor() protoreflect.EnumDescriptor {
+	return file_dep_proto_enumTypes[1].Descriptor()
+}
+
+func (Operation) Type() protoreflect.EnumType {
+	return &file_dep_proto_enumTypes[1]
+}
+
+func (x Operation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Operation.Descriptor instead.
+func (Operation) EnumDescriptor() ([]byte, []int) {
+	return file_dep_proto_rawDescGZIP(), []int{1}
+}
+
+type UiMode int32
+
+const (
+	UiMode_UI_MODE_UNSPECIFIED UiMode = 0
+	// Form handling and html templates for htmx.
+	UiMode_UI_MODE_HTMX UiMode = 1
+	// Persistence and JSON handlers only.
+	UiMode_UI_MODE_NONE UiMode = 2
+)
+
+// Enum value maps for UiMode.
+var (
+	UiMode_name = map[int32]string{
+		0: "UI_MODE_UNSPECIFIED",
+		1: "UI_MODE_HTMX",
+		2: "UI_MODE_NONE",
+	}
+	UiMode_value = map[string]int32{
+		"UI_MODE_UNSPECIFIED": 0,
+		"UI_MODE_HTMX":        1,
+		"UI_MODE_NONE":        2,
+	}
+)
+
+func (x UiMode) Enum() *UiMode {
+	p := new(UiMode)
+	*p = x
+	return p
+}
+
+func (x UiMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UiMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_dep_proto_enumTypes[2].Descriptor()
+}
+
+func (UiMode) Type() protoreflect.EnumType {
+	return &file_dep_proto_enumTypes[2]
+}
+
+func (x UiMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UiMode.Descriptor instead.
+func (UiMode) EnumDescriptor() ([]byte, []int) {
+	return file_dep_proto_rawDescGZIP(), []int{2}
+}
+
+type Storage int32
+
+const (
+	Storage_STORAGE_UNSPECIFIED Storage = 0
+	// The whole record is kept in a single JSONB data column.
+	Storage_STORAGE_DOCUMENT Storage = 1
+	// Every field is kept in a column of its own, named by the column field
+	// option, so fields can be indexed and queried.
+	Storage_STORAGE_COLUMNS Storage = 2
+)
+
+// Enum value maps for Storage.
+var (
+	Storage_name = map[int32]string{
+		0: "STORAGE_UNSPECIFIED",
+		1: "STORAGE_DOCUMENT",
+		2: "STORAGE_COLUMNS",
+	}
+	Storage_value = map[string]int32{
+		"STORAGE_UNSPECIFIED": 0,
+		"STORAGE_DOCUMENT":    1,
+		"STORAGE_COLUMNS":     2,
+	}
+)
+
+func (x Storage) Enum() *Storage {
+	p := new(Storage)
+	*p = x
+	return p
+}
+
+func (x Storage) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Storage) Descriptor() protoreflect.EnumDescriptor {
+	return file_dep_proto_enumTypes[3].Descriptor()
+}
+
+func (Storage) Type() protoreflect.EnumType {
+	return &file_dep_proto_enumTypes[3]
+}
+
+func (x Storage) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Storage.Descriptor instead.
+func (Storage) EnumDescriptor() ([]byte, []int) {
+	return file_dep_proto_rawDescGZIP(), []int{3}
+}
+
+type Widget int32
+
+const (
+	Widget_WIDGET_UNSPECIFIED Widget = 0
+	Widget_WIDGET_TEXT        Widget = 1
+	Widget_WIDGET_TEXTAREA    Widget = 2
+	Widget_WIDGET_EMAIL       Widget = 3
+	Widget_WIDGET_PASSWORD    Widget = 4
+	Widget_WIDGET_NUMBER      Widget = 5
+	Widget_WIDGET_CHECKBOX    Widget = 6
+	Widget_WIDGET_SELECT      Widget = 7
+	Widget_WIDGET_DATE        Widget = 8
+	Widget_WIDGET_DATETIME    Widget = 9
+	Widget_WIDGET_HIDDEN      Widget = 10
+	Widget_WIDGET_URL         Widget = 11
+)
+
+// Enum value maps for Widget.
+var (
+	Widget_name = map[int32]string{
+		0:  "WIDGET_UNSPECIFIED",
+		1:  "WIDGET_TEXT",
+		2:  "WIDGET_TEXTAREA",
+		3:  "WIDGET_EMAIL",
+		4:  "WIDGET_PASSWORD",
+		5:  "WIDGET_NUMBER",
+		6:  "WIDGET_CHECKBOX",
+		7:  "WIDGET_SELECT",
+		8:  "WIDGET_DATE",
+		9:  "WIDGET_DATETIME",
+		10: "WIDGET_HIDDEN",
+		11: "WIDGET_URL",
+	}
+	Widget_value = map[string]int32{
+		"WIDGET_UNSPECIFIED": 0,
+		"WIDGET_TEXT":        1,
+		"WIDGET_TEXTAREA":    2,
+		"WIDGET_EMAIL":       3,
+		"WIDGET_PASSWORD":    4,
+		"WIDGET_NUMBER":      5,
+		"WIDGET_CHECKBOX":    6,
+		"WIDGET_SELECT":      7,
+		"WIDGET_DATE":        8,
+		"WIDGET_DATETIME":    9,
+		"WIDGET_HIDDEN":      10,
+		"WIDGET_URL":         11,
+	}
+)
+
+func (x Widget) Enum() *Widget {
+	p := new(Widget)
+	*p = x
+	return p
+}
+
+func (x Widget) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Widget) Descriptor() protoreflect.EnumDescriptor {
+	return file_dep_proto_enumTypes[4].Descriptor()
+}
+
+func (Widget) Type() protoreflect.EnumType {
+	return &file_dep_proto_enumTypes[4]
+}
+
+func (x Widget) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Widget.Descriptor instead.
+func (Widget) EnumDescriptor() ([]byte, []int) {
+	return file_dep_proto_rawDescGZIP(), []int{4}
+}
+
+// Audit fields, the *_at ones are google.protobuf.Timestamp fields, the *_by
+// ones strings.
+type Audit int32
+
+const (
+	Audit_AUDIT_UNSPECIFIED Audit = 0
+	Audit_AUDIT_CREATED_AT  Audit = 1
+	Audit_AUDIT_UPDATED_AT  Audit = 2
+	Audit_AUDIT_CREATED_BY  Audit = 3
+	Audit_AUDIT_UPDATED_BY  Audit = 4
+)
+
+// Enum value maps for Audit.
+var (
+	Audit_name = map[int32]string{
+		0: "AUDIT_UNSPECIFIED",
+		1: "AUDIT_CREATED_AT",
+		2: "AUDIT_UPDATED_AT",
+		3: "AUDIT_CREATED_BY",
+		4: "AUDIT_UPDATED_BY",
+	}
+	Audit_value = map[string]int32{
+		"AUDIT_UNSPECIFIED": 0,
+		"AUDIT_CREATED_AT":  1,
+		"AUDIT_UPDATED_AT":  2,
+		"AUDIT_CREATED_BY":  3,
+		"AUDIT_UPDATED_BY":  4,
+	}
+)
+
+func (x Audit) Enum() *Audit {
+	p := new(Audit)
+	*p = x
+	return p
+}
+
+func (x Audit) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Audit) Descriptor() protoreflect.EnumDescriptor {
+	return file_dep_proto_enumTypes[5].Descriptor()
+}
+
+func (Audit) Type() protoreflect.EnumType {
+	return &file_dep_proto_enumTypes[5]
+}
+
+func (x Audit) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Audit.Descriptor instead.
+func (Audit) EnumDescriptor() ([]byte, []int) {
+	return file_dep_proto_rawDescGZIP(), []int{5}
+}
+
+// DepMessageOptions configures the code generated for a single resource.
+// Every field is optional, unset fields fall back to the defaults noted below.
+type DepMessageOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name of the backing table. Defaults to the lower cased message name.
+	Table string `protobuf:"bytes,1,opt,name=table,proto3" json:"table,omitempty"`
+	// How ids are assigned to new records. Defaults to ID_STRATEGY_SERIAL.
+	// The strategy sets the Go type of the ids in the generated methods and
+	// the type of the id column.
+	IdStrategy IdStrategy `protobuf:"varint,2,opt,name=id_strategy,json=idStrategy,proto3,enum=dep.IdStrategy" json:"id_strategy,omitempty"`
+	// Global resources are shared by all tenants, generated methods then take
+	// no tenant argument.
+	Global bool `protobuf:"varint,3,opt,name=global,proto3" json:"global,omitempty"`
+	// Operations to generate. Empty means all of them.
+	Operations []Operation `protobuf:"varint,4,rep,packed,name=operations,proto3,enum=dep.Operation" json:"operations,omitempty"`
+	// Prefix the resource routes are mounted under. Defaults to "/" + table.
+	RoutePrefix string `protobuf:"bytes,5,opt,name=route_prefix,json=routePrefix,proto3" json:"route_prefix,omitempty"`
+	// UI generated for the resource. Defaults to UI_MODE_HTMX.
+	UiMode UiMode `protobuf:"varint,6,opt,name=ui_mode,json=uiMode,proto3,enum=dep.UiMode" json:"ui_mode,omitempty"`
+	// How records are laid out in the table. Defaults to STORAGE_DOCUMENT.
+	Storage Storage `protobuf:"varint,7,opt,name=storage,proto3,enum=dep.Storage" json:"storage,omitempty"`
+	// Versioned resources keep a version with every record, starting at 1 and
+	// growing with every write. Update, Patch and Delete then take the version
+	// they expect and fail with dep.ErrConflict when the record moved on.
+	Versioned bool `protobuf:"varint,8,opt,name=versioned,proto3" json:"versioned,omitempty"`
+	// Delete moves records to a trash, marking them with deleted_at, rather
+	// than removing them. List, Get and the writes skip deleted records,
+	// ListDeleted lists them, Restore brings one back and Purge removes it
+	// for good.
+	SoftDelete bool `protobuf:"varint,9,opt,name=soft_delete,json=softDelete,proto3" json:"soft_delete,omitempty"`
+	// History keeps every revision of a record in <table>_history: the value
+	// each write left it at and the one before, who made the write and when.
+	// History lists them and GetAtRevision reads one back.
+	History bool `protobuf:"varint,10,opt,name=history,proto3" json:"history,omitempty"`
+	// Name of the field holding the id of ID_STRATEGY_NATURAL_KEY resources.
+	IdField string `protobuf:"bytes,11,opt,name=id_field,json=idField,proto3" json:"id_field,omitempty"`
+	// Indexes over groups of fields, the unique and indexed field options
+	// declare the ones over a single field.
+	Indexes []*Index `protobuf:"bytes,12,rep,name=indexes,proto3" json:"indexes,omitempty"`
+}
+
+func (x *DepMessageOptions) Reset() {
+	*x = DepMessageOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dep_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DepMessageOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DepMessageOptions) ProtoMessage() {}
+
+func (x *DepMessageOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_dep_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DepMessageOptions.ProtoReflect.Descriptor instead.
+func (*DepMessageOptions) Descriptor() ([]byte, []int) {
+	return file_dep_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DepMessageOptions) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+func (x *DepMessageOptions) GetIdStrategy() IdStrategy {
+	if x != nil {
+		return x.IdStrategy
+	}
+	return IdStrategy_ID_STRATEGY_UNSPECIFIED
+}
+
+func (x *DepMessageOptions) GetGlobal() bool {
+	if x != nil {
+		return x.Global
+	}
+	return false
+}
+
+func (x *DepMessageOptions) GetOperations() []Operation {
+	if x != nil {
+		return x.Operations
+	}
+	return nil
+}
+
+func (x *DepMessageOptions) GetRoutePrefix() string {
+	if x != nil {
+		return x.RoutePrefix
+	}
+	return ""
+}
+
+func (x *DepMessageOptions) GetUiMode() UiMode {
+	if x != nil {
+		return x.UiMode
+	}
+	return UiMode_UI_MODE_UNSPECIFIED
+}
+
+func (x *DepMessageOptions) GetStorage() Storage {
+	if x != nil {
+		return x.Storage
+	}
+	return Storage_STORAGE_UNSPECIFIED
+}
+
+func (x *DepMessageOptions) GetVersioned() bool {
+	if x != nil {
+		return x.Versioned
+	}
+	return false
+}
+
+func (x *DepMessageOptions) GetSoftDelete() bool {
+	if x != nil {
+		return x.SoftDelete
+	}
+	return false
+}
+
+func (x *DepMessageOptions) GetHistory() bool {
+	if x != nil {
+		return x.History
+	}
+	return false
+}
+
+func (x *DepMessageOptions) GetIdField() string {
+	if x != nil {
+		return x.IdField
+	}
+	return ""
+}
+
+func (x *DepMessageOptions) GetIndexes() []*Index {
+	if x != nil {
+		return x.Indexes
+	}
+	return nil
+}
+
+// Index declares an index over fields of a resource. The fields have to be
+// plain scalar or enum fields.
+type Index struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Proto names of the fields, in the order of the index.
+	Fields []string `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	// No two records of a tenant may hold the same values in all of the
+	// fields, writes storing taken ones fail with dep.ErrAlreadyExists. The
+	// resource gets a GetBy<Field>And<Field> finder.
+	Unique bool `protobuf:"varint,2,opt,name=unique,proto3" json:"unique,omitempty"`
+}
+
+func (x *Index) Reset() {
+	*x = Index{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dep_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Index) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Index) ProtoMessage() {}
+
+func (x *Index) ProtoReflect() protoreflect.Message {
+	mi := &file_dep_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Index.ProtoReflect.Descriptor instead.
+func (*Index) Descriptor() ([]byte, []int) {
+	return file_dep_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Index) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *Index) GetUnique() bool {
+	if x != nil {
+		return x.Unique
+	}
+	return false
+}
+
+// DepFieldOptions configures a single field of a resource.
+type DepFieldOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Create and Validate reject records where the field is empty.
+	Required bool `protobuf:"varint,1,opt,name=required,proto3" json:"required,omitempty"`
+	// The field is assigned by the server, it is never read from forms.
+	ReadOnly bool `protobuf:"varint,2,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	// The field is left out of views and forms entirely.
+	Hidden bool `protobuf:"varint,3,opt,name=hidden,proto3" json:"hidden,omitempty"`
+	// Label shown in views and forms. Defaults to the Go field name.
+	Label string `protobuf:"bytes,4,opt,name=label,proto3" json:"label,omitempty"`
+	// Placeholder of the form input.
+	Placeholder string `protobuf:"bytes,5,opt,name=placeholder,proto3" json:"placeholder,omitempty"`
+	// Form input used for the field. Defaults to one matching the field kind.
+	Widget Widget `protobuf:"varint,6,opt,name=widget,proto3,enum=dep.Widget" json:"widget,omitempty"`
+	// Name of the backing column. Defaults to the proto field name.
+	Column string `protobuf:"bytes,7,opt,name=column,proto3" json:"column,omitempty"`
+	// The field may be used to filter lists.
+	Searchable bool `protobuf:"varint,8,opt,name=searchable,proto3" json:"searchable,omitempty"`
+	// The field may be used to order lists.
+	Sortable bool `protobuf:"varint,9,opt,name=sortable,proto3" json:"sortable,omitempty"`
+	// Minimum and maximum length of strings (in characters) and bytes.
+	MinLen uint32 `protobuf:"varint,10,opt,name=min_len,json=minLen,proto3" json:"min_len,omitempty"`
+	MaxLen uint32 `protobuf:"varint,11,opt,name=max_len,json=maxLen,proto3" json:"max_len,omitempty"`
+	// Regular expression (RE2 syntax) strings have to match.
+	Pattern string `protobuf:"bytes,12,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// The string has to be an email address.
+	Email bool `protobuf:"varint,13,opt,name=email,proto3" json:"email,omitempty"`
+	// The string has to be an absolute URL.
+	Url bool `protobuf:"varint,14,opt,name=url,proto3" json:"url,omitempty"`
+	// Inclusive range of numeric fields.
+	Min *float64 `protobuf:"fixed64,15,opt,name=min,proto3,oneof" json:"min,omitempty"`
+	Max *float64 `protobuf:"fixed64,16,opt,name=max,proto3,oneof" json:"max,omitempty"`
+	// Enums have to hold one of their defined values.
+	DefinedOnly bool `protobuf:"varint,17,opt,name=defined_only,json=definedOnly,proto3" json:"defined_only,omitempty"`
+	// Minimum and maximum number of items of repeated and map fields.
+	MinItems uint32 `protobuf:"varint,18,opt,name=min_items,json=minItems,proto3" json:"min_items,omitempty"`
+	MaxItems uint32 `protobuf:"varint,19,opt,name=max_items,json=maxItems,proto3" json:"max_items,omitempty"`
+	// The field records when or by whom the record was created or last
+	// updated. Create and the writes fill it in, whatever the client sent, from
+	// the time and the actor of the request context (see dep.WithActor). It is
+	// read only.
+	Audit Audit `protobuf:"varint,20,opt,name=audit,proto3,enum=dep.Audit" json:"audit,omitempty"`
+	// No two records of a tenant may hold the same value, writes storing a
+	// taken one fail with dep.ErrAlreadyExists. The field gets a unique index
+	// and the resource a GetBy<Field> finder. Only plain scalar and enum
+	// fields can be unique or indexed.
+	Unique bool `protobuf:"varint,21,opt,name=unique,proto3" json:"unique,omitempty"`
+	// The field gets an index, e.g. for lists filtering on it.
+	Indexed bool `protobuf:"varint,22,opt,name=indexed,proto3" json:"indexed,omitempty"`
+}
+
+func (x *DepFieldOptions) Reset() {
+	*x = DepFieldOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dep_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DepFieldOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DepFieldOptions) ProtoMessage() {}
+
+func (x *DepFieldOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_dep_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DepFieldOptions.ProtoReflect.Descriptor instead.
+func (*DepFieldOptions) Descriptor() ([]byte, []int) {
+	return file_dep_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DepFieldOptions) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *DepFieldOptions) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+func (x *DepFieldOptions) GetHidden() bool {
+	if x != nil {
+		return x.Hidden
+	}
+	return false
+}
+
+func (x *DepFieldOptions) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *DepFieldOptions) GetPlaceholder() string {
+	if x != nil {
+		return x.Placeholder
+	}
+	return ""
+}
+
+func (x *DepFieldOptions) GetWidget() Widget {
+	if x != nil {
+		return x.Widget
+	}
+	return Widget_WIDGET_UNSPECIFIED
+}
+
+func (x *DepFieldOptions) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *DepFieldOptions) GetSearchable() bool {
+	if x != nil {
+		return x.Searchable
+	}
+	return false
+}
+
+func (x *DepFieldOptions) GetSortable() bool {
+	if x != nil {
+		return x.Sortable
+	}
+	return false
+}
+
+func (x *DepFieldOptions) GetMinLen() uint32 {
+	if x != nil {
+		return x.MinLen
+	}
+	return 0
+}
+
+func (x *DepFieldOptions) GetMaxLen() uint32 {
+	if x != nil {
+		return x.MaxLen
+	}
+	return 0
+}
+
+func (x *DepFieldOptions) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *DepFieldOptions) GetEmail() bool {
+	if x != nil {
+		return x.Email
+	}
+	return false
+}
+
+func (x *DepFieldOptions) GetUrl() bool {
+	if x != nil {
+		return x.Url
+	}
+	return false
+}
+
+func (x *DepFieldOptions) GetMin() float64 {
+	if x != nil && x.Min != nil {
+		return *x.Min
+	}
+	return 0
+}
+
+func (x *DepFieldOptions) GetMax() float64 {
+	if x != nil && x.Max != nil {
+		return *x.Max
+	}
+	return 0
+}
+
+func (x *DepFieldOptions) GetDefinedOnly() bool {
+	if x != nil {
+		return x.DefinedOnly
+	}
+	return false
+}
+
+func (x *DepFieldOptions) GetMinItems() uint32 {
+	if x != nil {
+		return x.MinItems
+	}
+	return 0
+}
+
+func (x *DepFieldOptions) GetMaxItems() uint32 {
+	if x != nil {
+		return x.MaxItems
+	}
+	return 0
+}
+
+func (x *DepFieldOptions) GetAudit() Audit {
+	if x != nil {
+		return x.Audit
+	}
+	return Audit_AUDIT_UNSPECIFIED
+}
+
+func (x *DepFieldOptions) GetUnique() bool {
+	if x != nil {
+		return x.Unique
+	}
+	return false
+}
+
+func (x *DepFieldOptions) GetIndexed() bool {
+	if x != nil {
+		return x.Indexed
+	}
+	return false
+}
+
 var file_dep_proto_extTypes = []protoimpl.ExtensionInfo{
 	{
 		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
@@ -29,12 +852,43 @@ var file_dep_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "bytes,90002,opt,name=opts",
 		Filename:      "dep.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*DepMessageOptions)(nil),
+		Field:         90003,
+		Name:          "dep.resource",
+		Tag:           "bytes,90003,opt,name=resource",
+		Filename:      "dep.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*DepFieldOptions)(nil),
+		Field:         90004,
+		Name:          "dep.field",
+		Tag:           "bytes,90004,opt,name=field",
+		Filename:      "dep.proto",
+	},
 }
 
 // Extension fields to descriptorpb.MessageOptions.
 var (
+	// Legacy switch, `option (dep.opts) = "htmx";` generates the message with
+	// default DepMessageOptions. Prefer (dep.resource) for new protos.
+	//
 	// optional string opts = 90002;
 	E_Opts = &file_dep_proto_extTypes[0]
+	// Marks the message as a resource and configures what gets generated.
+	//
+	// optional dep.DepMessageOptions resource = 90003;
+	E_Resource = &file_dep_proto_extTypes[1]
+)
+
+// Extension fields to descriptorpb.FieldOptions.
+var (
+	// Configures how a field of a resource is stored, validated and rendered.
+	//
+	// optional dep.DepFieldOptions field = 90004;
+	E_Field = &file_dep_proto_extTypes[2]
 )
 
 var File_dep_proto protoreflect.FileDescriptor
@@ -43,25 +897,192 @@ var file_dep_proto_rawDesc = []byte{
 	0x0a, 0x09, 0x64, 0x65, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x64, 0x65, 0x70,
 	0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
 	0x66, 0x2f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x3a, 0x35, 0x0a, 0x04, 0x6f, 0x70, 0x74, 0x73, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f,
+	0x74, 0x6f, 0x22, 0xae, 0x03, 0x0a, 0x11, 0x44, 0x65, 0x70, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x61, 0x62, 0x6c,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x30,
+	0x0a, 0x0b, 0x69, 0x64, 0x5f, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x64, 0x65, 0x70, 0x2e, 0x49, 0x64, 0x53, 0x74, 0x72, 0x61,
+	0x74, 0x65, 0x67, 0x79, 0x52, 0x0a, 0x69, 0x64, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79,
+	0x12, 0x16, 0x0a, 0x06, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x12, 0x2e, 0x0a, 0x0a, 0x6f, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x64,
+	0x65, 0x70, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x6f, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x6f, 0x75, 0x74,
+	0x65, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x72, 0x6f, 0x75, 0x74, 0x65, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x24, 0x0a, 0x07, 0x75,
+	0x69, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x64,
+	0x65, 0x70, 0x2e, 0x55, 0x69, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x06, 0x75, 0x69, 0x4d, 0x6f, 0x64,
+	0x65, 0x12, 0x26, 0x0a, 0x07, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x64, 0x65, 0x70, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
+	0x52, 0x07, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x6f, 0x66, 0x74, 0x5f,
+	0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x73, 0x6f,
+	0x66, 0x74, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x68, 0x69, 0x73, 0x74,
+	0x6f, 0x72, 0x79, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x68, 0x69, 0x73, 0x74, 0x6f,
+	0x72, 0x79, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x64, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x0b,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x69, 0x64, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x24, 0x0a,
+	0x07, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a,
+	0x2e, 0x64, 0x65, 0x70, 0x2e, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x07, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x65, 0x73, 0x22, 0x37, 0x0a, 0x05, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x16, 0x0a, 0x06,
+	0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69,
+	0x65, 0x6c, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x75, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x22, 0xf6, 0x04, 0x0a,
+	0x0f, 0x44, 0x65, 0x70, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x12, 0x1b, 0x0a, 0x09,
+	0x72, 0x65, 0x61, 0x64, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x08, 0x72, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x69, 0x64,
+	0x64, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x68, 0x69, 0x64, 0x64, 0x65,
+	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x6c, 0x61, 0x63, 0x65,
+	0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x6c,
+	0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x12, 0x23, 0x0a, 0x06, 0x77, 0x69, 0x64,
+	0x67, 0x65, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x64, 0x65, 0x70, 0x2e,
+	0x57, 0x69, 0x64, 0x67, 0x65, 0x74, 0x52, 0x06, 0x77, 0x69, 0x64, 0x67, 0x65, 0x74, 0x12, 0x16,
+	0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68,
+	0x61, 0x62, 0x6c, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x73, 0x65, 0x61, 0x72,
+	0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x6f, 0x72, 0x74, 0x61, 0x62,
+	0x6c, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x73, 0x6f, 0x72, 0x74, 0x61, 0x62,
+	0x6c, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x69, 0x6e, 0x5f, 0x6c, 0x65, 0x6e, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x06, 0x6d, 0x69, 0x6e, 0x4c, 0x65, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x6d,
+	0x61, 0x78, 0x5f, 0x6c, 0x65, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6d, 0x61,
+	0x78, 0x4c, 0x65, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65,
+	0x6d, 0x61, 0x69, 0x6c, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x0e, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x15, 0x0a, 0x03, 0x6d, 0x69, 0x6e, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x03, 0x6d, 0x69, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a,
+	0x03, 0x6d, 0x61, 0x78, 0x18, 0x10, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x03, 0x6d, 0x61,
+	0x78, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x64, 0x5f,
+	0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x11, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x64, 0x65, 0x66, 0x69,
+	0x6e, 0x65, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x69, 0x6e, 0x5f, 0x69,
+	0x74, 0x65, 0x6d, 0x73, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6d, 0x69, 0x6e, 0x49,
+	0x74, 0x65, 0x6d, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x69, 0x74, 0x65, 0x6d,
+	0x73, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x49, 0x74, 0x65, 0x6d,
+	0x73, 0x12, 0x20, 0x0a, 0x05, 0x61, 0x75, 0x64, 0x69, 0x74, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0a, 0x2e, 0x64, 0x65, 0x70, 0x2e, 0x41, 0x75, 0x64, 0x69, 0x74, 0x52, 0x05, 0x61, 0x75,
+	0x64, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x18, 0x15, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x75, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x65, 0x64, 0x18, 0x16, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x65, 0x64, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x6d, 0x69, 0x6e, 0x42, 0x06, 0x0a,
+	0x04, 0x5f, 0x6d, 0x61, 0x78, 0x2a, 0xa6, 0x01, 0x0a, 0x0a, 0x49, 0x64, 0x53, 0x74, 0x72, 0x61,
+	0x74, 0x65, 0x67, 0x79, 0x12, 0x1b, 0x0a, 0x17, 0x49, 0x44, 0x5f, 0x53, 0x54, 0x52, 0x41, 0x54,
+	0x45, 0x47, 0x59, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x16, 0x0a, 0x12, 0x49, 0x44, 0x5f, 0x53, 0x54, 0x52, 0x41, 0x54, 0x45, 0x47, 0x59,
+	0x5f, 0x53, 0x45, 0x52, 0x49, 0x41, 0x4c, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x49, 0x44, 0x5f,
+	0x53, 0x54, 0x52, 0x41, 0x54, 0x45, 0x47, 0x59, 0x5f, 0x55, 0x55, 0x49, 0x44, 0x5f, 0x56, 0x34,
+	0x10, 0x02, 0x12, 0x17, 0x0a, 0x13, 0x49, 0x44, 0x5f, 0x53, 0x54, 0x52, 0x41, 0x54, 0x45, 0x47,
+	0x59, 0x5f, 0x55, 0x55, 0x49, 0x44, 0x5f, 0x56, 0x37, 0x10, 0x03, 0x12, 0x14, 0x0a, 0x10, 0x49,
+	0x44, 0x5f, 0x53, 0x54, 0x52, 0x41, 0x54, 0x45, 0x47, 0x59, 0x5f, 0x55, 0x4c, 0x49, 0x44, 0x10,
+	0x04, 0x12, 0x1b, 0x0a, 0x17, 0x49, 0x44, 0x5f, 0x53, 0x54, 0x52, 0x41, 0x54, 0x45, 0x47, 0x59,
+	0x5f, 0x4e, 0x41, 0x54, 0x55, 0x52, 0x41, 0x4c, 0x5f, 0x4b, 0x45, 0x59, 0x10, 0x05, 0x2a, 0x8f,
+	0x01, 0x0a, 0x09, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x19, 0x0a, 0x15,
+	0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43,
+	0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x4f, 0x50, 0x45, 0x52, 0x41,
+	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d, 0x4f,
+	0x50, 0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x47, 0x45, 0x54, 0x10, 0x02, 0x12, 0x14,
+	0x0a, 0x10, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x52, 0x45, 0x41,
+	0x54, 0x45, 0x10, 0x03, 0x12, 0x14, 0x0a, 0x10, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x49, 0x4f,
+	0x4e, 0x5f, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x10, 0x04, 0x12, 0x14, 0x0a, 0x10, 0x4f, 0x50,
+	0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x05,
+	0x2a, 0x45, 0x0a, 0x06, 0x55, 0x69, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x17, 0x0a, 0x13, 0x55, 0x49,
+	0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x10, 0x0a, 0x0c, 0x55, 0x49, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x48,
+	0x54, 0x4d, 0x58, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x55, 0x49, 0x5f, 0x4d, 0x4f, 0x44, 0x45,
+	0x5f, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x02, 0x2a, 0x4d, 0x0a, 0x07, 0x53, 0x74, 0x6f, 0x72, 0x61,
+	0x67, 0x65, 0x12, 0x17, 0x0a, 0x13, 0x53, 0x54, 0x4f, 0x52, 0x41, 0x47, 0x45, 0x5f, 0x55, 0x4e,
+	0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x14, 0x0a, 0x10, 0x53,
+	0x54, 0x4f, 0x52, 0x41, 0x47, 0x45, 0x5f, 0x44, 0x4f, 0x43, 0x55, 0x4d, 0x45, 0x4e, 0x54, 0x10,
+	0x01, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x54, 0x4f, 0x52, 0x41, 0x47, 0x45, 0x5f, 0x43, 0x4f, 0x4c,
+	0x55, 0x4d, 0x4e, 0x53, 0x10, 0x02, 0x2a, 0xf1, 0x01, 0x0a, 0x06, 0x57, 0x69, 0x64, 0x67, 0x65,
+	0x74, 0x12, 0x16, 0x0a, 0x12, 0x57, 0x49, 0x44, 0x47, 0x45, 0x54, 0x5f, 0x55, 0x4e, 0x53, 0x50,
+	0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x57, 0x49, 0x44,
+	0x47, 0x45, 0x54, 0x5f, 0x54, 0x45, 0x58, 0x54, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x57, 0x49,
+	0x44, 0x47, 0x45, 0x54, 0x5f, 0x54, 0x45, 0x58, 0x54, 0x41, 0x52, 0x45, 0x41, 0x10, 0x02, 0x12,
+	0x10, 0x0a, 0x0c, 0x57, 0x49, 0x44, 0x47, 0x45, 0x54, 0x5f, 0x45, 0x4d, 0x41, 0x49, 0x4c, 0x10,
+	0x03, 0x12, 0x13, 0x0a, 0x0f, 0x57, 0x49, 0x44, 0x47, 0x45, 0x54, 0x5f, 0x50, 0x41, 0x53, 0x53,
+	0x57, 0x4f, 0x52, 0x44, 0x10, 0x04, 0x12, 0x11, 0x0a, 0x0d, 0x57, 0x49, 0x44, 0x47, 0x45, 0x54,
+	0x5f, 0x4e, 0x55, 0x4d, 0x42, 0x45, 0x52, 0x10, 0x05, 0x12, 0x13, 0x0a, 0x0f, 0x57, 0x49, 0x44,
+	0x47, 0x45, 0x54, 0x5f, 0x43, 0x48, 0x45, 0x43, 0x4b, 0x42, 0x4f, 0x58, 0x10, 0x06, 0x12, 0x11,
+	0x0a, 0x0d, 0x57, 0x49, 0x44, 0x47, 0x45, 0x54, 0x5f, 0x53, 0x45, 0x4c, 0x45, 0x43, 0x54, 0x10,
+	0x07, 0x12, 0x0f, 0x0a, 0x0b, 0x57, 0x49, 0x44, 0x47, 0x45, 0x54, 0x5f, 0x44, 0x41, 0x54, 0x45,
+	0x10, 0x08, 0x12, 0x13, 0x0a, 0x0f, 0x57, 0x49, 0x44, 0x47, 0x45, 0x54, 0x5f, 0x44, 0x41, 0x54,
+	0x45, 0x54, 0x49, 0x4d, 0x45, 0x10, 0x09, 0x12, 0x11, 0x0a, 0x0d, 0x57, 0x49, 0x44, 0x47, 0x45,
+	0x54, 0x5f, 0x48, 0x49, 0x44, 0x44, 0x45, 0x4e, 0x10, 0x0a, 0x12, 0x0e, 0x0a, 0x0a, 0x57, 0x49,
+	0x44, 0x47, 0x45, 0x54, 0x5f, 0x55, 0x52, 0x4c, 0x10, 0x0b, 0x2a, 0x76, 0x0a, 0x05, 0x41, 0x75,
+	0x64, 0x69, 0x74, 0x12, 0x15, 0x0a, 0x11, 0x41, 0x55, 0x44, 0x49, 0x54, 0x5f, 0x55, 0x4e, 0x53,
+	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x14, 0x0a, 0x10, 0x41, 0x55,
+	0x44, 0x49, 0x54, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x41, 0x54, 0x10, 0x01,
+	0x12, 0x14, 0x0a, 0x10, 0x41, 0x55, 0x44, 0x49, 0x54, 0x5f, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45,
+	0x44, 0x5f, 0x41, 0x54, 0x10, 0x02, 0x12, 0x14, 0x0a, 0x10, 0x41, 0x55, 0x44, 0x49, 0x54, 0x5f,
+	0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x42, 0x59, 0x10, 0x03, 0x12, 0x14, 0x0a, 0x10,
+	0x41, 0x55, 0x44, 0x49, 0x54, 0x5f, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x42, 0x59,
+	0x10, 0x04, 0x3a, 0x35, 0x0a, 0x04, 0x6f, 0x70, 0x74, 0x73, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f,
 	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73,
 	0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x92, 0xbf, 0x05, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x6f, 0x70, 0x74, 0x73, 0x42, 0x25, 0x5a, 0x23, 0x67, 0x69, 0x74,
-	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x71, 0x7a, 0x78, 0x2f, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x2d, 0x67, 0x6f, 0x2d, 0x64, 0x65, 0x70, 0x2f, 0x64, 0x65, 0x70, 0x3b, 0x64, 0x65, 0x70,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6f, 0x70, 0x74, 0x73, 0x3a, 0x55, 0x0a, 0x08, 0x72, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x93, 0xbf, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x64, 0x65, 0x70, 0x2e, 0x44, 0x65, 0x70, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x3a, 0x4b, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c,
+	0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x94, 0xbf, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x14, 0x2e, 0x64, 0x65, 0x70, 0x2e, 0x44, 0x65, 0x70, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x42, 0x1b, 0x5a,
+	0x19, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d, 0x67, 0x65, 0x6e, 0x2d, 0x67, 0x6f, 0x2d, 0x64,
+	0x65, 0x70, 0x2f, 0x64, 0x65, 0x70, 0x3b, 0x64, 0x65, 0x70, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
+var (
+	file_dep_proto_rawDescOnce sync.Once
+	file_dep_proto_rawDescData = file_dep_proto_rawDesc
+)
+
+func file_dep_proto_rawDescGZIP() []byte {
+	file_dep_proto_rawDescOnce.Do(func() {
+		file_dep_proto_rawDescData = protoimpl.X.CompressGZIP(file_dep_proto_rawDescData)
+	})
+	return file_dep_proto_rawDescData
+}
+
+var file_dep_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_dep_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_dep_proto_goTypes = []interface{}{
-	(*descriptorpb.MessageOptions)(nil), // 0: google.protobuf.MessageOptions
+	(IdStrategy)(0),                     // 0: dep.IdStrategy
+	(Operation)(0),                      // 1: dep.Operation
+	(UiMode)(0),                         // 2: dep.UiMode
+	(Storage)(0),                        // 3: dep.Storage
+	(Widget)(0),                         // 4: dep.Widget
+	(Audit)(0),                          // 5: dep.Audit
+	(*DepMessageOptions)(nil),           // 6: dep.DepMessageOptions
+	(*Index)(nil),                       // 7: dep.Index
+	(*DepFieldOptions)(nil),             // 8: dep.DepFieldOptions
+	(*descriptorpb.MessageOptions)(nil), // 9: google.protobuf.MessageOptions
+	(*descriptorpb.FieldOptions)(nil),   // 10: google.protobuf.FieldOptions
 }
 var file_dep_proto_depIdxs = []int32{
-	0, // 0: dep.opts:extendee -> google.protobuf.MessageOptions
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	0, // [0:1] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: dep.DepMessageOptions.id_strategy:type_name -> dep.IdStrategy
+	1,  // 1: dep.DepMessageOptions.operations:type_name -> dep.Operation
+	2,  // 2: dep.DepMessageOptions.ui_mode:type_name -> dep.UiMode
+	3,  // 3: dep.DepMessageOptions.storage:type_name -> dep.Storage
+	7,  // 4: dep.DepMessageOptions.indexes:type_name -> dep.Index
+	4,  // 5: dep.DepFieldOptions.widget:type_name -> dep.Widget
+	5,  // 6: dep.DepFieldOptions.audit:type_name -> dep.Audit
+	9,  // 7: dep.opts:extendee -> google.protobuf.MessageOptions
+	9,  // 8: dep.resource:extendee -> google.protobuf.MessageOptions
+	10, // 9: dep.field:extendee -> google.protobuf.FieldOptions
+	6,  // 10: dep.resource:type_name -> dep.DepMessageOptions
+	8,  // 11: dep.field:type_name -> dep.DepFieldOptions
+	12, // [12:12] is the sub-list for method output_type
+	12, // [12:12] is the sub-list for method input_type
+	10, // [10:12] is the sub-list for extension type_name
+	7,  // [7:10] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_dep_proto_init() }
@@ -69,18 +1090,59 @@ func file_dep_proto_init() {
 	if File_dep_proto != nil {
 		return
 	}
+	if !protoimpl.UnsafeEnabled {
+		file_dep_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DepMessageOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dep_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Index); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dep_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DepFieldOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_dep_proto_msgTypes[2].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_dep_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   0,
-			NumExtensions: 1,
+			NumEnums:      6,
+			NumMessages:   3,
+			NumExtensions: 3,
 			NumServices:   0,
 		},
 		GoTypes:           file_dep_proto_goTypes,
 		DependencyIndexes: file_dep_proto_depIdxs,
+		EnumInfos:         file_dep_proto_enumTypes,
+		MessageInfos:      file_dep_proto_msgTypes,
 		ExtensionInfos:    file_dep_proto_extTypes,
 	}.Build()
 	File_dep_proto = out.File
@@ -0,0 +1,20 @@
+package dep
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// EnumNumber reads back an enum value stored by name, values being the name
+// to number map protoc-gen-go generates for the enum. String writes the
+// digits of numbers the enum has no name for, those are read as numbers.
+func EnumNumber(values map[string]int32, name string) (int32, error) {
+	if n, ok := values[name]; ok {
+		return n, nil
+	}
+	n, err := strconv.ParseInt(name, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("dep: %q is no value of the enum", name)
+	}
+	return int32(n), nil
+}
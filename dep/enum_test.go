@@ -0,0 +1,23 @@
+package dep
+
+import "testing"
+
+func TestEnumNumber(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want int32
+	}{
+		{"WIDGET_TEXT", int32(Widget_WIDGET_TEXT)},
+		{Widget(42).String(), 42},
+		{"-3", -3},
+	} {
+		if got, err := EnumNumber(Widget_value, tt.name); err != nil || got != tt.want {
+			t.Errorf("%s: got %d, %v, want %d", tt.name, got, err, tt.want)
+		}
+	}
+	for _, name := range []string{"", "WIDGET_NOPE", "4294967296"} {
+		if _, err := EnumNumber(Widget_value, name); err == nil {
+			t.Errorf("%q: want an error", name)
+		}
+	}
+}
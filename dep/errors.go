@@ -0,0 +1,59 @@
+package dep
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNotFound is returned by repositories when no record has the requested
+// id, handlers answer it with a 404.
+var ErrNotFound = errors.New("dep: not found")
+
+// ErrConflict is returned by the writes of versioned resources when the
+// record is no longer at the version they were given, handlers answer it
+// with a 412.
+var ErrConflict = errors.New("dep: version conflict")
+
+// ErrAlreadyExists is returned by repositories when a write would store the
+// unique fields of another record, handlers answer it with a 409. The error
+// returned is an *AlreadyExistsError naming the fields.
+var ErrAlreadyExists = errors.New("dep: already exists")
+
+// AlreadyExistsError is the ErrAlreadyExists of a write, Fields holds the
+// proto names of the unique fields it found taken, all of them together.
+// It is empty when the index violated is not known.
+type AlreadyExistsError struct {
+	Fields []string
+}
+
+func (e *AlreadyExistsError) Error() string {
+	if len(e.Fields) == 0 {
+		return ErrAlreadyExists.Error()
+	}
+	return "dep: " + strings.Join(e.Fields, " and ") + " already taken"
+}
+
+// Is makes errors.Is(err, ErrAlreadyExists) hold.
+func (e *AlreadyExistsError) Is(target error) bool {
+	return target == ErrAlreadyExists
+}
+
+// ValidationErrors marks every field of e, so forms show the problem next
+// to their inputs.
+func (e *AlreadyExistsError) ValidationErrors() ValidationErrors {
+	errs := make(ValidationErrors)
+	for _, field := range e.Fields {
+		var others []string
+		for _, other := range e.Fields {
+			if other != field {
+				others = append(others, other)
+			}
+		}
+		if len(others) == 0 {
+			errs.Add(field, "is already taken")
+		} else {
+			errs.Add(field, "is already taken together with "+strings.Join(others, " and "))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,77 @@
+package dep
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// VersionFormValue is the hidden input edit forms of versioned resources
+// carry, the version the form was rendered from. It stands in for If-Match
+// as htmx does not send one.
+const VersionFormValue = "_version"
+
+// ETag formats version as the strong entity tag of a record.
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// IfMatch returns the version a write has to find the record at, read from
+// the If-Match header of req or, without one, the VersionFormValue of a
+// submitted form. It is 0, any version, when neither is given or the header
+// is "*", and -1 when the header only holds tags ETag does not produce, which
+// no record matches.
+func IfMatch(req *http.Request) (int64, error) {
+	header := req.Header.Get("If-Match")
+	if header == "" {
+		v := req.FormValue(VersionFormValue)
+		if v == "" {
+			return 0, nil
+		}
+		version, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || version < 1 {
+			return 0, fmt.Errorf("%w: %s: %q is not a version", ErrInvalidArgument, VersionFormValue, v)
+		}
+		return version, nil
+	}
+
+	if strings.TrimSpace(header) == "*" {
+		return 0, nil
+	}
+	for _, tag := range strings.Split(header, ",") {
+		if version, ok := parseETag(strings.TrimSpace(tag)); ok {
+			return version, nil
+		}
+	}
+	return -1, nil
+}
+
+// NoneMatch reports whether the If-None-Match header of req names the
+// record at version, so a GET can be answered with a 304. Weak tags are
+// compared as if they were strong.
+func NoneMatch(req *http.Request, version int64) bool {
+	header := req.Header.Get("If-None-Match")
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if v, ok := parseETag(tag); ok && v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// parseETag reads the version back from a tag ETag formatted.
+func parseETag(tag string) (int64, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
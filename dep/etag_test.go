@@ -0,0 +1,51 @@
+package dep
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIfMatch(t *testing.T) {
+	for header, want := range map[string]int64{
+		"":               0,
+		"*":              0,
+		`"3"`:            3,
+		`W/"3", "4"`:     4,
+		`"abc"`:          -1,
+		`W/"3"`:          -1,
+		ETag(1234567890): 1234567890,
+	} {
+		req := httptest.NewRequest("PUT", "/", nil)
+		if header != "" {
+			req.Header.Set("If-Match", header)
+		}
+		if got, err := IfMatch(req); err != nil || got != want {
+			t.Errorf("%s: got %d, %v, want %d", header, got, err, want)
+		}
+	}
+
+	req := httptest.NewRequest("DELETE", "/?_version=7", nil)
+	if got, err := IfMatch(req); err != nil || got != 7 {
+		t.Errorf("form: got %d, %v", got, err)
+	}
+	req = httptest.NewRequest("DELETE", "/?_version=seven", nil)
+	if _, err := IfMatch(req); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("malformed form value: got %v", err)
+	}
+}
+
+func TestNoneMatch(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	if NoneMatch(req, 2) {
+		t.Error("no header matched")
+	}
+	req.Header.Set("If-None-Match", `"1", W/"2"`)
+	if !NoneMatch(req, 2) || NoneMatch(req, 3) {
+		t.Errorf("%s: matched the wrong versions", req.Header.Get("If-None-Match"))
+	}
+	req.Header.Set("If-None-Match", "*")
+	if !NoneMatch(req, 3) {
+		t.Error("* did not match")
+	}
+}
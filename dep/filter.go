@@ -0,0 +1,276 @@
+package dep
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// The filter grammar is the subset of AIP-160 that maps onto columns:
+//
+//	expression  = sequence { "AND" sequence }
+//	sequence    = factor { factor }
+//	factor      = term { "OR" term }
+//	term        = [ "NOT" | "-" ] simple
+//	simple      = restriction | "(" expression ")"
+//	restriction = field comparator value
+//	comparator  = "=" | "!=" | "<" | "<=" | ">" | ">=" | ":"
+//
+// As in AIP-160 OR binds tighter than AND, and terms next to each other are
+// joined with AND.
+
+// expr is a node of a parsed filter. AND, OR and NOT have args, every other
+// op is a comparison of field with value.
+type expr struct {
+	op     string
+	args   []*expr
+	field  *field
+	value  any
+	prefix bool
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenComparator
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize splits a filter into words, quoted strings, comparators and
+// parentheses.
+func tokenize(filter string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(filter); {
+		c := filter[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")"})
+			i++
+		case c == '"' || c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(filter) && filter[j] != c; j++ {
+				if filter[j] == '\\' && j+1 < len(filter) {
+					j++
+				}
+				b.WriteByte(filter[j])
+			}
+			if j >= len(filter) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: b.String()})
+			i = j + 1
+		case strings.IndexByte("=!<>:", c) >= 0:
+			op := string(c)
+			if i+1 < len(filter) && filter[i+1] == '=' && c != '=' && c != ':' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected ! at %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenComparator, text: op})
+			i += len(op)
+		default:
+			j := i
+			for j < len(filter) && !strings.ContainsRune(" \t\n\r()\"'=!<>:", rune(filter[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: filter[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	field  func(name string) (*field, error)
+}
+
+// parseFilter parses filter, resolving field names with lookup. An empty
+// filter gives a nil expr.
+func parseFilter(filter string, lookup func(name string) (*field, error)) (*expr, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &parser{tokens: tokens, field: lookup}
+	e, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return e, nil
+}
+
+func (p *parser) peek() *token {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *parser) keyword(word string) bool {
+	t := p.peek()
+	return t != nil && t.kind == tokenWord && t.text == word
+}
+
+func (p *parser) expression() (*expr, error) {
+	left, err := p.sequence()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		p.pos++
+		right, err := p.sequence()
+		if err != nil {
+			return nil, err
+		}
+		left = &expr{op: "AND", args: []*expr{left, right}}
+	}
+	return left, nil
+}
+
+func (p *parser) sequence() (*expr, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t == nil || t.kind == tokenClose || p.keyword("AND") {
+			return left, nil
+		}
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = &expr{op: "AND", args: []*expr{left, right}}
+	}
+}
+
+func (p *parser) factor() (*expr, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &expr{op: "OR", args: []*expr{left, right}}
+	}
+	return left, nil
+}
+
+func (p *parser) term() (*expr, error) {
+	t := p.peek()
+	negate := false
+	switch {
+	case p.keyword("NOT"):
+		p.pos++
+		negate = true
+	case t != nil && t.kind == tokenWord && len(t.text) > 1 && t.text[0] == '-':
+		t.text = t.text[1:]
+		negate = true
+	}
+
+	e, err := p.simple()
+	if err != nil {
+		return nil, err
+	}
+	if negate {
+		e = &expr{op: "NOT", args: []*expr{e}}
+	}
+	return e, nil
+}
+
+func (p *parser) simple() (*expr, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("unexpected end of filter")
+	}
+	if t.kind == tokenOpen {
+		p.pos++
+		e, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t == nil || t.kind != tokenClose {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return e, nil
+	}
+	return p.restriction()
+}
+
+func (p *parser) restriction() (*expr, error) {
+	name := p.peek()
+	if name.kind != tokenWord || !isIdent(name.text) {
+		return nil, fmt.Errorf("expected a field name, got %q", name.text)
+	}
+	p.pos++
+	f, err := p.field(name.text)
+	if err != nil {
+		return nil, err
+	}
+	if !f.Filter {
+		return nil, fmt.Errorf("field %s cannot be filtered on", name.text)
+	}
+
+	op := p.peek()
+	if op == nil || op.kind != tokenComparator {
+		return nil, fmt.Errorf("expected a comparator after %s", name.text)
+	}
+	p.pos++
+	value := p.peek()
+	if value == nil || (value.kind != tokenWord && value.kind != tokenString) {
+		return nil, fmt.Errorf("expected a value after %s %s", name.text, op.text)
+	}
+	p.pos++
+
+	e := &expr{op: op.text, field: f}
+	if !f.allows(e.op) {
+		return nil, fmt.Errorf("%s does not support %s", name.text, e.op)
+	}
+	text := value.text
+	if f.kind == kindString && (e.op == "=" || e.op == "!=") && strings.HasSuffix(text, "*") {
+		text, e.prefix = strings.TrimSuffix(text, "*"), true
+	}
+	if e.value, err = f.parse(text); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func isIdent(s string) bool {
+	for i, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,234 @@
+package dep
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"io"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// The operations a Revision records.
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionPatch   = "patch"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+	RevisionPurge   = "purge"
+)
+
+// Revision is an entry in the history of a record, the write that took it
+// from Old to New. Old is nil for the first revision the history has of the
+// record, New for writes that removed it or moved it to the trash.
+type Revision[T proto.Message] struct {
+	// Revision counts the writes to the record, from 1.
+	Revision  int64     `json:"revision"`
+	Operation string    `json:"operation"`
+	Actor     string    `json:"actor"`
+	ChangedAt time.Time `json:"changed_at"`
+	Old       T         `json:"old,omitempty"`
+	New       T         `json:"new,omitempty"`
+}
+
+type revisionJSON struct {
+	Revision  int64           `json:"revision"`
+	Operation string          `json:"operation"`
+	Actor     string          `json:"actor"`
+	ChangedAt time.Time       `json:"changed_at"`
+	Old       json.RawMessage `json:"old,omitempty"`
+	New       json.RawMessage `json:"new,omitempty"`
+}
+
+// MarshalJSON encodes Old and New with protojson, the way the handlers write
+// a single record.
+func (r Revision[T]) MarshalJSON() ([]byte, error) {
+	old, err := marshalValue(r.Old)
+	if err != nil {
+		return nil, err
+	}
+	new, err := marshalValue(r.New)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(revisionJSON{r.Revision, r.Operation, r.Actor, r.ChangedAt, old, new})
+}
+
+// UnmarshalJSON reads a revision MarshalJSON wrote.
+func (r *Revision[T]) UnmarshalJSON(data []byte) error {
+	var raw revisionJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.Revision, r.Operation, r.Actor, r.ChangedAt = raw.Revision, raw.Operation, raw.Actor, raw.ChangedAt
+	if err := unmarshalValue(raw.Old, &r.Old); err != nil {
+		return err
+	}
+	return unmarshalValue(raw.New, &r.New)
+}
+
+// Changes returns the fields the revision changed.
+func (r Revision[T]) Changes() ([]Change, error) {
+	return Diff(r.Old, r.New)
+}
+
+// ScanRevision reads a row of a history table into a Revision, the row holds
+// the revision, operation, actor, changed_at, old_data and new_data columns
+// in that order.
+func ScanRevision[T proto.Message](row interface{ Scan(dest ...any) error }) (Revision[T], error) {
+	var r Revision[T]
+	var old, new []byte
+	if err := row.Scan(&r.Revision, &r.Operation, &r.Actor, &r.ChangedAt, &old, &new); err != nil {
+		return r, err
+	}
+
+	var err error
+	if old != nil {
+		if r.Old, err = unmarshalRevision[T](old); err != nil {
+			return r, err
+		}
+	}
+	if new != nil {
+		if r.New, err = unmarshalRevision[T](new); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+func unmarshalRevision[T proto.Message](data []byte) (T, error) {
+	var zero T
+	m := zero.ProtoReflect().Type().New().Interface().(T)
+	return m, UnmarshalDocument(data, m)
+}
+
+// MarshalDocument returns m as the protojson text history tables store, nil
+// when m is nil.
+func MarshalDocument(m proto.Message) (any, error) {
+	if m == nil || !m.ProtoReflect().IsValid() {
+		return nil, nil
+	}
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drivers send []byte as bytea, JSONB wants text.
+	return string(data), nil
+}
+
+// UnmarshalDocument reads m from the protojson text MarshalDocument
+// returned. Unknown fields are discarded so removed fields do not break
+// reads.
+func UnmarshalDocument(data []byte, m proto.Message) error {
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, m)
+}
+
+// Change is a field a revision changed, with its values before and after
+// formatted as text. A value is empty when the field was not set.
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Diff returns the top level fields that differ between old and new, in the
+// order they are declared. Either may be nil, its fields are then all unset.
+func Diff(old, new proto.Message) ([]Change, error) {
+	before, err := documentFields(old)
+	if err != nil {
+		return nil, err
+	}
+	after, err := documentFields(new)
+	if err != nil {
+		return nil, err
+	}
+
+	m := new
+	if m == nil || !m.ProtoReflect().IsValid() {
+		m = old
+	}
+	if m == nil {
+		return nil, nil
+	}
+
+	var changes []Change
+	fields := m.ProtoReflect().Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		name := string(fields.Get(i).Name())
+		if before[name] == after[name] {
+			continue
+		}
+		changes = append(changes, Change{Field: name, Old: displayValue(before[name]), New: displayValue(after[name])})
+	}
+	return changes, nil
+}
+
+// documentFields returns the compacted protojson of every field set in m by
+// proto name.
+func documentFields(m proto.Message) (map[string]string, error) {
+	if m == nil || !m.ProtoReflect().IsValid() {
+		return nil, nil
+	}
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string, len(raw))
+	for name, value := range raw {
+		// protojson varies its whitespace, compact it so equal values
+		// compare equal.
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, value); err != nil {
+			return nil, err
+		}
+		fields[name] = buf.String()
+	}
+	return fields, nil
+}
+
+// displayValue formats a json value for people, strings without quotes.
+func displayValue(value string) string {
+	var s string
+	if json.Unmarshal([]byte(value), &s) == nil {
+		return s
+	}
+	return value
+}
+
+var historyTemplate = template.Must(template.New("history").Parse(`
+<table class="history">
+  <thead>
+    <tr><th>Revision</th><th>Changed at</th><th>Changed by</th><th>Operation</th><th>Changes</th></tr>
+  </thead>
+  <tbody>
+  {{- range . }}
+    <tr>
+      <td>{{ .Revision }}</td>
+      <td>{{ .ChangedAt.Format "2006-01-02 15:04" }}</td>
+      <td>{{ .Actor }}</td>
+      <td>{{ .Operation }}</td>
+      <td>
+      {{- range .Changes }}
+        <p><span>{{ .Field }}</span> <del>{{ .Old }}</del> <ins>{{ .New }}</ins></p>
+      {{- end }}
+      </td>
+    </tr>
+  {{- end }}
+  </tbody>
+</table>
+`))
+
+// RenderHistory writes revisions as an html table for htmx, each row lists
+// the fields the revision changed with their old and new values.
+func RenderHistory[T proto.Message](w io.Writer, revisions []Revision[T]) error {
+	return historyTemplate.Execute(w, revisions)
+}
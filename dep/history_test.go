@@ -0,0 +1,96 @@
+package dep
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDiff(t *testing.T) {
+	old := &DepMessageOptions{Table: "users", Versioned: true, Operations: []Operation{Operation_OPERATION_GET}}
+	new := &DepMessageOptions{Table: "people", Versioned: true, UiMode: UiMode_UI_MODE_NONE, Operations: []Operation{Operation_OPERATION_GET}}
+
+	changes, err := Diff(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{Field: "table", Old: "users", New: "people"},
+		{Field: "ui_mode", Old: "", New: "UI_MODE_NONE"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("got %v, want %v", changes, want)
+	}
+
+	changes, err = Diff((*DepMessageOptions)(nil), old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []Change{
+		{Field: "table", Old: "", New: "users"},
+		{Field: "operations", Old: "", New: `["OPERATION_GET"]`},
+		{Field: "versioned", Old: "", New: "true"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("created: got %v, want %v", changes, want)
+	}
+}
+
+// revisionRow stands in for the row of a history table.
+type revisionRow []any
+
+func (r revisionRow) Scan(dest ...any) error {
+	for i, v := range r {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+func TestScanRevision(t *testing.T) {
+	old := &DepMessageOptions{Table: "users"}
+	data, err := MarshalDocument(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc, err := MarshalDocument((*DepMessageOptions)(nil)); doc != nil || err != nil {
+		t.Errorf("nil message: got %v, %v", doc, err)
+	}
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	r, err := ScanRevision[*DepMessageOptions](revisionRow{int64(2), RevisionDelete, "ann", at, []byte(data.(string)), []byte(nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Revision != 2 || r.Operation != RevisionDelete || r.Actor != "ann" || !r.ChangedAt.Equal(at) {
+		t.Errorf("got %+v", r)
+	}
+	if !proto.Equal(r.Old, old) || r.New != nil {
+		t.Errorf("got old %v, new %v", r.Old, r.New)
+	}
+
+	encoded, err := json.Marshal([]Revision[*DepMessageOptions]{r})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []Revision[*DepMessageOptions]
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || decoded[0].Revision != 2 || !proto.Equal(decoded[0].Old, old) || decoded[0].New != nil {
+		t.Errorf("json: got %s", encoded)
+	}
+
+	var b strings.Builder
+	if err := RenderHistory(&b, []Revision[*DepMessageOptions]{r}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<td>2</td>", "<td>2024-05-01 12:00</td>", "<td>ann</td>", "<span>table</span> <del>users</del> <ins></ins>"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("history does not contain %s:\n%s", want, b.String())
+		}
+	}
+}
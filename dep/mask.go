@@ -0,0 +1,273 @@
+package dep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// ParseFieldMask reads the mask of a PATCH request, the comma separated
+// update_mask query parameter or, without one, the fields present in the
+// json body.
+func ParseFieldMask(query url.Values, body []byte) (*fieldmaskpb.FieldMask, error) {
+	mask := new(fieldmaskpb.FieldMask)
+	if v := query.Get("update_mask"); v != "" {
+		for _, path := range strings.Split(v, ",") {
+			mask.Paths = append(mask.Paths, strings.TrimSpace(path))
+		}
+		return mask, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	}
+	for name := range fields {
+		mask.Paths = append(mask.Paths, name)
+	}
+	return mask, nil
+}
+
+// FieldMaskPaths checks the paths of mask against the fields of m and
+// returns them with proto field names, paths may use json names as well.
+// A lone "*" stands for every field.
+func FieldMaskPaths(m proto.Message, mask *fieldmaskpb.FieldMask) ([]string, error) {
+	desc := m.ProtoReflect().Descriptor()
+	if len(mask.GetPaths()) == 1 && mask.Paths[0] == "*" {
+		fields := desc.Fields()
+		paths := make([]string, fields.Len())
+		for i := range paths {
+			paths[i] = string(fields.Get(i).Name())
+		}
+		return paths, nil
+	}
+	if len(mask.GetPaths()) == 0 {
+		return nil, fmt.Errorf("%w: update_mask names no fields", ErrInvalidArgument)
+	}
+
+	paths := make([]string, len(mask.Paths))
+	for i, path := range mask.Paths {
+		fields, err := resolvePath(desc, path)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, len(fields))
+		for j, fd := range fields {
+			names[j] = string(fd.Name())
+		}
+		paths[i] = strings.Join(names, ".")
+	}
+	return paths, nil
+}
+
+// resolvePath returns the fields path leads through, every one but the last
+// has to be a singular message.
+func resolvePath(desc protoreflect.MessageDescriptor, path string) ([]protoreflect.FieldDescriptor, error) {
+	var fields []protoreflect.FieldDescriptor
+	for _, name := range strings.Split(path, ".") {
+		if desc == nil {
+			return nil, fmt.Errorf("%w: update_mask: %s cannot have subfields", ErrInvalidArgument, fields[len(fields)-1].Name())
+		}
+		fd := desc.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = desc.Fields().ByJSONName(name)
+		}
+		if fd == nil {
+			return nil, fmt.Errorf("%w: update_mask: %s has no field %q", ErrInvalidArgument, desc.Name(), name)
+		}
+		fields = append(fields, fd)
+
+		// Well known types have json of their own, they are set as a whole.
+		desc = nil
+		if fd.Message() != nil && !fd.IsList() && !fd.IsMap() && fd.Message().ParentFile().Package() != "google.protobuf" {
+			desc = fd.Message()
+		}
+	}
+	return fields, nil
+}
+
+// MaskedErrors keeps the ValidationErrors of err on the fields paths name,
+// or within them, other errors are returned as they are.
+func MaskedErrors(err error, paths []string) error {
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	masked := make(ValidationErrors)
+	for field, msg := range errs {
+		for _, path := range paths {
+			if field == path || strings.HasPrefix(field, path+".") {
+				masked[field] = msg
+				break
+			}
+		}
+	}
+	return masked.Err()
+}
+
+// ApplyFieldMask copies the fields paths name from src to dst, clearing
+// those src does not have. Nested messages of dst on the way are created,
+// dst shares nothing with src afterwards.
+func ApplyFieldMask(dst, src proto.Message, paths []string) {
+	src = proto.Clone(src)
+	for _, path := range paths {
+		fields, err := resolvePath(dst.ProtoReflect().Descriptor(), path)
+		if err != nil {
+			continue
+		}
+
+		to, from := dst.ProtoReflect(), src.ProtoReflect()
+		last := len(fields) - 1
+		for _, fd := range fields[:last] {
+			to, from = to.Mutable(fd).Message(), from.Get(fd).Message()
+		}
+		if fd := fields[last]; from.Has(fd) {
+			to.Set(fd, from.Get(fd))
+		} else {
+			to.Clear(fd)
+		}
+	}
+}
+
+// MergePatches returns the JSON merge patches (RFC 7396) storing the fields
+// paths name in the protojson document of a record from m. remove deletes
+// them and store writes the ones m has, applied in this order they replace
+// messages, lists and maps as a whole like ApplyFieldMask does. They are
+// strings as drivers send []byte as binary.
+func MergePatches(m proto.Message, paths []string) (remove, store string, err error) {
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return "", "", err
+	}
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return "", "", err
+	}
+
+	removeDoc, storeDoc := make(map[string]any), make(map[string]any)
+	for _, path := range paths {
+		fields, err := resolvePath(m.ProtoReflect().Descriptor(), path)
+		if err != nil {
+			return "", "", err
+		}
+
+		c, s, v := removeDoc, storeDoc, doc
+		last := len(fields) - 1
+		for i, fd := range fields {
+			name := fd.JSONName()
+			value, ok := v[name]
+			if i == last {
+				if c != nil {
+					c[name] = nil
+				}
+				if !ok {
+					break
+				}
+				s[name] = value
+			}
+			// Storing a member of a oneof clears the others.
+			if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() && c != nil {
+				for j := 0; j < oneof.Fields().Len(); j++ {
+					if other := oneof.Fields().Get(j); other != fd {
+						c[other.JSONName()] = nil
+					}
+				}
+			}
+			if i == last {
+				break
+			}
+			c, s = patchChild(c, name), patchChild(s, name)
+			v, _ = value.(map[string]any)
+		}
+	}
+
+	removeJSON, err := json.Marshal(removeDoc)
+	if err != nil {
+		return "", "", err
+	}
+	storeJSON, err := json.Marshal(storeDoc)
+	if err != nil {
+		return "", "", err
+	}
+	return string(removeJSON), string(storeJSON), nil
+}
+
+// patchChild returns the object at name in patch, adding it if need be. It
+// is nil when patch, or another path, already removes name as a whole.
+func patchChild(patch map[string]any, name string) map[string]any {
+	if patch == nil {
+		return nil
+	}
+	existing, ok := patch[name]
+	if !ok {
+		child := make(map[string]any)
+		patch[name] = child
+		return child
+	}
+	child, _ := existing.(map[string]any)
+	return child
+}
+
+// PatchColumns returns the SET list and the values storing the fields paths
+// name with STORAGE_COLUMNS, placeholders are numbered from first with
+// Postgres. columns and values hold all columns in the order of the fields
+// of m. Paths name whole fields, a oneof member being set stores the others
+// as well to clear them.
+func PatchColumns(d Dialect, first int, m proto.Message, paths []string, columns []string, values []any) (string, []any, error) {
+	fields := m.ProtoReflect().Descriptor().Fields()
+	picked := make(map[int]bool)
+	var order []int
+	pick := func(fd protoreflect.FieldDescriptor) {
+		if i := fd.Index(); !picked[i] {
+			picked[i] = true
+			order = append(order, i)
+		}
+	}
+
+	for _, path := range paths {
+		fd := fields.ByName(protoreflect.Name(path))
+		if fd == nil {
+			return "", nil, fmt.Errorf("%w: update_mask: %s is kept in a single column, it can only be stored as a whole", ErrInvalidArgument, strings.Split(path, ".")[0])
+		}
+		pick(fd)
+		if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() && m.ProtoReflect().Has(fd) {
+			for i := 0; i < oneof.Fields().Len(); i++ {
+				pick(oneof.Fields().Get(i))
+			}
+		}
+	}
+
+	set := make([]string, len(order))
+	args := make([]any, len(order))
+	for i, index := range order {
+		placeholder := "?"
+		if d == Postgres {
+			placeholder = "$" + strconv.Itoa(first+i)
+		}
+		set[i] = columns[index] + " = " + placeholder
+		args[i] = values[index]
+	}
+	return strings.Join(set, ", "), args, nil
+}
+
+// FormHas reports whether form has the input name, or inputs nested in it
+// with dotted names.
+func FormHas(form url.Values, name string) bool {
+	for key := range form {
+		if key == name || strings.HasPrefix(key, name+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package dep
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestFieldMaskPaths(t *testing.T) {
+	m := new(DepMessageOptions)
+	paths, err := FieldMaskPaths(m, &fieldmaskpb.FieldMask{Paths: []string{"table", "routePrefix", "ui_mode"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"table", "route_prefix", "ui_mode"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got %v, want %v", paths, want)
+	}
+
+	paths, err = FieldMaskPaths(m, &fieldmaskpb.FieldMask{Paths: []string{"*"}})
+	if err != nil || len(paths) != m.ProtoReflect().Descriptor().Fields().Len() {
+		t.Errorf("*: got %v, %v", paths, err)
+	}
+
+	for _, mask := range []*fieldmaskpb.FieldMask{nil, {Paths: []string{"nope"}}, {Paths: []string{"table.name"}}} {
+		if _, err := FieldMaskPaths(m, mask); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%v: got %v", mask, err)
+		}
+	}
+}
+
+func TestParseFieldMask(t *testing.T) {
+	mask, err := ParseFieldMask(url.Values{"update_mask": {"table, global"}}, nil)
+	if err != nil || !reflect.DeepEqual(mask.Paths, []string{"table", "global"}) {
+		t.Errorf("update_mask: got %v, %v", mask, err)
+	}
+
+	mask, err = ParseFieldMask(nil, []byte(`{"table": "t", "global": false}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(mask.Paths)
+	if !reflect.DeepEqual(mask.Paths, []string{"global", "table"}) {
+		t.Errorf("body: got %v", mask.Paths)
+	}
+}
+
+func TestApplyFieldMask(t *testing.T) {
+	dst := &DepMessageOptions{Table: "t", Global: true, Operations: []Operation{Operation_OPERATION_GET}}
+	src := &DepMessageOptions{Table: "u", RoutePrefix: "/u", Operations: []Operation{Operation_OPERATION_LIST, Operation_OPERATION_CREATE}}
+	ApplyFieldMask(dst, src, []string{"table", "global", "operations"})
+
+	want := &DepMessageOptions{Table: "u", Operations: []Operation{Operation_OPERATION_LIST, Operation_OPERATION_CREATE}}
+	if !proto.Equal(dst, want) {
+		t.Errorf("got %v, want %v", dst, want)
+	}
+	src.Operations[0] = Operation_OPERATION_DELETE
+	if dst.Operations[0] != Operation_OPERATION_LIST {
+		t.Error("dst shares a list with src")
+	}
+}
+
+func TestMergePatches(t *testing.T) {
+	src := &DepFieldOptions{Label: "Full name", Min: proto.Float64(0), Widget: Widget_WIDGET_EMAIL}
+	remove, store, err := MergePatches(src, []string{"label", "min", "max", "widget"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"label":null,"max":null,"min":null,"widget":null}`; remove != want {
+		t.Errorf("remove: got %s, want %s", remove, want)
+	}
+	if want := `{"label":"Full name","min":0,"widget":"WIDGET_EMAIL"}`; store != want {
+		t.Errorf("store: got %s, want %s", store, want)
+	}
+}
+
+func TestMaskedErrors(t *testing.T) {
+	errs := ValidationErrors{"name": "is required", "address.city": "is required", "seats": "must be positive"}
+	err := MaskedErrors(errs, []string{"address", "seats"})
+	if want := (ValidationErrors{"address.city": "is required", "seats": "must be positive"}); !reflect.DeepEqual(err, want) {
+		t.Errorf("got %v, want %v", err, want)
+	}
+	if err := MaskedErrors(errs, []string{"label"}); err != nil {
+		t.Errorf("unmasked fields: got %v", err)
+	}
+}
+
+func TestPatchColumns(t *testing.T) {
+	m := &DepMessageOptions{Table: "t", Global: true}
+	columns := []string{"table_name", "id_strategy", "global"}
+	set, args, err := PatchColumns(Postgres, 3, m, []string{"global", "table"}, columns, []any{"t", "", true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set != "global = $3, table_name = $4" || !reflect.DeepEqual(args, []any{true, "t"}) {
+		t.Errorf("got %s %v", set, args)
+	}
+	if set, _, _ := PatchColumns(SQLite, 1, m, []string{"table"}, columns, []any{"t", "", true}); set != "table_name = ?" {
+		t.Errorf("sqlite: got %s", set)
+	}
+	if _, _, err := PatchColumns(SQLite, 1, m, []string{"table.x"}, columns, nil); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("nested path: got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package dep
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Page sizes of List, a request asking for more than MaxPageSize gets
+// MaxPageSize records.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 1000
+)
+
+// ErrInvalidArgument is wrapped by the errors List returns for options it
+// cannot use, handlers answer it with a 400.
+var ErrInvalidArgument = errors.New("dep: invalid argument")
+
+// ListOptions selects the page of records List returns, following AIP-158.
+type ListOptions struct {
+	// PageSize is the maximum number of records on the page, zero means
+	// DefaultPageSize.
+	PageSize int
+	// PageToken is the NextPageToken of the previous page, empty for the
+	// first one.
+	PageToken string
+	// Skip leaves out that many records before the page starts.
+	Skip int
+	// Filter selects the records, in the AIP-160 syntax, e.g.
+	// `status = ACTIVE AND name = "A*"`. Only searchable fields can be used.
+	Filter string
+	// OrderBy is a comma separated list of sortable fields, each optionally
+	// followed by desc. Records with equal fields are ordered by id.
+	OrderBy string
+}
+
+// ParseListOptions reads the page_size, page_token, skip, filter and
+// order_by query parameters.
+func ParseListOptions(query url.Values) (ListOptions, error) {
+	var opts ListOptions
+	var err error
+	if v := query.Get("page_size"); v != "" {
+		if opts.PageSize, err = strconv.Atoi(v); err != nil {
+			return opts, fmt.Errorf("%w: page_size %q is not a number", ErrInvalidArgument, v)
+		}
+	}
+	if v := query.Get("skip"); v != "" {
+		if opts.Skip, err = strconv.Atoi(v); err != nil {
+			return opts, fmt.Errorf("%w: skip %q is not a number", ErrInvalidArgument, v)
+		}
+	}
+	opts.PageToken = query.Get("page_token")
+	opts.Filter = query.Get("filter")
+	opts.OrderBy = query.Get("order_by")
+	return opts, nil
+}
+
+// Limit returns the number of records to put on the page.
+func (o ListOptions) Limit() (int, error) {
+	switch {
+	case o.PageSize < 0:
+		return 0, fmt.Errorf("%w: negative page_size", ErrInvalidArgument)
+	case o.Skip < 0:
+		return 0, fmt.Errorf("%w: negative skip", ErrInvalidArgument)
+	case o.PageSize == 0:
+		return DefaultPageSize, nil
+	case o.PageSize > MaxPageSize:
+		return MaxPageSize, nil
+	}
+	return o.PageSize, nil
+}
+
+// Cursor is the position a page token holds, the page continues after the
+// record with id ID, in json, and the order_by fields in Keys. Query
+// identifies the filter and order_by the token was issued for.
+type Cursor struct {
+	ID    json.RawMessage `json:"id"`
+	Keys  []string        `json:"keys,omitempty"`
+	Query string          `json:"q,omitempty"`
+}
+
+// Cursor decodes the page token, the zero Cursor starts at the first
+// record.
+func (o ListOptions) Cursor() (Cursor, error) {
+	var c Cursor
+	if o.PageToken == "" {
+		return c, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(o.PageToken)
+	if err == nil {
+		err = json.Unmarshal(raw, &c)
+	}
+	if err != nil {
+		return c, fmt.Errorf("%w: malformed page_token", ErrInvalidArgument)
+	}
+	return c, nil
+}
+
+// Token encodes c as an opaque page token.
+func (c Cursor) Token() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// Record is a stored message together with its id, K is the type of the ids
+// the id strategy of the resource assigns.
+type Record[T, K any] struct {
+	ID    K `json:"id"`
+	Value T `json:"value"`
+}
+
+type recordJSON[K any] struct {
+	ID    K               `json:"id"`
+	Value json.RawMessage `json:"value"`
+}
+
+// MarshalJSON encodes a message Value with protojson, the way the handlers
+// write a single record.
+func (r Record[T, K]) MarshalJSON() ([]byte, error) {
+	value, err := marshalValue(r.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(recordJSON[K]{ID: r.ID, Value: value})
+}
+
+// UnmarshalJSON reads a record MarshalJSON wrote.
+func (r *Record[T, K]) UnmarshalJSON(data []byte) error {
+	var raw recordJSON[K]
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.ID = raw.ID
+	return unmarshalValue(raw.Value, &r.Value)
+}
+
+// marshalValue encodes v with protojson when it is a message, a nil message
+// has no encoding.
+func marshalValue(v any) (json.RawMessage, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return json.Marshal(v)
+	}
+	if !m.ProtoReflect().IsValid() {
+		return nil, nil
+	}
+	return protojson.Marshal(m)
+}
+
+// unmarshalValue reads into v what marshalValue returned.
+func unmarshalValue[T any](data json.RawMessage, v *T) error {
+	m, ok := any(*v).(proto.Message)
+	if !ok {
+		return json.Unmarshal(data, v)
+	}
+	if len(data) == 0 || string(data) == "null" {
+		var zero T
+		*v = zero
+		return nil
+	}
+	m = m.ProtoReflect().Type().New().Interface()
+	if err := UnmarshalDocument(data, m); err != nil {
+		return err
+	}
+	*v = m.(T)
+	return nil
+}
+
+// Page is what List returns, the records in the requested order.
+type Page[T, K any] struct {
+	Items []Record[T, K] `json:"items"`
+	// NextPageToken is passed as PageToken to get the following page, it is
+	// empty on the last one.
+	NextPageToken string `json:"next_page_token,omitempty"`
+	// TotalSize is the number of records over all pages.
+	TotalSize int `json:"total_size"`
+}
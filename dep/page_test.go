@@ -0,0 +1,70 @@
+package dep
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestListOptions(t *testing.T) {
+	opts, err := ParseListOptions(url.Values{"page_size": {"5000"}, "skip": {"2"}, "page_token": {Cursor{ID: json.RawMessage("7")}.Token()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit, err := opts.Limit(); err != nil || limit != MaxPageSize {
+		t.Errorf("limit: got %d, %v", limit, err)
+	}
+	if c, err := opts.Cursor(); err != nil || string(c.ID) != "7" {
+		t.Errorf("cursor: got %+v, %v", c, err)
+	}
+	if limit, _ := (ListOptions{}).Limit(); limit != DefaultPageSize {
+		t.Errorf("default limit: got %d", limit)
+	}
+
+	for _, bad := range []ListOptions{{PageSize: -1}, {Skip: -1}} {
+		if _, err := bad.Limit(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%+v: got %v", bad, err)
+		}
+	}
+	if _, err := (ListOptions{PageToken: "not a token"}).Cursor(); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("malformed token: got %v", err)
+	}
+	if _, err := ParseListOptions(url.Values{"page_size": {"ten"}}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("page_size ten: got %v", err)
+	}
+}
+
+func TestPageJSON(t *testing.T) {
+	page := Page[*DepMessageOptions, int64]{
+		Items:     []Record[*DepMessageOptions, int64]{{ID: 1, Value: &DepMessageOptions{Table: "users", UiMode: UiMode_UI_MODE_HTMX}}, {ID: 2}},
+		TotalSize: 2,
+	}
+	data, err := json.Marshal(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The values are protojson, with json names and enums by name.
+	var raw struct {
+		Items []struct {
+			Value map[string]any `json:"value"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if len(raw.Items) != 2 || raw.Items[0].Value["uiMode"] != "UI_MODE_HTMX" || raw.Items[1].Value != nil {
+		t.Errorf("got %s", data)
+	}
+
+	var got Page[*DepMessageOptions, int64]
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Items) != 2 || got.Items[0].ID != 1 || !proto.Equal(got.Items[0].Value, page.Items[0].Value) || got.Items[1].Value != nil || got.TotalSize != 2 {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -0,0 +1,592 @@
+package dep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Dialect is the SQL flavour List statements are built for.
+type Dialect int
+
+const (
+	Postgres Dialect = iota
+	SQLite
+)
+
+// SQLiteTime is the layout timestamps are written to SQLite in. julianday
+// and the drivers read it back, modernc.org/sqlite stores a time.Time in the
+// layout of its String method otherwise, which julianday does not.
+const SQLiteTime = "2006-01-02 15:04:05.999999999-07:00"
+
+// ListField is a field List can filter or order by. Expr is the SQL reading
+// it from a row, it has to give the zero value of the field when it is not
+// set, so SQL and Go agree on comparisons.
+type ListField struct {
+	Name   string
+	Expr   string
+	Filter bool
+	Sort   bool
+}
+
+// Schema describes a resource to List: the fields that can be used in
+// filter and order_by and how to read them in SQL.
+type Schema struct {
+	// Message is any value of the resource, only its descriptor is used.
+	Message proto.Message
+	Dialect Dialect
+	Fields  []ListField
+	// ID is any value of the type of the record ids, page tokens hold one.
+	// The ids are int64 when it is nil.
+	ID any
+}
+
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindUint
+	kindFloat
+	kindBool
+	kindEnum
+	kindTime
+)
+
+// field is a ListField resolved against the message descriptor.
+type field struct {
+	ListField
+	desc protoreflect.FieldDescriptor
+	kind kind
+}
+
+const timestampName = "google.protobuf.Timestamp"
+
+func (s *Schema) field(name string) (*field, error) {
+	for _, f := range s.Fields {
+		if f.Name != name {
+			continue
+		}
+		desc := s.Message.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(name))
+		if desc == nil || desc.IsList() || desc.IsMap() {
+			return nil, fmt.Errorf("field %s cannot be listed by", name)
+		}
+		ret := &field{ListField: f, desc: desc}
+		switch desc.Kind() {
+		case protoreflect.StringKind:
+			ret.kind = kindString
+		case protoreflect.BoolKind:
+			ret.kind = kindBool
+		case protoreflect.EnumKind:
+			ret.kind = kindEnum
+		case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+			protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+			ret.kind = kindInt
+		case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+			ret.kind = kindUint
+		case protoreflect.FloatKind, protoreflect.DoubleKind:
+			ret.kind = kindFloat
+		case protoreflect.MessageKind:
+			if desc.Message().FullName() != timestampName {
+				return nil, fmt.Errorf("field %s cannot be listed by", name)
+			}
+			ret.kind = kindTime
+		default:
+			return nil, fmt.Errorf("field %s cannot be listed by", name)
+		}
+		return ret, nil
+	}
+	return nil, fmt.Errorf("unknown field %s", name)
+}
+
+// wide reports whether the field holds 64 bit unsigned integers.
+func (f *field) wide() bool {
+	return f.desc.Kind() == protoreflect.Uint64Kind || f.desc.Kind() == protoreflect.Fixed64Kind
+}
+
+// allows reports whether op can compare the field.
+func (f *field) allows(op string) bool {
+	switch f.kind {
+	case kindBool, kindEnum:
+		return op == "=" || op == "!=" || op == ":"
+	}
+	return true
+}
+
+// parse reads a filter value or cursor key of the field.
+func (f *field) parse(text string) (any, error) {
+	var v any
+	var err error
+	switch f.kind {
+	case kindString:
+		v = text
+	case kindInt:
+		v, err = strconv.ParseInt(text, 10, 64)
+	case kindUint:
+		v, err = strconv.ParseUint(text, 10, 64)
+	case kindFloat:
+		v, err = strconv.ParseFloat(text, 64)
+	case kindBool:
+		v, err = strconv.ParseBool(text)
+	case kindEnum:
+		if f.desc.Enum().Values().ByName(protoreflect.Name(text)) == nil {
+			return nil, fmt.Errorf("%s is not a value of %s", text, f.desc.Enum().Name())
+		}
+		v = text
+	case kindTime:
+		var t time.Time
+		t, err = time.Parse(time.RFC3339Nano, text)
+		v = t.UTC()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid %s", text, f.Name)
+	}
+	return v, nil
+}
+
+// format is the inverse of parse.
+func (f *field) format(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	panic("unreachable")
+}
+
+// get reads the field from m the way parse returns values.
+func (f *field) get(m protoreflect.Message) any {
+	v := m.Get(f.desc)
+	switch f.kind {
+	case kindString:
+		return v.String()
+	case kindInt:
+		return v.Int()
+	case kindUint:
+		return v.Uint()
+	case kindFloat:
+		return v.Float()
+	case kindBool:
+		return v.Bool()
+	case kindEnum:
+		if value := f.desc.Enum().Values().ByNumber(v.Enum()); value != nil {
+			return string(value.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	}
+	if !m.Has(f.desc) {
+		return time.Unix(0, 0).UTC()
+	}
+	ts := v.Message()
+	fields := ts.Descriptor().Fields()
+	return time.Unix(ts.Get(fields.ByName("seconds")).Int(), ts.Get(fields.ByName("nanos")).Int()).UTC()
+}
+
+// compare orders a and b, which hold values of the same field.
+func compare(a, b any) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case int64:
+		return compareOrdered(a, b.(int64))
+	case uint64:
+		return compareOrdered(a, b.(uint64))
+	case float64:
+		return compareOrdered(a, b.(float64))
+	case bool:
+		switch b := b.(bool); {
+		case a == b:
+			return 0
+		case b:
+			return -1
+		}
+		return 1
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+	panic("unreachable")
+}
+
+// compareIDs orders ids of the same type the way databases order the
+// columns holding them: integers by value, strings and the bytes of arrays
+// like UUIDs and ULIDs lexically.
+func compareIDs(a, b any) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(va.Int(), vb.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareOrdered(va.Uint(), vb.Uint())
+	case reflect.String:
+		return strings.Compare(va.String(), vb.String())
+	case reflect.Array:
+		ba, bb := make([]byte, va.Len()), make([]byte, vb.Len())
+		reflect.Copy(reflect.ValueOf(ba), va)
+		reflect.Copy(reflect.ValueOf(bb), vb)
+		return bytes.Compare(ba, bb)
+	}
+	panic(fmt.Sprintf("dep: cannot order ids of type %T", a))
+}
+
+func compareOrdered[T int | int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+type orderTerm struct {
+	field *field
+	desc  bool
+}
+
+// Query is a List request checked against a Schema.
+type Query struct {
+	schema *Schema
+	filter *expr
+	order  []orderTerm
+	limit  int
+	skip   int
+	hash   string
+
+	// after holds the position of the page token, keys follow order.
+	after bool
+	id    any
+	keys  []any
+}
+
+// Query checks the filter, order_by and page token of opts, errors wrap
+// ErrInvalidArgument.
+func (s *Schema) Query(opts ListOptions) (*Query, error) {
+	limit, err := opts.Limit()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := opts.Cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(opts.Filter + "\x00" + opts.OrderBy))
+	q := &Query{schema: s, limit: limit, skip: opts.Skip, hash: strconv.FormatUint(h.Sum64(), 36)}
+
+	if q.filter, err = parseFilter(opts.Filter, s.field); err != nil {
+		return nil, fmt.Errorf("%w: filter: %v", ErrInvalidArgument, err)
+	}
+	if q.order, err = s.parseOrderBy(opts.OrderBy); err != nil {
+		return nil, fmt.Errorf("%w: order_by: %v", ErrInvalidArgument, err)
+	}
+
+	if opts.PageToken != "" {
+		if cursor.Query != q.hash || len(cursor.Keys) != len(q.order) {
+			return nil, fmt.Errorf("%w: page_token was issued for another filter or order_by", ErrInvalidArgument)
+		}
+		if q.id, err = s.parseID(cursor.ID); err != nil {
+			return nil, fmt.Errorf("%w: malformed page_token", ErrInvalidArgument)
+		}
+		q.after = true
+		for i, key := range cursor.Keys {
+			v, err := q.order[i].field.parse(key)
+			if err != nil {
+				return nil, fmt.Errorf("%w: malformed page_token", ErrInvalidArgument)
+			}
+			q.keys = append(q.keys, v)
+		}
+	}
+	return q, nil
+}
+
+// parseID reads the json of an id into a value of the type of ID.
+func (s *Schema) parseID(data json.RawMessage) (any, error) {
+	t := reflect.TypeOf(s.ID)
+	if t == nil {
+		t = reflect.TypeOf(int64(0))
+	}
+	id := reflect.New(t)
+	if err := json.Unmarshal(data, id.Interface()); err != nil {
+		return nil, err
+	}
+	return id.Elem().Interface(), nil
+}
+
+// parseOrderBy reads a comma separated list of fields, each optionally
+// followed by asc or desc.
+func (s *Schema) parseOrderBy(orderBy string) ([]orderTerm, error) {
+	var order []orderTerm
+	if strings.TrimSpace(orderBy) == "" {
+		return nil, nil
+	}
+	for _, part := range strings.Split(orderBy, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, fmt.Errorf("malformed %q", strings.TrimSpace(part))
+		}
+		f, err := s.field(words[0])
+		if err != nil {
+			return nil, err
+		}
+		if !f.Sort {
+			return nil, fmt.Errorf("field %s cannot be ordered by", f.Name)
+		}
+		term := orderTerm{field: f}
+		if len(words) == 2 {
+			switch strings.ToLower(words[1]) {
+			case "asc":
+			case "desc":
+				term.desc = true
+			default:
+				return nil, fmt.Errorf("expected asc or desc after %s", f.Name)
+			}
+		}
+		order = append(order, term)
+	}
+	return order, nil
+}
+
+// builder collects the arguments of a statement.
+type builder struct {
+	dialect Dialect
+	args    []any
+}
+
+func (b *builder) arg(v any) string {
+	// database/sql refuses uint64 values past the int64 range, databases
+	// read their decimal text as well.
+	if u, ok := v.(uint64); ok && u > math.MaxInt64 {
+		v = strconv.FormatUint(u, 10)
+	}
+	if b.dialect == SQLite {
+		if t, ok := v.(time.Time); ok {
+			v = t.UTC().Format(SQLiteTime)
+		}
+		b.args = append(b.args, v)
+		return "?"
+	}
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// column is the SQL of f, or of its value v, in a comparison.
+func (b *builder) column(f *field, sql string) string {
+	if b.dialect == SQLite && f.kind == kindTime {
+		return "julianday(" + sql + ")"
+	}
+	if b.dialect == SQLite && f.wide() {
+		// SQLite keeps 64 bit unsigned integers as text, padded to the
+		// length of the largest one they order like numbers.
+		return "substr('00000000000000000000' || " + sql + ", -20)"
+	}
+	return sql
+}
+
+func (b *builder) condition(e *expr) string {
+	switch e.op {
+	case "AND", "OR":
+		return "(" + b.condition(e.args[0]) + " " + e.op + " " + b.condition(e.args[1]) + ")"
+	case "NOT":
+		return "NOT " + b.condition(e.args[0])
+	}
+
+	column := b.column(e.field, e.field.Expr)
+	position := "strpos"
+	if b.dialect == SQLite {
+		position = "instr"
+	}
+	switch {
+	case e.prefix && e.op == "=":
+		return position + "(" + column + ", " + b.arg(e.value) + ") = 1"
+	case e.prefix:
+		return position + "(" + column + ", " + b.arg(e.value) + ") <> 1"
+	case e.op == ":" && e.field.kind == kindString:
+		return position + "(" + column + ", " + b.arg(e.value) + ") > 0"
+	case e.op == ":":
+		return column + " = " + b.column(e.field, b.arg(e.value))
+	}
+	return column + " " + e.op + " " + b.column(e.field, b.arg(e.value))
+}
+
+// after is the keyset condition selecting the records past the page token.
+// Every comparison gets its own argument, SQLite placeholders cannot be
+// referred to twice.
+func (b *builder) after(q *Query) string {
+	var ors []string
+	for i := 0; i <= len(q.order); i++ {
+		var ands []string
+		for j, term := range q.order[:i] {
+			ands = append(ands, b.column(term.field, term.field.Expr)+" = "+b.column(term.field, b.arg(q.keys[j])))
+		}
+		if i == len(q.order) {
+			ands = append(ands, "id > "+b.arg(q.id))
+		} else {
+			term, op := q.order[i], ">"
+			if term.desc {
+				op = "<"
+			}
+			ands = append(ands, b.column(term.field, term.field.Expr)+" "+op+" "+b.column(term.field, b.arg(q.keys[i])))
+		}
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return strings.Join(ors, " OR ")
+}
+
+func (q *Query) where(b *builder, base string, withCursor bool) string {
+	if q.filter != nil {
+		base += " AND " + b.condition(q.filter)
+	}
+	if withCursor && q.after {
+		base += " AND (" + b.after(q) + ")"
+	}
+	return base
+}
+
+// Count returns the statement counting the records matching the filter.
+// base selects the records of a tenant and ends in a WHERE condition on
+// args.
+func (q *Query) Count(base string, args ...any) (string, []any) {
+	b := &builder{dialect: q.schema.Dialect, args: args}
+	return q.where(b, base, false), b.args
+}
+
+// Select returns the statement reading the page, with one record more than
+// the page size so Paginate can tell whether there is a next page. base is
+// like for Count, selecting id and what the records are scanned from.
+func (q *Query) Select(base string, args ...any) (string, []any) {
+	b := &builder{dialect: q.schema.Dialect, args: args}
+	stmt := q.where(b, base, true)
+
+	var order []string
+	for _, term := range q.order {
+		column := b.column(term.field, term.field.Expr)
+		if term.desc {
+			column += " DESC"
+		}
+		order = append(order, column)
+	}
+	order = append(order, "id")
+	stmt += " ORDER BY " + strings.Join(order, ", ")
+	stmt += " LIMIT " + b.arg(q.limit+1) + " OFFSET " + b.arg(q.skip)
+	return stmt, b.args
+}
+
+// match reports whether m passes e, the Go side of condition.
+func match(e *expr, m protoreflect.Message) bool {
+	switch e.op {
+	case "AND":
+		return match(e.args[0], m) && match(e.args[1], m)
+	case "OR":
+		return match(e.args[0], m) || match(e.args[1], m)
+	case "NOT":
+		return !match(e.args[0], m)
+	}
+
+	v := e.field.get(m)
+	switch {
+	case e.prefix:
+		return strings.HasPrefix(v.(string), e.value.(string)) == (e.op == "=")
+	case e.op == ":" && e.field.kind == kindString:
+		return strings.Contains(v.(string), e.value.(string))
+	}
+	c := compare(v, e.value)
+	switch e.op {
+	case "=", ":":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// compareRecords orders two records the way Select does.
+func (q *Query) compareRecords(aID any, a protoreflect.Message, bID any, bKeys func(int) any) int {
+	for i, term := range q.order {
+		c := compare(term.field.get(a), bKeys(i))
+		if term.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareIDs(aID, bID)
+}
+
+// Paginate cuts page to the page size of q and sets NextPageToken when
+// records were left out. page holds what Select read.
+func Paginate[T proto.Message, K any](q *Query, page *Page[T, K]) {
+	if len(page.Items) <= q.limit {
+		return
+	}
+	page.Items = page.Items[:q.limit]
+
+	last := page.Items[q.limit-1]
+	id, _ := json.Marshal(last.ID)
+	cursor := Cursor{ID: id, Query: q.hash}
+	for _, term := range q.order {
+		cursor.Keys = append(cursor.Keys, term.field.format(term.field.get(last.Value.ProtoReflect())))
+	}
+	page.NextPageToken = cursor.Token()
+}
+
+// ListRecords returns the page of records q selects, for repositories that
+// keep records in memory.
+func ListRecords[T proto.Message, K any](q *Query, records []Record[T, K]) *Page[T, K] {
+	var matched []Record[T, K]
+	for _, r := range records {
+		if q.filter == nil || match(q.filter, r.Value.ProtoReflect()) {
+			matched = append(matched, r)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		b := matched[j]
+		return q.compareRecords(matched[i].ID, matched[i].Value.ProtoReflect(), b.ID, func(k int) any {
+			return q.order[k].field.get(b.Value.ProtoReflect())
+		}) < 0
+	})
+
+	page := &Page[T, K]{TotalSize: len(matched)}
+	skipped := 0
+	for _, r := range matched {
+		if q.after && q.compareRecords(r.ID, r.Value.ProtoReflect(), q.id, func(k int) any { return q.keys[k] }) <= 0 {
+			continue
+		}
+		if skipped < q.skip {
+			skipped++
+			continue
+		}
+		if len(page.Items) > q.limit {
+			break
+		}
+		page.Items = append(page.Items, r)
+	}
+	Paginate(q, page)
+	return page
+}
@@ -0,0 +1,230 @@
+package dep
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// testSchema lists DepFieldOptions, which has a field of most kinds.
+var testSchema = &Schema{
+	Message: new(DepFieldOptions),
+	Fields: []ListField{
+		{Name: "label", Expr: "label", Filter: true, Sort: true},
+		{Name: "widget", Expr: "widget", Filter: true, Sort: true},
+		{Name: "min_len", Expr: "min_len", Filter: true, Sort: true},
+		{Name: "required", Expr: "required", Filter: true},
+		{Name: "min", Expr: "min", Filter: true},
+		{Name: "pattern", Expr: "pattern"},
+	},
+}
+
+func TestQuerySQL(t *testing.T) {
+	tests := []struct {
+		filter, orderBy string
+		where           string
+		args            []any
+	}{
+		{"", "", "WHERE tenant = $1", []any{"t"}},
+		{`label = "Name"`, "", "WHERE tenant = $1 AND label = $2", []any{"t", "Name"}},
+		{`label = "Na*" widget != WIDGET_TEXT`, "", "WHERE tenant = $1 AND (strpos(label, $2) = 1 AND widget != $3)", []any{"t", "Na", "WIDGET_TEXT"}},
+		{"min_len > 2 OR required = true AND -label:x", "", "WHERE tenant = $1 AND ((min_len > $2 OR required = $3) AND NOT strpos(label, $4) > 0)", []any{"t", uint64(2), true, "x"}},
+		{"NOT (min < -1.5 OR min >= 10)", "label desc", "WHERE tenant = $1 AND NOT (min < $2 OR min >= $3)", []any{"t", -1.5, 10.0}},
+	}
+	for _, tt := range tests {
+		q, err := testSchema.Query(ListOptions{Filter: tt.filter, OrderBy: tt.orderBy})
+		if err != nil {
+			t.Errorf("%s: %v", tt.filter, err)
+			continue
+		}
+		stmt, args := q.Count("WHERE tenant = $1", "t")
+		if stmt != tt.where || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s:\ngot  %s %v\nwant %s %v", tt.filter, stmt, args, tt.where, tt.args)
+		}
+	}
+}
+
+func TestQueryInvalid(t *testing.T) {
+	for _, opts := range []ListOptions{
+		{Filter: "nope = 1"},
+		{Filter: "pattern = x"},
+		{Filter: "min_len = many"},
+		{Filter: "widget = WIDGET_NOPE"},
+		{Filter: "widget > WIDGET_TEXT"},
+		{Filter: "label ="},
+		{Filter: `label = "open`},
+		{Filter: "(label = x"},
+		{Filter: "label = x)"},
+		{OrderBy: "required"},
+		{OrderBy: "label sideways"},
+		{OrderBy: "label,"},
+		{PageToken: Cursor{ID: json.RawMessage("1"), Query: "other"}.Token()},
+	} {
+		if _, err := testSchema.Query(opts); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%+v: got %v", opts, err)
+		}
+	}
+}
+
+func TestListRecords(t *testing.T) {
+	var records []Record[*DepFieldOptions, int64]
+	for i, label := range []string{"b", "a", "c", "a", "d"} {
+		records = append(records, Record[*DepFieldOptions, int64]{ID: int64(i + 1), Value: &DepFieldOptions{Label: label, MinLen: uint32(i)}})
+	}
+
+	var got []int64
+	opts := ListOptions{PageSize: 2, Filter: "label != d", OrderBy: "label desc"}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("page tokens do not end")
+		}
+		q, err := testSchema.Query(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page := ListRecords(q, records)
+		if page.TotalSize != 4 {
+			t.Errorf("total_size: got %d, want 4", page.TotalSize)
+		}
+		for _, r := range page.Items {
+			got = append(got, r.ID)
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		opts.PageToken = page.NextPageToken
+	}
+	if want := []int64{3, 1, 2, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got ids %v, want %v", got, want)
+	}
+
+	// A token carries the order_by keys, it continues after the last record
+	// even if that one is gone.
+	q, _ := testSchema.Query(ListOptions{PageSize: 1, OrderBy: "label"})
+	first := ListRecords(q, records)
+	q, err := testSchema.Query(ListOptions{PageSize: 1, OrderBy: "label", PageToken: first.NextPageToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := ListRecords(q, records[2:])
+	if len(second.Items) != 1 || second.Items[0].ID != 4 {
+		t.Errorf("after %v got %v", first.Items, second.Items)
+	}
+}
+
+func TestListRecordsByArrayID(t *testing.T) {
+	// UUIDs and ULIDs are byte arrays, ordered by their bytes.
+	schema := &Schema{Message: new(DepFieldOptions), ID: [2]byte{}}
+	var records []Record[*DepFieldOptions, [2]byte]
+	for _, id := range [][2]byte{{1, 0}, {0, 2}, {0, 1}} {
+		records = append(records, Record[*DepFieldOptions, [2]byte]{ID: id, Value: &DepFieldOptions{}})
+	}
+
+	var got [][2]byte
+	opts := ListOptions{PageSize: 1}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("page tokens do not end")
+		}
+		q, err := schema.Query(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page := ListRecords(q, records)
+		for _, r := range page.Items {
+			got = append(got, r.ID)
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		opts.PageToken = page.NextPageToken
+	}
+	if want := [][2]byte{{0, 1}, {0, 2}, {1, 0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got ids %v, want %v", got, want)
+	}
+
+	q, _ := schema.Query(ListOptions{PageSize: 1})
+	cursor, _ := ListOptions{PageToken: ListRecords(q, records).NextPageToken}.Cursor()
+	cursor.ID = json.RawMessage(`"x"`)
+	if _, err := schema.Query(ListOptions{PageToken: cursor.Token()}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("token with a string id: got %v", err)
+	}
+}
+
+func TestQuerySelect(t *testing.T) {
+	q, err := testSchema.Query(ListOptions{PageSize: 10, OrderBy: "label desc, min_len"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := &Page[*DepFieldOptions, int64]{}
+	for i := 0; i < 11; i++ {
+		page.Items = append(page.Items, Record[*DepFieldOptions, int64]{ID: int64(i + 1), Value: &DepFieldOptions{Label: "x", MinLen: 7}})
+	}
+	Paginate(q, page)
+
+	q, err = testSchema.Query(ListOptions{PageSize: 10, OrderBy: "label desc, min_len", PageToken: page.NextPageToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, args := q.Select("SELECT id FROM t WHERE tenant = $1", "t")
+	want := "SELECT id FROM t WHERE tenant = $1 AND ((label < $2) OR (label = $3 AND min_len > $4) OR (label = $5 AND min_len = $6 AND id > $7))" +
+		" ORDER BY label DESC, min_len, id LIMIT $8 OFFSET $9"
+	if stmt != want {
+		t.Errorf("got  %s\nwant %s", stmt, want)
+	}
+	if want := []any{"t", "x", "x", uint64(7), "x", uint64(7), int64(10), 11, 0}; !reflect.DeepEqual(args, want) {
+		t.Errorf("got args %v, want %v", args, want)
+	}
+
+	sqlite := &Schema{Message: testSchema.Message, Dialect: SQLite, Fields: testSchema.Fields}
+	q, _ = sqlite.Query(ListOptions{Filter: "label:x"})
+	if stmt, _ := q.Select("SELECT id FROM t WHERE tenant = ?", "t"); stmt != "SELECT id FROM t WHERE tenant = ? AND instr(label, ?) > 0 ORDER BY id LIMIT ? OFFSET ?" {
+		t.Errorf("sqlite: %s", stmt)
+	}
+}
+
+func TestQueryUint64(t *testing.T) {
+	s := &Schema{Message: new(wrapperspb.UInt64Value), Dialect: SQLite, Fields: []ListField{{Name: "value", Expr: "value", Filter: true, Sort: true}}}
+	q, err := s.Query(ListOptions{Filter: "value >= 18446744073709551615 OR value = 7", OrderBy: "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, args := q.Select("SELECT id FROM t WHERE tenant = ?", "t")
+	pad := func(sql string) string { return "substr('00000000000000000000' || " + sql + ", -20)" }
+	want := "SELECT id FROM t WHERE tenant = ? AND (" + pad("value") + " >= " + pad("?") + " OR " + pad("value") + " = " + pad("?") + ")" +
+		" ORDER BY " + pad("value") + ", id LIMIT ? OFFSET ?"
+	if stmt != want {
+		t.Errorf("got  %s\nwant %s", stmt, want)
+	}
+	if want := []any{"t", "18446744073709551615", uint64(7), 51, 0}; !reflect.DeepEqual(args, want) {
+		t.Errorf("got args %v, want %v", args, want)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	m := &DepFieldOptions{Label: "Name", Widget: Widget_WIDGET_TEXT, MinLen: 3, Min: proto.Float64(1)}
+	for filter, want := range map[string]bool{
+		"label = Name":                      true,
+		`label = "N*"`:                      true,
+		`label != "N*"`:                     false,
+		"label:am":                          true,
+		"widget = WIDGET_TEXT min_len >= 3": true,
+		"widget = WIDGET_TEXT min_len > 3":  false,
+		"min_len < 2 OR min = 1":            true,
+		"NOT required = true":               true,
+		"-min:1":                            false,
+	} {
+		q, err := testSchema.Query(ListOptions{Filter: filter})
+		if err != nil {
+			t.Errorf("%s: %v", filter, err)
+			continue
+		}
+		if got := match(q.filter, m.ProtoReflect()); got != want {
+			t.Errorf("%s: got %v, want %v", filter, got, want)
+		}
+	}
+}
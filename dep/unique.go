@@ -0,0 +1,65 @@
+package dep
+
+import (
+	"errors"
+	"strings"
+)
+
+// UniqueKey describes a unique index of a table, so AlreadyExists can tell
+// which one a write violated.
+type UniqueKey struct {
+	// Index is the name of the index, which Postgres errors quote and
+	// SQLite errors give for indexes on expressions.
+	Index string
+	// Columns lists the columns of an index on plain columns the way SQLite
+	// errors do, e.g. "hellos.tenant, hellos.email".
+	Columns string
+	// Fields holds the proto names of the fields the index keeps unique.
+	Fields []string
+}
+
+// uniqueViolation is the SQLSTATE of Postgres unique violations.
+const uniqueViolation = "23505"
+
+// sqliteUnique leads the messages of SQLite unique violations.
+const sqliteUnique = "UNIQUE constraint failed: "
+
+// AlreadyExists returns an *AlreadyExistsError for a unique violation
+// reported by a Postgres or SQLite driver, naming the fields of the key of
+// keys it violated. Other errors, and nil, are returned as they are.
+//
+// Postgres drivers are recognised by the SQLState method of their errors,
+// as both pgx and lib/pq have, SQLite drivers by the message.
+func AlreadyExists(err error, keys ...UniqueKey) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) && state.SQLState() == uniqueViolation {
+		for _, key := range keys {
+			if strings.Contains(msg, `"`+key.Index+`"`) {
+				return &AlreadyExistsError{Fields: key.Fields}
+			}
+		}
+		return &AlreadyExistsError{}
+	}
+
+	i := strings.Index(msg, sqliteUnique)
+	if i < 0 {
+		return err
+	}
+	// What failed is either the columns or the index, some drivers follow
+	// it with the error code in parentheses.
+	failed := msg[i+len(sqliteUnique):]
+	if j := strings.Index(failed, " ("); j >= 0 {
+		failed = failed[:j]
+	}
+	for _, key := range keys {
+		if failed == "index '"+key.Index+"'" || key.Columns != "" && failed == key.Columns {
+			return &AlreadyExistsError{Fields: key.Fields}
+		}
+	}
+	return &AlreadyExistsError{}
+}
@@ -0,0 +1,69 @@
+package dep
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// pgError stands in for the errors of Postgres drivers.
+type pgError struct {
+	code, msg string
+}
+
+func (e *pgError) Error() string    { return e.msg }
+func (e *pgError) SQLState() string { return e.code }
+
+func TestAlreadyExists(t *testing.T) {
+	keys := []UniqueKey{
+		{Index: "hellos_email_key", Fields: []string{"email"}},
+		{Index: "order_customer_serial_key", Columns: "order.tenant, order.customer_name, order.serial", Fields: []string{"customer", "serial"}},
+	}
+	for _, c := range []struct {
+		err    error
+		fields []string
+	}{
+		{&pgError{"23505", `ERROR: duplicate key value violates unique constraint "hellos_email_key" (SQLSTATE 23505)`}, []string{"email"}},
+		{fmt.Errorf("create: %w", &pgError{"23505", `pq: duplicate key value violates unique constraint "order_customer_serial_key"`}), []string{"customer", "serial"}},
+		{&pgError{"23505", `duplicate key value violates unique constraint "hellos_pkey"`}, nil},
+		{errors.New("UNIQUE constraint failed: index 'hellos_email_key'"), []string{"email"}},
+		{errors.New("constraint failed: UNIQUE constraint failed: order.tenant, order.customer_name, order.serial (2067)"), []string{"customer", "serial"}},
+		{errors.New("UNIQUE constraint failed: order.tenant, order.customer_name"), nil},
+	} {
+		err := AlreadyExists(c.err, keys...)
+		var exists *AlreadyExistsError
+		if !errors.As(err, &exists) || !errors.Is(err, ErrAlreadyExists) {
+			t.Errorf("%v: got %v", c.err, err)
+			continue
+		}
+		if !reflect.DeepEqual(exists.Fields, c.fields) {
+			t.Errorf("%v: got fields %v, want %v", c.err, exists.Fields, c.fields)
+		}
+	}
+
+	other := &pgError{"23503", "foreign key violation"}
+	if err := AlreadyExists(other, keys...); err != other {
+		t.Errorf("other error: got %v", err)
+	}
+	if err := AlreadyExists(nil, keys...); err != nil {
+		t.Errorf("nil: got %v", err)
+	}
+}
+
+func TestAlreadyExistsError(t *testing.T) {
+	err := &AlreadyExistsError{Fields: []string{"customer", "serial"}}
+	if got := err.Error(); got != "dep: customer and serial already taken" {
+		t.Errorf("got %q", got)
+	}
+	want := ValidationErrors{
+		"customer": "is already taken together with serial",
+		"serial":   "is already taken together with customer",
+	}
+	if got := err.ValidationErrors(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v", got)
+	}
+	if got := (&AlreadyExistsError{}).Error(); got != ErrAlreadyExists.Error() {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,46 @@
+package dep
+
+import (
+	"sort"
+	"strings"
+)
+
+// ValidationErrors holds what is wrong with a record keyed by proto field
+// name. Generated Validate methods return it when a constraint fails.
+type ValidationErrors map[string]string
+
+// Add records msg for field, only the first problem of a field is kept.
+func (e ValidationErrors) Add(field, msg string) {
+	if _, ok := e[field]; !ok {
+		e[field] = msg
+	}
+}
+
+// Err returns e as an error, or nil when nothing was added.
+func (e ValidationErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e ValidationErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	msgs := make([]string, len(fields))
+	for i, field := range fields {
+		msgs[i] = field + " " + e[field]
+	}
+	return strings.Join(msgs, ", ")
+}
+
+// FormData is what generated form templates are executed with, the record
+// and the problems to show next to the inputs of its fields.
+type FormData struct {
+	Value  any
+	Errors ValidationErrors
+}
@@ -0,0 +1,714 @@
+package example
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"protoc-gen-go-dep/dep"
+)
+
+// newServer mounts the Hello routes backed by repo under /{tenant}/hellos.
+func newServer(repo HelloRepository) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/{tenant}", func(r chi.Router) {
+		r.Mount("/hellos", NewHelloHandler(repo).Routes())
+	})
+	return r
+}
+
+func do(t *testing.T, h http.Handler, method, target string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var req *http.Request
+	if form != nil {
+		req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlersWithMemoryRepository(t *testing.T) {
+	repo := NewHelloMemoryRepository()
+	h := newServer(repo)
+
+	rec := do(t, h, http.MethodPost, "/acme/hellos/", url.Values{"Hello__Email": {"ada@example.com"}, "Hello__Name": {"Ada"}})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", rec.Code, rec.Body)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/acme/hellos/1" {
+		t.Errorf("create: got Location %q, want /acme/hellos/1", loc)
+	}
+
+	rec = do(t, h, http.MethodPost, "/acme/hellos/", url.Values{"Hello__Name": {"No email"}})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("create without email: got %d, want 400", rec.Code)
+	}
+
+	rec = do(t, h, http.MethodGet, "/acme/hellos/", nil)
+	var list dep.Page[*Hello, int64]
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 || list.Items[0].ID != 1 || list.Items[0].Value.GetEmail() != "ada@example.com" {
+		t.Errorf("list: %s", rec.Body)
+	}
+
+	rec = do(t, h, http.MethodGet, "/acme/hellos/one", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("get malformed id: got %d, want 404", rec.Code)
+	}
+
+	// Tenants do not see each other's records.
+	rec = do(t, h, http.MethodGet, "/other/hellos/1", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("get from other tenant: got %d, want 404", rec.Code)
+	}
+
+	rec = do(t, h, http.MethodPut, "/acme/hellos/1", url.Values{"Hello__Email": {"ada@example.org"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", rec.Code, rec.Body)
+	}
+	got, _, err := repo.Get(context.Background(), "acme", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetEmail() != "ada@example.org" {
+		t.Errorf("email after update: %q", got.GetEmail())
+	}
+
+	// Records handed out are copies.
+	got.Email = "changed@example.com"
+	if again, _, _ := repo.Get(context.Background(), "acme", 1); again.GetEmail() != "ada@example.org" {
+		t.Errorf("repository record was modified through a copy")
+	}
+
+	rec = do(t, h, http.MethodDelete, "/acme/hellos/1", nil)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("delete: got %d, want 204", rec.Code)
+	}
+	rec = do(t, h, http.MethodDelete, "/acme/hellos/1", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("second delete: got %d, want 404", rec.Code)
+	}
+}
+
+func TestListPages(t *testing.T) {
+	repo := NewHelloMemoryRepository()
+	h := newServer(repo)
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		if _, err := repo.Create(context.Background(), "acme", &Hello{Email: email}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var emails []string
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("page tokens do not end")
+		}
+		rec := do(t, h, http.MethodGet, "/acme/hellos/?page_size=2&page_token="+token, nil)
+		var page dep.Page[*Hello, int64]
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatalf("%v: %s", err, rec.Body)
+		}
+		if page.TotalSize != 3 {
+			t.Errorf("total_size: got %d, want 3", page.TotalSize)
+		}
+		for _, item := range page.Items {
+			emails = append(emails, item.Value.GetEmail())
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		token = page.NextPageToken
+	}
+	if strings.Join(emails, " ") != "a@example.com b@example.com c@example.com" {
+		t.Errorf("pages hold %q", emails)
+	}
+
+	for _, query := range []string{"page_size=-1", "page_size=two", "page_token=nope"} {
+		if rec := do(t, h, http.MethodGet, "/acme/hellos/?"+query, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestListFilter(t *testing.T) {
+	repo := NewHelloMemoryRepository()
+	h := newServer(repo)
+	for _, name := range []string{"Bob", "Ada", "Bea", "Cy"} {
+		if _, err := repo.Create(context.Background(), "acme", &Hello{Email: strings.ToLower(name) + "@example.com", Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	query := url.Values{"filter": {`name = "B*" OR email:cy`}, "order_by": {"name desc"}}
+	rec := do(t, h, http.MethodGet, "/acme/hellos/?"+query.Encode(), nil)
+	var page dep.Page[*Hello, int64]
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatalf("%v: %s", err, rec.Body)
+	}
+	var names []string
+	for _, item := range page.Items {
+		names = append(names, item.Value.GetName())
+	}
+	if strings.Join(names, " ") != "Cy Bob Bea" || page.TotalSize != 3 {
+		t.Errorf("got %q of %d", names, page.TotalSize)
+	}
+
+	for _, query := range []string{"filter=nope%3Dx", "filter=name%3D", "order_by=email"} {
+		if rec := do(t, h, http.MethodGet, "/acme/hellos/?"+query, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestPatch(t *testing.T) {
+	repo := NewHelloMemoryRepository()
+	h := newServer(repo)
+	if _, err := repo.Create(context.Background(), "acme", &Hello{Email: "ada@example.com", Name: "Ada"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A form only changes the fields it submits.
+	rec := do(t, h, http.MethodPatch, "/acme/hellos/1", url.Values{"Hello__Name": {"Ada L."}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("patch form: %d %s", rec.Code, rec.Body)
+	}
+	if got, _, _ := repo.Get(context.Background(), "acme", 1); got.GetEmail() != "ada@example.com" || got.GetName() != "Ada L." {
+		t.Errorf("after form patch: %v", got)
+	}
+
+	patch := func(target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Without an update_mask the fields present in the body are stored.
+	rec = patch("/acme/hellos/1", `{"email": "ada@example.org"}`)
+	var got Hello
+	if err := protojson.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("%v: %s", err, rec.Body)
+	}
+	if got.GetEmail() != "ada@example.org" || got.GetName() != "Ada L." {
+		t.Errorf("after json patch: %v", &got)
+	}
+
+	// update_mask picks from the body, masked fields it lacks are cleared.
+	rec = patch("/acme/hellos/1?update_mask=name", `{"email": "ignored@example.com"}`)
+	if got, _, _ := repo.Get(context.Background(), "acme", 1); rec.Code != http.StatusOK || got.GetEmail() != "ada@example.org" || got.GetName() != "" {
+		t.Errorf("after masked patch: %d %v", rec.Code, got)
+	}
+
+	for target, body := range map[string]string{
+		"/acme/hellos/1?update_mask=nope":  `{}`,
+		"/acme/hellos/1?update_mask=email": `{}`,
+		"/acme/hellos/1":                   `{"email": ""}`,
+	} {
+		if rec := patch(target, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s %s: got %d, want 400", target, body, rec.Code)
+		}
+	}
+	if rec := patch("/acme/hellos/2", `{"name": "x"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown id: got %d, want 404", rec.Code)
+	}
+
+	// The repository validates on its own, without the handlers.
+	var invalid dep.ValidationErrors
+	if _, err := repo.Update(context.Background(), "acme", 1, 0, &Hello{Name: "No email"}); !errors.As(err, &invalid) {
+		t.Errorf("update without email: got %v, want dep.ValidationErrors", err)
+	}
+}
+
+func TestHandleForm(t *testing.T) {
+	form := func(values url.Values) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	// Enums take the number of a defined value, datetime-local inputs keep
+	// their seconds.
+	var task Task
+	if _, err := task.HandleForm(form(url.Values{"Task__Title": {"ship"}, "Task__Priority": {"2"}, "Task__DueAt": {"2024-03-01T09:30:15"}})); err != nil {
+		t.Fatal(err)
+	}
+	if due := time.Date(2024, 3, 1, 9, 30, 15, 0, time.UTC); task.Priority != Priority_PRIORITY_HIGH || !task.DueAt.AsTime().Equal(due) {
+		t.Errorf("form: got %v", &task)
+	}
+
+	var invalid dep.ValidationErrors
+	if _, err := new(Task).HandleForm(form(url.Values{"Task__Title": {"ship"}, "Task__Priority": {"7"}})); !errors.As(err, &invalid) || invalid["priority"] == "" {
+		t.Errorf("undefined enum number: got %v, want a priority error", err)
+	}
+}
+
+func TestProtoJSON(t *testing.T) {
+	h := newServer(NewHelloMemoryRepository())
+
+	send := func(method, target, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Fields go by their json names and unknown ones are skipped.
+	if rec := send(http.MethodPost, "/acme/hellos/", `{"email": "ada@example.com", "nickname": "ada"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s", rec.Code, rec.Body)
+	}
+	if rec := send(http.MethodPatch, "/acme/hellos/1", `{"nickname": "ada"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("patch of an unknown field: got %d, want 400", rec.Code)
+	}
+
+	// Timestamps are written as RFC 3339 strings, in records and in pages.
+	rec := send(http.MethodGet, "/acme/hellos/1", "")
+	var got map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["createdAt"].(string); !ok {
+		t.Errorf("get: %s", rec.Body)
+	}
+	rec = send(http.MethodGet, "/acme/hellos/", "")
+	var page struct {
+		Items []struct {
+			Value map[string]any `json:"value"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 1 || page.Items[0].Value["email"] != "ada@example.com" {
+		t.Errorf("list: %s", rec.Body)
+	} else if _, ok := page.Items[0].Value["createdAt"].(string); !ok {
+		t.Errorf("list: %s", rec.Body)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	repo := NewHelloMemoryRepository()
+	h := newServer(repo)
+	if _, err := repo.Create(context.Background(), "acme", &Hello{Email: "ada@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(method, target string, header http.Header, form url.Values) *httptest.ResponseRecorder {
+		var req *http.Request
+		if form != nil {
+			req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			req = httptest.NewRequest(method, target, nil)
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(http.MethodGet, "/acme/hellos/1", nil, nil)
+	if etag := rec.Header().Get("ETag"); etag != `"1"` {
+		t.Errorf("get: ETag %q, want \"1\"", etag)
+	}
+	rec = send(http.MethodGet, "/acme/hellos/1", http.Header{"If-None-Match": {`"1"`}}, nil)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("get if-none-match: got %d, want 304", rec.Code)
+	}
+
+	form := url.Values{"Hello__Email": {"ada@example.org"}}
+	rec = send(http.MethodPut, "/acme/hellos/1", http.Header{"If-Match": {`"1"`}}, form)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"2"` {
+		t.Fatalf("update: %d %q %s", rec.Code, rec.Header().Get("ETag"), rec.Body)
+	}
+
+	// The writer that read version 1 has lost, whichever way it sends it.
+	if rec := send(http.MethodPut, "/acme/hellos/1", http.Header{"If-Match": {`"1"`}}, form); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale update: got %d, want 412", rec.Code)
+	}
+	if rec := send(http.MethodPatch, "/acme/hellos/1", nil, url.Values{"Hello__Name": {"Ada"}, dep.VersionFormValue: {"1"}}); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale form patch: got %d, want 412", rec.Code)
+	}
+	if rec := send(http.MethodDelete, "/acme/hellos/1", http.Header{"If-Match": {`"1"`}}, nil); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale delete: got %d, want 412", rec.Code)
+	}
+
+	// Edit forms carry the version they were rendered from.
+	rec = send(http.MethodGet, "/acme/hellos/1/edit", nil, nil)
+	if !strings.Contains(rec.Body.String(), `name="_version" value="2"`) {
+		t.Errorf("edit form lacks the version: %s", rec.Body)
+	}
+	rec = send(http.MethodPatch, "/acme/hellos/1", nil, url.Values{"Hello__Name": {"Ada"}, dep.VersionFormValue: {"2"}})
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"3"` {
+		t.Errorf("form patch: %d %q %s", rec.Code, rec.Header().Get("ETag"), rec.Body)
+	}
+
+	// Without a precondition the write goes through.
+	if rec := send(http.MethodDelete, "/acme/hellos/1", nil, nil); rec.Code != http.StatusNoContent {
+		t.Errorf("delete: got %d, want 204", rec.Code)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	repo := NewHelloMemoryRepository()
+	h := newServer(repo)
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		if _, err := repo.Create(context.Background(), "acme", &Hello{Email: email}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := func(target string) []int64 {
+		t.Helper()
+		rec := do(t, h, http.MethodGet, target, nil)
+		var page dep.Page[*Hello, int64]
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatalf("%v: %s", err, rec.Body)
+		}
+		var ids []int64
+		for _, item := range page.Items {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	if rec := do(t, h, http.MethodDelete, "/acme/hellos/1", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: got %d, want 204", rec.Code)
+	}
+	if rec := do(t, h, http.MethodGet, "/acme/hellos/1", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("get deleted: got %d, want 404", rec.Code)
+	}
+	if ids := list("/acme/hellos/"); len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("list: got %v, want [2]", ids)
+	}
+	if ids := list("/acme/hellos/deleted"); len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("trash: got %v, want [1]", ids)
+	}
+
+	// Only records in the trash can be restored or purged.
+	if rec := do(t, h, http.MethodPost, "/acme/hellos/deleted/2/restore", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("restore live: got %d, want 404", rec.Code)
+	}
+	if rec := do(t, h, http.MethodDelete, "/acme/hellos/deleted/2", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("purge live: got %d, want 404", rec.Code)
+	}
+
+	if rec := do(t, h, http.MethodPost, "/acme/hellos/deleted/1/restore", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("restore: got %d, want 204", rec.Code)
+	}
+	rec := do(t, h, http.MethodGet, "/acme/hellos/1", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"1"` {
+		t.Errorf("get restored: %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+
+	do(t, h, http.MethodDelete, "/acme/hellos/1", nil)
+	if rec := do(t, h, http.MethodDelete, "/acme/hellos/deleted/1", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("purge: got %d, want 204", rec.Code)
+	}
+	if ids := list("/acme/hellos/deleted"); len(ids) != 0 {
+		t.Errorf("trash after purge: got %v", ids)
+	}
+	if rec := do(t, h, http.MethodPost, "/acme/hellos/deleted/1/restore", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("restore purged: got %d, want 404", rec.Code)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	repo := NewHelloMemoryRepository()
+	// The actor would come from authentication, here it is a header.
+	h := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(dep.WithActor(req.Context(), req.Header.Get("X-User"))))
+		})
+	}(newServer(repo))
+
+	send := func(method, target, user, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", user)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code >= 300 {
+			t.Fatalf("%s %s: %d %s", method, target, rec.Code, rec.Body)
+		}
+		return rec
+	}
+
+	forged := `"createdAt": "2000-01-01T00:00:00Z", "createdBy": "mallory", "updatedBy": "mallory"`
+	send(http.MethodPost, "/acme/hellos/", "ada", `{"email": "ada@example.com", `+forged+`}`)
+	created, _, err := repo.Get(context.Background(), "acme", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.GetCreatedBy() != "ada" || created.GetUpdatedBy() != "ada" || created.GetCreatedAt().AsTime().Year() == 2000 {
+		t.Fatalf("create: %v", created)
+	}
+	if !created.GetCreatedAt().AsTime().Equal(created.GetUpdatedAt().AsTime()) {
+		t.Errorf("create: created at %v, updated at %v", created.GetCreatedAt(), created.GetUpdatedAt())
+	}
+
+	// Later writes only move the updated fields, and answer with the ones
+	// recording the creation as stored.
+	rec := send(http.MethodPut, "/acme/hellos/1", "bea", `{"email": "ada@example.org", `+forged+`}`)
+	updated := new(Hello)
+	if err := protojson.Unmarshal(rec.Body.Bytes(), updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.GetCreatedBy() != "ada" || !updated.GetCreatedAt().AsTime().Equal(created.GetCreatedAt().AsTime()) || updated.GetUpdatedBy() != "bea" {
+		t.Errorf("update response: %s", rec.Body)
+	}
+	send(http.MethodPatch, "/acme/hellos/1", "cy", `{"name": "Ada", `+forged+`}`)
+	got, _, _ := repo.Get(context.Background(), "acme", 1)
+	if got.GetCreatedBy() != "ada" || !got.GetCreatedAt().AsTime().Equal(created.GetCreatedAt().AsTime()) {
+		t.Errorf("created fields changed: %v", got)
+	}
+	if got.GetUpdatedBy() != "cy" || got.GetEmail() != "ada@example.org" || got.GetName() != "Ada" {
+		t.Errorf("after patch: %v", got)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	repo := NewHelloMemoryRepository()
+	h := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(dep.WithActor(req.Context(), req.Header.Get("X-User"))))
+		})
+	}(newServer(repo))
+
+	send := func(method, target, user string, header http.Header, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", user)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code >= 300 {
+			t.Fatalf("%s %s: %d %s", method, target, rec.Code, rec.Body)
+		}
+		return rec
+	}
+
+	send(http.MethodPost, "/acme/hellos/", "ada", nil, `{"email": "ada@example.com"}`)
+	send(http.MethodPut, "/acme/hellos/1", "bea", nil, `{"email": "ada@example.org"}`)
+	send(http.MethodDelete, "/acme/hellos/1", "cy", nil, "")
+
+	// Who changed the email and when.
+	var revisions []dep.Revision[*Hello]
+	rec := send(http.MethodGet, "/acme/hellos/1/history", "", nil, "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &revisions); err != nil {
+		t.Fatalf("%v: %s", err, rec.Body)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("got %d revisions, want 3: %s", len(revisions), rec.Body)
+	}
+	for i, want := range []struct{ operation, actor string }{
+		{dep.RevisionCreate, "ada"},
+		{dep.RevisionUpdate, "bea"},
+		{dep.RevisionDelete, "cy"},
+	} {
+		if r := revisions[i]; r.Revision != int64(i+1) || r.Operation != want.operation || r.Actor != want.actor || r.ChangedAt.IsZero() {
+			t.Errorf("revision %d: got %+v", i+1, r)
+		}
+	}
+	if update := revisions[1]; update.Old.GetEmail() != "ada@example.com" || update.New.GetEmail() != "ada@example.org" {
+		t.Errorf("update: got %v to %v", update.Old, update.New)
+	}
+	if revisions[2].New != nil {
+		t.Errorf("delete: got %v, want no value", revisions[2].New)
+	}
+
+	rec = send(http.MethodGet, "/acme/hellos/1/history", "", http.Header{"Hx-Request": {"true"}}, "")
+	if body := rec.Body.String(); !strings.Contains(body, "<span>email</span> <del>ada@example.com</del> <ins>ada@example.org</ins>") {
+		t.Errorf("diff view does not show the email change:\n%s", body)
+	}
+
+	var first Hello
+	rec = send(http.MethodGet, "/acme/hellos/1/history/1", "", nil, "")
+	if err := protojson.Unmarshal(rec.Body.Bytes(), &first); err != nil || first.GetEmail() != "ada@example.com" {
+		t.Errorf("revision 1: %v %s", err, rec.Body)
+	}
+	for _, target := range []string{"/acme/hellos/1/history/3", "/acme/hellos/1/history/x", "/acme/hellos/2/history"} {
+		if rec := do(t, h, http.MethodGet, target, nil); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: got %d, want 404", target, rec.Code)
+		}
+	}
+}
+
+func TestUnique(t *testing.T) {
+	repo := NewHelloMemoryRepository()
+	h := newServer(repo)
+	ctx := context.Background()
+	for _, email := range []string{"ada@example.com", "bob@example.com"} {
+		if _, err := repo.Create(ctx, "acme", &Hello{Email: email}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := do(t, h, http.MethodPost, "/acme/hellos/", url.Values{"Hello__Email": {"ada@example.com"}})
+	if rec.Code != http.StatusConflict {
+		t.Errorf("create taken: got %d, want 409", rec.Code)
+	}
+	// Emails are unique within a tenant.
+	if rec := do(t, h, http.MethodPost, "/other/hellos/", url.Values{"Hello__Email": {"ada@example.com"}}); rec.Code != http.StatusCreated {
+		t.Errorf("create in other tenant: got %d, want 201", rec.Code)
+	}
+	if _, err := repo.Update(ctx, "acme", 2, 0, &Hello{Email: "ada@example.com"}); !errors.Is(err, dep.ErrAlreadyExists) {
+		t.Errorf("update taken: got %v, want ErrAlreadyExists", err)
+	}
+	// A record keeps its own values.
+	if _, err := repo.Update(ctx, "acme", 1, 0, &Hello{Email: "ada@example.com", Name: "Ada"}); err != nil {
+		t.Errorf("update own: %v", err)
+	}
+
+	x, id, version, err := repo.GetByEmail(ctx, "acme", "ada@example.com")
+	if err != nil || id != 1 || version != 2 || x.Name != "Ada" {
+		t.Errorf("GetByEmail: got %v %d %d %v", x, id, version, err)
+	}
+	if _, _, _, err := repo.GetByEmail(ctx, "acme", "eve@example.com"); !errors.Is(err, dep.ErrNotFound) {
+		t.Errorf("GetByEmail unknown: got %v, want ErrNotFound", err)
+	}
+
+	// Deleted records free their values, until they are restored.
+	if err := repo.Delete(ctx, "acme", 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Create(ctx, "acme", &Hello{Email: "bob@example.com"}); err != nil {
+		t.Fatalf("create freed: %v", err)
+	}
+	if rec := do(t, h, http.MethodPost, "/acme/hellos/deleted/2/restore", nil); rec.Code != http.StatusConflict {
+		t.Errorf("restore taken: got %d, want 409", rec.Code)
+	}
+
+	// htmx gets the form back with the field marked.
+	req := httptest.NewRequest(http.MethodPost, "/acme/hellos/", strings.NewReader(url.Values{"Hello__Email": {"ada@example.com"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("htmx create taken: got %d, want 409", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `value="ada@example.com"`) || !strings.Contains(body, `<small class="error">is already taken</small>`) {
+		t.Errorf("htmx create taken: got %s", body)
+	}
+}
+
+// TestUniqueUnset checks that the memory repository leaves zero values out of
+// unique indexes, like the NULLs of the SQL ones, and that creates failing on
+// taken values do not use up an id.
+func TestUniqueUnset(t *testing.T) {
+	repo := NewNoteMemoryRepository()
+	ctx := context.Background()
+
+	first, err := repo.Create(ctx, "acme", &Note{Text: "a", Slug: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Create(ctx, "acme", &Note{Text: "b", Slug: "a"}); !errors.Is(err, dep.ErrAlreadyExists) {
+		t.Errorf("create taken: got %v, want ErrAlreadyExists", err)
+	}
+	for _, text := range []string{"c", "d"} {
+		if id, err := repo.Create(ctx, "acme", &Note{Text: text}); err != nil {
+			t.Errorf("create unset: %v", err)
+		} else if id != first+1 {
+			t.Errorf("create unset: got id %d, want %d", id, first+1)
+		}
+		first++
+	}
+	if err := repo.Update(ctx, "acme", first, &Note{Text: "d"}); err != nil {
+		t.Errorf("update unset: %v", err)
+	}
+	if _, _, err := repo.GetBySlug(ctx, "acme", ""); !errors.Is(err, dep.ErrNotFound) {
+		t.Errorf("GetBySlug unset: got %v, want ErrNotFound", err)
+	}
+}
+
+// emptyDB is a database/sql driver standing in for a database without
+// records, its queries return no rows and its statements touch none.
+type emptyDB struct{}
+
+func (emptyDB) Connect(context.Context) (driver.Conn, error) { return emptyDB{}, nil }
+func (emptyDB) Driver() driver.Driver                        { return nil }
+func (emptyDB) Prepare(string) (driver.Stmt, error)          { return emptyDB{}, nil }
+func (emptyDB) Begin() (driver.Tx, error)                    { return emptyDB{}, nil }
+func (emptyDB) Commit() error                                { return nil }
+func (emptyDB) Rollback() error                              { return nil }
+func (emptyDB) Close() error                                 { return nil }
+func (emptyDB) NumInput() int                                { return -1 }
+func (emptyDB) Exec([]driver.Value) (driver.Result, error)   { return driver.RowsAffected(0), nil }
+func (emptyDB) Query([]driver.Value) (driver.Rows, error)    { return emptyDB{}, nil }
+func (emptyDB) Columns() []string                            { return nil }
+func (emptyDB) Next([]driver.Value) error                    { return io.EOF }
+
+// TestNotFound runs the SQL repository of the unversioned Note, on a database
+// without records, and the memory one through the same calls, both report
+// unknown ids with dep.ErrNotFound and the handlers answer them with a 404.
+func TestNotFound(t *testing.T) {
+	db := sql.OpenDB(emptyDB{})
+	defer db.Close()
+
+	ctx := context.Background()
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"text"}}
+	for name, repo := range map[string]NoteRepository{
+		"sql":    NewNoteSQLRepository(db),
+		"memory": NewNoteMemoryRepository(),
+	} {
+		if _, err := repo.Get(ctx, "acme", 1); !errors.Is(err, dep.ErrNotFound) {
+			t.Errorf("%s get: got %v, want dep.ErrNotFound", name, err)
+		}
+		if err := repo.Update(ctx, "acme", 1, &Note{Text: "x"}); !errors.Is(err, dep.ErrNotFound) {
+			t.Errorf("%s update: got %v, want dep.ErrNotFound", name, err)
+		}
+		if _, err := repo.Patch(ctx, "acme", 1, &Note{Text: "x"}, mask); !errors.Is(err, dep.ErrNotFound) {
+			t.Errorf("%s patch: got %v, want dep.ErrNotFound", name, err)
+		}
+		if err := repo.Delete(ctx, "acme", 1); !errors.Is(err, dep.ErrNotFound) {
+			t.Errorf("%s delete: got %v, want dep.ErrNotFound", name, err)
+		}
+
+		r := chi.NewRouter()
+		r.Route("/{tenant}", func(r chi.Router) {
+			r.Mount("/notes", NewNoteHandler(repo).Routes())
+		})
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+			req := httptest.NewRequest(method, "/acme/notes/1", strings.NewReader(`{"text": "x"}`))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("%s %s: got %d, want 404", name, method, rec.Code)
+			}
+		}
+	}
+}
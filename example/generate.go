@@ -1,3 +1,3 @@
 package example
 
-//go:generate protoc --go-dep_out=. --go-dep_opt=paths=source_relative --go_out=. --go_opt=paths=source_relative -I . example.proto
+//go:generate protoc --go-dep_out=. --go-dep_opt=paths=source_relative --go_out=. --go_opt=paths=source_relative -I . -I ../proto/options example.proto
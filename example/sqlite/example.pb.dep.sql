@@ -0,0 +1,53 @@
+-- Code generated by protoc-gen-go-dep. DO NOT EDIT.
+-- source: example.proto
+
+-- Hello records, one document per row.
+CREATE TABLE IF NOT EXISTS hellos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant TEXT NOT NULL,
+    deleted_at DATETIME,
+    version INTEGER NOT NULL DEFAULT 1,
+    data TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS hellos_tenant_idx ON hellos (tenant);
+CREATE UNIQUE INDEX IF NOT EXISTS hellos_email_key ON hellos (tenant, (NULLIF(COALESCE(json_extract(data, '$.email'), ''), ''))) WHERE deleted_at IS NULL;
+
+-- Revisions of hellos, the values before and after every write.
+CREATE TABLE IF NOT EXISTS hellos_history (
+    tenant TEXT NOT NULL,
+    id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    operation TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    old_data TEXT,
+    new_data TEXT,
+    PRIMARY KEY (tenant, id, revision)
+);
+
+-- Note records, one document per row.
+CREATE TABLE IF NOT EXISTS notes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant TEXT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS notes_tenant_idx ON notes (tenant);
+CREATE UNIQUE INDEX IF NOT EXISTS notes_slug_key ON notes (tenant, (NULLIF(COALESCE(json_extract(data, '$.slug'), ''), '')));
+
+-- Task records, one column per field.
+CREATE TABLE IF NOT EXISTS tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    title TEXT NOT NULL,
+    priority TEXT NOT NULL,
+    due_at DATETIME,
+    estimate TEXT NOT NULL,
+    tags TEXT NOT NULL,
+    steps TEXT NOT NULL,
+    created_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS tasks_tenant_idx ON tasks (tenant);
@@ -0,0 +1,5 @@
+// Package sqlite holds the example resources generated with db=sqlite, its
+// tests run them against the generated schema in an in-memory database.
+package sqlite
+
+//go:generate protoc --go-dep_out=. --go-dep_opt=paths=source_relative,db=sqlite,Mexample.proto=protoc-gen-go-dep/example/sqlite;sqlite --go_out=. --go_opt=paths=source_relative,Mexample.proto=protoc-gen-go-dep/example/sqlite;sqlite -I .. -I ../../proto/options example.proto
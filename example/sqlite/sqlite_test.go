@@ -0,0 +1,309 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	_ "modernc.org/sqlite"
+
+	"protoc-gen-go-dep/dep"
+)
+
+// openDB returns an in-memory SQLite database holding the generated schema.
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection opens a database of its own.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	schema, err := os.ReadFile("example.pb.dep.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(string(schema)); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestHello(t *testing.T) {
+	ctx := dep.WithActor(context.Background(), "ada")
+	repo := NewHelloSQLRepository(openDB(t))
+
+	id, err := repo.Create(ctx, "acme", &Hello{Email: "ada@example.com", Name: "Ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Create(ctx, "acme", &Hello{Email: "bea@example.com", Name: "Bea"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Create(ctx, "acme", &Hello{Email: "ada@example.com"}); !errors.Is(err, dep.ErrAlreadyExists) {
+		t.Errorf("create taken email: got %v, want ErrAlreadyExists", err)
+	}
+	if _, err := repo.Create(ctx, "other", &Hello{Email: "ada@example.com"}); err != nil {
+		t.Errorf("create in another tenant: %v", err)
+	}
+
+	got, version, err := repo.Get(ctx, "acme", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetEmail() != "ada@example.com" || version != 1 || got.GetCreatedBy() != "ada" || got.GetCreatedAt() == nil {
+		t.Errorf("get: %v version %d", got, version)
+	}
+	if _, _, err := repo.Get(ctx, "other", id+1); !errors.Is(err, dep.ErrNotFound) {
+		t.Errorf("get from another tenant: got %v, want ErrNotFound", err)
+	}
+
+	page, err := repo.List(ctx, "acme", dep.ListOptions{OrderBy: "name desc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.TotalSize != 2 || len(page.Items) != 2 || page.Items[0].Value.GetName() != "Bea" {
+		t.Errorf("list: %+v", page)
+	}
+	page, err = repo.List(ctx, "acme", dep.ListOptions{Filter: `email = "ada@example.com"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != id {
+		t.Errorf("list filtered: %+v", page)
+	}
+
+	update := &Hello{Email: "ada@example.org", Name: "Ada"}
+	if version, err = repo.Update(dep.WithActor(ctx, "bea"), "acme", id, 1, update); err != nil || version != 2 {
+		t.Fatalf("update: version %d, %v", version, err)
+	}
+	if update.GetCreatedBy() != "ada" || update.GetUpdatedBy() != "bea" {
+		t.Errorf("update: got %v, want the creation kept", update)
+	}
+	if _, err := repo.Update(ctx, "acme", id, 1, &Hello{Email: "ada@example.net"}); !errors.Is(err, dep.ErrConflict) {
+		t.Errorf("stale update: got %v, want ErrConflict", err)
+	}
+	got, found, version, err := repo.GetByEmail(ctx, "acme", "ada@example.org")
+	if err != nil || found != id || version != 2 || got.GetCreatedBy() != "ada" {
+		t.Errorf("get by email: %v id %d version %d, %v", got, found, version, err)
+	}
+
+	revisions, err := repo.History(ctx, "acme", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 || revisions[1].Actor != "bea" || revisions[1].Old.GetEmail() != "ada@example.com" {
+		t.Errorf("history: %+v", revisions)
+	}
+
+	patched, version, err := repo.Patch(ctx, "acme", id, 2, &Hello{Name: "Ada L."}, &fieldmaskpb.FieldMask{Paths: []string{"name"}})
+	if err != nil || version != 3 || patched.GetName() != "Ada L." || patched.GetEmail() != "ada@example.org" {
+		t.Errorf("patch: %v version %d, %v", patched, version, err)
+	}
+
+	if err := repo.Delete(ctx, "acme", id, 2); !errors.Is(err, dep.ErrConflict) {
+		t.Errorf("stale delete: got %v, want ErrConflict", err)
+	}
+	if err := repo.Delete(ctx, "acme", id, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := repo.Get(ctx, "acme", id); !errors.Is(err, dep.ErrNotFound) {
+		t.Errorf("get deleted: got %v, want ErrNotFound", err)
+	}
+	trash, err := repo.ListDeleted(ctx, "acme", dep.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash.Items) != 1 || trash.Items[0].ID != id {
+		t.Errorf("trash: %+v", trash)
+	}
+
+	if err := repo.Restore(ctx, "acme", id); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := repo.Get(ctx, "acme", id); err != nil {
+		t.Errorf("get restored: %v", err)
+	}
+	if err := repo.Delete(ctx, "acme", id, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Purge(ctx, "acme", id); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Restore(ctx, "acme", id); !errors.Is(err, dep.ErrNotFound) {
+		t.Errorf("restore purged: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestNote(t *testing.T) {
+	ctx := context.Background()
+	repo := NewNoteSQLRepository(openDB(t))
+
+	id, err := repo.Create(ctx, "acme", &Note{Text: "first", Slug: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Notes without a slug do not take one.
+	for i := 0; i < 2; i++ {
+		if _, err := repo.Create(ctx, "acme", &Note{Text: "unnamed"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.Update(ctx, "acme", id, &Note{Text: "changed", Slug: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.Get(ctx, "acme", id)
+	if err != nil || got.GetText() != "changed" {
+		t.Errorf("get: %v %v", got, err)
+	}
+	if err := repo.Update(ctx, "acme", id+10, &Note{Text: "missing"}); !errors.Is(err, dep.ErrNotFound) {
+		t.Errorf("update unknown: got %v, want ErrNotFound", err)
+	}
+
+	if err := repo.Delete(ctx, "acme", id); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, "acme", id); !errors.Is(err, dep.ErrNotFound) {
+		t.Errorf("delete twice: got %v, want ErrNotFound", err)
+	}
+	page, err := repo.List(ctx, "acme", dep.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.TotalSize != 2 {
+		t.Errorf("list: got %d notes, want 2", page.TotalSize)
+	}
+}
+
+func TestTask(t *testing.T) {
+	ctx := context.Background()
+	repo := NewTaskSQLRepository(openDB(t))
+
+	due := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	task := &Task{
+		Title:    "ship",
+		Priority: Priority_PRIORITY_HIGH,
+		DueAt:    timestamppb.New(due),
+		Estimate: math.MaxUint64,
+		Tags:     []string{"release", "urgent"},
+		Steps: map[string]*Step{
+			"build": {Text: "build it", DoneAt: timestamppb.New(due.Add(-time.Hour))},
+			"tag":   {Text: "tag it"},
+		},
+	}
+	id, err := repo.Create(ctx, "acme", task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, title := range []string{"plan", "test", "write"} {
+		next := &Task{Title: title, Priority: Priority_PRIORITY_LOW, DueAt: timestamppb.New(due.AddDate(0, 0, i+1)), Estimate: uint64(i + 1)}
+		if _, err := repo.Create(ctx, "acme", next); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, version, err := repo.Get(ctx, "acme", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 || got.GetCreatedAt() == nil {
+		t.Errorf("get: %v version %d", got, version)
+	}
+	got.CreatedAt, task.CreatedAt = nil, nil
+	if !proto.Equal(got, task) {
+		t.Errorf("get: got %v, want %v", got, task)
+	}
+
+	for _, tc := range []struct {
+		filter string
+		want   []string
+	}{
+		{filter: "priority = PRIORITY_HIGH", want: []string{"ship"}},
+		{filter: `due_at > "2024-03-02T12:00:00Z"`, want: []string{"test", "write"}},
+		{filter: "estimate > 2", want: []string{"ship", "write"}},
+		{filter: `title = "t*"`, want: []string{"test"}},
+	} {
+		page, err := repo.List(ctx, "acme", dep.ListOptions{Filter: tc.filter, OrderBy: "title"})
+		if err != nil {
+			t.Fatalf("list %s: %v", tc.filter, err)
+		}
+		var titles []string
+		for _, item := range page.Items {
+			titles = append(titles, item.Value.GetTitle())
+		}
+		if !equalStrings(titles, tc.want) {
+			t.Errorf("list %s: got %v, want %v", tc.filter, titles, tc.want)
+		}
+	}
+
+	var titles []string
+	opts := dep.ListOptions{PageSize: 3, OrderBy: "due_at desc"}
+	for {
+		page, err := repo.List(ctx, "acme", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range page.Items {
+			titles = append(titles, item.Value.GetTitle())
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		opts.PageToken = page.NextPageToken
+	}
+	if want := []string{"write", "test", "plan", "ship"}; !equalStrings(titles, want) {
+		t.Errorf("list pages: got %v, want %v", titles, want)
+	}
+
+	patch := &Task{Title: "ship it", Steps: map[string]*Step{"push": {Text: "push it"}}}
+	patched, version, err := repo.Patch(ctx, "acme", id, 1, patch, &fieldmaskpb.FieldMask{Paths: []string{"title", "steps"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 2 || patched.GetTitle() != "ship it" || len(patched.GetSteps()) != 1 ||
+		patched.GetEstimate() != math.MaxUint64 || !patched.GetDueAt().AsTime().Equal(due) {
+		t.Errorf("patch: %v version %d", patched, version)
+	}
+
+	patched.Priority = Priority_PRIORITY_LOW
+	if _, err := repo.Update(ctx, "acme", id, 1, patched); !errors.Is(err, dep.ErrConflict) {
+		t.Errorf("stale update: got %v, want ErrConflict", err)
+	}
+	if version, err = repo.Update(ctx, "acme", id, 2, patched); err != nil || version != 3 {
+		t.Fatalf("update: version %d, %v", version, err)
+	}
+	page, err := repo.List(ctx, "acme", dep.ListOptions{Filter: "priority = PRIORITY_HIGH"})
+	if err != nil || len(page.Items) != 0 {
+		t.Errorf("list after update: %+v, %v", page, err)
+	}
+
+	if err := repo.Delete(ctx, "acme", id, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := repo.Get(ctx, "acme", id); !errors.Is(err, dep.ErrNotFound) {
+		t.Errorf("get deleted: got %v, want ErrNotFound", err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}